@@ -6,13 +6,22 @@ import (
 	"net/http"
 
 	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
 	"github.com/gin-gonic/gin"
 )
 
+// ErrInvalidAccountNumber is returned when an account number is malformed or its check digits do not match.
+var ErrInvalidAccountNumber = errors.New("invalid account number")
+
+// accountNumberAttempts is the number of attempts to generate a unique account number.
+const accountNumberAttempts = 3
+
 // CreateAccountRequest holds parameters for createAccount handler.
+// The account is owned by the authenticated user.
 type CreateAccountRequest struct {
-	Owner    string `json:"owner" binding:"required,ascii"`
 	Currency string `json:"currency" binding:"required,uppercase"`
+	// AccountType defaults to checking, system accounts can not be created by users.
+	AccountType string `json:"account_type" binding:"omitempty,oneof=checking savings business"`
 }
 
 func (s *Server) createAccount(c *gin.Context) {
@@ -23,18 +32,41 @@ func (s *Server) createAccount(c *gin.Context) {
 		return
 	}
 
+	if req.AccountType == "" {
+		req.AccountType = db.AccountTypeChecking
+	}
+
 	// store the new account into the database
 	params := db.CreateAccountParams{
-		Owner:    req.Owner,
-		Balance:  0,
-		Currency: req.Currency,
+		Owner:       authPayload(c).Username,
+		Balance:     0,
+		Currency:    req.Currency,
+		AccountType: req.AccountType,
 	}
 
-	account, err := s.store.CreateAccount(c, params)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse(err))
+	var account db.Account
 
-		return
+	// regenerate the account number in the unlikely case of a collision
+	for i := 0; i < accountNumberAttempts; i++ {
+		number, err := util.NewAccountNumber()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
+
+		params.Number = number
+
+		account, err = s.store.OpenAccountTx(c, params)
+		if err == nil {
+			break
+		}
+
+		if !isUniqueViolation(err) || i == accountNumberAttempts-1 {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, account)
@@ -54,7 +86,35 @@ func (s *Server) getAccountByID(c *gin.Context) {
 	}
 
 	// query the account ID
-	account, err := s.store.GetAccount(c, req.ID)
+	account, ok := s.authorizeAccount(c, req.ID, permView)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// GetAccountByNumberRequest holds parameters for getAccountByNumber handler.
+type GetAccountByNumberRequest struct {
+	Number string `uri:"number" binding:"required"`
+}
+
+func (s *Server) getAccountByNumber(c *gin.Context) {
+	var req GetAccountByNumberRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	// typos are caught by the check digits without a database round trip
+	if !util.ValidAccountNumber(req.Number) {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidAccountNumber))
+
+		return
+	}
+
+	account, err := s.store.GetAccountByNumber(c, req.Number)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, errorResponse(err))
@@ -65,6 +125,11 @@ func (s *Server) getAccountByID(c *gin.Context) {
 		return
 	}
 
+	account, ok := s.authorizeAccount(c, account.ID, permView)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, account)
 }
 
@@ -74,6 +139,7 @@ type ListAccountsRequest struct {
 	PageSize int32 `form:"page_size" binding:"required,min=1,max=1000"`
 }
 
+// listAccounts lists the accounts held by the authenticated user.
 func (s *Server) listAccounts(c *gin.Context) {
 	var req ListAccountsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -84,6 +150,7 @@ func (s *Server) listAccounts(c *gin.Context) {
 
 	// query accounts
 	params := db.ListAccountsParams{
+		Owner:  authPayload(c).Username,
 		Limit:  req.PageSize,
 		Offset: (req.PageNum - 1) * req.PageSize,
 	}
@@ -127,6 +194,17 @@ func (s *Server) updateAccount(c *gin.Context) {
 		return
 	}
 
+	account, ok := s.authorizeAccount(c, reqURI.ID, permManage)
+	if !ok {
+		return
+	}
+
+	if account.FrozenAt.Valid {
+		c.JSON(http.StatusUnprocessableEntity, errorResponse(db.ErrAccountFrozen))
+
+		return
+	}
+
 	account, err := s.store.UpdateAccountBalance(c, db.UpdateAccountBalanceParams{
 		ID:      reqURI.ID,
 		Balance: reqJSON.Balance,
@@ -157,12 +235,14 @@ func (s *Server) deleteAccount(c *gin.Context) {
 		return
 	}
 
-	if err := s.store.DeleteAccount(c, req.ID); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			c.JSON(http.StatusNotFound, errorResponse(err))
-		} else {
-			c.JSON(http.StatusInternalServerError, errorResponse(err))
-		}
+	if _, ok := s.authorizeAccount(c, req.ID, permOwn); !ok {
+		return
+	}
+
+	if _, err := s.store.CloseAccountTx(c, req.ID); err != nil {
+		c.JSON(transferErrorStatus(err), errorResponse(err))
+
+		return
 	}
 
 	c.JSON(http.StatusOK, nil)
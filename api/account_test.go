@@ -15,6 +15,7 @@ import (
 	"github.com/chutommy/simple-bank/db/mocks"
 	db "github.com/chutommy/simple-bank/db/sqlc"
 	"github.com/chutommy/simple-bank/util"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -30,21 +31,22 @@ func TestServer_CreateAccount(t *testing.T) {
 	tests := []struct {
 		name          string
 		apiRequest    api.CreateAccountRequest
+		noAuth        bool
 		buildStub     func(store *mocks.Store)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			apiRequest: api.CreateAccountRequest{
-				Owner:    account.Owner,
 				Currency: account.Currency,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("CreateAccount", mock.Anything, db.CreateAccountParams{
-					Owner:    account.Owner,
-					Balance:  0,
-					Currency: account.Currency,
-				}).Return(db.Account{
+				store.On("OpenAccountTx", mock.Anything, matchCreateAccountParams(db.CreateAccountParams{
+					Owner:       account.Owner,
+					Balance:     0,
+					Currency:    account.Currency,
+					AccountType: db.AccountTypeChecking,
+				})).Return(db.Account{
 					Owner:    account.Owner,
 					Currency: account.Currency,
 					Balance:  0,
@@ -59,10 +61,72 @@ func TestServer_CreateAccount(t *testing.T) {
 				assert.Equal(t, int64(0), resultAccount.Balance)
 			},
 		},
+		{
+			name: "Savings",
+			apiRequest: api.CreateAccountRequest{
+				Currency:    account.Currency,
+				AccountType: db.AccountTypeSavings,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("OpenAccountTx", mock.Anything, matchCreateAccountParams(db.CreateAccountParams{
+					Owner:       account.Owner,
+					Balance:     0,
+					Currency:    account.Currency,
+					AccountType: db.AccountTypeSavings,
+				})).Return(db.Account{
+					Owner:       account.Owner,
+					Currency:    account.Currency,
+					AccountType: db.AccountTypeSavings,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.AccountTypeSavings, bytesToAccount(t, recorder.Body).AccountType)
+			},
+		},
+		{
+			name: "NumberCollision",
+			apiRequest: api.CreateAccountRequest{
+				Currency: account.Currency,
+			},
+			buildStub: func(store *mocks.Store) {
+				params := matchCreateAccountParams(db.CreateAccountParams{
+					Owner:       account.Owner,
+					Currency:    account.Currency,
+					AccountType: db.AccountTypeChecking,
+				})
+				store.On("OpenAccountTx", mock.Anything, params).
+					Return(db.Account{}, &pq.Error{Code: "23505"}).Once()
+				store.On("OpenAccountTx", mock.Anything, params).
+					Return(db.Account{Owner: account.Owner, Currency: account.Currency}, nil).Once()
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "SystemAccountType",
+			apiRequest: api.CreateAccountRequest{
+				Currency:    account.Currency,
+				AccountType: db.AccountTypeSystem,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "NoAuthorization",
+			apiRequest: api.CreateAccountRequest{Currency: account.Currency},
+			noAuth:     true,
+			buildStub:  func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 		{
 			name: "InvalidCurrency",
 			apiRequest: api.CreateAccountRequest{
-				Owner:    account.Owner,
 				Currency: strings.ToLower(account.Currency),
 			},
 			buildStub: func(store *mocks.Store) {},
@@ -73,15 +137,15 @@ func TestServer_CreateAccount(t *testing.T) {
 		{
 			name: "InternalError",
 			apiRequest: api.CreateAccountRequest{
-				Owner:    account.Owner,
 				Currency: account.Currency,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("CreateAccount", mock.Anything, db.CreateAccountParams{
-					Owner:    account.Owner,
-					Balance:  0,
-					Currency: account.Currency,
-				}).Return(db.Account{}, sql.ErrConnDone)
+				store.On("OpenAccountTx", mock.Anything, matchCreateAccountParams(db.CreateAccountParams{
+					Owner:       account.Owner,
+					Balance:     0,
+					Currency:    account.Currency,
+					AccountType: db.AccountTypeChecking,
+				})).Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -93,7 +157,7 @@ func TestServer_CreateAccount(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct a server with a mock db.Store
 			store := new(mocks.Store)
-			server := api.NewServer(store)
+			server := newTestServer(t, store)
 			test.buildStub(store)
 
 			// construct request and response recorder
@@ -101,6 +165,9 @@ func TestServer_CreateAccount(t *testing.T) {
 			b, err := json.Marshal(test.apiRequest)
 			require.NoError(t, err)
 			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+			if !test.noAuth {
+				addAuthorization(t, req, account.Owner)
+			}
 			recorder := httptest.NewRecorder()
 
 			// server
@@ -158,6 +225,21 @@ func TestServer_GetAccountByID(t *testing.T) {
 				assert.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name:       "NotHolder",
+			apiRequest: api.GetAccountByIDRequest{ID: account.ID},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).
+					Return(db.Account{ID: account.ID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  account.Owner,
+				}).Return(db.AccountHolder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name:       "InternalError",
 			apiRequest: api.GetAccountByIDRequest{ID: account.ID},
@@ -175,11 +257,98 @@ func TestServer_GetAccountByID(t *testing.T) {
 			// construct server with mock db.Store
 			mockStore := new(mocks.Store)
 			test.buildStub(mockStore)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 
 			// construct a request and response recorder
 			url := fmt.Sprintf("/accounts/%d", test.apiRequest.ID)
 			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, account.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check result
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetAccountByNumber(t *testing.T) {
+	number, err := util.NewAccountNumber()
+	require.NoError(t, err)
+
+	account := db.Account{
+		ID:       util.RandomInt(1, 2048),
+		Owner:    util.RandomOwner(),
+		Balance:  util.RandomBalance(),
+		Currency: util.RandomCurrency(),
+		Number:   number,
+	}
+
+	// the last digit is mistyped
+	typo := number[:len(number)-1] + string('0'+(number[len(number)-1]-'0'+1)%10)
+
+	tests := []struct {
+		name          string
+		number        string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			number: account.Number,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccountByNumber", mock.Anything, account.Number).Return(account, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, account, bytesToAccount(t, recorder.Body))
+			},
+		},
+		{
+			name:      "InvalidCheckDigits",
+			number:    typo,
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			number: account.Number,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccountByNumber", mock.Anything, account.Number).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			number: account.Number,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccountByNumber", mock.Anything, account.Number).Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+			server := newTestServer(t, mockStore)
+
+			// construct a request and response recorder
+			url := fmt.Sprintf("/accounts/by-number/%s", test.number)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, account.Owner)
 			recorder := httptest.NewRecorder()
 
 			// serve
@@ -193,16 +362,17 @@ func TestServer_GetAccountByID(t *testing.T) {
 }
 
 func TestServer_ListAccounts(t *testing.T) {
+	owner := util.RandomOwner()
 	accounts := []db.Account{
 		{
 			ID:       util.RandomInt(1, 1024),
-			Owner:    util.RandomOwner(),
+			Owner:    owner,
 			Balance:  util.RandomBalance(),
 			Currency: util.RandomCurrency(),
 		},
 		{
 			ID:       util.RandomInt(1025, 2048),
-			Owner:    util.RandomOwner(),
+			Owner:    owner,
 			Balance:  util.RandomBalance(),
 			Currency: util.RandomCurrency(),
 		},
@@ -222,6 +392,7 @@ func TestServer_ListAccounts(t *testing.T) {
 			},
 			buildStub: func(store *mocks.Store) {
 				store.On("ListAccounts", mock.Anything, db.ListAccountsParams{
+					Owner:  owner,
 					Limit:  10,
 					Offset: 0,
 				}).Return(accounts, nil)
@@ -252,6 +423,7 @@ func TestServer_ListAccounts(t *testing.T) {
 			},
 			buildStub: func(store *mocks.Store) {
 				store.On("ListAccounts", mock.Anything, db.ListAccountsParams{
+					Owner:  owner,
 					Limit:  10,
 					Offset: 0,
 				}).Return(nil, sql.ErrNoRows)
@@ -268,6 +440,7 @@ func TestServer_ListAccounts(t *testing.T) {
 			},
 			buildStub: func(store *mocks.Store) {
 				store.On("ListAccounts", mock.Anything, db.ListAccountsParams{
+					Owner:  owner,
 					Limit:  10,
 					Offset: 0,
 				}).Return(nil, sql.ErrConnDone)
@@ -282,7 +455,7 @@ func TestServer_ListAccounts(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct a server with mock db.Store
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
@@ -292,6 +465,7 @@ func TestServer_ListAccounts(t *testing.T) {
 				test.accountRequest.PageSize,
 			)
 			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, owner)
 			recorder := httptest.NewRecorder()
 
 			// serve
@@ -330,6 +504,7 @@ func TestServer_UpdateAccount(t *testing.T) {
 			paramsURI:  api.UpdateAccountRequestURI{ID: account1.ID},
 			paramsJSON: api.UpdateAccountRequestJSON{Balance: account2.Balance},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
 				store.On("UpdateAccountBalance", mock.Anything, db.UpdateAccountBalanceParams{
 					ID:      account1.ID,
 					Balance: account2.Balance,
@@ -365,11 +540,25 @@ func TestServer_UpdateAccount(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name:       "Frozen",
+			paramsURI:  api.UpdateAccountRequestURI{ID: account1.ID},
+			paramsJSON: api.UpdateAccountRequestJSON{Balance: account2.Balance},
+			buildStub: func(store *mocks.Store) {
+				frozen := account1
+				frozen.FrozenAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.On("GetAccount", mock.Anything, account1.ID).Return(frozen, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
 		{
 			name:       "NotFound",
 			paramsURI:  api.UpdateAccountRequestURI{ID: account1.ID},
 			paramsJSON: api.UpdateAccountRequestJSON{Balance: account2.Balance},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
 				store.On("UpdateAccountBalance", mock.Anything, db.UpdateAccountBalanceParams{
 					ID:      account1.ID,
 					Balance: account2.Balance,
@@ -379,11 +568,33 @@ func TestServer_UpdateAccount(t *testing.T) {
 				assert.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name:       "Viewer",
+			paramsURI:  api.UpdateAccountRequestURI{ID: account1.ID},
+			paramsJSON: api.UpdateAccountRequestJSON{Balance: account2.Balance},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).
+					Return(db.Account{ID: account1.ID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account1.ID,
+					Username:  account1.Owner,
+				}).Return(db.AccountHolder{
+					AccountID: account1.ID,
+					Username:  account1.Owner,
+					Role:      db.HolderRoleViewer,
+					Status:    db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name:       "InternalError",
 			paramsURI:  api.UpdateAccountRequestURI{ID: account1.ID},
 			paramsJSON: api.UpdateAccountRequestJSON{Balance: account2.Balance},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
 				store.On("UpdateAccountBalance", mock.Anything, db.UpdateAccountBalanceParams{
 					ID:      account1.ID,
 					Balance: account2.Balance,
@@ -399,7 +610,7 @@ func TestServer_UpdateAccount(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct server with mock db.Store
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
@@ -407,6 +618,7 @@ func TestServer_UpdateAccount(t *testing.T) {
 			b, err := json.Marshal(test.paramsJSON)
 			require.NoError(t, err)
 			req := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(b))
+			addAuthorization(t, req, account1.Owner)
 			recorder := httptest.NewRecorder()
 
 			// server
@@ -438,8 +650,9 @@ func TestServer_DeleteAccount(t *testing.T) {
 			name:   "OK",
 			params: api.DeleteAccountRequest{ID: account.ID},
 			buildStub: func(store *mocks.Store) {
-				store.On("DeleteAccount", mock.Anything, account.ID).
-					Return(nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("CloseAccountTx", mock.Anything, account.ID).
+					Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
@@ -458,19 +671,53 @@ func TestServer_DeleteAccount(t *testing.T) {
 			name:   "NotFound",
 			params: api.DeleteAccountRequest{ID: account.ID},
 			buildStub: func(store *mocks.Store) {
-				store.On("DeleteAccount", mock.Anything, account.ID).
-					Return(sql.ErrNoRows)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("CloseAccountTx", mock.Anything, account.ID).
+					Return(db.Account{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusNotFound, recorder.Code)
 			},
 		},
+		{
+			name:   "Frozen",
+			params: api.DeleteAccountRequest{ID: account.ID},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("CloseAccountTx", mock.Anything, account.ID).
+					Return(db.Account{}, fmt.Errorf("can not close account: %w", db.ErrAccountFrozen))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "CoOwner",
+			params: api.DeleteAccountRequest{ID: account.ID},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).
+					Return(db.Account{ID: account.ID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  account.Owner,
+				}).Return(db.AccountHolder{
+					AccountID: account.ID,
+					Username:  account.Owner,
+					Role:      db.HolderRoleCoOwner,
+					Status:    db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name:   "InternalError",
 			params: api.DeleteAccountRequest{ID: account.ID},
 			buildStub: func(store *mocks.Store) {
-				store.On("DeleteAccount", mock.Anything, account.ID).
-					Return(sql.ErrConnDone)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("CloseAccountTx", mock.Anything, account.ID).
+					Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -482,12 +729,13 @@ func TestServer_DeleteAccount(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct a server with mock Store
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
 			url := fmt.Sprintf("/accounts/%d", test.params.ID)
 			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			addAuthorization(t, req, account.Owner)
 			recorder := httptest.NewRecorder()
 
 			// server
@@ -500,6 +748,17 @@ func TestServer_DeleteAccount(t *testing.T) {
 	}
 }
 
+// matchCreateAccountParams matches the params of OpenAccountTx with
+// a generated account number.
+func matchCreateAccountParams(want db.CreateAccountParams) interface{} {
+	return mock.MatchedBy(func(arg db.CreateAccountParams) bool {
+		number := arg.Number
+		arg.Number = ""
+
+		return arg == want && util.ValidAccountNumber(number)
+	})
+}
+
 func bytesToAccount(t *testing.T, data *bytes.Buffer) db.Account {
 	t.Helper()
 
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrOwnRole is returned when a user tries to change their own role.
+var ErrOwnRole = errors.New("users can not change their own role")
+
+var (
+	// staffRoles are the roles of all staff users.
+	staffRoles = []string{db.UserRoleSupport, db.UserRoleAdmin, db.UserRoleAuditor}
+	// operatorRoles are the roles allowed to freeze and unfreeze accounts.
+	operatorRoles = []string{db.UserRoleSupport, db.UserRoleAdmin}
+	// auditRoles are the roles allowed to inspect the audit log.
+	auditRoles = []string{db.UserRoleAdmin, db.UserRoleAuditor}
+)
+
+// UserResponse is a db.User without its credentials.
+type UserResponse struct {
+	Username           string    `json:"username"`
+	Role               string    `json:"role"`
+	FirstName          string    `json:"first_name"`
+	LastName           string    `json:"last_name"`
+	Email              string    `json:"email"`
+	EmailVerified      bool      `json:"email_verified"`
+	PasswordModifiedAt time.Time `json:"password_modified_at"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+func newUserResponse(user db.User) UserResponse {
+	return UserResponse{
+		Username:           user.Username,
+		Role:               user.Role,
+		FirstName:          user.FirstName,
+		LastName:           user.LastName,
+		Email:              user.Email,
+		EmailVerified:      user.EmailVerifiedAt.Valid,
+		PasswordModifiedAt: user.PasswordModifiedAt,
+		CreatedAt:          user.CreatedAt,
+	}
+}
+
+// SearchUsersRequestQuery holds query parameters for searchUsers handler.
+// Q matches a part of the username, the email or the full name.
+type SearchUsersRequestQuery struct {
+	Q        string `form:"q"`
+	Role     string `form:"role" binding:"omitempty,oneof=customer support admin auditor"`
+	PageNum  int32  `form:"page_num" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+// searchUsers lists the users matching the query in the order of their usernames.
+func (s *Server) searchUsers(c *gin.Context) {
+	var req SearchUsersRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	users, err := s.store.SearchUsers(c, db.SearchUsersParams{
+		Query:     req.Q,
+		Role:      req.Role,
+		MaxUsers:  req.PageSize,
+		SkipUsers: (req.PageNum - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp := make([]UserResponse, len(users))
+	for i, user := range users {
+		resp[i] = newUserResponse(user)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetUserRoleRequestURI holds URI parameters for setUserRole handler.
+type SetUserRoleRequestURI struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+// SetUserRoleRequestJSON holds JSON parameters for setUserRole handler.
+type SetUserRoleRequestJSON struct {
+	Role string `json:"role" binding:"required,oneof=customer support admin auditor"`
+}
+
+// setUserRole changes the role of the user. The new role is embedded
+// in the tokens issued after the change.
+func (s *Server) setUserRole(c *gin.Context) {
+	var reqURI SetUserRoleRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqJSON SetUserRoleRequestJSON
+	if err := c.ShouldBindJSON(&reqJSON); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	// an administrator can not lock themselves out
+	if reqURI.Username == authPayload(c).Username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrOwnRole))
+
+		return
+	}
+
+	user, err := s.store.SetUserRole(c, db.SetUserRoleParams{
+		Username: reqURI.Username,
+		Role:     reqJSON.Role,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// SearchAccountsRequestQuery holds query parameters for searchAccounts handler.
+// Empty filters match every account.
+type SearchAccountsRequestQuery struct {
+	Owner    string `form:"owner"`
+	Number   string `form:"number"`
+	Currency string `form:"currency" binding:"omitempty,uppercase"`
+	// Frozen limits the search to the frozen accounts.
+	Frozen   bool  `form:"frozen"`
+	PageNum  int32 `form:"page_num" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+// searchAccounts lists the accounts matching the filters in the order of their IDs.
+func (s *Server) searchAccounts(c *gin.Context) {
+	var req SearchAccountsRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	accounts, err := s.store.SearchAccounts(c, db.SearchAccountsParams{
+		Owner:        req.Owner,
+		Number:       req.Number,
+		Currency:     req.Currency,
+		FrozenOnly:   req.Frozen,
+		MaxAccounts:  req.PageSize,
+		SkipAccounts: (req.PageNum - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// AdminAccountRequestURI holds URI parameters for the administrative account handlers.
+type AdminAccountRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// FreezeAccountRequestJSON holds JSON parameters for freezeAccount handler.
+type FreezeAccountRequestJSON struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// freezeAccount freezes the account, so it can not be debited nor closed until
+// it is unfrozen. Freezing a frozen account only updates the reason.
+func (s *Server) freezeAccount(c *gin.Context) {
+	var reqURI AdminAccountRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqJSON FreezeAccountRequestJSON
+	if err := c.ShouldBindJSON(&reqJSON); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	account, err := s.store.FreezeAccount(c, db.FreezeAccountParams{
+		ID:           reqURI.ID,
+		FrozenReason: reqJSON.Reason,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// unfreezeAccount lifts the freeze of the account.
+func (s *Server) unfreezeAccount(c *gin.Context) {
+	var req AdminAccountRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	account, err := s.store.UnfreezeAccount(c, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// CreateAdjustmentRequestJSON holds JSON parameters for createAdjustment handler.
+// A positive Amount credits the account, a negative one debits it.
+type CreateAdjustmentRequestJSON struct {
+	Amount int64  `json:"amount" binding:"required"`
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// createAdjustment posts a manual adjustment of the account balance
+// against the system account of its currency.
+func (s *Server) createAdjustment(c *gin.Context) {
+	var reqURI AdminAccountRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqJSON CreateAdjustmentRequestJSON
+	if err := c.ShouldBindJSON(&reqJSON); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	result, err := s.store.AdjustAccountTx(c, db.AdjustAccountTxParams{
+		AccountID: reqURI.ID,
+		Amount:    reqJSON.Amount,
+		Reason:    reqJSON.Reason,
+		CreatedBy: authPayload(c).Username,
+	})
+	if err != nil {
+		c.JSON(adjustmentErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// adjustmentErrorStatus maps errors of the adjustment transaction to HTTP status codes.
+func adjustmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrNoSystemAccount):
+		return http.StatusUnprocessableEntity
+	default:
+		return transferErrorStatus(err)
+	}
+}
+
+// ListAdjustmentsRequestQuery holds query parameters for listAdjustments handler.
+type ListAdjustmentsRequestQuery struct {
+	PageNum  int32 `form:"page_num" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+// listAdjustments lists the adjustments of the account, the latest first.
+func (s *Server) listAdjustments(c *gin.Context) {
+	var reqURI AdminAccountRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqQuery ListAdjustmentsRequestQuery
+	if err := c.ShouldBindQuery(&reqQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	adjustments, err := s.store.ListAccountAdjustments(c, db.ListAccountAdjustmentsParams{
+		AccountID: reqURI.ID,
+		Limit:     reqQuery.PageSize,
+		Offset:    (reqQuery.PageNum - 1) * reqQuery.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, adjustments)
+}
@@ -0,0 +1,578 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// serveAdmin serves the request of a staff user with the role.
+func serveAdmin(t *testing.T, store *mocks.Store, role, method, url string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+
+	// construct server with mock db.Store
+	server := newTestServer(t, store)
+
+	// prepare request and response recorder
+	req := httptest.NewRequest(method, url, bytes.NewReader(data))
+	addRoleAuthorization(t, req, util.RandomOwner(), role)
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	return recorder
+}
+
+func TestServer_SearchUsers(t *testing.T) {
+	user := db.User{
+		Username:       util.RandomOwner(),
+		HashedPassword: util.RandomString(32),
+		FirstName:      util.RandomOwner(),
+		LastName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+		Role:           db.UserRoleCustomer,
+	}
+
+	tests := []struct {
+		name          string
+		role          string
+		query         string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  db.UserRoleSupport,
+			query: "q=john&role=customer&page_num=2&page_size=5",
+			buildStub: func(store *mocks.Store) {
+				store.On("SearchUsers", mock.Anything, db.SearchUsersParams{
+					Query:     "john",
+					Role:      db.UserRoleCustomer,
+					MaxUsers:  5,
+					SkipUsers: 5,
+				}).Return([]db.User{user}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.NotContains(t, recorder.Body.String(), user.HashedPassword)
+
+				var result []api.UserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Len(t, result, 1)
+				assert.Equal(t, user.Username, result[0].Username)
+				assert.Equal(t, user.Role, result[0].Role)
+			},
+		},
+		{
+			name:      "Customer",
+			role:      db.UserRoleCustomer,
+			query:     "page_num=1&page_size=5",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRole",
+			role:      db.UserRoleAdmin,
+			query:     "role=root&page_num=1&page_size=5",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			role:  db.UserRoleAuditor,
+			query: "page_num=1&page_size=5",
+			buildStub: func(store *mocks.Store) {
+				store.On("SearchUsers", mock.Anything, mock.Anything).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+
+			recorder := serveAdmin(t, mockStore, test.role, http.MethodGet, "/admin/users?"+test.query, nil)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_SetUserRole(t *testing.T) {
+	user := db.User{
+		Username: util.RandomOwner(),
+		Role:     db.UserRoleSupport,
+	}
+
+	tests := []struct {
+		name          string
+		role          string
+		username      string
+		params        api.SetUserRoleRequestJSON
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			role:     db.UserRoleAdmin,
+			username: user.Username,
+			params:   api.SetUserRoleRequestJSON{Role: db.UserRoleSupport},
+			buildStub: func(store *mocks.Store) {
+				store.On("SetUserRole", mock.Anything, db.SetUserRoleParams{
+					Username: user.Username,
+					Role:     db.UserRoleSupport,
+				}).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.UserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, db.UserRoleSupport, result.Role)
+			},
+		},
+		{
+			name:      "Support",
+			role:      db.UserRoleSupport,
+			username:  user.Username,
+			params:    api.SetUserRoleRequestJSON{Role: db.UserRoleAdmin},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRole",
+			role:      db.UserRoleAdmin,
+			username:  user.Username,
+			params:    api.SetUserRoleRequestJSON{Role: "root"},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			role:     db.UserRoleAdmin,
+			username: user.Username,
+			params:   api.SetUserRoleRequestJSON{Role: db.UserRoleAuditor},
+			buildStub: func(store *mocks.Store) {
+				store.On("SetUserRole", mock.Anything, mock.Anything).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+
+			url := fmt.Sprintf("/admin/users/%s/role", test.username)
+			recorder := serveAdmin(t, mockStore, test.role, http.MethodPut, url, test.params)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_SetUserRole_Own(t *testing.T) {
+	username := util.RandomOwner()
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+
+	// prepare request and response recorder
+	body, err := json.Marshal(api.SetUserRoleRequestJSON{Role: db.UserRoleCustomer})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/admin/users/%s/role", username), bytes.NewReader(body))
+	addRoleAuthorization(t, req, username, db.UserRoleAdmin)
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	// check response
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_SearchAccounts(t *testing.T) {
+	account := db.Account{
+		ID:           util.RandomInt(1, 2048),
+		Owner:        util.RandomOwner(),
+		Currency:     util.RandomCurrency(),
+		FrozenAt:     sql.NullTime{Time: time.Now().UTC().Truncate(time.Second), Valid: true},
+		FrozenReason: "suspicious activity",
+	}
+
+	tests := []struct {
+		name          string
+		role          string
+		query         string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  db.UserRoleAuditor,
+			query: fmt.Sprintf("owner=%s&currency=%s&frozen=true&page_num=1&page_size=10", account.Owner, account.Currency),
+			buildStub: func(store *mocks.Store) {
+				store.On("SearchAccounts", mock.Anything, db.SearchAccountsParams{
+					Owner:        account.Owner,
+					Currency:     account.Currency,
+					FrozenOnly:   true,
+					MaxAccounts:  10,
+					SkipAccounts: 0,
+				}).Return([]db.Account{account}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result []db.Account
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Len(t, result, 1)
+				assert.Equal(t, account.ID, result[0].ID)
+				assert.Equal(t, account.FrozenReason, result[0].FrozenReason)
+			},
+		},
+		{
+			name:      "Customer",
+			role:      db.UserRoleCustomer,
+			query:     "page_num=1&page_size=10",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPage",
+			role:      db.UserRoleSupport,
+			query:     "page_num=0&page_size=10",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+
+			recorder := serveAdmin(t, mockStore, test.role, http.MethodGet, "/admin/accounts?"+test.query, nil)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_FreezeAccount(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
+	tests := []struct {
+		name          string
+		role          string
+		params        api.FreezeAccountRequestJSON
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			role:   db.UserRoleSupport,
+			params: api.FreezeAccountRequestJSON{Reason: "reported stolen card"},
+			buildStub: func(store *mocks.Store) {
+				frozen := account
+				frozen.FrozenAt = sql.NullTime{Time: time.Now(), Valid: true}
+				frozen.FrozenReason = "reported stolen card"
+				store.On("FreezeAccount", mock.Anything, db.FreezeAccountParams{
+					ID:           account.ID,
+					FrozenReason: "reported stolen card",
+				}).Return(frozen, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				result := bytesToAccount(t, recorder.Body)
+				assert.True(t, result.FrozenAt.Valid)
+			},
+		},
+		{
+			name:      "MissingReason",
+			role:      db.UserRoleAdmin,
+			params:    api.FreezeAccountRequestJSON{},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "Auditor",
+			role:      db.UserRoleAuditor,
+			params:    api.FreezeAccountRequestJSON{Reason: "reported stolen card"},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			role:   db.UserRoleAdmin,
+			params: api.FreezeAccountRequestJSON{Reason: "reported stolen card"},
+			buildStub: func(store *mocks.Store) {
+				store.On("FreezeAccount", mock.Anything, mock.Anything).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+
+			url := fmt.Sprintf("/admin/accounts/%d/freeze", account.ID)
+			recorder := serveAdmin(t, mockStore, test.role, http.MethodPost, url, test.params)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_UnfreezeAccount(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
+	tests := []struct {
+		name          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *mocks.Store) {
+				store.On("UnfreezeAccount", mock.Anything, account.ID).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				result := bytesToAccount(t, recorder.Body)
+				assert.False(t, result.FrozenAt.Valid)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStub: func(store *mocks.Store) {
+				store.On("UnfreezeAccount", mock.Anything, account.ID).Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+
+			url := fmt.Sprintf("/admin/accounts/%d/unfreeze", account.ID)
+			recorder := serveAdmin(t, mockStore, db.UserRoleSupport, http.MethodPost, url, nil)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_CreateAdjustment(t *testing.T) {
+	account := db.Account{
+		ID:       util.RandomInt(1, 2048),
+		Owner:    util.RandomOwner(),
+		Balance:  util.RandomBalance(),
+		Currency: util.RandomCurrency(),
+	}
+
+	tests := []struct {
+		name          string
+		role          string
+		params        api.CreateAdjustmentRequestJSON
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			role:   db.UserRoleAdmin,
+			params: api.CreateAdjustmentRequestJSON{Amount: -25, Reason: "duplicate card payment"},
+			buildStub: func(store *mocks.Store) {
+				store.On("AdjustAccountTx", mock.Anything, mock.MatchedBy(func(arg db.AdjustAccountTxParams) bool {
+					return arg.AccountID == account.ID && arg.Amount == -25 &&
+						arg.Reason == "duplicate card payment" && arg.CreatedBy != ""
+				})).Return(db.AdjustAccountTxResult{
+					Adjustment: db.Adjustment{ID: 1, AccountID: account.ID, Amount: -25},
+					Account:    account,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.AdjustAccountTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, int64(-25), result.Adjustment.Amount)
+			},
+		},
+		{
+			name:      "ZeroAmount",
+			role:      db.UserRoleAdmin,
+			params:    api.CreateAdjustmentRequestJSON{Reason: "duplicate card payment"},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingReason",
+			role:      db.UserRoleAdmin,
+			params:    api.CreateAdjustmentRequestJSON{Amount: 25},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "Support",
+			role:      db.UserRoleSupport,
+			params:    api.CreateAdjustmentRequestJSON{Amount: 25, Reason: "goodwill"},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "Frozen",
+			role:   db.UserRoleAdmin,
+			params: api.CreateAdjustmentRequestJSON{Amount: -25, Reason: "duplicate card payment"},
+			buildStub: func(store *mocks.Store) {
+				store.On("AdjustAccountTx", mock.Anything, mock.Anything).
+					Return(db.AdjustAccountTxResult{}, fmt.Errorf("can not adjust account: %w", db.ErrAccountFrozen))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "NoSystemAccount",
+			role:   db.UserRoleAdmin,
+			params: api.CreateAdjustmentRequestJSON{Amount: 25, Reason: "goodwill"},
+			buildStub: func(store *mocks.Store) {
+				store.On("AdjustAccountTx", mock.Anything, mock.Anything).
+					Return(db.AdjustAccountTxResult{}, fmt.Errorf("can not adjust account: %w", db.ErrNoSystemAccount))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			role:   db.UserRoleAdmin,
+			params: api.CreateAdjustmentRequestJSON{Amount: 25, Reason: "goodwill"},
+			buildStub: func(store *mocks.Store) {
+				store.On("AdjustAccountTx", mock.Anything, mock.Anything).
+					Return(db.AdjustAccountTxResult{}, fmt.Errorf("can not adjust account: %w", sql.ErrNoRows))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+
+			url := fmt.Sprintf("/admin/accounts/%d/adjustments", account.ID)
+			recorder := serveAdmin(t, mockStore, test.role, http.MethodPost, url, test.params)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ListAdjustments(t *testing.T) {
+	accountID := util.RandomInt(1, 2048)
+	adjustments := []db.Adjustment{
+		{ID: 2, AccountID: accountID, Amount: 10, Reason: "goodwill"},
+		{ID: 1, AccountID: accountID, Amount: -10, Reason: "duplicate card payment"},
+	}
+
+	mockStore := new(mocks.Store)
+	mockStore.On("ListAccountAdjustments", mock.Anything, db.ListAccountAdjustmentsParams{
+		AccountID: accountID,
+		Limit:     10,
+		Offset:    10,
+	}).Return(adjustments, nil)
+
+	url := fmt.Sprintf("/admin/accounts/%d/adjustments?page_num=2&page_size=10", accountID)
+	recorder := serveAdmin(t, mockStore, db.UserRoleAuditor, http.MethodGet, url, nil)
+
+	// check response
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var result []db.Adjustment
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	assert.Equal(t, adjustments, result)
+	mockStore.AssertExpectations(t)
+}
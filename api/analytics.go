@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidPeriod is returned when the start of the period is not before its end.
+var ErrInvalidPeriod = errors.New("the start of the period must be before its end")
+
+// groupByCategory aggregates the entries by their categories.
+const groupByCategory = "category"
+
+// GetAccountAnalyticsRequestURI holds URI parameters for getAccountAnalytics handler.
+type GetAccountAnalyticsRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// GetAccountAnalyticsRequestQuery holds query parameters for getAccountAnalytics handler.
+// The period starts at From and ends before To, it is not limited by default.
+type GetAccountAnalyticsRequestQuery struct {
+	GroupBy string    `form:"group_by" binding:"required,oneof=category month"`
+	From    time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To      time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+}
+
+// getAccountAnalytics returns the inflow and the outflow of the account
+// aggregated either by the categories or by the months of the entries.
+func (s *Server) getAccountAnalytics(c *gin.Context) {
+	var reqURI GetAccountAnalyticsRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqQuery GetAccountAnalyticsRequestQuery
+	if err := c.ShouldBindQuery(&reqQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if reqQuery.To.IsZero() {
+		reqQuery.To = time.Now().UTC()
+	}
+
+	if !reqQuery.From.Before(reqQuery.To) {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidPeriod))
+
+		return
+	}
+
+	if _, ok := s.authorizeAccount(c, reqURI.ID, permView); !ok {
+		return
+	}
+
+	var result interface{}
+	var err error
+	if reqQuery.GroupBy == groupByCategory {
+		result, err = s.store.GetAccountAnalyticsByCategory(c, db.GetAccountAnalyticsByCategoryParams{
+			AccountID: reqURI.ID,
+			FromTime:  reqQuery.From,
+			ToTime:    reqQuery.To,
+		})
+	} else {
+		result, err = s.store.GetAccountAnalyticsByMonth(c, db.GetAccountAnalyticsByMonthParams{
+			AccountID: reqURI.ID,
+			FromTime:  reqQuery.From,
+			ToTime:    reqQuery.To,
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
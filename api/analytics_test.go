@@ -0,0 +1,133 @@
+package api_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_GetAccountAnalytics(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	from := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	byCategory := []db.GetAccountAnalyticsByCategoryRow{
+		{CategoryID: sql.NullInt64{Int64: 2, Valid: true}, Category: "rent", Outflow: 1200, Entries: 12},
+		{Category: "", Inflow: 3000, Outflow: 150, Entries: 7},
+	}
+	byMonth := []db.GetAccountAnalyticsByMonthRow{
+		{Month: from, Inflow: 250, Outflow: 100, Entries: 3},
+		{Month: from.AddDate(0, 1, 0), Inflow: 250, Entries: 1},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "ByCategory",
+			query: "group_by=category&from=2021-01-01&to=2022-01-01",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountAnalyticsByCategory", mock.Anything, db.GetAccountAnalyticsByCategoryParams{
+					AccountID: account.ID,
+					FromTime:  from,
+					ToTime:    to,
+				}).Return(byCategory, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result []db.GetAccountAnalyticsByCategoryRow
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, byCategory, result)
+			},
+		},
+		{
+			name:  "ByMonth",
+			query: "group_by=month&from=2021-01-01",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountAnalyticsByMonth", mock.Anything, mock.MatchedBy(
+					func(arg db.GetAccountAnalyticsByMonthParams) bool {
+						return arg.AccountID == account.ID &&
+							arg.FromTime.Equal(from) &&
+							time.Since(arg.ToTime) < time.Minute
+					})).Return(byMonth, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result []db.GetAccountAnalyticsByMonthRow
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, byMonth, result)
+			},
+		},
+		{
+			name:      "InvalidGroupBy",
+			query:     "group_by=week",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPeriod",
+			query:     "group_by=month&from=2022-01-01&to=2021-01-01",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "group_by=category",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountAnalyticsByCategory", mock.Anything, mock.Anything).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/analytics?%s", account.ID, test.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, account.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,465 @@
+package api
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrAPIKeyNotOwned is returned when the API key belongs to another user.
+	ErrAPIKeyNotOwned = errors.New("API key belongs to another user")
+	// ErrAPIKeyRevoked is returned when the API key has been revoked.
+	ErrAPIKeyRevoked = errors.New("API key has been revoked")
+	// ErrAPIKeyExpired is returned when the API key has expired.
+	ErrAPIKeyExpired = errors.New("API key has expired")
+	// ErrAPIKeyIPNotAllowed is returned when the API key is used from an IP outside of its allowlist.
+	ErrAPIKeyIPNotAllowed = errors.New("API key is not allowed from the client IP")
+	// ErrAPIKeyScope is returned when the API key lacks the scope required by the endpoint.
+	ErrAPIKeyScope = errors.New("API key lacks the required scope")
+	// ErrInvalidAllowedIP is returned when an entry of the IP allowlist is neither an IP address nor a CIDR range.
+	ErrInvalidAllowedIP = errors.New("invalid IP address or CIDR range")
+	// ErrAPIKeyExpiryPassed is returned when the API key is created with an expiry in the past.
+	ErrAPIKeyExpiryPassed = errors.New("expiry of the API key must be in the future")
+)
+
+// endpointScopes are the scopes an API key needs to call the endpoints, keyed by
+// the method and the path of the route. The endpoints missing here can not be
+// called with an API key at all.
+var endpointScopes = map[string]string{
+	"POST /accounts":                         "accounts:write",
+	"GET /accounts":                          "accounts:read",
+	"GET /accounts/by-number/:number":        "accounts:read",
+	"GET /accounts/:id":                      "accounts:read",
+	"PUT /accounts/:id":                      "accounts:write",
+	"DELETE /accounts/:id":                   "accounts:write",
+	"GET /accounts/:id/transfers":            "accounts:read",
+	"GET /accounts/:id/analytics":            "accounts:read",
+	"GET /accounts/:id/balance":              "accounts:read",
+	"GET /accounts/:id/balance-history":      "accounts:read",
+	"GET /accounts/:id/events":               "accounts:read",
+	"GET /accounts/:id/holders":              "accounts:read",
+	"POST /accounts/:id/holders":             "accounts:write",
+	"POST /accounts/:id/holders/accept":      "accounts:write",
+	"DELETE /accounts/:id/holders/:username": "accounts:write",
+	"GET /accounts/:id/approval-policy":      "accounts:read",
+	"PUT /accounts/:id/approval-policy":      "accounts:write",
+	"DELETE /accounts/:id/approval-policy":   "accounts:write",
+
+	"GET /entries/id/:id":                "entries:read",
+	"GET /entries/accountid/:account_id": "entries:read",
+	"POST /entries":                      "entries:write",
+	"PUT /entries/:id":                   "entries:write",
+	"PUT /entries/:id/category":          "entries:write",
+	"DELETE /entries/:id":                "entries:write",
+
+	"POST /transfers":                     "transfers:write",
+	"GET /transfers/quote":                "transfers:read",
+	"POST /transfers/batch":               "transfers:write",
+	"GET /transfers/batch/:id":            "transfers:read",
+	"GET /transfers/pending/:id":          "transfers:read",
+	"POST /transfers/pending/:id/approve": "transfers:write",
+	"POST /transfers/pending/:id/reject":  "transfers:write",
+
+	"GET /users":                   "users:read",
+	"GET /users/me/summary":        "accounts:read",
+	"GET /users/sessions":          "users:read",
+	"DELETE /users/sessions/:id":   "users:write",
+	"POST /users/payees":           "users:write",
+	"GET /users/payees":            "users:read",
+	"GET /users/payees/:id":        "users:read",
+	"PUT /users/payees/:id":        "users:write",
+	"DELETE /users/payees/:id":     "users:write",
+	"POST /users/categories":       "users:write",
+	"GET /users/categories":        "users:read",
+	"DELETE /users/categories/:id": "users:write",
+
+	"POST /users/category-rules":       "users:write",
+	"GET /users/category-rules":        "users:read",
+	"DELETE /users/category-rules/:id": "users:write",
+
+	"POST /users/webhooks":                                       "users:write",
+	"GET /users/webhooks":                                        "users:read",
+	"GET /users/webhooks/:id":                                    "users:read",
+	"DELETE /users/webhooks/:id":                                 "users:write",
+	"GET /users/webhooks/:id/deliveries":                         "users:read",
+	"GET /users/webhooks/:id/deliveries/:delivery_id":            "users:read",
+	"POST /users/webhooks/:id/deliveries/:delivery_id/redeliver": "users:write",
+	"POST /users/payment-requests":                               "users:write",
+	"GET /users/payment-requests":                                "users:read",
+	"GET /users/payment-requests/:id":                            "users:read",
+	"POST /users/payment-requests/:id/pay":                       "transfers:write",
+	"POST /users/payment-requests/:id/decline":                   "users:write",
+
+	"GET /admin/users":                     "admin:read",
+	"PUT /admin/users/:username/role":      "admin:write",
+	"GET /admin/users/:username/lock":      "admin:read",
+	"DELETE /admin/users/:username/lock":   "admin:write",
+	"GET /admin/accounts":                  "admin:read",
+	"POST /admin/accounts/:id/freeze":      "admin:write",
+	"POST /admin/accounts/:id/unfreeze":    "admin:write",
+	"GET /admin/accounts/:id/adjustments":  "admin:read",
+	"POST /admin/accounts/:id/adjustments": "admin:write",
+	"GET /admin/audit-log":                 "admin:read",
+	"GET /admin/audit-log/verify":          "admin:read",
+}
+
+// requiredScope returns the scope an API key needs to call the endpoint of the request,
+// or an empty string if the endpoint can not be called with an API key.
+func requiredScope(c *gin.Context) string {
+	return endpointScopes[c.Request.Method+" "+c.FullPath()]
+}
+
+// ipAllowed checks whether the client IP matches an IP address or a CIDR range
+// of the allowlist. An empty allowlist allows any IP.
+func ipAllowed(allowlist []string, clientIP string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, allowed := range allowlist {
+		if _, network, err := net.ParseCIDR(allowed); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		if ip.Equal(net.ParseIP(allowed)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeAllowedIP returns the canonical form of the IP address or the CIDR range
+// of an allowlist. It returns false if the entry is neither of them.
+func normalizeAllowedIP(allowed string) (string, bool) {
+	if _, network, err := net.ParseCIDR(allowed); err == nil {
+		return network.String(), true
+	}
+
+	if ip := net.ParseIP(allowed); ip != nil {
+		return ip.String(), true
+	}
+
+	return "", false
+}
+
+// authenticateAPIKey verifies the API key of the request and returns the payload
+// of its owner. It aborts the request and returns false if the key is not valid
+// or may not call the endpoint.
+func authenticateAPIKey(c *gin.Context, store db.Store, key string) (*token.Payload, bool) {
+	prefix, secretHash, err := token.ParseAPIKey(key)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+
+		return nil, false
+	}
+
+	apiKey, err := store.GetAPIKeyByPrefix(c, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrInvalidAPIKey))
+		} else {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return nil, false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(apiKey.SecretHash), []byte(secretHash)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(token.ErrInvalidAPIKey))
+
+		return nil, false
+	}
+
+	if apiKey.RevokedAt.Valid {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrAPIKeyRevoked))
+
+		return nil, false
+	}
+
+	if apiKey.ExpiresAt.Valid && !time.Now().Before(apiKey.ExpiresAt.Time) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrAPIKeyExpired))
+
+		return nil, false
+	}
+
+	if !ipAllowed(apiKey.AllowedIps, c.ClientIP()) {
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(ErrAPIKeyIPNotAllowed))
+
+		return nil, false
+	}
+
+	scope := requiredScope(c)
+	if scope == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(ErrAPIKeyNotAllowed))
+
+		return nil, false
+	}
+
+	if !containsString(apiKey.Scopes, scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(fmt.Errorf("%w: %s", ErrAPIKeyScope, scope)))
+
+		return nil, false
+	}
+
+	// the role is read on every request, so a changed role applies immediately
+	user, err := store.GetUser(c, apiKey.Username)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+
+		return nil, false
+	}
+
+	if err := store.TouchAPIKey(c, apiKey.ID); err != nil {
+		_ = c.Error(err)
+	}
+
+	c.Set(authorizationAPIKeyKey, apiKey)
+
+	return &token.Payload{
+		ID:        apiKey.Prefix,
+		Username:  user.Username,
+		Role:      user.Role,
+		IssuedAt:  apiKey.CreatedAt,
+		ExpiredAt: apiKey.ExpiresAt.Time,
+	}, true
+}
+
+// containsString checks whether the value is one of the values.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateAPIKeyRequest holds parameters for createAPIKey handler. AllowedIPs
+// holds IP addresses and CIDR ranges, an empty list allows any IP. A zero
+// ExpiresAt creates a key which does not expire.
+type CreateAPIKeyRequest struct {
+	Name       string    `json:"name" binding:"required,max=100"`
+	Scopes     []string  `json:"scopes" binding:"required,min=1,dive,oneof=accounts:read accounts:write entries:read entries:write transfers:read transfers:write users:read users:write admin:read admin:write"`
+	AllowedIPs []string  `json:"allowed_ips" binding:"max=100"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// APIKeyResponse holds an API key returned by the API key handlers. The key itself
+// is returned only when the key is created or rotated, only its hash is stored.
+type APIKeyResponse struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	Prefix     string    `json:"prefix"`
+	Scopes     []string  `json:"scopes"`
+	AllowedIPs []string  `json:"allowed_ips"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Revoked    bool      `json:"revoked"`
+	CreatedAt  time.Time `json:"created_at"`
+	Key        string    `json:"key,omitempty"`
+}
+
+func newAPIKeyResponse(apiKey db.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     apiKey.Scopes,
+		AllowedIPs: apiKey.AllowedIps,
+		ExpiresAt:  apiKey.ExpiresAt.Time,
+		LastUsedAt: apiKey.LastUsedAt.Time,
+		Revoked:    apiKey.RevokedAt.Valid,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+// createAPIKey creates a new API key acting on behalf of the authenticated user.
+// A service gets a user of its own to own its keys.
+func (s *Server) createAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	allowedIPs := make([]string, len(req.AllowedIPs))
+	for i, allowed := range req.AllowedIPs {
+		var ok bool
+		if allowedIPs[i], ok = normalizeAllowedIP(allowed); !ok {
+			c.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("%w: %s", ErrInvalidAllowedIP, allowed)))
+
+			return
+		}
+	}
+
+	if !req.ExpiresAt.IsZero() && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrAPIKeyExpiryPassed))
+
+		return
+	}
+
+	prefix, err := token.NewAPIKeyPrefix()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	key, secretHash, err := token.NewAPIKey(prefix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	apiKey, err := s.store.CreateAPIKey(c, db.CreateAPIKeyParams{
+		Username:   authPayload(c).Username,
+		Name:       req.Name,
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		Scopes:     req.Scopes,
+		AllowedIps: allowedIPs,
+		ExpiresAt:  sql.NullTime{Time: req.ExpiresAt, Valid: !req.ExpiresAt.IsZero()},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp := newAPIKeyResponse(apiKey)
+	resp.Key = key
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (s *Server) listAPIKeys(c *gin.Context) {
+	apiKeys, err := s.store.ListAPIKeys(c, authPayload(c).Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp := make([]APIKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		resp[i] = newAPIKeyResponse(apiKey)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// APIKeyRequestURI holds URI parameters for API key handlers.
+type APIKeyRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// rotateAPIKey replaces the secret of the API key. The old key stops working
+// immediately, the prefix, the scopes and the allowlist are kept.
+func (s *Server) rotateAPIKey(c *gin.Context) {
+	var req APIKeyRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	apiKey, ok := s.ownAPIKey(c, req.ID)
+	if !ok {
+		return
+	}
+
+	key, secretHash, err := token.NewAPIKey(apiKey.Prefix)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	// a revoked key can not be rotated back to life
+	apiKey, err = s.store.RotateAPIKey(c, db.RotateAPIKeyParams{
+		ID:         req.ID,
+		SecretHash: secretHash,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusConflict, errorResponse(ErrAPIKeyRevoked))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	resp := newAPIKeyResponse(apiKey)
+	resp.Key = key
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// revokeAPIKey revokes the API key for good.
+func (s *Server) revokeAPIKey(c *gin.Context) {
+	var req APIKeyRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.ownAPIKey(c, req.ID); !ok {
+		return
+	}
+
+	apiKey, err := s.store.RevokeAPIKey(c, req.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newAPIKeyResponse(apiKey))
+}
+
+// ownAPIKey returns the API key with the given ID if it belongs to the authenticated
+// user. It writes the error response and returns false if not.
+func (s *Server) ownAPIKey(c *gin.Context, id int64) (db.APIKey, bool) {
+	apiKey, err := s.store.GetAPIKey(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return apiKey, false
+	}
+
+	if apiKey.Username != authPayload(c).Username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrAPIKeyNotOwned))
+
+		return apiKey, false
+	}
+
+	return apiKey, true
+}
@@ -0,0 +1,485 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// randomAPIKey returns a new API key of the user with the scopes and the key itself.
+func randomAPIKey(t *testing.T, username string, scopes ...string) (db.APIKey, string) {
+	t.Helper()
+
+	prefix, err := token.NewAPIKeyPrefix()
+	require.NoError(t, err)
+
+	key, secretHash, err := token.NewAPIKey(prefix)
+	require.NoError(t, err)
+
+	return db.APIKey{
+		ID:         util.RandomInt(1, 1024),
+		Username:   username,
+		Name:       util.RandomOwner(),
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		Scopes:     scopes,
+		AllowedIps: []string{},
+		CreatedAt:  time.Now(),
+	}, key
+}
+
+func TestServer_AuthMiddleware_APIKey(t *testing.T) {
+	user := db.User{
+		Username: util.RandomOwner(),
+		Role:     db.UserRoleCustomer,
+	}
+	apiKey, key := randomAPIKey(t, user.Username, "users:read")
+	_, otherKey := randomAPIKey(t, user.Username, "users:read")
+	writeAPIKey, writeKey := randomAPIKey(t, user.Username, "users:write")
+
+	// allowKey stubs the lookup of the key and the calls following its acceptance
+	allowKey := func(store *mocks.Store, apiKey db.APIKey) {
+		store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(apiKey, nil)
+		store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+		store.On("TouchAPIKey", mock.Anything, apiKey.ID).Return(nil)
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		key           string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodGet,
+			url:    "/users/sessions",
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				allowKey(store, apiKey)
+				store.On("ListActiveSessions", mock.Anything, user.Username).Return([]db.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "AllowedIP",
+			method: http.MethodGet,
+			url:    "/users/sessions",
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				allowed := apiKey
+				allowed.AllowedIps = []string{"10.0.0.1", "192.0.2.0/24"}
+				allowKey(store, allowed)
+				store.On("ListActiveSessions", mock.Anything, user.Username).Return([]db.Session{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "IPNotAllowed",
+			method: http.MethodGet,
+			url:    "/users/sessions",
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				allowed := apiKey
+				allowed.AllowedIps = []string{"10.0.0.0/8"}
+				store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(allowed, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "MissingScope",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/users/sessions/%d", util.RandomInt(1, 1024)),
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "users:write")
+			},
+		},
+		{
+			name:   "Revoked",
+			method: http.MethodGet,
+			url:    "/users/sessions",
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				revoked := apiKey
+				revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+				store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(revoked, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Expired",
+			method: http.MethodGet,
+			url:    "/users/sessions",
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				expired := apiKey
+				expired.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+				store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(expired, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "WrongSecret",
+			method: http.MethodGet,
+			url:    "/users/sessions",
+			key:    "sbk_" + apiKey.Prefix + "_" + strings.SplitN(otherKey, "_", 3)[2],
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "UnknownKey",
+			method: http.MethodGet,
+			url:    "/users/sessions",
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(db.APIKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "Malformed",
+			method:    http.MethodGet,
+			url:       "/users/sessions",
+			key:       util.RandomString(32),
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "KeyManagement",
+			method: http.MethodGet,
+			url:    "/users/api-keys",
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), api.ErrAPIKeyNotAllowed.Error())
+			},
+		},
+		{
+			name:   "DeleteUser",
+			method: http.MethodDelete,
+			url:    "/users",
+			key:    writeKey,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKeyByPrefix", mock.Anything, writeAPIKey.Prefix).Return(writeAPIKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), api.ErrAPIKeyNotAllowed.Error())
+			},
+		},
+		{
+			name:   "PayPaymentRequest",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/users/payment-requests/%d/pay", util.RandomInt(1, 1024)),
+			key:    writeKey,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKeyByPrefix", mock.Anything, writeAPIKey.Prefix).Return(writeAPIKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "transfers:write")
+			},
+		},
+		{
+			name:   "UserSummary",
+			method: http.MethodGet,
+			url:    "/users/me/summary",
+			key:    key,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKeyByPrefix", mock.Anything, apiKey.Prefix).Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "accounts:read")
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			req := httptest.NewRequest(test.method, test.url, nil)
+			req.Header.Set("Authorization", "ApiKey "+test.key)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_CreateAPIKey(t *testing.T) {
+	username := util.RandomOwner()
+	apiKey, _ := randomAPIKey(t, username, "accounts:read", "transfers:write")
+
+	tests := []struct {
+		name          string
+		params        api.CreateAPIKeyRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			params: api.CreateAPIKeyRequest{
+				Name:       apiKey.Name,
+				Scopes:     apiKey.Scopes,
+				AllowedIPs: []string{"192.0.2.7", "10.1.2.3/8"},
+				ExpiresAt:  time.Now().Add(24 * time.Hour),
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(arg db.CreateAPIKeyParams) bool {
+					return arg.Username == username && arg.Name == apiKey.Name && arg.Prefix != "" &&
+						arg.SecretHash != "" && arg.ExpiresAt.Valid &&
+						assert.ObjectsAreEqual([]string{"192.0.2.7", "10.0.0.0/8"}, arg.AllowedIps)
+				})).Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.APIKeyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, apiKey.ID, body.ID)
+				assert.True(t, strings.HasPrefix(body.Key, "sbk_"))
+			},
+		},
+		{
+			name: "NoAllowlist",
+			params: api.CreateAPIKeyRequest{
+				Name:   apiKey.Name,
+				Scopes: apiKey.Scopes,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("CreateAPIKey", mock.Anything, mock.MatchedBy(func(arg db.CreateAPIKeyParams) bool {
+					return arg.AllowedIps != nil && len(arg.AllowedIps) == 0 && !arg.ExpiresAt.Valid
+				})).Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidScope",
+			params: api.CreateAPIKeyRequest{
+				Name:   apiKey.Name,
+				Scopes: []string{"accounts:delete"},
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAllowedIP",
+			params: api.CreateAPIKeyRequest{
+				Name:       apiKey.Name,
+				Scopes:     apiKey.Scopes,
+				AllowedIPs: []string{"localhost"},
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ExpiryPassed",
+			params: api.CreateAPIKeyRequest{
+				Name:      apiKey.Name,
+				Scopes:    apiKey.Scopes,
+				ExpiresAt: time.Now().Add(-time.Hour),
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/api-keys", bytes.NewReader(b))
+			addAuthorization(t, req, username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_RotateAPIKey(t *testing.T) {
+	username := util.RandomOwner()
+	apiKey, key := randomAPIKey(t, username, "accounts:read")
+
+	tests := []struct {
+		name          string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: username,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKey", mock.Anything, apiKey.ID).Return(apiKey, nil)
+				store.On("RotateAPIKey", mock.Anything, mock.MatchedBy(func(arg db.RotateAPIKeyParams) bool {
+					return arg.ID == apiKey.ID && arg.SecretHash != apiKey.SecretHash
+				})).Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.APIKeyResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.True(t, strings.HasPrefix(body.Key, "sbk_"+apiKey.Prefix+"_"))
+				assert.NotEqual(t, key, body.Key)
+			},
+		},
+		{
+			name:     "Revoked",
+			username: username,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKey", mock.Anything, apiKey.ID).Return(apiKey, nil)
+				store.On("RotateAPIKey", mock.Anything, mock.Anything).Return(db.APIKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwned",
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKey", mock.Anything, apiKey.ID).Return(apiKey, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: username,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAPIKey", mock.Anything, apiKey.ID).Return(db.APIKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/users/api-keys/%d/rotate", apiKey.ID)
+			req := httptest.NewRequest(http.MethodPost, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_RevokeAPIKey(t *testing.T) {
+	username := util.RandomOwner()
+	apiKey, _ := randomAPIKey(t, username, "accounts:read")
+	revoked := apiKey
+	revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	mockStore.On("GetAPIKey", mock.Anything, apiKey.ID).Return(apiKey, nil)
+	mockStore.On("RevokeAPIKey", mock.Anything, apiKey.ID).Return(revoked, nil)
+
+	// prepare request and response recorder
+	req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/users/api-keys/%d", apiKey.ID), nil)
+	addAuthorization(t, req, username)
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	// check response
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var body api.APIKeyResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.True(t, body.Revoked)
+	assert.Empty(t, body.Key)
+	mockStore.AssertExpectations(t)
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalPolicyRequestURI holds URI parameters for approval policy handlers.
+type ApprovalPolicyRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getApprovalPolicy(c *gin.Context) {
+	var req ApprovalPolicyRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.authorizeAccount(c, req.ID, permView); !ok {
+		return
+	}
+
+	policy, err := s.store.GetApprovalPolicy(c, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	approvers, err := s.store.ListApprovalPolicyApprovers(c, req.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, db.SetApprovalPolicyTxResult{
+		Policy:    policy,
+		Approvers: approvers,
+	})
+}
+
+// SetApprovalPolicyRequestJSON holds JSON parameters for setApprovalPolicy handler.
+type SetApprovalPolicyRequestJSON struct {
+	Threshold int64    `json:"threshold" binding:"required,min=1"`
+	Approvers []string `json:"approvers" binding:"required,min=1,dive,required"`
+}
+
+// setApprovalPolicy creates or replaces the approval policy of the account. Only the
+// owners may change the policy, so the other holders can not bypass the approvers.
+func (s *Server) setApprovalPolicy(c *gin.Context) {
+	var reqURI ApprovalPolicyRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqJSON SetApprovalPolicyRequestJSON
+	if err := c.ShouldBindJSON(&reqJSON); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.authorizeAccount(c, reqURI.ID, permOwn); !ok {
+		return
+	}
+
+	result, err := s.store.SetApprovalPolicyTx(c, db.SetApprovalPolicyTxParams{
+		AccountID: reqURI.ID,
+		Threshold: reqJSON.Threshold,
+		Approvers: reqJSON.Approvers,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrApproverNotHolder) {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// deleteApprovalPolicy removes the approval policy of the account, only the owners may remove it.
+func (s *Server) deleteApprovalPolicy(c *gin.Context) {
+	var req ApprovalPolicyRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.authorizeAccount(c, req.ID, permOwn); !ok {
+		return
+	}
+
+	if err := s.store.DeleteApprovalPolicy(c, req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// PendingTransferRequest holds URI parameters for pending transfer handlers.
+type PendingTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getPendingTransfer returns the pending transfer to the eligible approvers
+// and to the holders of the account the money is sent from.
+func (s *Server) getPendingTransfer(c *gin.Context) {
+	var req PendingTransferRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	pending, err := s.store.GetPendingTransfer(c, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	_, err = s.store.GetApprovalPolicyApprover(c, db.GetApprovalPolicyApproverParams{
+		AccountID: pending.FromAccountID,
+		Username:  authPayload(c).Username,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if err != nil {
+		if _, ok := s.authorizeAccount(c, pending.FromAccountID, permView); !ok {
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, pending)
+}
+
+func (s *Server) approvePendingTransfer(c *gin.Context) {
+	var req PendingTransferRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	result, err := s.store.ApprovePendingTransferTx(c, db.DecidePendingTransferTxParams{
+		PendingTransferID: req.ID,
+		Approver:          authPayload(c).Username,
+		PayeeCap:          s.payeeCap(),
+	})
+	if err != nil {
+		c.JSON(decisionErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) rejectPendingTransfer(c *gin.Context) {
+	var req PendingTransferRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	pending, err := s.store.RejectPendingTransferTx(c, db.DecidePendingTransferTxParams{
+		PendingTransferID: req.ID,
+		Approver:          authPayload(c).Username,
+	})
+	if err != nil {
+		c.JSON(decisionErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, pending)
+}
+
+// decisionErrorStatus maps errors of the approve and reject transactions to HTTP status codes.
+func decisionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrSelfApproval), errors.Is(err, db.ErrIneligibleApprover),
+		errors.Is(err, db.ErrInitiatorNotPermitted):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrPendingTransferDecided):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrPendingTransferExpired):
+		return http.StatusGone
+	default:
+		return transferErrorStatus(err)
+	}
+}
@@ -0,0 +1,480 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_SetApprovalPolicy(t *testing.T) {
+	account := db.Account{
+		ID:       util.RandomInt(1, 2048),
+		Owner:    util.RandomOwner(),
+		Balance:  util.RandomBalance(),
+		Currency: util.RandomCurrency(),
+	}
+	approvers := []string{util.RandomOwner(), util.RandomOwner()}
+	threshold := util.RandomAmount()
+
+	tests := []struct {
+		name          string
+		username      string
+		paramsJSON    api.SetApprovalPolicyRequestJSON
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			username:   account.Owner,
+			paramsJSON: api.SetApprovalPolicyRequestJSON{Threshold: threshold, Approvers: approvers},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("SetApprovalPolicyTx", mock.Anything, db.SetApprovalPolicyTxParams{
+					AccountID: account.ID,
+					Threshold: threshold,
+					Approvers: approvers,
+				}).Return(db.SetApprovalPolicyTxResult{
+					Policy:    db.ApprovalPolicy{AccountID: account.ID, Threshold: threshold},
+					Approvers: approvers,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.SetApprovalPolicyTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, threshold, result.Policy.Threshold)
+				assert.Equal(t, approvers, result.Approvers)
+			},
+		},
+		{
+			name:       "NoApprovers",
+			username:   account.Owner,
+			paramsJSON: api.SetApprovalPolicyRequestJSON{Threshold: threshold},
+			buildStub:  func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "NotOwner",
+			username:   "stranger",
+			paramsJSON: api.SetApprovalPolicyRequestJSON{Threshold: threshold, Approvers: approvers},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  "stranger",
+				}).Return(db.AccountHolder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "CoOwner",
+			username:   "co-owner",
+			paramsJSON: api.SetApprovalPolicyRequestJSON{Threshold: threshold, Approvers: approvers},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  "co-owner",
+				}).Return(db.AccountHolder{
+					AccountID: account.ID,
+					Username:  "co-owner",
+					Role:      db.HolderRoleCoOwner,
+					Status:    db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "ApproverNotHolder",
+			username:   account.Owner,
+			paramsJSON: api.SetApprovalPolicyRequestJSON{Threshold: threshold, Approvers: approvers},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("SetApprovalPolicyTx", mock.Anything, mock.Anything).
+					Return(db.SetApprovalPolicyTxResult{}, fmt.Errorf("tx err: %w", db.ErrApproverNotHolder))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "AccountNotFound",
+			username:   account.Owner,
+			paramsJSON: api.SetApprovalPolicyRequestJSON{Threshold: threshold, Approvers: approvers},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:       "InternalError",
+			username:   account.Owner,
+			paramsJSON: api.SetApprovalPolicyRequestJSON{Threshold: threshold, Approvers: approvers},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("SetApprovalPolicyTx", mock.Anything, mock.Anything).
+					Return(db.SetApprovalPolicyTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/approval-policy", account.ID)
+			b, err := json.Marshal(test.paramsJSON)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(b))
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetPendingTransfer(t *testing.T) {
+	user := util.RandomOwner()
+	pending := db.PendingTransfer{
+		ID:            util.RandomInt(1, 2048),
+		FromAccountID: util.RandomInt(1, 1024),
+		ToAccountID:   util.RandomInt(1025, 2048),
+		Amount:        util.RandomAmount(),
+		Initiator:     util.RandomOwner(),
+		Status:        db.PendingTransferStatusPending,
+	}
+	approverArg := db.GetApprovalPolicyApproverParams{
+		AccountID: pending.FromAccountID,
+		Username:  user,
+	}
+	holderArg := db.GetAccountHolderParams{
+		AccountID: pending.FromAccountID,
+		Username:  user,
+	}
+
+	tests := []struct {
+		name          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Approver",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPendingTransfer", mock.Anything, pending.ID).Return(pending, nil)
+				store.On("GetApprovalPolicyApprover", mock.Anything, approverArg).
+					Return(db.ApprovalPolicyApprover{AccountID: pending.FromAccountID, Username: user}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.PendingTransfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, pending.ID, result.ID)
+			},
+		},
+		{
+			name: "Holder",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPendingTransfer", mock.Anything, pending.ID).Return(pending, nil)
+				store.On("GetApprovalPolicyApprover", mock.Anything, approverArg).
+					Return(db.ApprovalPolicyApprover{}, sql.ErrNoRows)
+				store.On("GetAccount", mock.Anything, pending.FromAccountID).
+					Return(db.Account{ID: pending.FromAccountID, Owner: user}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Unauthorized",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPendingTransfer", mock.Anything, pending.ID).Return(pending, nil)
+				store.On("GetApprovalPolicyApprover", mock.Anything, approverArg).
+					Return(db.ApprovalPolicyApprover{}, sql.ErrNoRows)
+				store.On("GetAccount", mock.Anything, pending.FromAccountID).
+					Return(db.Account{ID: pending.FromAccountID, Owner: pending.Initiator}, nil)
+				store.On("GetAccountHolder", mock.Anything, holderArg).
+					Return(db.AccountHolder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.NotContains(t, recorder.Body.String(), "amount")
+			},
+		},
+		{
+			name: "NotFound",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPendingTransfer", mock.Anything, pending.ID).
+					Return(db.PendingTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPendingTransfer", mock.Anything, pending.ID).Return(pending, nil)
+				store.On("GetApprovalPolicyApprover", mock.Anything, approverArg).
+					Return(db.ApprovalPolicyApprover{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/transfers/pending/%d", pending.ID)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, user)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ApprovePendingTransfer(t *testing.T) {
+	approver := util.RandomOwner()
+	pending := db.PendingTransfer{
+		ID:            util.RandomInt(1, 2048),
+		FromAccountID: util.RandomInt(1, 1024),
+		ToAccountID:   util.RandomInt(1025, 2048),
+		Amount:        util.RandomAmount(),
+		Initiator:     util.RandomOwner(),
+		Status:        db.PendingTransferStatusApproved,
+	}
+	arg := db.DecidePendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approver:          approver,
+		PayeeCap: db.PayeeCap{
+			CoolingOff: testConfig.PayeeCoolingOff,
+			Limit:      testConfig.PayeeCoolingOffLimit,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *mocks.Store) {
+				store.On("ApprovePendingTransferTx", mock.Anything, arg).
+					Return(db.ApprovePendingTransferTxResult{PendingTransfer: pending}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.ApprovePendingTransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, db.PendingTransferStatusApproved, result.PendingTransfer.Status)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStub: func(store *mocks.Store) {
+				store.On("ApprovePendingTransferTx", mock.Anything, arg).
+					Return(db.ApprovePendingTransferTxResult{}, fmt.Errorf("tx err: %w", sql.ErrNoRows))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "SelfApproval",
+			buildStub: func(store *mocks.Store) {
+				store.On("ApprovePendingTransferTx", mock.Anything, arg).
+					Return(db.ApprovePendingTransferTxResult{}, fmt.Errorf("tx err: %w", db.ErrSelfApproval))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "IneligibleApprover",
+			buildStub: func(store *mocks.Store) {
+				store.On("ApprovePendingTransferTx", mock.Anything, arg).
+					Return(db.ApprovePendingTransferTxResult{}, fmt.Errorf("tx err: %w", db.ErrIneligibleApprover))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InitiatorNotPermitted",
+			buildStub: func(store *mocks.Store) {
+				store.On("ApprovePendingTransferTx", mock.Anything, arg).
+					Return(db.ApprovePendingTransferTxResult{}, fmt.Errorf("tx err: %w", db.ErrInitiatorNotPermitted))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyDecided",
+			buildStub: func(store *mocks.Store) {
+				store.On("ApprovePendingTransferTx", mock.Anything, arg).
+					Return(db.ApprovePendingTransferTxResult{}, fmt.Errorf("tx err: %w", db.ErrPendingTransferDecided))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "Expired",
+			buildStub: func(store *mocks.Store) {
+				store.On("ApprovePendingTransferTx", mock.Anything, arg).
+					Return(db.ApprovePendingTransferTxResult{}, fmt.Errorf("tx err: %w", db.ErrPendingTransferExpired))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusGone, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStub: func(store *mocks.Store) {
+				store.On("ApprovePendingTransferTx", mock.Anything, arg).
+					Return(db.ApprovePendingTransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/transfers/pending/%d/approve", pending.ID)
+			req := httptest.NewRequest(http.MethodPost, url, nil)
+			addAuthorization(t, req, approver)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_RejectPendingTransfer(t *testing.T) {
+	approver := util.RandomOwner()
+	pending := db.PendingTransfer{
+		ID:            util.RandomInt(1, 2048),
+		FromAccountID: util.RandomInt(1, 1024),
+		ToAccountID:   util.RandomInt(1025, 2048),
+		Amount:        util.RandomAmount(),
+		Initiator:     util.RandomOwner(),
+		Status:        db.PendingTransferStatusRejected,
+	}
+	arg := db.DecidePendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approver:          approver,
+	}
+
+	tests := []struct {
+		name          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *mocks.Store) {
+				store.On("RejectPendingTransferTx", mock.Anything, arg).Return(pending, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.PendingTransferStatusRejected, bytesToPendingTransfer(t, recorder.Body.Bytes()).Status)
+			},
+		},
+		{
+			name: "IneligibleApprover",
+			buildStub: func(store *mocks.Store) {
+				store.On("RejectPendingTransferTx", mock.Anything, arg).
+					Return(db.PendingTransfer{}, fmt.Errorf("tx err: %w", db.ErrIneligibleApprover))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/transfers/pending/%d/reject", pending.ID)
+			req := httptest.NewRequest(http.MethodPost, url, nil)
+			addAuthorization(t, req, approver)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
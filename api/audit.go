@@ -0,0 +1,123 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// auditVerifyBatchSize is the number of audit records verified at once.
+const auditVerifyBatchSize = 1000
+
+// ListAuditLogRequestQuery holds query parameters for listAuditLog handler.
+// Empty filters match every record, the period is not limited by default.
+type ListAuditLogRequestQuery struct {
+	Actor     string    `form:"actor"`
+	Action    string    `form:"action"`
+	Entity    string    `form:"entity"`
+	EntityID  string    `form:"entity_id"`
+	RequestID string    `form:"request_id"`
+	From      time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To        time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	PageNum   int32     `form:"page_num" binding:"required,min=1"`
+	PageSize  int32     `form:"page_size" binding:"required,min=1,max=1000"`
+}
+
+// listAuditLog lists the audit records matching the filters, the latest first.
+// Both From and To days are included.
+func (s *Server) listAuditLog(c *gin.Context) {
+	var req ListAuditLogRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	to := time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if !req.To.IsZero() {
+		to = req.To.AddDate(0, 0, 1)
+	}
+
+	if to.Before(req.From) {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidPeriod))
+
+		return
+	}
+
+	records, err := s.store.ListAuditLog(c, db.ListAuditLogParams{
+		Actor:       req.Actor,
+		Action:      req.Action,
+		Entity:      req.Entity,
+		EntityID:    req.EntityID,
+		RequestID:   req.RequestID,
+		FromTime:    req.From,
+		ToTime:      to,
+		MaxRecords:  req.PageSize,
+		SkipRecords: (req.PageNum - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// VerifyAuditLogResponse holds the result of verifyAuditLog handler. LastHash can be kept
+// outside of the database to detect the removal of the latest records later on.
+type VerifyAuditLogResponse struct {
+	Valid    bool   `json:"valid"`
+	Records  int    `json:"records"`
+	LastHash string `json:"last_hash"`
+	Error    string `json:"error,omitempty"`
+}
+
+// verifyAuditLog walks the whole hash chain of the audit log and reports the first
+// record which has been altered, removed or inserted. The records not chained yet by
+// the chaining job are not verified.
+func (s *Server) verifyAuditLog(c *gin.Context) {
+	var resp VerifyAuditLogResponse
+	var after int64
+
+	for {
+		records, err := s.store.ListAuditLogAfter(c, db.ListAuditLogAfterParams{
+			ChainSeq: after,
+			Limit:    auditVerifyBatchSize,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
+
+		if err := db.VerifyAuditChain(resp.LastHash, records); err != nil {
+			if !errors.Is(err, db.ErrAuditChainBroken) {
+				c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+				return
+			}
+
+			resp.Error = err.Error()
+			c.JSON(http.StatusOK, resp)
+
+			return
+		}
+
+		resp.Records += len(records)
+		if len(records) > 0 {
+			resp.LastHash = records[len(records)-1].Hash
+			after = records[len(records)-1].ChainSeq
+		}
+
+		if len(records) < auditVerifyBatchSize {
+			break
+		}
+	}
+
+	resp.Valid = true
+	c.JSON(http.StatusOK, resp)
+}
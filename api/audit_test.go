@@ -0,0 +1,224 @@
+package api_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// auditChain constructs n chained audit records.
+func auditChain(t *testing.T, n int) []db.AuditLog {
+	t.Helper()
+
+	records := make([]db.AuditLog, n)
+
+	var prevHash string
+	for i := range records {
+		records[i] = db.AuditLog{
+			ID:        int64(i + 1),
+			Actor:     util.RandomOwner(),
+			Action:    db.AuditActionUpdate,
+			Entity:    "accounts",
+			EntityID:  fmt.Sprint(util.RandomInt(1, 2048)),
+			Before:    json.RawMessage(`{"balance":1}`),
+			After:     json.RawMessage(`{"balance":2}`),
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+			PrevHash:  prevHash,
+			ChainSeq:  int64(i + 1),
+		}
+
+		hash, err := db.AuditHash(records[i])
+		require.NoError(t, err)
+
+		records[i].Hash = hash
+		prevHash = hash
+	}
+
+	return records
+}
+
+func TestServer_ListAuditLog(t *testing.T) {
+	records := auditChain(t, 3)
+
+	tests := []struct {
+		name          string
+		role          string
+		query         string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  db.UserRoleAuditor,
+			query: "entity=accounts&entity_id=7&from=2021-01-01&to=2021-01-31&page_num=2&page_size=10",
+			buildStub: func(store *mocks.Store) {
+				store.On("ListAuditLog", mock.Anything, db.ListAuditLogParams{
+					Entity:      "accounts",
+					EntityID:    "7",
+					FromTime:    time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+					ToTime:      time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC),
+					MaxRecords:  10,
+					SkipRecords: 10,
+				}).Return(records, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.NotEmpty(t, recorder.Header().Get("X-Request-ID"))
+
+				var result []db.AuditLog
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Len(t, result, len(records))
+				assert.Equal(t, records[0].Hash, result[0].Hash)
+			},
+		},
+		{
+			name:      "Support",
+			role:      db.UserRoleSupport,
+			query:     "page_num=1&page_size=10",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPeriod",
+			role:      db.UserRoleAdmin,
+			query:     "from=2021-02-01&to=2021-01-01&page_num=1&page_size=10",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			role:  db.UserRoleAuditor,
+			query: "page_num=1&page_size=10",
+			buildStub: func(store *mocks.Store) {
+				store.On("ListAuditLog", mock.Anything, mock.Anything).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit-log?"+test.query, nil)
+			addRoleAuthorization(t, req, util.RandomOwner(), test.role)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_VerifyAuditLog(t *testing.T) {
+	tests := []struct {
+		name          string
+		buildStub     func(store *mocks.Store, records []db.AuditLog)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, records []db.AuditLog)
+	}{
+		{
+			name: "Valid",
+			buildStub: func(store *mocks.Store, records []db.AuditLog) {
+				store.On("ListAuditLogAfter", mock.Anything, db.ListAuditLogAfterParams{Limit: 1000}).
+					Return(records, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, records []db.AuditLog) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.VerifyAuditLogResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.True(t, result.Valid)
+				assert.Equal(t, len(records), result.Records)
+				assert.Equal(t, records[len(records)-1].Hash, result.LastHash)
+			},
+		},
+		{
+			name: "Altered",
+			buildStub: func(store *mocks.Store, records []db.AuditLog) {
+				records[1].After = json.RawMessage(`{"balance":1000000}`)
+				store.On("ListAuditLogAfter", mock.Anything, mock.Anything).Return(records, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, records []db.AuditLog) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.VerifyAuditLogResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.False(t, result.Valid)
+				assert.Contains(t, result.Error, "record 2")
+			},
+		},
+		{
+			name: "Removed",
+			buildStub: func(store *mocks.Store, records []db.AuditLog) {
+				store.On("ListAuditLogAfter", mock.Anything, mock.Anything).
+					Return(append(records[:1:1], records[2:]...), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, records []db.AuditLog) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.VerifyAuditLogResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.False(t, result.Valid)
+				assert.Contains(t, result.Error, "record 3")
+			},
+		},
+		{
+			name: "InternalError",
+			buildStub: func(store *mocks.Store, records []db.AuditLog) {
+				store.On("ListAuditLogAfter", mock.Anything, mock.Anything).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, records []db.AuditLog) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			records := auditChain(t, 3)
+
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore, records)
+
+			// prepare request and response recorder
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit-log/verify", nil)
+			addRoleAuthorization(t, req, util.RandomOwner(), db.UserRoleAuditor)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder, records)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
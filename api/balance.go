@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// maxBalanceHistoryPoints limits the length of a balance history.
+const maxBalanceHistoryPoints = 400
+
+// ErrBalanceHistoryTooLong is returned when a balance history has too many points.
+var ErrBalanceHistoryTooLong = errors.New("the period is too long for the interval")
+
+// historyIntervalDays approximates the length of the balance history intervals in days.
+var historyIntervalDays = map[string]int{
+	"day":   1,
+	"week":  7,
+	"month": 28,
+}
+
+// GetAccountBalanceRequestURI holds URI parameters for getAccountBalance handler.
+type GetAccountBalanceRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// GetAccountBalanceRequestQuery holds query parameters for getAccountBalance handler.
+// The balance is taken at the end of the AsOf day, the current balance is returned by default.
+type GetAccountBalanceRequestQuery struct {
+	AsOf time.Time `form:"as_of" time_format:"2006-01-02" time_utc:"1"`
+}
+
+// GetAccountBalanceResponse holds the balance returned by getAccountBalance handler.
+type GetAccountBalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	AsOf      time.Time `json:"as_of"`
+	Balance   int64     `json:"balance"`
+}
+
+// getAccountBalance returns the balance of the account at a point in time. The balance
+// is computed from the nearest end-of-day snapshot and the entries created after it.
+func (s *Server) getAccountBalance(c *gin.Context) {
+	var reqURI GetAccountBalanceRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqQuery GetAccountBalanceRequestQuery
+	if err := c.ShouldBindQuery(&reqQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	account, ok := s.authorizeAccount(c, reqURI.ID, permView)
+	if !ok {
+		return
+	}
+
+	if reqQuery.AsOf.IsZero() {
+		c.JSON(http.StatusOK, GetAccountBalanceResponse{
+			AccountID: account.ID,
+			AsOf:      time.Now().UTC(),
+			Balance:   account.Balance,
+		})
+
+		return
+	}
+
+	asOf := reqQuery.AsOf.AddDate(0, 0, 1)
+
+	balance, err := s.store.GetAccountBalanceAsOf(c, db.GetAccountBalanceAsOfParams{
+		AsOf:      asOf,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAccountBalanceResponse{AccountID: account.ID, AsOf: asOf, Balance: balance})
+}
+
+// GetAccountBalanceHistoryRequestURI holds URI parameters for getAccountBalanceHistory handler.
+type GetAccountBalanceHistoryRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// GetAccountBalanceHistoryRequestQuery holds query parameters for getAccountBalanceHistory handler.
+// Both From and To days are included, the history ends today by default.
+type GetAccountBalanceHistoryRequestQuery struct {
+	From     time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To       time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+	Interval string    `form:"interval,default=day" binding:"oneof=day week month"`
+}
+
+// getAccountBalanceHistory returns the closing balances of the account for every
+// interval of the period, starting with the interval containing the From day.
+func (s *Server) getAccountBalanceHistory(c *gin.Context) {
+	var reqURI GetAccountBalanceHistoryRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqQuery GetAccountBalanceHistoryRequestQuery
+	if err := c.ShouldBindQuery(&reqQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if reqQuery.To.IsZero() {
+		now := time.Now().UTC()
+		reqQuery.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	if reqQuery.To.Before(reqQuery.From) {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidPeriod))
+
+		return
+	}
+
+	end := reqQuery.To.AddDate(0, 0, 1)
+
+	days := int(end.Sub(reqQuery.From) / (24 * time.Hour))
+	if days/historyIntervalDays[reqQuery.Interval] > maxBalanceHistoryPoints {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrBalanceHistoryTooLong))
+
+		return
+	}
+
+	if _, ok := s.authorizeAccount(c, reqURI.ID, permView); !ok {
+		return
+	}
+
+	history, err := s.store.GetAccountBalanceHistory(c, db.GetAccountBalanceHistoryParams{
+		Step:      reqQuery.Interval,
+		ToTime:    end,
+		FromTime:  reqQuery.From,
+		AccountID: reqURI.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
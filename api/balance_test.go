@@ -0,0 +1,221 @@
+package api_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_GetAccountBalance(t *testing.T) {
+	account := db.Account{
+		ID:      util.RandomInt(1, 2048),
+		Owner:   util.RandomOwner(),
+		Balance: util.RandomAmount(),
+	}
+	endOfDay := time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		query         string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "AsOf",
+			query: "?as_of=2021-03-31",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountBalanceAsOf", mock.Anything, db.GetAccountBalanceAsOfParams{
+					AsOf:      endOfDay,
+					AccountID: account.ID,
+				}).Return(int64(42), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.GetAccountBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, api.GetAccountBalanceResponse{
+					AccountID: account.ID,
+					AsOf:      endOfDay,
+					Balance:   42,
+				}, result)
+			},
+		},
+		{
+			name: "Current",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.GetAccountBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, account.Balance, result.Balance)
+			},
+		},
+		{
+			name:      "InvalidDate",
+			query:     "?as_of=31.3.2021",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "?as_of=2021-03-31",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountBalanceAsOf", mock.Anything, mock.Anything).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/balance%s", account.ID, test.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, account.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetAccountBalanceHistory(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	from := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	history := []db.GetAccountBalanceHistoryRow{
+		{Period: from, Balance: 100},
+		{Period: from.AddDate(0, 1, 0), Balance: 80},
+		{Period: from.AddDate(0, 2, 0), Balance: 120},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "from=2021-01-01&to=2021-03-31&interval=month",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountBalanceHistory", mock.Anything, db.GetAccountBalanceHistoryParams{
+					Step:      "month",
+					ToTime:    time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC),
+					FromTime:  from,
+					AccountID: account.ID,
+				}).Return(history, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result []db.GetAccountBalanceHistoryRow
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, history, result)
+			},
+		},
+		{
+			name:  "DefaultInterval",
+			query: "from=2021-01-01&to=2021-01-31",
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountBalanceHistory", mock.Anything, mock.MatchedBy(
+					func(arg db.GetAccountBalanceHistoryParams) bool {
+						return arg.Step == "day"
+					})).Return(history, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "MissingFrom",
+			query:     "to=2021-01-31",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidInterval",
+			query:     "from=2021-01-01&interval=year",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPeriod",
+			query:     "from=2021-02-01&to=2021-01-01",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "TooLong",
+			query:     "from=2000-01-01&to=2021-01-01&interval=day",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/balance-history?%s", account.ID, test.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, account.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrBatchRequiresApproval is returned when the legs of a batch exceed the threshold
+	// of an approval policy of the funding account.
+	ErrBatchRequiresApproval = errors.New("batch exceeds the approval threshold of the funding account")
+	// ErrBatchNotOwned is returned when the batch was submitted by another user.
+	ErrBatchNotOwned = errors.New("batch was submitted by another user")
+)
+
+// BatchTransferLeg holds parameters of a single leg of makeBatchTransfer handler.
+type BatchTransferLeg struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64 `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64 `json:"amount" binding:"required,min=1"`
+}
+
+// MakeBatchTransferRequest holds parameters for makeBatchTransfer handler.
+type MakeBatchTransferRequest struct {
+	Mode string             `json:"mode" binding:"required,oneof=atomic best_effort"`
+	Legs []BatchTransferLeg `json:"legs" binding:"required,min=1,max=1000,dive"`
+	// OTP is the TOTP code confirming a batch whose total is above the step-up amount.
+	OTP string `json:"otp"`
+}
+
+func (s *Server) makeBatchTransfer(c *gin.Context) {
+	var req MakeBatchTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	// sum up the outgoing amounts of every funding account
+	var fromAccountIDs []int64
+	var total int64
+	totals := make(map[int64]int64)
+	legs := make([]db.TransferTxParams, len(req.Legs))
+
+	for i, leg := range req.Legs {
+		if _, ok := totals[leg.FromAccountID]; !ok {
+			fromAccountIDs = append(fromAccountIDs, leg.FromAccountID)
+		}
+
+		totals[leg.FromAccountID] += leg.Amount
+		total += leg.Amount
+		legs[i] = db.TransferTxParams{
+			FromAccountID: leg.FromAccountID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        leg.Amount,
+			PayeeCap:      s.payeeCap(),
+		}
+	}
+
+	for _, id := range fromAccountIDs {
+		if !s.authorizeSpend(c, id, totals[id]) {
+			return
+		}
+	}
+
+	if !s.stepUpTransfer(c, total, req.OTP) {
+		return
+	}
+
+	// batches can not bypass the approval policies
+	for _, id := range fromAccountIDs {
+		policy, err := s.store.GetApprovalPolicy(c, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
+
+		if totals[id] > policy.Threshold {
+			c.JSON(http.StatusForbidden, errorResponse(ErrBatchRequiresApproval))
+
+			return
+		}
+	}
+
+	result, err := s.store.BatchTransferTx(c, db.BatchTransferTxParams{
+		Initiator: authPayload(c).Username,
+		Mode:      req.Mode,
+		Legs:      legs,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if result.Batch.Mode == db.BatchModeAtomic && result.Batch.Status == db.BatchStatusFailed {
+		c.JSON(http.StatusUnprocessableEntity, result)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetBatchRequest holds parameters for getBatch handler.
+type GetBatchRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getBatch(c *gin.Context) {
+	var req GetBatchRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	batch, err := s.store.GetBatch(c, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	if batch.Initiator != authPayload(c).Username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrBatchNotOwned))
+
+		return
+	}
+
+	legs, err := s.store.ListBatchLegs(c, batch.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, db.BatchTransferTxResult{
+		Batch: batch,
+		Legs:  legs,
+	})
+}
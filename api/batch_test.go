@@ -0,0 +1,288 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_MakeBatchTransfer(t *testing.T) {
+	username := util.RandomOwner()
+	fromAccountID := util.RandomInt(1, 1024)
+	legs := []api.BatchTransferLeg{
+		{FromAccountID: fromAccountID, ToAccountID: util.RandomInt(1025, 2048), Amount: util.RandomAmount()},
+		{FromAccountID: fromAccountID, ToAccountID: util.RandomInt(2049, 4096), Amount: util.RandomAmount()},
+	}
+	total := legs[0].Amount + legs[1].Amount
+	payeeCap := db.PayeeCap{
+		CoolingOff: testConfig.PayeeCoolingOff,
+		Limit:      testConfig.PayeeCoolingOffLimit,
+	}
+	params := func(mode string) db.BatchTransferTxParams {
+		return db.BatchTransferTxParams{
+			Initiator: username,
+			Mode:      mode,
+			Legs: []db.TransferTxParams{
+				{FromAccountID: legs[0].FromAccountID, ToAccountID: legs[0].ToAccountID, Amount: legs[0].Amount, PayeeCap: payeeCap},
+				{FromAccountID: legs[1].FromAccountID, ToAccountID: legs[1].ToAccountID, Amount: legs[1].Amount, PayeeCap: payeeCap},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		params        api.MakeBatchTransferRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Atomic",
+			params: api.MakeBatchTransferRequest{Mode: db.BatchModeAtomic, Legs: legs},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, fromAccountID).
+					Return(db.Account{ID: fromAccountID, Owner: username}, nil)
+				store.On("GetApprovalPolicy", mock.Anything, fromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("BatchTransferTx", mock.Anything, params(db.BatchModeAtomic)).
+					Return(db.BatchTransferTxResult{
+						Batch: db.Batch{Mode: db.BatchModeAtomic, Status: db.BatchStatusCompleted},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, db.BatchStatusCompleted, bytesToBatchResult(t, recorder.Body.Bytes()).Batch.Status)
+			},
+		},
+		{
+			name:   "AtomicFailed",
+			params: api.MakeBatchTransferRequest{Mode: db.BatchModeAtomic, Legs: legs},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, fromAccountID).
+					Return(db.Account{ID: fromAccountID, Owner: username}, nil)
+				store.On("GetApprovalPolicy", mock.Anything, fromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("BatchTransferTx", mock.Anything, params(db.BatchModeAtomic)).
+					Return(db.BatchTransferTxResult{
+						Batch: db.Batch{Mode: db.BatchModeAtomic, Status: db.BatchStatusFailed},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				assert.Equal(t, db.BatchStatusFailed, bytesToBatchResult(t, recorder.Body.Bytes()).Batch.Status)
+			},
+		},
+		{
+			name:   "BestEffort",
+			params: api.MakeBatchTransferRequest{Mode: db.BatchModeBestEffort, Legs: legs},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, fromAccountID).
+					Return(db.Account{ID: fromAccountID, Owner: username}, nil)
+				store.On("GetApprovalPolicy", mock.Anything, fromAccountID).
+					Return(db.ApprovalPolicy{AccountID: fromAccountID, Threshold: total}, nil)
+				store.On("BatchTransferTx", mock.Anything, params(db.BatchModeBestEffort)).
+					Return(db.BatchTransferTxResult{
+						Batch: db.Batch{Mode: db.BatchModeBestEffort, Status: db.BatchStatusPartiallyCompleted},
+						Legs: []db.BatchLeg{
+							{Position: 0, Status: db.BatchLegStatusCompleted},
+							{Position: 1, Status: db.BatchLegStatusFailed},
+						},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				result := bytesToBatchResult(t, recorder.Body.Bytes())
+				assert.Equal(t, db.BatchStatusPartiallyCompleted, result.Batch.Status)
+				assert.Len(t, result.Legs, 2)
+			},
+		},
+		{
+			name:   "RequiresApproval",
+			params: api.MakeBatchTransferRequest{Mode: db.BatchModeAtomic, Legs: legs},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, fromAccountID).
+					Return(db.Account{ID: fromAccountID, Owner: username}, nil)
+				store.On("GetApprovalPolicy", mock.Anything, fromAccountID).
+					Return(db.ApprovalPolicy{AccountID: fromAccountID, Threshold: total - 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "SpendLimitExceeded",
+			params: api.MakeBatchTransferRequest{Mode: db.BatchModeAtomic, Legs: legs},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, fromAccountID).
+					Return(db.Account{ID: fromAccountID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: fromAccountID,
+					Username:  username,
+				}).Return(db.AccountHolder{
+					AccountID:  fromAccountID,
+					Username:   username,
+					Role:       db.HolderRoleSignatory,
+					SpendLimit: sql.NullInt64{Int64: total - 1, Valid: true},
+					Status:     db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidMode",
+			params:    api.MakeBatchTransferRequest{Mode: "sometimes", Legs: legs},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidLeg",
+			params: api.MakeBatchTransferRequest{Mode: db.BatchModeAtomic, Legs: []api.BatchTransferLeg{
+				{FromAccountID: fromAccountID, ToAccountID: fromAccountID, Amount: util.RandomAmount()},
+			}},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			params: api.MakeBatchTransferRequest{Mode: db.BatchModeAtomic, Legs: legs},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, fromAccountID).
+					Return(db.Account{ID: fromAccountID, Owner: username}, nil)
+				store.On("GetApprovalPolicy", mock.Anything, fromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("BatchTransferTx", mock.Anything, params(db.BatchModeAtomic)).
+					Return(db.BatchTransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := "/transfers/batch"
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+			addAuthorization(t, req, username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetBatch(t *testing.T) {
+	batch := db.Batch{
+		ID:        util.RandomInt(1, 2048),
+		Initiator: util.RandomOwner(),
+		Mode:      db.BatchModeBestEffort,
+		Status:    db.BatchStatusCompleted,
+	}
+	legs := []db.BatchLeg{{BatchID: batch.ID, Status: db.BatchLegStatusCompleted}}
+
+	tests := []struct {
+		name          string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: batch.Initiator,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetBatch", mock.Anything, batch.ID).Return(batch, nil)
+				store.On("ListBatchLegs", mock.Anything, batch.ID).Return(legs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				result := bytesToBatchResult(t, recorder.Body.Bytes())
+				assert.Equal(t, batch.ID, result.Batch.ID)
+				assert.Len(t, result.Legs, 1)
+			},
+		},
+		{
+			name:     "NotOwned",
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetBatch", mock.Anything, batch.ID).Return(batch, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: batch.Initiator,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetBatch", mock.Anything, batch.ID).Return(db.Batch{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/transfers/batch/%d", batch.ID)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func bytesToBatchResult(t *testing.T, b []byte) db.BatchTransferTxResult {
+	t.Helper()
+
+	var result db.BatchTransferTxResult
+	err := json.Unmarshal(b, &result)
+	require.NoError(t, err)
+
+	return result
+}
@@ -0,0 +1,265 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrCategoryNotOwned is returned when the category belongs to another user
+	// or is a system category which can not be changed.
+	ErrCategoryNotOwned = errors.New("category belongs to another user")
+	// ErrCategoryRuleNotOwned is returned when the category rule belongs to another user.
+	ErrCategoryRuleNotOwned = errors.New("category rule belongs to another user")
+	// ErrEmptyCategoryRule is returned when a category rule matches nothing.
+	ErrEmptyCategoryRule = errors.New("category rule requires a counterparty account or a pattern")
+)
+
+// CreateCategoryRequest holds parameters for createCategory handler.
+type CreateCategoryRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+}
+
+func (s *Server) createCategory(c *gin.Context) {
+	var req CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	category, err := s.store.CreateCategory(c, db.CreateCategoryParams{
+		Owner: sql.NullString{String: authPayload(c).Username, Valid: true},
+		Name:  req.Name,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// listCategories lists the system categories followed by the categories of the user.
+func (s *Server) listCategories(c *gin.Context) {
+	categories, err := s.store.ListCategories(c, sql.NullString{String: authPayload(c).Username, Valid: true})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+// CategoryRequestURI holds URI parameters for category handlers.
+type CategoryRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) deleteCategory(c *gin.Context) {
+	var req CategoryRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	category, ok := s.usableCategory(c, req.ID)
+	if !ok {
+		return
+	}
+
+	if !category.Owner.Valid {
+		c.JSON(http.StatusForbidden, errorResponse(ErrCategoryNotOwned))
+
+		return
+	}
+
+	if err := s.store.DeleteCategory(c, req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// CreateCategoryRuleRequest holds parameters for createCategoryRule handler. A rule
+// matches entries by the counterparty account, by a pattern in their descriptions
+// or by both. The counterparty is referenced either by its ID or by its account number.
+type CreateCategoryRuleRequest struct {
+	CategoryID                int64  `json:"category_id" binding:"required,min=1"`
+	CounterpartyAccountID     int64  `json:"counterparty_account_id" binding:"min=0"`
+	CounterpartyAccountNumber string `json:"counterparty_account_number"`
+	Pattern                   string `json:"pattern" binding:"max=140"`
+	Priority                  int32  `json:"priority"`
+}
+
+func (s *Server) createCategoryRule(c *gin.Context) {
+	var req CreateCategoryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	hasCounterparty := req.CounterpartyAccountID != 0 || req.CounterpartyAccountNumber != ""
+	if !hasCounterparty && req.Pattern == "" {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrEmptyCategoryRule))
+
+		return
+	}
+
+	var counterparty sql.NullInt64
+	if hasCounterparty {
+		id, ok := s.resolveAccountID(c, req.CounterpartyAccountID, req.CounterpartyAccountNumber)
+		if !ok {
+			return
+		}
+
+		counterparty = sql.NullInt64{Int64: id, Valid: true}
+	}
+
+	if _, ok := s.usableCategory(c, req.CategoryID); !ok {
+		return
+	}
+
+	rule, err := s.store.CreateCategoryRule(c, db.CreateCategoryRuleParams{
+		Owner:                 authPayload(c).Username,
+		CategoryID:            req.CategoryID,
+		CounterpartyAccountID: counterparty,
+		Pattern:               sql.NullString{String: req.Pattern, Valid: req.Pattern != ""},
+		Priority:              req.Priority,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// listCategoryRules lists the category rules of the user in the order they are applied.
+func (s *Server) listCategoryRules(c *gin.Context) {
+	rules, err := s.store.ListCategoryRules(c, authPayload(c).Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (s *Server) deleteCategoryRule(c *gin.Context) {
+	var req CategoryRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	rule, err := s.store.GetCategoryRule(c, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	if rule.Owner != authPayload(c).Username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrCategoryRuleNotOwned))
+
+		return
+	}
+
+	if err := s.store.DeleteCategoryRule(c, req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// SetEntryCategoryRequestJSON holds JSON parameters for setEntryCategory handler.
+// A zero category ID removes the category of the entry.
+type SetEntryCategoryRequestJSON struct {
+	CategoryID int64 `json:"category_id" binding:"min=0"`
+}
+
+func (s *Server) setEntryCategory(c *gin.Context) {
+	var reqURI UpdateEntryRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqJSON SetEntryCategoryRequestJSON
+	if err := c.ShouldBindJSON(&reqJSON); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if !s.authorizeEntry(c, reqURI.ID) {
+		return
+	}
+
+	if reqJSON.CategoryID != 0 {
+		if _, ok := s.usableCategory(c, reqJSON.CategoryID); !ok {
+			return
+		}
+	}
+
+	entry, err := s.store.SetEntryCategory(c, db.SetEntryCategoryParams{
+		ID:         reqURI.ID,
+		CategoryID: sql.NullInt64{Int64: reqJSON.CategoryID, Valid: reqJSON.CategoryID != 0},
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// usableCategory returns the category with the given ID if it is either a system
+// category or a category of the authenticated user. It writes the error response
+// and returns false if not.
+func (s *Server) usableCategory(c *gin.Context, id int64) (db.Category, bool) {
+	category, err := s.store.GetCategory(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return category, false
+	}
+
+	if category.Owner.Valid && category.Owner.String != authPayload(c).Username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrCategoryNotOwned))
+
+		return category, false
+	}
+
+	return category, true
+}
@@ -0,0 +1,307 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_CreateCategory(t *testing.T) {
+	owner := util.RandomOwner()
+	category := db.Category{
+		ID:    util.RandomInt(1, 2048),
+		Owner: sql.NullString{String: owner, Valid: true},
+		Name:  "holidays",
+	}
+	arg := db.CreateCategoryParams{
+		Owner: category.Owner,
+		Name:  category.Name,
+	}
+
+	tests := []struct {
+		name          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *mocks.Store) {
+				store.On("CreateCategory", mock.Anything, arg).Return(category, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.Category
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, category, result)
+			},
+		},
+		{
+			name: "DuplicateName",
+			buildStub: func(store *mocks.Store) {
+				store.On("CreateCategory", mock.Anything, arg).Return(db.Category{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(api.CreateCategoryRequest{Name: category.Name})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/categories", bytes.NewReader(b))
+			addAuthorization(t, req, owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_DeleteCategory(t *testing.T) {
+	owner := util.RandomOwner()
+
+	tests := []struct {
+		name      string
+		category  db.Category
+		buildStub func(store *mocks.Store, category db.Category)
+		code      int
+	}{
+		{
+			name:     "OK",
+			category: db.Category{ID: util.RandomInt(1, 2048), Owner: sql.NullString{String: owner, Valid: true}},
+			buildStub: func(store *mocks.Store, category db.Category) {
+				store.On("DeleteCategory", mock.Anything, category.ID).Return(nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:      "SystemCategory",
+			category:  db.Category{ID: util.RandomInt(1, 2048), Name: "rent"},
+			buildStub: func(store *mocks.Store, category db.Category) {},
+			code:      http.StatusForbidden,
+		},
+		{
+			name:      "NotOwned",
+			category:  db.Category{ID: util.RandomInt(1, 2048), Owner: sql.NullString{String: util.RandomOwner(), Valid: true}},
+			buildStub: func(store *mocks.Store, category db.Category) {},
+			code:      http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			mockStore.On("GetCategory", mock.Anything, test.category.ID).Return(test.category, nil)
+			test.buildStub(mockStore, test.category)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/users/categories/%d", test.category.ID)
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			addAuthorization(t, req, owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			assert.Equal(t, test.code, recorder.Code)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_CreateCategoryRule(t *testing.T) {
+	owner := util.RandomOwner()
+	category := db.Category{ID: util.RandomInt(1, 2048), Name: "groceries"}
+	counterparty := util.RandomInt(1, 2048)
+	rule := db.CategoryRule{
+		ID:                    util.RandomInt(1, 2048),
+		Owner:                 owner,
+		CategoryID:            category.ID,
+		CounterpartyAccountID: sql.NullInt64{Int64: counterparty, Valid: true},
+		Pattern:               sql.NullString{String: "market", Valid: true},
+		Priority:              10,
+	}
+
+	tests := []struct {
+		name          string
+		params        api.CreateCategoryRuleRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			params: api.CreateCategoryRuleRequest{
+				CategoryID:            category.ID,
+				CounterpartyAccountID: counterparty,
+				Pattern:               rule.Pattern.String,
+				Priority:              rule.Priority,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetCategory", mock.Anything, category.ID).Return(category, nil)
+				store.On("CreateCategoryRule", mock.Anything, db.CreateCategoryRuleParams{
+					Owner:                 owner,
+					CategoryID:            category.ID,
+					CounterpartyAccountID: rule.CounterpartyAccountID,
+					Pattern:               rule.Pattern,
+					Priority:              rule.Priority,
+				}).Return(rule, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.CategoryRule
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, rule, result)
+			},
+		},
+		{
+			name:      "Empty",
+			params:    api.CreateCategoryRuleRequest{CategoryID: category.ID},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "CategoryNotOwned",
+			params: api.CreateCategoryRuleRequest{CategoryID: category.ID, Pattern: "market"},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetCategory", mock.Anything, category.ID).Return(db.Category{
+					ID:    category.ID,
+					Owner: sql.NullString{String: util.RandomOwner(), Valid: true},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/category-rules", bytes.NewReader(b))
+			addAuthorization(t, req, owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_SetEntryCategory(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	entry := db.Entry{
+		ID:        util.RandomInt(1, 2048),
+		AccountID: account.ID,
+		Amount:    -util.RandomAmount(),
+	}
+	category := db.Category{ID: util.RandomInt(1, 2048), Name: "rent"}
+
+	tests := []struct {
+		name       string
+		categoryID int64
+		buildStub  func(store *mocks.Store)
+		code       int
+	}{
+		{
+			name:       "OK",
+			categoryID: category.ID,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetCategory", mock.Anything, category.ID).Return(category, nil)
+				store.On("SetEntryCategory", mock.Anything, db.SetEntryCategoryParams{
+					ID:         entry.ID,
+					CategoryID: sql.NullInt64{Int64: category.ID, Valid: true},
+				}).Return(entry, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:       "Clear",
+			categoryID: 0,
+			buildStub: func(store *mocks.Store) {
+				store.On("SetEntryCategory", mock.Anything, db.SetEntryCategoryParams{ID: entry.ID}).Return(entry, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:       "CategoryNotFound",
+			categoryID: category.ID,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetCategory", mock.Anything, category.ID).Return(db.Category{}, sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			mockStore.On("GetEntry", mock.Anything, entry.ID).Return(entry, nil)
+			mockStore.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(api.SetEntryCategoryRequestJSON{CategoryID: test.categoryID})
+			require.NoError(t, err)
+			url := fmt.Sprintf("/entries/%d/category", entry.ID)
+			req := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(b))
+			addAuthorization(t, req, account.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			assert.Equal(t, test.code, recorder.Code)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrInvalidUserToken is returned when the emailed token does not exist or has another purpose.
+	ErrInvalidUserToken = errors.New("invalid token")
+	// ErrEmailNotVerified is returned when an unverified user logs in and verified emails are required.
+	ErrEmailNotVerified = errors.New("email address has not been verified")
+)
+
+// sendUserToken issues a single-use token of the purpose valid for the expiry
+// and emails it to the user.
+func (s *Server) sendUserToken(c *gin.Context, user db.User, purpose string, expiry time.Duration) error {
+	userToken, tokenHash, err := token.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.store.CreateUserToken(c, db.CreateUserTokenParams{
+		Username:  user.Username,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(expiry),
+	}); err != nil {
+		return fmt.Errorf("failed to create the token: %w", err)
+	}
+
+	msg := mail.Message{To: user.Email}
+
+	switch purpose {
+	case db.UserTokenVerifyEmail:
+		msg.Subject = "Verify your email address"
+		msg.Body = fmt.Sprintf("Hello %s,\n\nuse the following token to verify your email address:\n\n%s\n\n"+
+			"The token expires in %s.\n", user.FirstName, userToken, expiry)
+	case db.UserTokenResetPassword:
+		msg.Subject = "Reset your password"
+		msg.Body = fmt.Sprintf("Hello %s,\n\nuse the following token to reset your password:\n\n%s\n\n"+
+			"The token expires in %s. If you have not requested the reset, ignore this email.\n",
+			user.FirstName, userToken, expiry)
+	}
+
+	if err := s.mailer.Send(c, msg); err != nil {
+		return fmt.Errorf("failed to send the token: %w", err)
+	}
+
+	return nil
+}
+
+// SendUserTokenRequest holds parameters for sendEmailVerification and requestPasswordReset handlers.
+type SendUserTokenRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+// sendEmailVerification emails a new verification token to the unverified user of the email.
+// It responds the same whether the user exists or not, so it can not be used to find
+// the registered addresses. Failures to send the email are only logged.
+func (s *Server) sendEmailVerification(c *gin.Context) {
+	var req SendUserTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	user, err := s.store.GetUserByEmail(c, req.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if err == nil && !user.EmailVerifiedAt.Valid {
+		if err := s.sendUserToken(c, user, db.UserTokenVerifyEmail, s.config.EmailVerificationExpiry); err != nil {
+			_ = c.Error(err)
+		}
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// ConfirmUserTokenRequest holds parameters for confirmEmailVerification handler.
+type ConfirmUserTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// confirmEmailVerification verifies the email address with the emailed token.
+func (s *Server) confirmEmailVerification(c *gin.Context) {
+	var req ConfirmUserTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	user, err := s.store.VerifyEmailTx(c, token.HashOpaqueToken(req.Token))
+	if err != nil {
+		c.JSON(userTokenErrorStatus(err), userTokenErrorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// requestPasswordReset emails a password reset token to the user of the email.
+// It responds the same whether the user exists or not, so it can not be used to find
+// the registered addresses. Failures to send the email are only logged.
+func (s *Server) requestPasswordReset(c *gin.Context) {
+	var req SendUserTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	user, err := s.store.GetUserByEmail(c, req.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if err == nil {
+		if err := s.sendUserToken(c, user, db.UserTokenResetPassword, s.config.PasswordResetExpiry); err != nil {
+			_ = c.Error(err)
+		}
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// ConfirmPasswordResetRequest holds parameters for confirmPasswordReset handler.
+type ConfirmPasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// confirmPasswordReset sets the new password with the emailed token
+// and logs the user out of all sessions.
+func (s *Server) confirmPasswordReset(c *gin.Context) {
+	var req ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	hashedPassword, ok := s.hashNewPassword(c, req.NewPassword)
+	if !ok {
+		return
+	}
+
+	user, err := s.store.ResetPasswordTx(c, db.ResetPasswordTxParams{
+		TokenHash:      token.HashOpaqueToken(req.Token),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		c.JSON(userTokenErrorStatus(err), userTokenErrorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// userTokenErrorStatus maps errors of the emailed token transactions to HTTP status codes.
+func userTokenErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrUserTokenUsed), errors.Is(err, db.ErrUserTokenExpired):
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// userTokenErrorResponse hides the details of a missing token.
+func userTokenErrorResponse(err error) gin.H {
+	if errors.Is(err, sql.ErrNoRows) {
+		return errorResponse(ErrInvalidUserToken)
+	}
+
+	return errorResponse(err)
+}
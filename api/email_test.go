@@ -0,0 +1,396 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// mailedToken returns the line of the message body which is the token with the hash.
+func mailedToken(t *testing.T, msg mail.Message, tokenHash string) string {
+	t.Helper()
+
+	for _, line := range strings.Split(msg.Body, "\n") {
+		if line != "" && token.HashOpaqueToken(line) == tokenHash {
+			return line
+		}
+	}
+
+	t.Fatalf("no token in the message: %q", msg.Body)
+
+	return ""
+}
+
+func TestServer_SendUserToken(t *testing.T) {
+	user := db.User{
+		Username:  util.RandomOwner(),
+		FirstName: util.RandomOwner(),
+		Email:     util.RandomEmail(),
+	}
+	verified := user
+	verified.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	tests := []struct {
+		name          string
+		url           string
+		params        api.SendUserTokenRequest
+		buildStub     func(store *mocks.Store, tokenHash *string)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+		mailed        bool
+	}{
+		{
+			name:   "Verification",
+			url:    "/users/email-verification",
+			params: api.SendUserTokenRequest{Email: user.Email},
+			buildStub: func(store *mocks.Store, tokenHash *string) {
+				store.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+				store.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(arg db.CreateUserTokenParams) bool {
+					*tokenHash = arg.TokenHash
+
+					return arg.Username == user.Username && arg.Purpose == db.UserTokenVerifyEmail &&
+						arg.ExpiresAt.After(time.Now().Add(testConfig.EmailVerificationExpiry-time.Minute))
+				})).Return(db.UserToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+			mailed: true,
+		},
+		{
+			name:   "AlreadyVerified",
+			url:    "/users/email-verification",
+			params: api.SendUserTokenRequest{Email: user.Email},
+			buildStub: func(store *mocks.Store, tokenHash *string) {
+				store.On("GetUserByEmail", mock.Anything, user.Email).Return(verified, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "PasswordReset",
+			url:    "/users/password-reset",
+			params: api.SendUserTokenRequest{Email: user.Email},
+			buildStub: func(store *mocks.Store, tokenHash *string) {
+				store.On("GetUserByEmail", mock.Anything, user.Email).Return(verified, nil)
+				store.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(arg db.CreateUserTokenParams) bool {
+					*tokenHash = arg.TokenHash
+
+					return arg.Username == user.Username && arg.Purpose == db.UserTokenResetPassword &&
+						arg.ExpiresAt.Before(time.Now().Add(testConfig.PasswordResetExpiry+time.Minute))
+				})).Return(db.UserToken{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+			mailed: true,
+		},
+		{
+			name:   "UnknownEmail",
+			url:    "/users/password-reset",
+			params: api.SendUserTokenRequest{Email: user.Email},
+			buildStub: func(store *mocks.Store, tokenHash *string) {
+				store.On("GetUserByEmail", mock.Anything, user.Email).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// the response does not reveal whether the user exists
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "TokenError",
+			url:    "/users/password-reset",
+			params: api.SendUserTokenRequest{Email: user.Email},
+			buildStub: func(store *mocks.Store, tokenHash *string) {
+				store.On("GetUserByEmail", mock.Anything, user.Email).Return(user, nil)
+				store.On("CreateUserToken", mock.Anything, mock.Anything).Return(db.UserToken{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			url:    "/users/email-verification",
+			params: api.SendUserTokenRequest{Email: user.Email},
+			buildStub: func(store *mocks.Store, tokenHash *string) {
+				store.On("GetUserByEmail", mock.Anything, user.Email).Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRequest",
+			url:       "/users/password-reset",
+			params:    api.SendUserTokenRequest{},
+			buildStub: func(store *mocks.Store, tokenHash *string) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			var tokenHash string
+			mockStore := new(mocks.Store)
+			mailer := mail.NewMemoryMailer()
+			server := newTestServerWithMailer(t, mockStore, mailer, testConfig)
+			test.buildStub(mockStore, &tokenHash)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, test.url, bytes.NewReader(b))
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+
+			messages := mailer.Messages()
+			if !test.mailed {
+				assert.Empty(t, messages)
+
+				return
+			}
+
+			// the email carries the token of the stored hash
+			require.Len(t, messages, 1)
+			assert.Equal(t, user.Email, messages[0].To)
+			assert.NotEmpty(t, mailedToken(t, messages[0], tokenHash))
+		})
+	}
+}
+
+func TestServer_ConfirmEmailVerification(t *testing.T) {
+	userToken, tokenHash, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+
+	user := db.User{
+		Username:        util.RandomOwner(),
+		Email:           util.RandomEmail(),
+		HashedPassword:  util.RandomString(32),
+		EmailVerifiedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	tests := []struct {
+		name          string
+		params        api.ConfirmUserTokenRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			params: api.ConfirmUserTokenRequest{Token: userToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("VerifyEmailTx", mock.Anything, tokenHash).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.NotContains(t, recorder.Body.String(), user.HashedPassword)
+
+				var body api.UserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, user.Username, body.Username)
+				assert.True(t, body.EmailVerified)
+			},
+		},
+		{
+			name:   "InvalidToken",
+			params: api.ConfirmUserTokenRequest{Token: userToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("VerifyEmailTx", mock.Anything, tokenHash).
+					Return(db.User{}, fmt.Errorf("can not verify email: %w", sql.ErrNoRows))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "Used",
+			params: api.ConfirmUserTokenRequest{Token: userToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("VerifyEmailTx", mock.Anything, tokenHash).
+					Return(db.User{}, fmt.Errorf("can not verify email: %w", db.ErrUserTokenUsed))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusGone, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			params: api.ConfirmUserTokenRequest{Token: userToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("VerifyEmailTx", mock.Anything, tokenHash).Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRequest",
+			params:    api.ConfirmUserTokenRequest{},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/email-verification/confirm", bytes.NewReader(b))
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ConfirmPasswordReset(t *testing.T) {
+	userToken, tokenHash, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+
+	user := db.User{
+		Username: util.RandomOwner(),
+		Email:    util.RandomEmail(),
+	}
+	newPassword := util.RandomString(12)
+
+	tests := []struct {
+		name          string
+		params        api.ConfirmPasswordResetRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			params: api.ConfirmPasswordResetRequest{Token: userToken, NewPassword: newPassword},
+			buildStub: func(store *mocks.Store) {
+				store.On("ResetPasswordTx", mock.Anything, mock.MatchedBy(func(arg db.ResetPasswordTxParams) bool {
+					return arg.TokenHash == tokenHash && matchPassword(newPassword)(arg.HashedPassword)
+				})).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Expired",
+			params: api.ConfirmPasswordResetRequest{Token: userToken, NewPassword: newPassword},
+			buildStub: func(store *mocks.Store) {
+				store.On("ResetPasswordTx", mock.Anything, mock.Anything).
+					Return(db.User{}, fmt.Errorf("can not reset password: %w", db.ErrUserTokenExpired))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusGone, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidToken",
+			params: api.ConfirmPasswordResetRequest{Token: userToken, NewPassword: newPassword},
+			buildStub: func(store *mocks.Store) {
+				store.On("ResetPasswordTx", mock.Anything, mock.Anything).
+					Return(db.User{}, fmt.Errorf("can not reset password: %w", sql.ErrNoRows))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "ShortPassword",
+			params:    api.ConfirmPasswordResetRequest{Token: userToken, NewPassword: "abc"},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/password-reset/confirm", bytes.NewReader(b))
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_LoginUser_RequireVerifiedEmail(t *testing.T) {
+	plainPassword := util.RandomOwner()
+	user := db.User{
+		Username:       util.RandomOwner(),
+		HashedPassword: hashPassword(t, plainPassword),
+		Role:           db.UserRoleCustomer,
+	}
+
+	cfg := *testConfig
+	cfg.RequireVerifiedEmail = true
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServerWithMailer(t, mockStore, mail.NewMemoryMailer(), &cfg)
+	mockStore.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+
+	// prepare request and response recorder
+	b, err := json.Marshal(api.LoginUserRequest{Username: user.Username, Password: plainPassword})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(b))
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	// check response
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	mockStore.AssertExpectations(t)
+}
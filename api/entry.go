@@ -32,6 +32,10 @@ func (s *Server) getEntryByID(c *gin.Context) {
 		return
 	}
 
+	if _, ok := s.authorizeAccount(c, entry.AccountID, permView); !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, entry)
 }
 
@@ -42,6 +46,8 @@ type ListEntriesRequestURI struct {
 type ListEntriesRequestQuery struct {
 	PageNum  int32 `form:"page_num" binding:"required,numeric,min=1"`
 	PageSize int32 `form:"page_size" binding:"required,numeric,min=1"`
+	// Q filters the entries by a full-text search over their descriptions.
+	Q string `form:"q"`
 }
 
 func (s *Server) listEntries(c *gin.Context) {
@@ -59,11 +65,26 @@ func (s *Server) listEntries(c *gin.Context) {
 		return
 	}
 
-	entries, err := s.store.ListEntries(c, db.ListEntriesParams{
-		AccountID: reqURI.AccountID,
-		Limit:     reqQuery.PageSize,
-		Offset:    (reqQuery.PageNum - 1) * reqQuery.PageSize,
-	})
+	if _, ok := s.authorizeAccount(c, reqURI.AccountID, permView); !ok {
+		return
+	}
+
+	var entries []db.Entry
+	var err error
+	if reqQuery.Q != "" {
+		entries, err = s.store.SearchEntries(c, db.SearchEntriesParams{
+			AccountID:  reqURI.AccountID,
+			Query:      reqQuery.Q,
+			PageSize:   reqQuery.PageSize,
+			PageOffset: (reqQuery.PageNum - 1) * reqQuery.PageSize,
+		})
+	} else {
+		entries, err = s.store.ListEntries(c, db.ListEntriesParams{
+			AccountID: reqURI.AccountID,
+			Limit:     reqQuery.PageSize,
+			Offset:    (reqQuery.PageNum - 1) * reqQuery.PageSize,
+		})
+	}
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, errorResponse(err))
@@ -78,8 +99,9 @@ func (s *Server) listEntries(c *gin.Context) {
 }
 
 type CreateEntryRequest struct {
-	AccountID int64 `json:"account_id" binding:"required,min=1"`
-	Amount    int64 `json:"amount" binding:"required,min=1"`
+	AccountID   int64  `json:"account_id" binding:"required,min=1"`
+	Amount      int64  `json:"amount" binding:"required,min=1"`
+	Description string `json:"description" binding:"max=500"`
 }
 
 func (s *Server) createEntry(c *gin.Context) {
@@ -90,9 +112,14 @@ func (s *Server) createEntry(c *gin.Context) {
 		return
 	}
 
+	if _, ok := s.authorizeAccount(c, req.AccountID, permManage); !ok {
+		return
+	}
+
 	entry, err := s.store.CreateEntry(c, db.CreateEntryParams{
-		AccountID: req.AccountID,
-		Amount:    req.Amount,
+		AccountID:   req.AccountID,
+		Amount:      req.Amount,
+		Description: req.Description,
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -130,6 +157,10 @@ func (s *Server) updateEntry(c *gin.Context) {
 		return
 	}
 
+	if !s.authorizeEntry(c, reqURI.ID) {
+		return
+	}
+
 	entry, err := s.store.UpdateEntryAmount(c, db.UpdateEntryAmountParams{
 		ID:     reqURI.ID,
 		Amount: reqJSON.Amount,
@@ -159,6 +190,10 @@ func (s *Server) deleteEntry(c *gin.Context) {
 		return
 	}
 
+	if !s.authorizeEntry(c, req.ID) {
+		return
+	}
+
 	if err := s.store.DeleteEntry(c, req.ID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, errorResponse(err))
@@ -171,3 +206,22 @@ func (s *Server) deleteEntry(c *gin.Context) {
 
 	c.JSON(http.StatusOK, nil)
 }
+
+// authorizeEntry checks whether the authenticated user may manage the account
+// of the entry. It writes the error response and returns false if not.
+func (s *Server) authorizeEntry(c *gin.Context, id int64) bool {
+	entry, err := s.store.GetEntry(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return false
+	}
+
+	_, ok := s.authorizeAccount(c, entry.AccountID, permManage)
+
+	return ok
+}
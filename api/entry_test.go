@@ -19,9 +19,14 @@ import (
 )
 
 func TestServer_GetEntryByID(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
 	entry := db.Entry{
 		ID:        util.RandomInt(1, 2048),
-		AccountID: util.RandomInt(1, 2048),
+		AccountID: account.ID,
 		Amount:    util.RandomAmount(),
 	}
 
@@ -36,6 +41,7 @@ func TestServer_GetEntryByID(t *testing.T) {
 			params: api.GetEntryByIDRequest{ID: entry.ID},
 			buildStub: func(store *mocks.Store) {
 				store.On("GetEntry", mock.Anything, entry.ID).Return(entry, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, resp.Code)
@@ -60,6 +66,22 @@ func TestServer_GetEntryByID(t *testing.T) {
 				assert.Equal(t, http.StatusNotFound, resp.Code)
 			},
 		},
+		{
+			name:   "NotHolder",
+			params: api.GetEntryByIDRequest{ID: entry.ID},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetEntry", mock.Anything, entry.ID).Return(entry, nil)
+				store.On("GetAccount", mock.Anything, account.ID).
+					Return(db.Account{ID: account.ID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  account.Owner,
+				}).Return(db.AccountHolder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
 		{
 			name:   "InternalError",
 			params: api.GetEntryByIDRequest{ID: entry.ID},
@@ -76,12 +98,13 @@ func TestServer_GetEntryByID(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct a server with a mock db.Store
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
 			url := fmt.Sprintf("/entries/id/%d", test.params.ID)
 			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, account.Owner)
 			resp := httptest.NewRecorder()
 
 			// server request
@@ -96,7 +119,8 @@ func TestServer_GetEntryByID(t *testing.T) {
 
 func TestServer_ListEntries(t *testing.T) {
 	account := db.Account{
-		ID: util.RandomInt(0, 2048),
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
 	}
 
 	entries := []db.Entry{
@@ -129,6 +153,7 @@ func TestServer_ListEntries(t *testing.T) {
 				PageSize: 10,
 			},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("ListEntries", mock.Anything, db.ListEntriesParams{
 					AccountID: account.ID,
 					Limit:     10,
@@ -140,6 +165,30 @@ func TestServer_ListEntries(t *testing.T) {
 				assert.Equal(t, entries, bufferToEntries(t, resp.Body))
 			},
 		},
+		{
+			name: "Search",
+			paramURI: api.ListEntriesRequestURI{
+				AccountID: account.ID,
+			},
+			paramQuery: api.ListEntriesRequestQuery{
+				PageNum:  2,
+				PageSize: 10,
+				Q:        "rent",
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("SearchEntries", mock.Anything, db.SearchEntriesParams{
+					AccountID:  account.ID,
+					Query:      "rent",
+					PageSize:   10,
+					PageOffset: 10,
+				}).Return(entries[:1], nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, entries[:1], bufferToEntries(t, resp.Body))
+			},
+		},
 		{
 			name: "InvalidID",
 			paramURI: api.ListEntriesRequestURI{
@@ -179,6 +228,7 @@ func TestServer_ListEntries(t *testing.T) {
 				PageSize: 10,
 			},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("ListEntries", mock.Anything, db.ListEntriesParams{
 					AccountID: account.ID,
 					Limit:     10,
@@ -199,6 +249,7 @@ func TestServer_ListEntries(t *testing.T) {
 				PageSize: 10,
 			},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("ListEntries", mock.Anything, db.ListEntriesParams{
 					AccountID: account.ID,
 					Limit:     10,
@@ -215,13 +266,14 @@ func TestServer_ListEntries(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct server with mock db
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
-			url := fmt.Sprintf("/entries/accountid/%d?page_num=%d&page_size=%d",
-				test.paramURI.AccountID, test.paramQuery.PageNum, test.paramQuery.PageSize)
+			url := fmt.Sprintf("/entries/accountid/%d?page_num=%d&page_size=%d&q=%s",
+				test.paramURI.AccountID, test.paramQuery.PageNum, test.paramQuery.PageSize, test.paramQuery.Q)
 			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, account.Owner)
 			resp := httptest.NewRecorder()
 
 			// serve
@@ -235,9 +287,14 @@ func TestServer_ListEntries(t *testing.T) {
 }
 
 func TestServer_CreateEntry(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
 	entry := db.Entry{
 		ID:        util.RandomInt(1, 1024),
-		AccountID: util.RandomInt(0, 2048),
+		AccountID: account.ID,
 		Amount:    util.RandomAmount(),
 	}
 
@@ -254,6 +311,7 @@ func TestServer_CreateEntry(t *testing.T) {
 				Amount:    entry.Amount,
 			},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("CreateEntry", mock.Anything, db.CreateEntryParams{
 					AccountID: entry.AccountID,
 					Amount:    entry.Amount,
@@ -281,10 +339,7 @@ func TestServer_CreateEntry(t *testing.T) {
 				Amount:    entry.Amount,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("CreateEntry", mock.Anything, db.CreateEntryParams{
-					AccountID: entry.AccountID,
-					Amount:    entry.Amount,
-				}).Return(db.Entry{}, sql.ErrNoRows)
+				store.On("GetAccount", mock.Anything, account.ID).Return(db.Account{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusNotFound, resp.Code)
@@ -297,6 +352,7 @@ func TestServer_CreateEntry(t *testing.T) {
 				Amount:    entry.Amount,
 			},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("CreateEntry", mock.Anything, db.CreateEntryParams{
 					AccountID: entry.AccountID,
 					Amount:    entry.Amount,
@@ -312,7 +368,7 @@ func TestServer_CreateEntry(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct server with mock db
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
@@ -320,6 +376,7 @@ func TestServer_CreateEntry(t *testing.T) {
 			b, err := json.Marshal(test.param)
 			require.NoError(t, err)
 			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+			addAuthorization(t, req, account.Owner)
 			resp := httptest.NewRecorder()
 
 			// serve
@@ -334,7 +391,8 @@ func TestServer_CreateEntry(t *testing.T) {
 
 func TestServer_UpdateEntry(t *testing.T) {
 	account := db.Account{
-		ID: util.RandomInt(1, 2048),
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
 	}
 
 	entry1 := db.Entry{
@@ -361,6 +419,8 @@ func TestServer_UpdateEntry(t *testing.T) {
 			paramURI:  api.UpdateEntryRequestURI{ID: entry1.ID},
 			paramJSON: api.UpdateEntryRequestJSON{Amount: entry2.Amount},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetEntry", mock.Anything, entry1.ID).Return(entry1, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("UpdateEntryAmount", mock.Anything, db.UpdateEntryAmountParams{
 					ID:     entry1.ID,
 					Amount: entry2.Amount,
@@ -394,10 +454,7 @@ func TestServer_UpdateEntry(t *testing.T) {
 			paramURI:  api.UpdateEntryRequestURI{ID: entry1.ID},
 			paramJSON: api.UpdateEntryRequestJSON{Amount: entry2.Amount},
 			buildStub: func(store *mocks.Store) {
-				store.On("UpdateEntryAmount", mock.Anything, db.UpdateEntryAmountParams{
-					ID:     entry1.ID,
-					Amount: entry2.Amount,
-				}).Return(db.Entry{}, sql.ErrNoRows)
+				store.On("GetEntry", mock.Anything, entry1.ID).Return(db.Entry{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusNotFound, resp.Code)
@@ -408,6 +465,8 @@ func TestServer_UpdateEntry(t *testing.T) {
 			paramURI:  api.UpdateEntryRequestURI{ID: entry1.ID},
 			paramJSON: api.UpdateEntryRequestJSON{Amount: entry2.Amount},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetEntry", mock.Anything, entry1.ID).Return(entry1, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("UpdateEntryAmount", mock.Anything, db.UpdateEntryAmountParams{
 					ID:     entry1.ID,
 					Amount: entry2.Amount,
@@ -423,7 +482,7 @@ func TestServer_UpdateEntry(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct server with mock db
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
@@ -431,6 +490,7 @@ func TestServer_UpdateEntry(t *testing.T) {
 			b, err := json.Marshal(test.paramJSON)
 			require.NoError(t, err)
 			req := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(b))
+			addAuthorization(t, req, account.Owner)
 			resp := httptest.NewRecorder()
 
 			// serve
@@ -444,9 +504,14 @@ func TestServer_UpdateEntry(t *testing.T) {
 }
 
 func TestServer_DeleteEntry(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
 	entry := db.Entry{
 		ID:        util.RandomInt(1, 1024),
-		AccountID: util.RandomInt(1, 2048),
+		AccountID: account.ID,
 		Amount:    util.RandomAmount(),
 	}
 
@@ -460,6 +525,8 @@ func TestServer_DeleteEntry(t *testing.T) {
 			name:  "OK",
 			param: api.DeleteEntryRequest{ID: entry.ID},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetEntry", mock.Anything, entry.ID).Return(entry, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("DeleteEntry", mock.Anything, entry.ID).Return(nil)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
@@ -478,16 +545,40 @@ func TestServer_DeleteEntry(t *testing.T) {
 			name:  "NotFound",
 			param: api.DeleteEntryRequest{ID: entry.ID},
 			buildStub: func(store *mocks.Store) {
-				store.On("DeleteEntry", mock.Anything, entry.ID).Return(sql.ErrNoRows)
+				store.On("GetEntry", mock.Anything, entry.ID).Return(db.Entry{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusNotFound, resp.Code)
 			},
 		},
+		{
+			name:  "Signatory",
+			param: api.DeleteEntryRequest{ID: entry.ID},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetEntry", mock.Anything, entry.ID).Return(entry, nil)
+				store.On("GetAccount", mock.Anything, account.ID).
+					Return(db.Account{ID: account.ID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  account.Owner,
+				}).Return(db.AccountHolder{
+					AccountID:  account.ID,
+					Username:   account.Owner,
+					Role:       db.HolderRoleSignatory,
+					SpendLimit: sql.NullInt64{Int64: 100, Valid: true},
+					Status:     db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
 		{
 			name:  "InternalError",
 			param: api.DeleteEntryRequest{ID: entry.ID},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetEntry", mock.Anything, entry.ID).Return(entry, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
 				store.On("DeleteEntry", mock.Anything, entry.ID).Return(sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
@@ -500,12 +591,13 @@ func TestServer_DeleteEntry(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct server with mock db
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
 			url := fmt.Sprintf("/entries/%d", test.param.ID)
 			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			addAuthorization(t, req, account.Owner)
 			resp := httptest.NewRecorder()
 
 			// serve
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrAccountNotOwned is returned when the authenticated user is not a holder of the account.
+	ErrAccountNotOwned = errors.New("account does not belong to the authenticated user")
+	// ErrPermissionDenied is returned when the role of the holder does not permit the action.
+	ErrPermissionDenied = errors.New("the role of the account holder does not permit the action")
+	// ErrSpendLimitExceeded is returned when a signatory exceeds its spend limit.
+	ErrSpendLimitExceeded = errors.New("amount exceeds the spend limit of the signatory")
+	// ErrMissingSpendLimit is returned when a signatory is invited without a spend limit.
+	ErrMissingSpendLimit = errors.New("signatory requires a spend limit")
+	// ErrPrimaryHolder is returned when the primary holder of the account is invited or removed.
+	ErrPrimaryHolder = errors.New("the primary holder of the account can not be changed")
+)
+
+// permission is a level of access to an account.
+type permission int
+
+const (
+	// permView allows reading the account.
+	permView permission = iota
+	// permSpend allows sending money from the account.
+	permSpend
+	// permManage allows changing the account and its settings.
+	permManage
+	// permOwn allows managing the holders and deleting the account.
+	permOwn
+)
+
+// rolePermissions maps roles of account holders to their highest permission.
+var rolePermissions = map[string]permission{
+	db.HolderRoleOwner:     permOwn,
+	db.HolderRoleCoOwner:   permManage,
+	db.HolderRoleSignatory: permSpend,
+	db.HolderRoleViewer:    permView,
+}
+
+// accountHolder returns the account and the holder record of the authenticated user.
+// It writes the error response and returns false if the user is not an active holder
+// of the account.
+func (s *Server) accountHolder(c *gin.Context, accountID int64) (db.Account, db.AccountHolder, bool) {
+	account, holder, err := s.lookupAccountHolder(c, accountID, authPayload(c).Username)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		case errors.Is(err, ErrAccountNotOwned):
+			c.JSON(http.StatusForbidden, errorResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return account, holder, false
+	}
+
+	return account, holder, true
+}
+
+// lookupAccountHolder returns the account and the holder record of the user. The primary
+// holder in the Owner column is always an owner. ErrAccountNotOwned is returned if
+// the user is not an active holder of the account.
+func (s *Server) lookupAccountHolder(ctx context.Context, accountID int64, username string) (db.Account, db.AccountHolder, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, db.AccountHolder{}, err
+	}
+
+	if account.Owner == username {
+		return account, db.AccountHolder{
+			AccountID: account.ID,
+			Username:  username,
+			Role:      db.HolderRoleOwner,
+			Status:    db.HolderStatusActive,
+		}, nil
+	}
+
+	holder, err := s.store.GetAccountHolder(ctx, db.GetAccountHolderParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return account, holder, ErrAccountNotOwned
+		}
+
+		return account, holder, err
+	}
+
+	if holder.Status != db.HolderStatusActive {
+		return account, holder, ErrAccountNotOwned
+	}
+
+	return account, holder, nil
+}
+
+// authorizeAccount checks whether the authenticated user holds the account with
+// the given permission. It writes the error response and returns false if not.
+func (s *Server) authorizeAccount(c *gin.Context, accountID int64, p permission) (db.Account, bool) {
+	account, holder, ok := s.accountHolder(c, accountID)
+	if !ok {
+		return account, false
+	}
+
+	if rolePermissions[holder.Role] < p {
+		c.JSON(http.StatusForbidden, errorResponse(ErrPermissionDenied))
+
+		return account, false
+	}
+
+	return account, true
+}
+
+// authorizeSpend checks whether the authenticated user may send the amount
+// from the account. It writes the error response and returns false if not.
+func (s *Server) authorizeSpend(c *gin.Context, accountID int64, amount int64) bool {
+	_, holder, ok := s.accountHolder(c, accountID)
+	if !ok {
+		return false
+	}
+
+	if rolePermissions[holder.Role] < permSpend {
+		c.JSON(http.StatusForbidden, errorResponse(ErrPermissionDenied))
+
+		return false
+	}
+
+	if holder.Role == db.HolderRoleSignatory && holder.SpendLimit.Valid && amount > holder.SpendLimit.Int64 {
+		c.JSON(http.StatusForbidden, errorResponse(ErrSpendLimitExceeded))
+
+		return false
+	}
+
+	return true
+}
+
+// AccountHolderRequestURI holds URI parameters for account holder handlers.
+type AccountHolderRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) listAccountHolders(c *gin.Context) {
+	var req AccountHolderRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.authorizeAccount(c, req.ID, permView); !ok {
+		return
+	}
+
+	holders, err := s.store.ListAccountHolders(c, req.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, holders)
+}
+
+// InviteAccountHolderRequestJSON holds JSON parameters for inviteAccountHolder handler.
+type InviteAccountHolderRequestJSON struct {
+	Username string `json:"username" binding:"required,ascii"`
+	Role     string `json:"role" binding:"required,oneof=owner co-owner viewer signatory"`
+	// SpendLimit limits single transfers of a signatory.
+	SpendLimit int64 `json:"spend_limit" binding:"omitempty,min=1"`
+}
+
+func (s *Server) inviteAccountHolder(c *gin.Context) {
+	var reqURI AccountHolderRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqJSON InviteAccountHolderRequestJSON
+	if err := c.ShouldBindJSON(&reqJSON); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if reqJSON.Role == db.HolderRoleSignatory && reqJSON.SpendLimit == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrMissingSpendLimit))
+
+		return
+	}
+
+	account, ok := s.authorizeAccount(c, reqURI.ID, permOwn)
+	if !ok {
+		return
+	}
+
+	if reqJSON.Username == account.Owner {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrPrimaryHolder))
+
+		return
+	}
+
+	params := db.CreateAccountHolderParams{
+		AccountID: account.ID,
+		Username:  reqJSON.Username,
+		Role:      reqJSON.Role,
+		InvitedBy: authPayload(c).Username,
+	}
+
+	if reqJSON.Role == db.HolderRoleSignatory {
+		params.SpendLimit = sql.NullInt64{Int64: reqJSON.SpendLimit, Valid: true}
+	}
+
+	holder, err := s.store.CreateAccountHolder(c, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, holder)
+}
+
+func (s *Server) acceptAccountHolder(c *gin.Context) {
+	var req AccountHolderRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	holder, err := s.store.AcceptAccountHolder(c, db.AcceptAccountHolderParams{
+		AccountID: req.ID,
+		Username:  authPayload(c).Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, holder)
+}
+
+// RemoveAccountHolderRequestURI holds URI parameters for removeAccountHolder handler.
+type RemoveAccountHolderRequestURI struct {
+	ID       int64  `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required"`
+}
+
+func (s *Server) removeAccountHolder(c *gin.Context) {
+	var req RemoveAccountHolderRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	// holders can always leave the account on their own
+	if req.Username != authPayload(c).Username {
+		account, ok := s.authorizeAccount(c, req.ID, permOwn)
+		if !ok {
+			return
+		}
+
+		if req.Username == account.Owner {
+			c.JSON(http.StatusBadRequest, errorResponse(ErrPrimaryHolder))
+
+			return
+		}
+	}
+
+	n, err := s.store.DeleteAccountHolder(c, db.DeleteAccountHolderParams{
+		AccountID: req.ID,
+		Username:  req.Username,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if n == 0 {
+		c.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,410 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ListAccountHolders(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	holders := []db.AccountHolder{
+		{AccountID: account.ID, Username: util.RandomOwner(), Role: db.HolderRoleViewer, Status: db.HolderStatusActive},
+		{AccountID: account.ID, Username: util.RandomOwner(), Role: db.HolderRoleCoOwner, Status: db.HolderStatusInvited},
+	}
+
+	tests := []struct {
+		name          string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Owner",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("ListAccountHolders", mock.Anything, account.ID).Return(holders, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result []db.AccountHolder
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Len(t, result, len(holders))
+			},
+		},
+		{
+			name:     "Viewer",
+			username: holders[0].Username,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  holders[0].Username,
+				}).Return(holders[0], nil)
+				store.On("ListAccountHolders", mock.Anything, account.ID).Return(holders, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "InvitedHolder",
+			username: holders[1].Username,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  holders[1].Username,
+				}).Return(holders[1], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("ListAccountHolders", mock.Anything, account.ID).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/holders", account.ID)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_InviteAccountHolder(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	invitee := util.RandomOwner()
+	coOwner := util.RandomOwner()
+
+	tests := []struct {
+		name          string
+		username      string
+		paramsJSON    api.InviteAccountHolderRequestJSON
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "OK",
+			username:   account.Owner,
+			paramsJSON: api.InviteAccountHolderRequestJSON{Username: invitee, Role: db.HolderRoleSignatory, SpendLimit: 500},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("CreateAccountHolder", mock.Anything, db.CreateAccountHolderParams{
+					AccountID:  account.ID,
+					Username:   invitee,
+					Role:       db.HolderRoleSignatory,
+					SpendLimit: sql.NullInt64{Int64: 500, Valid: true},
+					InvitedBy:  account.Owner,
+				}).Return(db.AccountHolder{
+					AccountID:  account.ID,
+					Username:   invitee,
+					Role:       db.HolderRoleSignatory,
+					SpendLimit: sql.NullInt64{Int64: 500, Valid: true},
+					Status:     db.HolderStatusInvited,
+					InvitedBy:  account.Owner,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.AccountHolder
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, db.HolderStatusInvited, result.Status)
+				assert.Equal(t, int64(500), result.SpendLimit.Int64)
+			},
+		},
+		{
+			name:       "InvalidRole",
+			username:   account.Owner,
+			paramsJSON: api.InviteAccountHolderRequestJSON{Username: invitee, Role: "janitor"},
+			buildStub:  func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "SignatoryWithoutLimit",
+			username:   account.Owner,
+			paramsJSON: api.InviteAccountHolderRequestJSON{Username: invitee, Role: db.HolderRoleSignatory},
+			buildStub:  func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "PrimaryHolder",
+			username:   account.Owner,
+			paramsJSON: api.InviteAccountHolderRequestJSON{Username: account.Owner, Role: db.HolderRoleViewer},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:       "CoOwner",
+			username:   coOwner,
+			paramsJSON: api.InviteAccountHolderRequestJSON{Username: invitee, Role: db.HolderRoleViewer},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  coOwner,
+				}).Return(db.AccountHolder{
+					AccountID: account.ID,
+					Username:  coOwner,
+					Role:      db.HolderRoleCoOwner,
+					Status:    db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:       "AlreadyHolder",
+			username:   account.Owner,
+			paramsJSON: api.InviteAccountHolderRequestJSON{Username: invitee, Role: db.HolderRoleViewer},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("CreateAccountHolder", mock.Anything, mock.Anything).
+					Return(db.AccountHolder{}, pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/holders", account.ID)
+			b, err := json.Marshal(test.paramsJSON)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_AcceptAccountHolder(t *testing.T) {
+	holder := db.AccountHolder{
+		AccountID: util.RandomInt(1, 2048),
+		Username:  util.RandomOwner(),
+		Role:      db.HolderRoleCoOwner,
+		Status:    db.HolderStatusActive,
+	}
+
+	tests := []struct {
+		name          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *mocks.Store) {
+				store.On("AcceptAccountHolder", mock.Anything, db.AcceptAccountHolderParams{
+					AccountID: holder.AccountID,
+					Username:  holder.Username,
+				}).Return(holder, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NoInvitation",
+			buildStub: func(store *mocks.Store) {
+				store.On("AcceptAccountHolder", mock.Anything, mock.Anything).
+					Return(db.AccountHolder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/holders/accept", holder.AccountID)
+			req := httptest.NewRequest(http.MethodPost, url, nil)
+			addAuthorization(t, req, holder.Username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_RemoveAccountHolder(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	viewer := util.RandomOwner()
+
+	tests := []struct {
+		name          string
+		username      string
+		holder        string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Owner",
+			username: account.Owner,
+			holder:   viewer,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("DeleteAccountHolder", mock.Anything, db.DeleteAccountHolderParams{
+					AccountID: account.ID,
+					Username:  viewer,
+				}).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Self",
+			username: viewer,
+			holder:   viewer,
+			buildStub: func(store *mocks.Store) {
+				store.On("DeleteAccountHolder", mock.Anything, db.DeleteAccountHolderParams{
+					AccountID: account.ID,
+					Username:  viewer,
+				}).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "PrimaryHolder",
+			username: viewer,
+			holder:   account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account.ID,
+					Username:  viewer,
+				}).Return(db.AccountHolder{
+					AccountID: account.ID,
+					Username:  viewer,
+					Role:      db.HolderRoleViewer,
+					Status:    db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: account.Owner,
+			holder:   viewer,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("DeleteAccountHolder", mock.Anything, mock.Anything).Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/holders/%s", account.ID, test.holder)
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrLoginLocked is returned when the username or the client IP is locked after too many failed logins.
+	ErrLoginLocked = errors.New("too many failed logins, the login is temporarily locked")
+	// ErrLoginDelayed is returned when the username logs in again before the delay after a failed login passed.
+	ErrLoginDelayed = errors.New("too many failed logins, retry later")
+)
+
+// rejectLogin responds with the error and the time the login can be retried at.
+func rejectLogin(c *gin.Context, err error, retryAt time.Time) {
+	retryAfter := int(math.Ceil(time.Until(retryAt).Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, errorResponse(err))
+}
+
+// loginAttempts returns the parameters of the login attempts of the client IP,
+// if it is throttled, and of the username.
+func (s *Server) loginAttempts(c *gin.Context, username string) []db.ReserveLoginAttemptTxParams {
+	now := time.Now()
+
+	var attempts []db.ReserveLoginAttemptTxParams
+	if s.config.LoginMaxIPFailures > 0 {
+		attempts = append(attempts, db.ReserveLoginAttemptTxParams{
+			Scope:       db.LoginThrottleIP,
+			Subject:     c.ClientIP(),
+			ResetBefore: now.Add(-s.config.LoginFailureWindow),
+			MaxFailures: s.config.LoginMaxIPFailures,
+			LockedUntil: now.Add(s.config.LoginLockoutDuration),
+		})
+	}
+
+	return append(attempts, db.ReserveLoginAttemptTxParams{
+		Scope:       db.LoginThrottleUsername,
+		Subject:     username,
+		ResetBefore: now.Add(-s.config.LoginFailureWindow),
+		MaxFailures: s.config.LoginMaxFailures,
+		LockedUntil: now.Add(s.config.LoginLockoutDuration),
+		Delay:       s.config.LoginDelay,
+		MaxDelay:    s.config.LoginLockoutDuration,
+	})
+}
+
+// reserveLoginAttempt checks whether the username and the client IP may log in and
+// counts the attempt as a failed login until the password is verified, so parallel
+// attempts can not pass the check before the failures of the others are counted.
+// The login is rejected while either of them is locked or before the delay after
+// the last failed login of the username passed. It writes the error response and
+// returns false if the login is rejected.
+func (s *Server) reserveLoginAttempt(c *gin.Context, username string) bool {
+	if s.config.LoginMaxFailures == 0 {
+		return true
+	}
+
+	attempts := s.loginAttempts(c, username)
+	for i, arg := range attempts {
+		result, err := s.store.ReserveLoginAttemptTx(c, arg)
+		if err == nil && result.RetryAt.IsZero() {
+			continue
+		}
+
+		// the rejected login does not count against the client IP
+		for _, reserved := range attempts[:i] {
+			s.releaseLoginAttempt(c, reserved.Scope, reserved.Subject)
+		}
+
+		switch {
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		case result.Locked:
+			rejectLogin(c, ErrLoginLocked, result.RetryAt)
+		default:
+			rejectLogin(c, ErrLoginDelayed, result.RetryAt)
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// recordLoginFailure locks the username and the client IP once their failures reach
+// the maximum and returns the throttle of the username and whether it has been locked.
+// The failure itself has been counted by reserveLoginAttempt. Failures to lock are
+// only logged, the login is rejected anyway.
+func (s *Server) recordLoginFailure(c *gin.Context, username string) (db.LoginThrottle, bool) {
+	if s.config.LoginMaxFailures == 0 {
+		return db.LoginThrottle{}, false
+	}
+
+	var (
+		throttle db.LoginThrottle
+		locked   bool
+	)
+
+	for _, arg := range s.loginAttempts(c, username) {
+		t, err := s.store.LockLoginThrottle(c, db.LockLoginThrottleParams{
+			LockedUntil: sql.NullTime{Time: arg.LockedUntil, Valid: true},
+			Scope:       arg.Scope,
+			Subject:     arg.Subject,
+			MaxFailures: arg.MaxFailures,
+		})
+		if err != nil {
+			// no row is locked until the failures reach the maximum
+			if !errors.Is(err, sql.ErrNoRows) {
+				_ = c.Error(err)
+			}
+
+			continue
+		}
+
+		if arg.Scope == db.LoginThrottleUsername {
+			throttle, locked = t, true
+		}
+	}
+
+	return throttle, locked
+}
+
+// resetLoginFailures forgets the failed logins of the username after a successful
+// login and stops counting the attempt against the client IP. Failures to reset
+// are only logged.
+func (s *Server) resetLoginFailures(c *gin.Context, username string) {
+	if s.config.LoginMaxFailures == 0 {
+		return
+	}
+
+	if err := s.store.DeleteLoginThrottle(c, db.DeleteLoginThrottleParams{
+		Scope:   db.LoginThrottleUsername,
+		Subject: username,
+	}); err != nil {
+		_ = c.Error(err)
+	}
+
+	if s.config.LoginMaxIPFailures > 0 {
+		s.releaseLoginAttempt(c, db.LoginThrottleIP, c.ClientIP())
+	}
+}
+
+// releaseLoginAttempt stops counting the reserved attempt as a failure. Failures
+// to release are only logged.
+func (s *Server) releaseLoginAttempt(c *gin.Context, scope, subject string) {
+	if err := s.store.ReleaseLoginAttempt(c, db.ReleaseLoginAttemptParams{
+		Scope:   scope,
+		Subject: subject,
+	}); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// sendLockoutNotice emails the user that their username has been locked.
+func (s *Server) sendLockoutNotice(c *gin.Context, user db.User, throttle db.LoginThrottle) error {
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Your account has been locked",
+		Body: fmt.Sprintf("Hello %s,\n\nafter %d failed login attempts your account has been locked until %s.\n\n"+
+			"If it was not you, someone may be guessing your password. Consider resetting it.\n",
+			user.FirstName, s.config.LoginMaxFailures, throttle.LockedUntil.Time.Format(time.RFC1123)),
+	}
+
+	if err := s.mailer.Send(c, msg); err != nil {
+		return fmt.Errorf("failed to send the lockout notice: %w", err)
+	}
+
+	return nil
+}
+
+// AdminUserRequestURI holds URI parameters for the login lock handlers.
+type AdminUserRequestURI struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+// LoginLockResponse holds the login lock status of a user. LockedUntil is zero
+// if the user is not locked.
+type LoginLockResponse struct {
+	Username    string    `json:"username"`
+	Locked      bool      `json:"locked"`
+	LockedUntil time.Time `json:"locked_until"`
+	Failures    int32     `json:"failures"`
+}
+
+func newLoginLockResponse(username string, throttle db.LoginThrottle) LoginLockResponse {
+	resp := LoginLockResponse{
+		Username: username,
+		Failures: throttle.Failures,
+	}
+
+	if throttle.LockedUntil.Valid && time.Now().Before(throttle.LockedUntil.Time) {
+		resp.Locked = true
+		resp.LockedUntil = throttle.LockedUntil.Time
+	}
+
+	return resp
+}
+
+// getLoginLock returns whether the user is locked after too many failed logins.
+func (s *Server) getLoginLock(c *gin.Context) {
+	var req AdminUserRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, err := s.store.GetUser(c, req.Username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	throttle, err := s.store.GetLoginThrottle(c, db.GetLoginThrottleParams{
+		Scope:   db.LoginThrottleUsername,
+		Subject: req.Username,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginLockResponse(req.Username, throttle))
+}
+
+// unlockLogin lifts the lock of the user and forgets their failed logins.
+func (s *Server) unlockLogin(c *gin.Context) {
+	var req AdminUserRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, err := s.store.GetUser(c, req.Username); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	// a user without failed logins has nothing to unlock
+	throttle, err := s.store.UnlockLoginThrottle(c, db.UnlockLoginThrottleParams{
+		Scope:   db.LoginThrottleUsername,
+		Subject: req.Username,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newLoginLockResponse(req.Username, throttle))
+}
@@ -0,0 +1,497 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testClientIP is the client IP of the requests constructed by httptest.NewRequest.
+const testClientIP = "192.0.2.1"
+
+// reserveAttempt matches the reservation of a login attempt of the scope.
+func reserveAttempt(scope string) interface{} {
+	return mock.MatchedBy(func(arg db.ReserveLoginAttemptTxParams) bool {
+		return arg.Scope == scope
+	})
+}
+
+// lockAttempt matches the lock of the throttle of the scope after the failures.
+func lockAttempt(scope string, maxFailures int32) interface{} {
+	return mock.MatchedBy(func(arg db.LockLoginThrottleParams) bool {
+		return arg.Scope == scope && arg.MaxFailures == maxFailures && arg.LockedUntil.Valid
+	})
+}
+
+func TestServer_LoginUser_Throttle(t *testing.T) {
+	plainPassword := util.RandomOwner()
+	user := db.User{
+		Username:       util.RandomOwner(),
+		HashedPassword: hashPassword(t, plainPassword),
+		FirstName:      util.RandomOwner(),
+		Email:          util.RandomEmail(),
+		Role:           db.UserRoleCustomer,
+	}
+
+	cfg := *testConfig
+	cfg.LoginMaxFailures = 3
+	cfg.LoginMaxIPFailures = 10
+	cfg.LoginDelay = time.Second
+	cfg.LoginFailureWindow = 15 * time.Minute
+	cfg.LoginLockoutDuration = 15 * time.Minute
+
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	ipAttempt := db.ReleaseLoginAttemptParams{Scope: db.LoginThrottleIP, Subject: testClientIP}
+
+	tests := []struct {
+		name          string
+		password      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer)
+	}{
+		{
+			name:     "OK",
+			password: plainPassword,
+			buildStub: func(store *mocks.Store) {
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleIP)).
+					Return(db.ReserveLoginAttemptTxResult{}, nil)
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleUsername)).
+					Return(db.ReserveLoginAttemptTxResult{}, nil)
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("DeleteLoginThrottle", mock.Anything, db.DeleteLoginThrottleParams{
+					Scope:   db.LoginThrottleUsername,
+					Subject: user.Username,
+				}).Return(nil)
+				store.On("ReleaseLoginAttempt", mock.Anything, ipAttempt).Return(nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+				store.On("CreateSession", mock.Anything, mock.Anything).Return(db.Session{Username: user.Username}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "Locked",
+			password: plainPassword,
+			buildStub: func(store *mocks.Store) {
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleIP)).
+					Return(db.ReserveLoginAttemptTxResult{}, nil)
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleUsername)).
+					Return(db.ReserveLoginAttemptTxResult{RetryAt: lockedUntil, Locked: true}, nil)
+				store.On("ReleaseLoginAttempt", mock.Anything, ipAttempt).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), api.ErrLoginLocked.Error())
+				assert.Equal(t, "600", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name:     "IPLocked",
+			password: plainPassword,
+			buildStub: func(store *mocks.Store) {
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleIP)).
+					Return(db.ReserveLoginAttemptTxResult{RetryAt: lockedUntil, Locked: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), api.ErrLoginLocked.Error())
+			},
+		},
+		{
+			name:     "Delayed",
+			password: plainPassword,
+			buildStub: func(store *mocks.Store) {
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleIP)).
+					Return(db.ReserveLoginAttemptTxResult{}, nil)
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleUsername)).
+					Return(db.ReserveLoginAttemptTxResult{RetryAt: time.Now().Add(time.Second)}, nil)
+				store.On("ReleaseLoginAttempt", mock.Anything, ipAttempt).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), api.ErrLoginDelayed.Error())
+				assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+			},
+		},
+		{
+			name:     "WrongPassword",
+			password: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("ReserveLoginAttemptTx", mock.Anything, mock.MatchedBy(func(arg db.ReserveLoginAttemptTxParams) bool {
+					return arg.Scope == db.LoginThrottleIP && arg.Subject == testClientIP &&
+						arg.MaxFailures == 10 && arg.Delay == 0
+				})).Return(db.ReserveLoginAttemptTxResult{}, nil)
+				store.On("ReserveLoginAttemptTx", mock.Anything, mock.MatchedBy(func(arg db.ReserveLoginAttemptTxParams) bool {
+					return arg.Scope == db.LoginThrottleUsername && arg.Subject == user.Username &&
+						arg.MaxFailures == 3 && arg.Delay == time.Second && arg.MaxDelay == 15*time.Minute
+				})).Return(db.ReserveLoginAttemptTxResult{}, nil)
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("LockLoginThrottle", mock.Anything, lockAttempt(db.LoginThrottleIP, 10)).
+					Return(db.LoginThrottle{}, sql.ErrNoRows)
+				store.On("LockLoginThrottle", mock.Anything, lockAttempt(db.LoginThrottleUsername, 3)).
+					Return(db.LoginThrottle{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Empty(t, mailer.Messages())
+			},
+		},
+		{
+			name:     "LockoutNotice",
+			password: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("ReserveLoginAttemptTx", mock.Anything, mock.Anything).
+					Return(db.ReserveLoginAttemptTxResult{}, nil).Twice()
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("LockLoginThrottle", mock.Anything, lockAttempt(db.LoginThrottleIP, 10)).
+					Return(db.LoginThrottle{}, sql.ErrNoRows)
+				store.On("LockLoginThrottle", mock.Anything, lockAttempt(db.LoginThrottleUsername, 3)).
+					Return(db.LoginThrottle{LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+				messages := mailer.Messages()
+				require.Len(t, messages, 1)
+				assert.Equal(t, user.Email, messages[0].To)
+				assert.Contains(t, messages[0].Body, lockedUntil.Format(time.RFC1123))
+			},
+		},
+		{
+			name:     "UnknownUser",
+			password: plainPassword,
+			buildStub: func(store *mocks.Store) {
+				store.On("ReserveLoginAttemptTx", mock.Anything, mock.Anything).
+					Return(db.ReserveLoginAttemptTxResult{}, nil).Twice()
+				store.On("GetUser", mock.Anything, user.Username).Return(db.User{}, sql.ErrNoRows)
+				store.On("LockLoginThrottle", mock.Anything, mock.Anything).
+					Return(db.LoginThrottle{LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true}}, nil).Twice()
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+				assert.Empty(t, mailer.Messages())
+			},
+		},
+		{
+			name:     "ThrottleError",
+			password: plainPassword,
+			buildStub: func(store *mocks.Store) {
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleIP)).
+					Return(db.ReserveLoginAttemptTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, mailer *mail.MemoryMailer) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			mailer := mail.NewMemoryMailer()
+			server := newTestServerWithMailer(t, mockStore, mailer, &cfg)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(api.LoginUserRequest{Username: user.Username, Password: test.password})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(b))
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder, mailer)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetLoginLock(t *testing.T) {
+	user := db.User{Username: util.RandomOwner()}
+	lockedUntil := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name          string
+		role          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Locked",
+			role: db.UserRoleSupport,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("GetLoginThrottle", mock.Anything, db.GetLoginThrottleParams{
+					Scope:   db.LoginThrottleUsername,
+					Subject: user.Username,
+				}).Return(db.LoginThrottle{LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.LoginLockResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.True(t, body.Locked)
+				assert.True(t, lockedUntil.Equal(body.LockedUntil))
+			},
+		},
+		{
+			name: "NoFailures",
+			role: db.UserRoleAuditor,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("GetLoginThrottle", mock.Anything, mock.Anything).Return(db.LoginThrottle{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.LoginLockResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, api.LoginLockResponse{Username: user.Username}, body)
+			},
+		},
+		{
+			name: "NotFound",
+			role: db.UserRoleAdmin,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "Customer",
+			role:      db.UserRoleCustomer,
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+
+			url := fmt.Sprintf("/admin/users/%s/lock", user.Username)
+			recorder := serveAdmin(t, mockStore, test.role, http.MethodGet, url, nil)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_UnlockLogin(t *testing.T) {
+	user := db.User{Username: util.RandomOwner()}
+
+	tests := []struct {
+		name          string
+		role          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: db.UserRoleSupport,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("UnlockLoginThrottle", mock.Anything, db.UnlockLoginThrottleParams{
+					Scope:   db.LoginThrottleUsername,
+					Subject: user.Username,
+				}).Return(db.LoginThrottle{Scope: db.LoginThrottleUsername, Subject: user.Username}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.LoginLockResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.False(t, body.Locked)
+			},
+		},
+		{
+			name: "NotLocked",
+			role: db.UserRoleAdmin,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("UnlockLoginThrottle", mock.Anything, mock.Anything).Return(db.LoginThrottle{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "Auditor",
+			role:      db.UserRoleAuditor,
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockStore := new(mocks.Store)
+			test.buildStub(mockStore)
+
+			url := fmt.Sprintf("/admin/users/%s/lock", user.Username)
+			recorder := serveAdmin(t, mockStore, test.role, http.MethodDelete, url, nil)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_DeleteUser_Throttle(t *testing.T) {
+	plainPassword := util.RandomOwner()
+	user := db.User{
+		Username:       util.RandomOwner(),
+		HashedPassword: hashPassword(t, plainPassword),
+		FirstName:      util.RandomOwner(),
+		Email:          util.RandomEmail(),
+		Role:           db.UserRoleCustomer,
+	}
+
+	cfg := *testConfig
+	cfg.LoginMaxFailures = 3
+	cfg.LoginDelay = time.Second
+	cfg.LoginFailureWindow = 15 * time.Minute
+	cfg.LoginLockoutDuration = 15 * time.Minute
+
+	tests := []struct {
+		name          string
+		password      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Locked",
+			password: plainPassword,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleUsername)).
+					Return(db.ReserveLoginAttemptTxResult{RetryAt: time.Now().Add(time.Minute), Locked: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+			},
+		},
+		{
+			name:     "WrongPassword",
+			password: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("ReserveLoginAttemptTx", mock.Anything, reserveAttempt(db.LoginThrottleUsername)).
+					Return(db.ReserveLoginAttemptTxResult{}, nil)
+				store.On("LockLoginThrottle", mock.Anything, lockAttempt(db.LoginThrottleUsername, 3)).
+					Return(db.LoginThrottle{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServerWithMailer(t, mockStore, mail.NewMemoryMailer(), &cfg)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(api.DeleteUserRequest{Password: test.password})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodDelete, "/users", bytes.NewReader(b))
+			addAuthorization(t, req, user.Username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_LoginUser_ClientIP(t *testing.T) {
+	username := util.RandomOwner()
+	forwardedIP := "203.0.113.7"
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		clientIP       string
+	}{
+		{
+			name:     "NoTrustedProxy",
+			clientIP: testClientIP,
+		},
+		{
+			name:           "TrustedProxy",
+			trustedProxies: []string{"192.0.2.0/24"},
+			clientIP:       forwardedIP,
+		},
+		{
+			name:           "UntrustedProxy",
+			trustedProxies: []string{"10.0.0.1"},
+			clientIP:       testClientIP,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := *testConfig
+			cfg.LoginMaxFailures = 3
+			cfg.LoginMaxIPFailures = 10
+			cfg.TrustedProxies = test.trustedProxies
+
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServerWithMailer(t, mockStore, mail.NewMemoryMailer(), &cfg)
+			mockStore.On("ReserveLoginAttemptTx", mock.Anything, mock.MatchedBy(func(arg db.ReserveLoginAttemptTxParams) bool {
+				return arg.Scope == db.LoginThrottleIP && arg.Subject == test.clientIP
+			})).Return(db.ReserveLoginAttemptTxResult{}, sql.ErrConnDone)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(api.LoginUserRequest{Username: username, Password: util.RandomOwner()})
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/login", bytes.NewReader(b))
+			req.Header.Set("X-Forwarded-For", forwardedIP)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -1,13 +1,137 @@
 package api_test
 
 import (
+	"fmt"
+	"net/http"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/config"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/chutommy/simple-bank/password"
+	"github.com/chutommy/simple-bank/stream"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/chutommy/simple-bank/util"
 	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
 )
 
+// testConfig is the configuration of the servers under test.
+var testConfig = &config.Config{
+	TokenSymmetricKey:        util.RandomString(32),
+	AccessTokenDuration:      time.Minute,
+	RefreshTokenDuration:     time.Hour,
+	ApprovalExpiry:           time.Hour,
+	PayeeCoolingOff:          24 * time.Hour,
+	PayeeCoolingOffLimit:     10000,
+	PaymentRequestExpiry:     7 * 24 * time.Hour,
+	RatesFile:                "testdata/rates.json",
+	WebhookTimeout:           time.Second,
+	WebhookBackoff:           time.Second,
+	WebhookMaxAttempts:       3,
+	OutboxSink:               "log",
+	OutboxInterval:           time.Second,
+	MailFrom:                 "no-reply@simple-bank.test",
+	EmailVerificationExpiry:  48 * time.Hour,
+	PasswordResetExpiry:      time.Hour,
+	TwoFactorIssuer:          "Simple Bank",
+	TwoFactorChallengeExpiry: 5 * time.Minute,
+	PasswordHashAlgorithm:    password.Argon2id,
+	PasswordArgon2Time:       1,
+	PasswordArgon2Memory:     64,
+	PasswordArgon2Threads:    1,
+	PasswordBcryptCost:       4,
+	PasswordMinLength:        6,
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
 }
+
+// newTestServer constructs a server with the given db.Store and testConfig.
+func newTestServer(t *testing.T, store db.Store) *api.Server {
+	t.Helper()
+
+	return newTestServerWithBroker(t, store, stream.NewHub())
+}
+
+// newTestServerWithBroker constructs a server with the given db.Store,
+// stream.Broker and testConfig.
+func newTestServerWithBroker(t *testing.T, store db.Store, events stream.Broker) *api.Server {
+	t.Helper()
+
+	server, err := api.NewServer(testConfig, store, events, mail.NewMemoryMailer())
+	require.NoError(t, err)
+
+	return server
+}
+
+// newTestServerWithMailer constructs a server with the given db.Store,
+// mail.Mailer and config.
+func newTestServerWithMailer(t *testing.T, store db.Store, mailer mail.Mailer, cfg *config.Config) *api.Server {
+	t.Helper()
+
+	server, err := api.NewServer(cfg, store, stream.NewHub(), mailer)
+	require.NoError(t, err)
+
+	return server
+}
+
+// addAuthorization signs a token for the customer and sets it as the bearer
+// token of the request.
+func addAuthorization(t *testing.T, req *http.Request, username string) {
+	t.Helper()
+
+	addRoleAuthorization(t, req, username, db.UserRoleCustomer)
+}
+
+// addRoleAuthorization signs a token for the username with the role and sets
+// it as the bearer token of the request.
+func addRoleAuthorization(t *testing.T, req *http.Request, username string, role string) {
+	t.Helper()
+
+	addSessionAuthorization(t, req, username, role, "")
+}
+
+// addSessionAuthorization signs a token for the username with the role issued
+// for the session family and sets it as the bearer token of the request.
+func addSessionAuthorization(t *testing.T, req *http.Request, username string, role string, familyID string) {
+	t.Helper()
+
+	maker, err := token.NewJWTMaker(testConfig.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	tkn, err := maker.CreateToken(username, role, familyID, testConfig.AccessTokenDuration)
+	require.NoError(t, err)
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", tkn))
+}
+
+// hashPassword hashes the password with the parameters of testConfig.
+func hashPassword(t *testing.T, plain string) string {
+	t.Helper()
+
+	hasher, err := password.NewHasher(password.Params{
+		Algorithm:     testConfig.PasswordHashAlgorithm,
+		Argon2Time:    testConfig.PasswordArgon2Time,
+		Argon2Memory:  testConfig.PasswordArgon2Memory,
+		Argon2Threads: testConfig.PasswordArgon2Threads,
+	})
+	require.NoError(t, err)
+
+	hash, err := hasher.Hash(plain)
+	require.NoError(t, err)
+
+	return hash
+}
+
+// matchPassword matches a hash of the password.
+func matchPassword(plain string) func(hash string) bool {
+	return func(hash string) bool {
+		return password.Verify(plain, hash) == nil
+	}
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	authorizationHeaderKey  = "Authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationTypeAPIKey = "apikey"
+	authorizationPayloadKey = "authorization_payload"
+	authorizationAPIKeyKey  = "authorization_api_key"
+
+	requestIDHeaderKey = "X-Request-ID"
+	// maxRequestIDLength limits the length of a request ID given by the client.
+	maxRequestIDLength = 128
+)
+
+var (
+	// ErrMissingAuthorization is returned when the authorization header is not provided.
+	ErrMissingAuthorization = errors.New("authorization header is not provided")
+	// ErrInvalidAuthorization is returned when the authorization header is malformed.
+	ErrInvalidAuthorization = errors.New("invalid authorization header format")
+	// ErrUnsupportedAuthorization is returned when the authorization type is not supported.
+	ErrUnsupportedAuthorization = errors.New("unsupported authorization type")
+	// ErrRoleNotAllowed is returned when the role of the user is not allowed to call the endpoint.
+	ErrRoleNotAllowed = errors.New("role of the user is not allowed")
+	// ErrAPIKeyNotAllowed is returned when an endpoint requiring a bearer token is called with an API key.
+	ErrAPIKeyNotAllowed = errors.New("endpoint can not be called with an API key")
+)
+
+// auditMiddleware assigns an ID to the request and stores the db.AuditActor
+// of the request into the context under db.AuditActorKey, so the changes
+// made by the request are audited. The request ID given by the client
+// in the X-Request-ID header is used if present.
+func auditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeaderKey)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = randomID()
+		}
+
+		c.Header(requestIDHeaderKey, requestID)
+		c.Set(db.AuditActorKey, db.AuditActor{
+			IP:        c.ClientIP(),
+			Route:     c.Request.Method + " " + c.FullPath(),
+			RequestID: requestID,
+		})
+		c.Next()
+	}
+}
+
+// roleMiddleware allows only the users with one of the roles to continue.
+// It must follow the authMiddleware.
+func roleMiddleware(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := authPayload(c).Role
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(ErrRoleNotAllowed))
+	}
+}
+
+// bearerOnlyMiddleware rejects the requests authenticated by an API key.
+// It must follow the authMiddleware.
+func bearerOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(authorizationAPIKeyKey); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(ErrAPIKeyNotAllowed))
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authMiddleware verifies the bearer token or the API key of the request and
+// stores its payload into the context under authorizationPayloadKey. The API key
+// is stored under authorizationAPIKeyKey as well.
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(authorizationHeaderKey)
+		if len(header) == 0 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrMissingAuthorization))
+
+			return
+		}
+
+		fields := strings.Fields(header)
+		if len(fields) != 2 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrInvalidAuthorization))
+
+			return
+		}
+
+		var payload *token.Payload
+
+		switch strings.ToLower(fields[0]) {
+		case authorizationTypeBearer:
+			var err error
+			if payload, err = tokenMaker.VerifyToken(fields[1]); err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+
+				return
+			}
+		case authorizationTypeAPIKey:
+			var ok bool
+			if payload, ok = authenticateAPIKey(c, store, fields[1]); !ok {
+				return
+			}
+		default:
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ErrUnsupportedAuthorization))
+
+			return
+		}
+
+		c.Set(authorizationPayloadKey, payload)
+
+		if actor, ok := c.Value(db.AuditActorKey).(db.AuditActor); ok {
+			actor.Username = payload.Username
+			c.Set(db.AuditActorKey, actor)
+		}
+
+		c.Next()
+	}
+}
+
+// authPayload returns the token payload stored by authMiddleware.
+func authPayload(c *gin.Context) *token.Payload {
+	return c.MustGet(authorizationPayloadKey).(*token.Payload)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/password"
+	"github.com/gin-gonic/gin"
+)
+
+// passwordPolicy returns the policy of the new passwords.
+func (s *Server) passwordPolicy() password.Policy {
+	return password.Policy{
+		MinLength:        s.config.PasswordMinLength,
+		MaxLength:        s.config.PasswordMaxLength,
+		RequireMixedCase: s.config.PasswordRequireMixedCase,
+		RequireDigit:     s.config.PasswordRequireDigit,
+		RequireSymbol:    s.config.PasswordRequireSymbol,
+	}
+}
+
+// hashNewPassword checks the new password against the password policy and hashes it.
+// It writes the error response and returns false if the password is rejected.
+func (s *Server) hashNewPassword(c *gin.Context, plain string) (string, bool) {
+	if err := s.passwordPolicy().Check(plain); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return "", false
+	}
+
+	hash, err := s.passwords.Hash(plain)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return "", false
+	}
+
+	return hash, true
+}
+
+// reauthenticate confirms a change of the account of the user by the password and,
+// if two-factor authentication is enabled, by a TOTP code. Wrong passwords count as
+// failed logins, so the password can not be guessed here instead. It writes the error
+// response and returns false if the user is not confirmed.
+func (s *Server) reauthenticate(c *gin.Context, user db.User, plain, code string) bool {
+	if !s.reserveLoginAttempt(c, user.Username) {
+		return false
+	}
+
+	if err := password.Verify(plain, user.HashedPassword); err != nil {
+		if !errors.Is(err, password.ErrMismatch) {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return false
+		}
+
+		if throttle, locked := s.recordLoginFailure(c, user.Username); locked {
+			if err := s.sendLockoutNotice(c, user, throttle); err != nil {
+				_ = c.Error(err)
+			}
+		}
+
+		c.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidCredentials))
+
+		return false
+	}
+
+	s.resetLoginFailures(c, user.Username)
+
+	credential, err := s.store.GetTOTPCredential(c, user.Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return false
+	}
+
+	if err != nil || !credential.ConfirmedAt.Valid {
+		return true
+	}
+
+	if code == "" {
+		c.JSON(http.StatusForbidden, errorResponse(ErrStepUpRequired))
+
+		return false
+	}
+
+	return s.useTOTPCode(c, credential, code)
+}
+
+// rehashPassword replaces the verified password hash of the user if it was made
+// with another algorithm or outdated parameters. The hash is replaced only if it
+// has not been changed meanwhile. Failures are only logged, the old hash still works.
+func (s *Server) rehashPassword(c *gin.Context, user db.User, plain string) {
+	if !s.passwords.NeedsRehash(user.HashedPassword) {
+		return
+	}
+
+	hash, err := s.passwords.Hash(plain)
+	if err != nil {
+		_ = c.Error(err)
+
+		return
+	}
+
+	if _, err := s.store.RehashUserPassword(c, db.RehashUserPasswordParams{
+		Username:          user.Username,
+		HashedPassword:    hash,
+		OldHashedPassword: user.HashedPassword,
+	}); err != nil {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrPayeeNotOwned is returned when the payee belongs to another user.
+	ErrPayeeNotOwned = errors.New("payee belongs to another user")
+)
+
+// CreatePayeeRequest holds parameters for createPayee handler. The target
+// account is referenced either by its ID or by its account number.
+type CreatePayeeRequest struct {
+	Nickname      string `json:"nickname" binding:"required"`
+	AccountID     int64  `json:"account_id" binding:"min=0"`
+	AccountNumber string `json:"account_number"`
+	// Reference is the default reference of transfers to the payee.
+	Reference string `json:"reference"`
+}
+
+func (s *Server) createPayee(c *gin.Context) {
+	var req CreatePayeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	accountID, ok := s.resolveAccountID(c, req.AccountID, req.AccountNumber)
+	if !ok {
+		return
+	}
+
+	payee, err := s.store.CreatePayee(c, db.CreatePayeeParams{
+		Owner:     authPayload(c).Username,
+		Nickname:  req.Nickname,
+		AccountID: accountID,
+		Reference: sql.NullString{String: req.Reference, Valid: req.Reference != ""},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, payee)
+}
+
+// PayeeRequestURI holds URI parameters for payee handlers.
+type PayeeRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getPayee(c *gin.Context) {
+	var req PayeeRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	payee, ok := s.ownPayee(c, req.ID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, payee)
+}
+
+// ListPayeesRequest holds parameters for listPayees handler.
+type ListPayeesRequest struct {
+	PageNum  int32 `form:"page_num" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=1000"`
+}
+
+func (s *Server) listPayees(c *gin.Context) {
+	var req ListPayeesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	payees, err := s.store.ListPayees(c, db.ListPayeesParams{
+		Owner:  authPayload(c).Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageNum - 1) * req.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, payees)
+}
+
+// UpdatePayeeRequestJSON holds JSON parameters for updatePayee handler.
+// The target account can not be changed, a new payee must be added instead.
+type UpdatePayeeRequestJSON struct {
+	Nickname  string `json:"nickname" binding:"required"`
+	Reference string `json:"reference"`
+}
+
+func (s *Server) updatePayee(c *gin.Context) {
+	var reqURI PayeeRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqJSON UpdatePayeeRequestJSON
+	if err := c.ShouldBindJSON(&reqJSON); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.ownPayee(c, reqURI.ID); !ok {
+		return
+	}
+
+	payee, err := s.store.UpdatePayee(c, db.UpdatePayeeParams{
+		ID:        reqURI.ID,
+		Nickname:  reqJSON.Nickname,
+		Reference: sql.NullString{String: reqJSON.Reference, Valid: reqJSON.Reference != ""},
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, payee)
+}
+
+func (s *Server) deletePayee(c *gin.Context) {
+	var req PayeeRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.ownPayee(c, req.ID); !ok {
+		return
+	}
+
+	if err := s.store.DeletePayee(c, req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// ownPayee returns the payee with the given ID of the authenticated user.
+// It writes the error response and returns false if the payee is not found
+// or belongs to another user.
+func (s *Server) ownPayee(c *gin.Context, id int64) (db.Payee, bool) {
+	payee, err := s.store.GetPayee(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return payee, false
+	}
+
+	if payee.Owner != authPayload(c).Username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrPayeeNotOwned))
+
+		return payee, false
+	}
+
+	return payee, true
+}
+
+// payeeCap returns the cap of the transfers to the payees added recently.
+func (s *Server) payeeCap() db.PayeeCap {
+	return db.PayeeCap{
+		CoolingOff: s.config.PayeeCoolingOff,
+		Limit:      s.config.PayeeCoolingOffLimit,
+	}
+}
@@ -0,0 +1,345 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_CreatePayee(t *testing.T) {
+	owner := util.RandomOwner()
+
+	number, err := util.NewAccountNumber()
+	require.NoError(t, err)
+
+	account := db.Account{
+		ID:     util.RandomInt(1, 2048),
+		Owner:  util.RandomOwner(),
+		Number: number,
+	}
+	payee := db.Payee{
+		ID:        util.RandomInt(1, 2048),
+		Owner:     owner,
+		Nickname:  util.RandomOwner(),
+		AccountID: account.ID,
+		Reference: sql.NullString{String: "rent", Valid: true},
+	}
+
+	tests := []struct {
+		name          string
+		params        api.CreatePayeeRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "AccountID",
+			params: api.CreatePayeeRequest{Nickname: payee.Nickname, AccountID: account.ID, Reference: "rent"},
+			buildStub: func(store *mocks.Store) {
+				store.On("CreatePayee", mock.Anything, db.CreatePayeeParams{
+					Owner:     owner,
+					Nickname:  payee.Nickname,
+					AccountID: account.ID,
+					Reference: payee.Reference,
+				}).Return(payee, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, payee.ID, bytesToPayee(t, recorder.Body.Bytes()).ID)
+			},
+		},
+		{
+			name:   "AccountNumber",
+			params: api.CreatePayeeRequest{Nickname: payee.Nickname, AccountNumber: account.Number},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccountByNumber", mock.Anything, account.Number).Return(account, nil)
+				store.On("CreatePayee", mock.Anything, db.CreatePayeeParams{
+					Owner:     owner,
+					Nickname:  payee.Nickname,
+					AccountID: account.ID,
+				}).Return(payee, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAccount",
+			params:    api.CreatePayeeRequest{Nickname: payee.Nickname},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "DuplicateNickname",
+			params: api.CreatePayeeRequest{Nickname: payee.Nickname, AccountID: account.ID},
+			buildStub: func(store *mocks.Store) {
+				store.On("CreatePayee", mock.Anything, mock.Anything).
+					Return(db.Payee{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			params: api.CreatePayeeRequest{Nickname: payee.Nickname, AccountID: account.ID},
+			buildStub: func(store *mocks.Store) {
+				store.On("CreatePayee", mock.Anything, mock.Anything).Return(db.Payee{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/payees", bytes.NewReader(b))
+			addAuthorization(t, req, owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetPayee(t *testing.T) {
+	payee := db.Payee{
+		ID:        util.RandomInt(1, 2048),
+		Owner:     util.RandomOwner(),
+		Nickname:  util.RandomOwner(),
+		AccountID: util.RandomInt(1, 2048),
+	}
+
+	tests := []struct {
+		name          string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: payee.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPayee", mock.Anything, payee.ID).Return(payee, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, payee, bytesToPayee(t, recorder.Body.Bytes()))
+			},
+		},
+		{
+			name:     "NotOwned",
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPayee", mock.Anything, payee.ID).Return(payee, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: payee.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPayee", mock.Anything, payee.ID).Return(db.Payee{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/users/payees/%d", payee.ID)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ListPayees(t *testing.T) {
+	owner := util.RandomOwner()
+	payees := []db.Payee{
+		{ID: util.RandomInt(1, 1024), Owner: owner, Nickname: "alice", AccountID: util.RandomInt(1, 2048)},
+		{ID: util.RandomInt(1025, 2048), Owner: owner, Nickname: "bob", AccountID: util.RandomInt(1, 2048)},
+	}
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	mockStore.On("ListPayees", mock.Anything, db.ListPayeesParams{
+		Owner:  owner,
+		Limit:  10,
+		Offset: 10,
+	}).Return(payees, nil)
+
+	// prepare request and response recorder
+	req := httptest.NewRequest(http.MethodGet, "/users/payees?page_num=2&page_size=10", nil)
+	addAuthorization(t, req, owner)
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	// check response
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var result []db.Payee
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	assert.Equal(t, payees, result)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_UpdatePayee(t *testing.T) {
+	payee := db.Payee{
+		ID:        util.RandomInt(1, 2048),
+		Owner:     util.RandomOwner(),
+		Nickname:  util.RandomOwner(),
+		AccountID: util.RandomInt(1, 2048),
+	}
+	nickname := util.RandomOwner()
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	mockStore.On("GetPayee", mock.Anything, payee.ID).Return(payee, nil)
+	mockStore.On("UpdatePayee", mock.Anything, db.UpdatePayeeParams{
+		ID:        payee.ID,
+		Nickname:  nickname,
+		Reference: sql.NullString{String: "invoice", Valid: true},
+	}).Return(db.Payee{
+		ID:        payee.ID,
+		Owner:     payee.Owner,
+		Nickname:  nickname,
+		AccountID: payee.AccountID,
+		Reference: sql.NullString{String: "invoice", Valid: true},
+	}, nil)
+
+	// prepare request and response recorder
+	b, err := json.Marshal(api.UpdatePayeeRequestJSON{Nickname: nickname, Reference: "invoice"})
+	require.NoError(t, err)
+	url := fmt.Sprintf("/users/payees/%d", payee.ID)
+	req := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(b))
+	addAuthorization(t, req, payee.Owner)
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	// check response
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, nickname, bytesToPayee(t, recorder.Body.Bytes()).Nickname)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_DeletePayee(t *testing.T) {
+	payee := db.Payee{
+		ID:        util.RandomInt(1, 2048),
+		Owner:     util.RandomOwner(),
+		Nickname:  util.RandomOwner(),
+		AccountID: util.RandomInt(1, 2048),
+	}
+
+	tests := []struct {
+		name          string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: payee.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPayee", mock.Anything, payee.ID).Return(payee, nil)
+				store.On("DeletePayee", mock.Anything, payee.ID).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwned",
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPayee", mock.Anything, payee.ID).Return(payee, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/users/payees/%d", payee.ID)
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func bytesToPayee(t *testing.T, b []byte) db.Payee {
+	t.Helper()
+
+	var payee db.Payee
+	require.NoError(t, json.Unmarshal(b, &payee))
+
+	return payee
+}
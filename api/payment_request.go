@@ -0,0 +1,283 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrSelfPaymentRequest is returned when users request money from themselves.
+	ErrSelfPaymentRequest = errors.New("money can not be requested from oneself")
+	// ErrPaymentRequestNotOwned is returned when the user is neither the requester nor the payer.
+	ErrPaymentRequestNotOwned = errors.New("payment request belongs to other users")
+	// ErrPaymentRequestApproval is returned when paying the request requires an approval
+	// by the approval policy of the source account.
+	ErrPaymentRequestApproval = errors.New("amount requires an approval, make a transfer instead")
+)
+
+// directionIncoming lists the payment requests the user is asked to pay.
+const directionIncoming = "incoming"
+
+// CreatePaymentRequestRequest holds parameters for createPaymentRequest handler.
+// The account the money is requested to is referenced either by its ID or by
+// its account number.
+type CreatePaymentRequestRequest struct {
+	Payer           string `json:"payer" binding:"required,ascii"`
+	ToAccountID     int64  `json:"to_account_id" binding:"min=0"`
+	ToAccountNumber string `json:"to_account_number"`
+	Amount          int64  `json:"amount" binding:"required,min=1"`
+	Memo            string `json:"memo" binding:"max=500"`
+}
+
+func (s *Server) createPaymentRequest(c *gin.Context) {
+	var req CreatePaymentRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	requester := authPayload(c).Username
+	if req.Payer == requester {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrSelfPaymentRequest))
+
+		return
+	}
+
+	var ok bool
+	if req.ToAccountID, ok = s.resolveAccountID(c, req.ToAccountID, req.ToAccountNumber); !ok {
+		return
+	}
+
+	if _, ok = s.authorizeAccount(c, req.ToAccountID, permView); !ok {
+		return
+	}
+
+	if _, err := s.store.GetUser(c, req.Payer); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	request, err := s.store.CreatePaymentRequest(c, db.CreatePaymentRequestParams{
+		Requester:   requester,
+		Payer:       req.Payer,
+		ToAccountID: req.ToAccountID,
+		Amount:      req.Amount,
+		Memo:        req.Memo,
+		ExpiresAt:   time.Now().Add(s.config.PaymentRequestExpiry),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// PaymentRequestRequestURI holds URI parameters for payment request handlers.
+type PaymentRequestRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getPaymentRequest(c *gin.Context) {
+	var req PaymentRequestRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	request, err := s.store.GetPaymentRequest(c, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	username := authPayload(c).Username
+	if request.Requester != username && request.Payer != username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrPaymentRequestNotOwned))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// ListPaymentRequestsRequest holds parameters for listPaymentRequests handler.
+// Incoming requests are the ones the user is asked to pay, outgoing requests
+// are the ones the user has created.
+type ListPaymentRequestsRequest struct {
+	Direction string `form:"direction" binding:"required,oneof=incoming outgoing"`
+	PageNum   int32  `form:"page_num" binding:"required,min=1"`
+	PageSize  int32  `form:"page_size" binding:"required,min=1,max=1000"`
+}
+
+func (s *Server) listPaymentRequests(c *gin.Context) {
+	var req ListPaymentRequestsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	username := authPayload(c).Username
+	offset := (req.PageNum - 1) * req.PageSize
+
+	var requests []db.PaymentRequest
+	var err error
+	if req.Direction == directionIncoming {
+		requests, err = s.store.ListIncomingPaymentRequests(c, db.ListIncomingPaymentRequestsParams{
+			Payer:  username,
+			Limit:  req.PageSize,
+			Offset: offset,
+		})
+	} else {
+		requests, err = s.store.ListOutgoingPaymentRequests(c, db.ListOutgoingPaymentRequestsParams{
+			Requester: username,
+			Limit:     req.PageSize,
+			Offset:    offset,
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, requests)
+}
+
+// PayPaymentRequestRequestJSON holds JSON parameters for payPaymentRequest handler.
+// The source account is referenced either by its ID or by its account number.
+type PayPaymentRequestRequestJSON struct {
+	FromAccountID     int64  `json:"from_account_id" binding:"min=0"`
+	FromAccountNumber string `json:"from_account_number"`
+	// OTP is the TOTP code confirming a payment above the step-up amount.
+	OTP string `json:"otp"`
+}
+
+func (s *Server) payPaymentRequest(c *gin.Context) {
+	var reqURI PaymentRequestRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqJSON PayPaymentRequestRequestJSON
+	if err := c.ShouldBindJSON(&reqJSON); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	fromAccountID, ok := s.resolveAccountID(c, reqJSON.FromAccountID, reqJSON.FromAccountNumber)
+	if !ok {
+		return
+	}
+
+	request, err := s.store.GetPaymentRequest(c, reqURI.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	username := authPayload(c).Username
+	if request.Payer != username {
+		c.JSON(http.StatusForbidden, errorResponse(db.ErrNotPayer))
+
+		return
+	}
+
+	if !s.authorizeSpend(c, fromAccountID, request.Amount) {
+		return
+	}
+
+	if !s.stepUpTransfer(c, request.Amount, reqJSON.OTP) {
+		return
+	}
+
+	// payment requests can not bypass the approval policy of the source account
+	policy, err := s.store.GetApprovalPolicy(c, fromAccountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if err == nil && request.Amount > policy.Threshold {
+		c.JSON(http.StatusForbidden, errorResponse(ErrPaymentRequestApproval))
+
+		return
+	}
+
+	result, err := s.store.PayPaymentRequestTx(c, db.PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            username,
+		FromAccountID:    fromAccountID,
+		PayeeCap:         s.payeeCap(),
+	})
+	if err != nil {
+		c.JSON(paymentRequestErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (s *Server) declinePaymentRequest(c *gin.Context) {
+	var req PaymentRequestRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	request, err := s.store.DeclinePaymentRequestTx(c, db.DeclinePaymentRequestTxParams{
+		PaymentRequestID: req.ID,
+		Payer:            authPayload(c).Username,
+	})
+	if err != nil {
+		c.JSON(paymentRequestErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// paymentRequestErrorStatus maps errors of the pay and decline transactions to HTTP status codes.
+func paymentRequestErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrNotPayer):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrPaymentRequestDecided):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrPaymentRequestExpired):
+		return http.StatusGone
+	default:
+		return transferErrorStatus(err)
+	}
+}
@@ -0,0 +1,468 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_CreatePaymentRequest(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	payer := db.User{Username: util.RandomOwner()}
+	request := db.PaymentRequest{
+		ID:          util.RandomInt(1, 2048),
+		Requester:   account.Owner,
+		Payer:       payer.Username,
+		ToAccountID: account.ID,
+		Amount:      util.RandomAmount(),
+		Memo:        "dinner",
+		Status:      db.PaymentRequestStatusPending,
+	}
+
+	tests := []struct {
+		name          string
+		params        api.CreatePaymentRequestRequest
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			params: api.CreatePaymentRequestRequest{
+				Payer:       payer.Username,
+				ToAccountID: account.ID,
+				Amount:      request.Amount,
+				Memo:        request.Memo,
+			},
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetUser", mock.Anything, payer.Username).Return(payer, nil)
+				store.On("CreatePaymentRequest", mock.Anything, mock.MatchedBy(func(arg db.CreatePaymentRequestParams) bool {
+					d := time.Now().Add(testConfig.PaymentRequestExpiry).Sub(arg.ExpiresAt)
+
+					return arg.Requester == account.Owner &&
+						arg.Payer == payer.Username &&
+						arg.ToAccountID == account.ID &&
+						arg.Amount == request.Amount &&
+						arg.Memo == request.Memo &&
+						d >= 0 && d < time.Second
+				})).Return(request, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, request.ID, bytesToPaymentRequest(t, recorder.Body.Bytes()).ID)
+			},
+		},
+		{
+			name: "Self",
+			params: api.CreatePaymentRequestRequest{
+				Payer:       account.Owner,
+				ToAccountID: account.ID,
+				Amount:      request.Amount,
+			},
+			username:  account.Owner,
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotOwned",
+			params: api.CreatePaymentRequestRequest{
+				Payer:       payer.Username,
+				ToAccountID: account.ID,
+				Amount:      request.Amount,
+			},
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, mock.Anything).
+					Return(db.AccountHolder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "PayerNotFound",
+			params: api.CreatePaymentRequestRequest{
+				Payer:       payer.Username,
+				ToAccountID: account.ID,
+				Amount:      request.Amount,
+			},
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetUser", mock.Anything, payer.Username).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidAmount",
+			params: api.CreatePaymentRequestRequest{
+				Payer:       payer.Username,
+				ToAccountID: account.ID,
+				Amount:      -1,
+			},
+			username:  account.Owner,
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/payment-requests", bytes.NewReader(b))
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetPaymentRequest(t *testing.T) {
+	request := db.PaymentRequest{
+		ID:        util.RandomInt(1, 2048),
+		Requester: util.RandomOwner(),
+		Payer:     util.RandomOwner(),
+		Amount:    util.RandomAmount(),
+		Status:    db.PaymentRequestStatusPending,
+	}
+
+	tests := []struct {
+		name     string
+		username string
+		code     int
+	}{
+		{name: "Requester", username: request.Requester, code: http.StatusOK},
+		{name: "Payer", username: request.Payer, code: http.StatusOK},
+		{name: "NotOwned", username: util.RandomOwner(), code: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			mockStore.On("GetPaymentRequest", mock.Anything, request.ID).Return(request, nil)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/users/payment-requests/%d", request.ID)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			assert.Equal(t, test.code, recorder.Code)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ListPaymentRequests(t *testing.T) {
+	username := util.RandomOwner()
+	requests := []db.PaymentRequest{
+		{ID: util.RandomInt(1, 1024), Requester: util.RandomOwner(), Payer: username},
+		{ID: util.RandomInt(1025, 2048), Requester: util.RandomOwner(), Payer: username},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Incoming",
+			query: "direction=incoming&page_num=2&page_size=5",
+			buildStub: func(store *mocks.Store) {
+				store.On("ListIncomingPaymentRequests", mock.Anything, db.ListIncomingPaymentRequestsParams{
+					Payer:  username,
+					Limit:  5,
+					Offset: 5,
+				}).Return(requests, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result []db.PaymentRequest
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, requests, result)
+			},
+		},
+		{
+			name:  "Outgoing",
+			query: "direction=outgoing&page_num=1&page_size=5",
+			buildStub: func(store *mocks.Store) {
+				store.On("ListOutgoingPaymentRequests", mock.Anything, db.ListOutgoingPaymentRequestsParams{
+					Requester: username,
+					Limit:     5,
+					Offset:    0,
+				}).Return([]db.PaymentRequest{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidDirection",
+			query:     "direction=all&page_num=1&page_size=5",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			req := httptest.NewRequest(http.MethodGet, "/users/payment-requests?"+test.query, nil)
+			addAuthorization(t, req, username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_PayPaymentRequest(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	request := db.PaymentRequest{
+		ID:          util.RandomInt(1, 2048),
+		Requester:   util.RandomOwner(),
+		Payer:       account.Owner,
+		ToAccountID: util.RandomInt(1, 2048),
+		Amount:      util.RandomAmount(),
+		Status:      db.PaymentRequestStatusPending,
+	}
+	arg := db.PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            account.Owner,
+		FromAccountID:    account.ID,
+		PayeeCap: db.PayeeCap{
+			CoolingOff: testConfig.PayeeCoolingOff,
+			Limit:      testConfig.PayeeCoolingOffLimit,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPaymentRequest", mock.Anything, request.ID).Return(request, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetApprovalPolicy", mock.Anything, account.ID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("PayPaymentRequestTx", mock.Anything, arg).Return(db.PayPaymentRequestTxResult{
+					PaymentRequest: db.PaymentRequest{ID: request.ID, Status: db.PaymentRequestStatusPaid},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result db.PayPaymentRequestTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, db.PaymentRequestStatusPaid, result.PaymentRequest.Status)
+			},
+		},
+		{
+			name:     "NotPayer",
+			username: request.Requester,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPaymentRequest", mock.Anything, request.ID).Return(request, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "ApprovalRequired",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPaymentRequest", mock.Anything, request.ID).Return(request, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetApprovalPolicy", mock.Anything, account.ID).
+					Return(db.ApprovalPolicy{AccountID: account.ID, Threshold: request.Amount - 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "AlreadyPaid",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPaymentRequest", mock.Anything, request.ID).Return(request, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetApprovalPolicy", mock.Anything, account.ID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("PayPaymentRequestTx", mock.Anything, arg).
+					Return(db.PayPaymentRequestTxResult{}, db.ErrPaymentRequestDecided)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPaymentRequest", mock.Anything, request.ID).Return(request, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetApprovalPolicy", mock.Anything, account.ID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("PayPaymentRequestTx", mock.Anything, arg).
+					Return(db.PayPaymentRequestTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPaymentRequest", mock.Anything, request.ID).Return(db.PaymentRequest{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(api.PayPaymentRequestRequestJSON{FromAccountID: account.ID})
+			require.NoError(t, err)
+			url := fmt.Sprintf("/users/payment-requests/%d/pay", request.ID)
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_DeclinePaymentRequest(t *testing.T) {
+	requestID := util.RandomInt(1, 2048)
+	payer := util.RandomOwner()
+	arg := db.DeclinePaymentRequestTxParams{
+		PaymentRequestID: requestID,
+		Payer:            payer,
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "OK", code: http.StatusOK},
+		{name: "NotPayer", err: db.ErrNotPayer, code: http.StatusForbidden},
+		{name: "Expired", err: db.ErrPaymentRequestExpired, code: http.StatusGone},
+		{name: "NotFound", err: sql.ErrNoRows, code: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			mockStore.On("DeclinePaymentRequestTx", mock.Anything, arg).Return(db.PaymentRequest{
+				ID:     requestID,
+				Status: db.PaymentRequestStatusDeclined,
+			}, test.err)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/users/payment-requests/%d/decline", requestID)
+			req := httptest.NewRequest(http.MethodPost, url, nil)
+			addAuthorization(t, req, payer)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			assert.Equal(t, test.code, recorder.Code)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func bytesToPaymentRequest(t *testing.T, b []byte) db.PaymentRequest {
+	t.Helper()
+
+	var request db.PaymentRequest
+	require.NoError(t, json.Unmarshal(b, &request))
+
+	return request
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	db "github.com/chutommy/simple-bank/db/sqlc"
 	"github.com/gin-gonic/gin"
 )
 
@@ -8,37 +9,166 @@ import (
 // the constructed gin router.
 func getRouter(s *Server) *gin.Engine {
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery())
+	// the client IP is used by the login throttling, the API key allowlists
+	// and the audit log, so the forwarding headers are trusted only from proxies
+	r.TrustedProxies = s.config.TrustedProxies
+	r.Use(gin.Logger(), gin.Recovery(), auditMiddleware())
 
-	accounts := r.Group("/accounts")
+	accounts := r.Group("/accounts", authMiddleware(s.tokenMaker, s.store))
 	{
 		accounts.POST("", s.createAccount)
-		accounts.GET("/:id", s.getAccountByID)
 		accounts.GET("", s.listAccounts)
-		accounts.PUT("/:id", s.updateAccount)
-		accounts.DELETE("/:id", s.deleteAccount)
+		accounts.GET("/by-number/:number", s.getAccountByNumber)
+
+		account := accounts.Group("/:id")
+		{
+			account.GET("", s.getAccountByID)
+			account.PUT("", s.updateAccount)
+			account.DELETE("", s.deleteAccount)
+			account.GET("/transfers", s.listAccountTransfers)
+			account.GET("/analytics", s.getAccountAnalytics)
+			account.GET("/balance", s.getAccountBalance)
+			account.GET("/balance-history", s.getAccountBalanceHistory)
+			account.GET("/events", s.streamAccountEvents)
+		}
+
+		holders := accounts.Group("/:id/holders")
+		{
+			holders.GET("", s.listAccountHolders)
+			holders.POST("", s.inviteAccountHolder)
+			holders.POST("/accept", s.acceptAccountHolder)
+			holders.DELETE("/:username", s.removeAccountHolder)
+		}
+
+		policy := accounts.Group("/:id/approval-policy")
+		{
+			policy.GET("", s.getApprovalPolicy)
+			policy.PUT("", s.setApprovalPolicy)
+			policy.DELETE("", s.deleteApprovalPolicy)
+		}
 	}
 
-	entries := r.Group("/entries")
+	entries := r.Group("/entries", authMiddleware(s.tokenMaker, s.store))
 	{
 		entries.GET("/id/:id", s.getEntryByID)
 		entries.GET("/accountid/:account_id", s.listEntries)
 		entries.POST("", s.createEntry)
 		entries.PUT("/:id", s.updateEntry)
+		entries.PUT("/:id/category", s.setEntryCategory)
 		entries.DELETE("/:id", s.deleteEntry)
 	}
 
-	transfers := r.Group("/transfers")
+	transfers := r.Group("/transfers", authMiddleware(s.tokenMaker, s.store))
 	{
 		transfers.POST("", s.makeTransfer)
+		transfers.GET("/quote", s.quoteTransfer)
+		transfers.POST("/batch", s.makeBatchTransfer)
+		transfers.GET("/batch/:id", s.getBatch)
+		transfers.GET("/pending/:id", s.getPendingTransfer)
+		transfers.POST("/pending/:id/approve", s.approvePendingTransfer)
+		transfers.POST("/pending/:id/reject", s.rejectPendingTransfer)
 	}
 
 	users := r.Group("/users")
 	{
-		users.GET("", s.getUser)
+		users.GET("", authMiddleware(s.tokenMaker, s.store), s.getUser)
 		users.POST("", s.createUser)
-		users.PUT("", s.updateUserPassword)
-		users.DELETE("", s.deleteUser)
+		users.PUT("", authMiddleware(s.tokenMaker, s.store), bearerOnlyMiddleware(), s.updateUserPassword)
+		users.DELETE("", authMiddleware(s.tokenMaker, s.store), bearerOnlyMiddleware(), s.deleteUser)
+		users.POST("/login", s.loginUser)
+		users.POST("/login/2fa", s.loginTwoFactor)
+		users.POST("/logout", s.logoutUser)
+		users.POST("/email-verification", s.sendEmailVerification)
+		users.POST("/email-verification/confirm", s.confirmEmailVerification)
+		users.POST("/password-reset", s.requestPasswordReset)
+		users.POST("/password-reset/confirm", s.confirmPasswordReset)
+		users.GET("/me/summary", authMiddleware(s.tokenMaker, s.store), s.getUserSummary)
+
+		sessions := users.Group("/sessions", authMiddleware(s.tokenMaker, s.store))
+		{
+			sessions.GET("", s.listSessions)
+			sessions.DELETE("/:id", s.revokeSession)
+		}
+
+		twoFactor := users.Group("/2fa", authMiddleware(s.tokenMaker, s.store), bearerOnlyMiddleware())
+		{
+			twoFactor.GET("", s.getTwoFactorStatus)
+			twoFactor.POST("/totp", s.enrollTOTP)
+			twoFactor.POST("/totp/confirm", s.confirmTOTP)
+			twoFactor.DELETE("/totp", s.disableTOTP)
+			twoFactor.POST("/recovery-codes", s.regenerateRecoveryCodes)
+		}
+
+		apiKeys := users.Group("/api-keys", authMiddleware(s.tokenMaker, s.store), bearerOnlyMiddleware())
+		{
+			apiKeys.POST("", s.createAPIKey)
+			apiKeys.GET("", s.listAPIKeys)
+			apiKeys.POST("/:id/rotate", s.rotateAPIKey)
+			apiKeys.DELETE("/:id", s.revokeAPIKey)
+		}
+
+		payees := users.Group("/payees", authMiddleware(s.tokenMaker, s.store))
+		{
+			payees.POST("", s.createPayee)
+			payees.GET("", s.listPayees)
+			payees.GET("/:id", s.getPayee)
+			payees.PUT("/:id", s.updatePayee)
+			payees.DELETE("/:id", s.deletePayee)
+		}
+
+		categories := users.Group("/categories", authMiddleware(s.tokenMaker, s.store))
+		{
+			categories.POST("", s.createCategory)
+			categories.GET("", s.listCategories)
+			categories.DELETE("/:id", s.deleteCategory)
+		}
+
+		rules := users.Group("/category-rules", authMiddleware(s.tokenMaker, s.store))
+		{
+			rules.POST("", s.createCategoryRule)
+			rules.GET("", s.listCategoryRules)
+			rules.DELETE("/:id", s.deleteCategoryRule)
+		}
+
+		webhooks := users.Group("/webhooks", authMiddleware(s.tokenMaker, s.store))
+		{
+			webhooks.POST("", s.createWebhookEndpoint)
+			webhooks.GET("", s.listWebhookEndpoints)
+			webhooks.GET("/:id", s.getWebhookEndpoint)
+			webhooks.DELETE("/:id", s.deleteWebhookEndpoint)
+			webhooks.GET("/:id/deliveries", s.listWebhookDeliveries)
+			webhooks.GET("/:id/deliveries/:delivery_id", s.getWebhookDelivery)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", s.redeliverWebhookDelivery)
+		}
+
+		requests := users.Group("/payment-requests", authMiddleware(s.tokenMaker, s.store))
+		{
+			requests.POST("", s.createPaymentRequest)
+			requests.GET("", s.listPaymentRequests)
+			requests.GET("/:id", s.getPaymentRequest)
+			requests.POST("/:id/pay", s.payPaymentRequest)
+			requests.POST("/:id/decline", s.declinePaymentRequest)
+		}
+	}
+
+	tokens := r.Group("/tokens")
+	{
+		tokens.POST("/renew", s.renewToken)
+	}
+
+	admin := r.Group("/admin", authMiddleware(s.tokenMaker, s.store))
+	{
+		admin.GET("/users", roleMiddleware(staffRoles...), s.searchUsers)
+		admin.PUT("/users/:username/role", roleMiddleware(db.UserRoleAdmin), s.setUserRole)
+		admin.GET("/users/:username/lock", roleMiddleware(staffRoles...), s.getLoginLock)
+		admin.DELETE("/users/:username/lock", roleMiddleware(operatorRoles...), s.unlockLogin)
+		admin.GET("/accounts", roleMiddleware(staffRoles...), s.searchAccounts)
+		admin.POST("/accounts/:id/freeze", roleMiddleware(operatorRoles...), s.freezeAccount)
+		admin.POST("/accounts/:id/unfreeze", roleMiddleware(operatorRoles...), s.unfreezeAccount)
+		admin.GET("/accounts/:id/adjustments", roleMiddleware(staffRoles...), s.listAdjustments)
+		admin.POST("/accounts/:id/adjustments", roleMiddleware(db.UserRoleAdmin), s.createAdjustment)
+		admin.GET("/audit-log", roleMiddleware(auditRoles...), s.listAuditLog)
+		admin.GET("/audit-log/verify", roleMiddleware(auditRoles...), s.verifyAuditLog)
 	}
 
 	return r
@@ -2,16 +2,29 @@ package api
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/chutommy/simple-bank/config"
 	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/chutommy/simple-bank/password"
+	"github.com/chutommy/simple-bank/rate"
+	"github.com/chutommy/simple-bank/stream"
+	"github.com/chutommy/simple-bank/token"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 )
 
+// uniqueViolation is the Postgres error code of a unique constraint violation.
+const uniqueViolation = "23505"
+
 var (
 	// ErrInvalidAddress is returned when an invalid server address is provided.
 	ErrInvalidAddress = errors.New("invalid server address")
@@ -21,22 +34,85 @@ var (
 
 // Server serves HTTP requests for the banking service.
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config     *config.Config
+	store      db.Store
+	tokenMaker token.Maker
+	rates      rate.Source
+	events     stream.Broker
+	mailer     mail.Mailer
+	passwords  *password.Hasher
+	router     *gin.Engine
+
+	// dummyHash is verified for unknown usernames, so the login takes the same time
+	dummyHash string
+
+	// done is closed when the server is shutting down, so long-lived streams can finish
+	done <-chan struct{}
 
 	Srv *http.Server
 }
 
 // NewServer constructs a new HTTP Server and setup the routing.
-func NewServer(store db.Store) *Server {
-	s := &Server{store: store}
+// The account event streams are woken up by the events Broker and the emails
+// are sent by the mailer.
+func NewServer(cfg *config.Config, store db.Store, events stream.Broker, mailer mail.Mailer) (*Server, error) {
+	tokenMaker, err := token.NewJWTMaker(cfg.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	for _, proxy := range cfg.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+		}
+	}
+
+	rates, err := rate.NewFileSource(cfg.RatesFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create exchange rate source: %w", err)
+	}
+
+	passwords, err := password.NewHasher(password.Params{
+		Algorithm:     cfg.PasswordHashAlgorithm,
+		Argon2Time:    cfg.PasswordArgon2Time,
+		Argon2Memory:  cfg.PasswordArgon2Memory,
+		Argon2Threads: cfg.PasswordArgon2Threads,
+		BcryptCost:    cfg.PasswordBcryptCost,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create password hasher: %w", err)
+	}
+
+	dummyHash, err := passwords.Hash("dummy password")
+	if err != nil {
+		return nil, fmt.Errorf("cannot hash dummy password: %w", err)
+	}
+
+	s := &Server{
+		config:     cfg,
+		store:      store,
+		tokenMaker: tokenMaker,
+		rates:      rates,
+		events:     events,
+		mailer:     mailer,
+		passwords:  passwords,
+		dummyHash:  dummyHash,
+	}
 	s.router = getRouter(s)
 
 	s.Srv = &http.Server{
 		Handler: s.router,
 	}
 
-	return s
+	// Shutdown does not wait for hijacked connections and would wait
+	// for the event streams forever, so they are closed explicitly
+	done, cancel := context.WithCancel(context.Background())
+	s.done = done.Done()
+	s.Srv.RegisterOnShutdown(cancel)
+
+	return s, nil
 }
 
 // Start initializes the HTTP Server on a given address.
@@ -64,7 +140,30 @@ func (s *Server) Stop() error {
 	return nil
 }
 
+// randomID returns a random hex-encoded 128-bit identifier.
+func randomID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
 // errorResponse serializes the error into a JSON key-value pair struct.
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
+
+// isUniqueViolation reports whether the error is caused by a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pqErrPtr *pq.Error
+	if errors.As(err, &pqErrPtr) {
+		return pqErrPtr.Code == uniqueViolation
+	}
+
+	var pqErr pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == uniqueViolation
+	}
+
+	return false
+}
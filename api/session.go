@@ -0,0 +1,234 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrInvalidRefreshToken is returned when the refresh token does not belong to any session.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrSessionNotOwned is returned when the session belongs to another user.
+	ErrSessionNotOwned = errors.New("session belongs to another user")
+)
+
+// startSession starts a new session family of the user and responds
+// with its tokens.
+func (s *Server) startSession(c *gin.Context, user db.User) {
+	refreshToken, refreshTokenHash, err := token.NewOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	session, err := s.store.CreateSession(c, db.CreateSessionParams{
+		FamilyID:         randomID(),
+		Username:         user.Username,
+		RefreshTokenHash: refreshTokenHash,
+		UserAgent:        c.Request.UserAgent(),
+		ClientIP:         c.ClientIP(),
+		ExpiresAt:        time.Now().Add(s.config.RefreshTokenDuration),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp, err := s.issueTokens(user, session, refreshToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// issueTokens issues a new access token of the user for the session
+// and returns it together with the refresh token of the session.
+func (s *Server) issueTokens(user db.User, session db.Session, refreshToken string) (LoginUserResponse, error) {
+	accessToken, err := s.tokenMaker.CreateToken(user.Username, user.Role, session.FamilyID, s.config.AccessTokenDuration)
+	if err != nil {
+		return LoginUserResponse{}, err
+	}
+
+	return LoginUserResponse{
+		SessionID:             session.ID,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  time.Now().Add(s.config.AccessTokenDuration),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// RenewTokenRequest holds parameters for renewToken handler.
+type RenewTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// renewToken exchanges the refresh token for a new access token and a new refresh
+// token. Each refresh token can be used only once, using it again revokes
+// the whole session family, so a stolen token is useless after its first use.
+func (s *Server) renewToken(c *gin.Context) {
+	var req RenewTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	refreshToken, refreshTokenHash, err := token.NewOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	result, err := s.store.RotateSessionTx(c, db.RotateSessionTxParams{
+		RefreshTokenHash:    token.HashOpaqueToken(req.RefreshToken),
+		NewRefreshTokenHash: refreshTokenHash,
+		UserAgent:           c.Request.UserAgent(),
+		ClientIP:            c.ClientIP(),
+		ExpiresAt:           time.Now().Add(s.config.RefreshTokenDuration),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidRefreshToken))
+		case errors.Is(err, db.ErrSessionRevoked),
+			errors.Is(err, db.ErrSessionExpired),
+			errors.Is(err, db.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, errorResponse(err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	resp, err := s.issueTokens(result.User, result.Session, refreshToken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// LogoutUserRequest holds parameters for logoutUser handler.
+type LogoutUserRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// logoutUser revokes the session family of the refresh token. The access tokens
+// issued for the session stay valid until they expire.
+func (s *Server) logoutUser(c *gin.Context) {
+	var req LogoutUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	session, err := s.store.GetSessionByRefreshToken(c, token.HashOpaqueToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidRefreshToken))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	if _, err := s.store.RevokeSessionFamily(c, session.FamilyID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// SessionResponse is a db.Session without its refresh token.
+type SessionResponse struct {
+	ID        int64     `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIP  string    `json:"client_ip"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// CreatedAt is the time of the login or of the latest renewal.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// listSessions lists the active sessions of the authenticated user, the latest first.
+func (s *Server) listSessions(c *gin.Context) {
+	sessions, err := s.store.ListActiveSessions(c, authPayload(c).Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		resp[i] = SessionResponse{
+			ID:        session.ID,
+			UserAgent: session.UserAgent,
+			ClientIP:  session.ClientIP,
+			ExpiresAt: session.ExpiresAt,
+			CreatedAt: session.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RevokeSessionRequestURI holds URI parameters for revokeSession handler.
+type RevokeSessionRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// revokeSession revokes the session family of the session, e.g. to log out
+// a lost device.
+func (s *Server) revokeSession(c *gin.Context) {
+	var req RevokeSessionRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	session, err := s.store.GetSession(c, req.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	if session.Username != authPayload(c).Username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrSessionNotOwned))
+
+		return
+	}
+
+	if _, err := s.store.RevokeSessionFamily(c, session.FamilyID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
@@ -0,0 +1,337 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_RenewToken(t *testing.T) {
+	user := db.User{
+		Username: util.RandomOwner(),
+		Role:     db.UserRoleCustomer,
+	}
+	session := db.Session{
+		ID:        util.RandomInt(1, 2048),
+		FamilyID:  util.RandomString(32),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	refreshToken, refreshTokenHash, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		params        api.RenewTokenRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			params: api.RenewTokenRequest{RefreshToken: refreshToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("RotateSessionTx", mock.Anything, mock.MatchedBy(func(arg db.RotateSessionTxParams) bool {
+					return arg.RefreshTokenHash == refreshTokenHash &&
+						arg.NewRefreshTokenHash != "" && arg.NewRefreshTokenHash != refreshTokenHash
+				})).Return(db.RotateSessionTxResult{Session: session, User: user}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.LoginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, session.ID, body.SessionID)
+				assert.NotEmpty(t, body.AccessToken)
+				assert.NotEmpty(t, body.RefreshToken)
+				assert.NotEqual(t, refreshToken, body.RefreshToken)
+			},
+		},
+		{
+			name:   "UnknownToken",
+			params: api.RenewTokenRequest{RefreshToken: refreshToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("RotateSessionTx", mock.Anything, mock.Anything).
+					Return(db.RotateSessionTxResult{}, fmt.Errorf("can not rotate session: %w", sql.ErrNoRows))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Reused",
+			params: api.RenewTokenRequest{RefreshToken: refreshToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("RotateSessionTx", mock.Anything, mock.Anything).
+					Return(db.RotateSessionTxResult{}, db.ErrRefreshTokenReused)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Revoked",
+			params: api.RenewTokenRequest{RefreshToken: refreshToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("RotateSessionTx", mock.Anything, mock.Anything).
+					Return(db.RotateSessionTxResult{}, fmt.Errorf("can not rotate session: %w", db.ErrSessionRevoked))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			params: api.RenewTokenRequest{RefreshToken: refreshToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("RotateSessionTx", mock.Anything, mock.Anything).
+					Return(db.RotateSessionTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRequest",
+			params:    api.RenewTokenRequest{},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/tokens/renew", bytes.NewReader(b))
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_LogoutUser(t *testing.T) {
+	refreshToken, refreshTokenHash, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+
+	session := db.Session{
+		ID:               util.RandomInt(1, 2048),
+		FamilyID:         util.RandomString(32),
+		Username:         util.RandomOwner(),
+		RefreshTokenHash: refreshTokenHash,
+	}
+
+	tests := []struct {
+		name          string
+		params        api.LogoutUserRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			params: api.LogoutUserRequest{RefreshToken: refreshToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetSessionByRefreshToken", mock.Anything, refreshTokenHash).Return(session, nil)
+				store.On("RevokeSessionFamily", mock.Anything, session.FamilyID).Return(int64(1), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "UnknownToken",
+			params: api.LogoutUserRequest{RefreshToken: refreshToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetSessionByRefreshToken", mock.Anything, refreshTokenHash).Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			params: api.LogoutUserRequest{RefreshToken: refreshToken},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetSessionByRefreshToken", mock.Anything, refreshTokenHash).Return(session, nil)
+				store.On("RevokeSessionFamily", mock.Anything, session.FamilyID).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRequest",
+			params:    api.LogoutUserRequest{},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/logout", bytes.NewReader(b))
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ListSessions(t *testing.T) {
+	username := util.RandomOwner()
+	sessions := []db.Session{
+		{
+			ID:               util.RandomInt(1, 2048),
+			Username:         username,
+			RefreshTokenHash: util.RandomString(64),
+			UserAgent:        "curl/7.68.0",
+			ClientIP:         "192.0.2.1",
+		},
+	}
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	mockStore.On("ListActiveSessions", mock.Anything, username).Return(sessions, nil)
+
+	// prepare request and response recorder
+	req := httptest.NewRequest(http.MethodGet, "/users/sessions", nil)
+	addAuthorization(t, req, username)
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	// check response
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), sessions[0].RefreshTokenHash)
+
+	var body []api.SessionResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	require.Len(t, body, 1)
+	assert.Equal(t, sessions[0].ID, body[0].ID)
+	assert.Equal(t, sessions[0].UserAgent, body[0].UserAgent)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_RevokeSession(t *testing.T) {
+	session := db.Session{
+		ID:       util.RandomInt(1, 2048),
+		FamilyID: util.RandomString(32),
+		Username: util.RandomOwner(),
+	}
+
+	tests := []struct {
+		name          string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: session.Username,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetSession", mock.Anything, session.ID).Return(session, nil)
+				store.On("RevokeSessionFamily", mock.Anything, session.FamilyID).Return(int64(2), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwned",
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetSession", mock.Anything, session.ID).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: session.Username,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetSession", mock.Anything, session.ID).Return(db.Session{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: session.Username,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetSession", mock.Anything, session.ID).Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/users/sessions/%d", session.ID)
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
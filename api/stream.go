@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/stream"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// lastEventIDHeader is the header of a reconnecting EventSource.
+	lastEventIDHeader = "Last-Event-ID"
+	// streamBatchSize is the maximal number of events fetched from the store at once.
+	streamBatchSize = 100
+	// streamHeartbeat is the period of the keep-alive messages of an idle stream.
+	streamHeartbeat = 15 * time.Second
+)
+
+// ErrInvalidLastEventID is returned when the Last-Event-ID is not an event ID.
+var ErrInvalidLastEventID = errors.New("invalid last event ID")
+
+// StreamAccountEventsRequestURI holds URI parameters for streamAccountEvents handler.
+type StreamAccountEventsRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// StreamAccountEventsRequestQuery holds query parameters for streamAccountEvents handler.
+// LastEventID replaces the Last-Event-ID header for clients which cannot set it.
+type StreamAccountEventsRequestQuery struct {
+	LastEventID string `form:"last_event_id"`
+}
+
+// AccountEventMessage is a message of an account event stream sent over WebSocket.
+// The ID is the value to resume the stream from.
+type AccountEventMessage struct {
+	ID   int64           `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// eventWriter writes the events of a stream to the client.
+type eventWriter interface {
+	WriteEvent(event db.Outbox) error
+	Heartbeat() error
+	// Done is closed when the client goes away.
+	Done() <-chan struct{}
+}
+
+// streamAccountEvents pushes the events of the account, such as new entries and balance
+// changes, as they are committed. The stream is served over WebSocket if the client asks
+// for an upgrade and as server-sent events otherwise. A stream resumes after the event
+// given by Last-Event-ID, it starts with the next new event by default. The stream is
+// closed once the credentials expire or the access to the account is revoked.
+func (s *Server) streamAccountEvents(c *gin.Context) {
+	var reqURI StreamAccountEventsRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqQuery StreamAccountEventsRequestQuery
+	if err := c.ShouldBindQuery(&reqQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	lastEventID := c.GetHeader(lastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = reqQuery.LastEventID
+	}
+
+	var after int64
+	if lastEventID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || after < 0 {
+			c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidLastEventID))
+
+			return
+		}
+	}
+
+	account, ok := s.authorizeAccount(c, reqURI.ID, permView)
+	if !ok {
+		return
+	}
+
+	// subscribe before reading the latest event, so no event is missed
+	wake, unsubscribe := s.events.Subscribe(account.ID)
+	defer unsubscribe()
+
+	if lastEventID == "" {
+		var err error
+		if after, err = s.store.GetLatestAccountEventID(c, account.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
+	}
+
+	var w eventWriter
+	if stream.IsWebSocket(c.Request) {
+		conn, err := stream.Upgrade(c.Writer, c.Request)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		w = wsEventWriter{conn}
+	} else {
+		w = newSSEEventWriter(c)
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	// API keys without an expiry have a zero ExpiredAt
+	var expired <-chan time.Time
+	if expiredAt := authPayload(c).ExpiredAt; !expiredAt.IsZero() {
+		timer := time.NewTimer(time.Until(expiredAt))
+		defer timer.Stop()
+
+		expired = timer.C
+	}
+
+	for {
+		events, err := s.store.ListAccountEventsAfter(c, db.ListAccountEventsAfterParams{
+			AccountID: account.ID,
+			AfterID:   after,
+			MaxEvents: streamBatchSize,
+		})
+		if err != nil {
+			return
+		}
+
+		for _, event := range events {
+			if err := w.WriteEvent(event); err != nil {
+				return
+			}
+
+			after = event.ID
+		}
+
+		// more events are waiting
+		if len(events) == streamBatchSize {
+			continue
+		}
+
+		select {
+		case <-wake:
+		case <-heartbeat.C:
+			if err := w.Heartbeat(); err != nil {
+				return
+			}
+		case <-expired:
+			return
+		case <-w.Done():
+			return
+		case <-s.done:
+			return
+		}
+
+		// the access may be revoked while the stream is open
+		if !s.streamAuthorized(c, account.ID) {
+			return
+		}
+	}
+}
+
+// streamAuthorized checks whether the authenticated user still may view the account
+// and whether the API key, if used, is still valid.
+func (s *Server) streamAuthorized(c *gin.Context, accountID int64) bool {
+	if v, ok := c.Get(authorizationAPIKeyKey); ok {
+		apiKey, err := s.store.GetAPIKeyByPrefix(c, v.(db.APIKey).Prefix)
+		if err != nil || apiKey.RevokedAt.Valid || (apiKey.ExpiresAt.Valid && !time.Now().Before(apiKey.ExpiresAt.Time)) {
+			return false
+		}
+	}
+
+	_, holder, err := s.lookupAccountHolder(c, accountID, authPayload(c).Username)
+
+	return err == nil && rolePermissions[holder.Role] >= permView
+}
+
+// sseEventWriter writes the events as server-sent events.
+type sseEventWriter struct {
+	c *gin.Context
+}
+
+func newSSEEventWriter(c *gin.Context) sseEventWriter {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	return sseEventWriter{c: c}
+}
+
+func (w sseEventWriter) WriteEvent(event db.Outbox) error {
+	if _, err := fmt.Fprintf(w.c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.EventType, event.Payload); err != nil {
+		return err
+	}
+
+	w.c.Writer.Flush()
+
+	return nil
+}
+
+func (w sseEventWriter) Heartbeat() error {
+	if _, err := fmt.Fprint(w.c.Writer, ": heartbeat\n\n"); err != nil {
+		return err
+	}
+
+	w.c.Writer.Flush()
+
+	return nil
+}
+
+func (w sseEventWriter) Done() <-chan struct{} {
+	return w.c.Request.Context().Done()
+}
+
+// wsEventWriter writes the events as AccountEventMessage text messages.
+type wsEventWriter struct {
+	conn *stream.Conn
+}
+
+func (w wsEventWriter) WriteEvent(event db.Outbox) error {
+	msg, err := json.Marshal(AccountEventMessage{
+		ID:   event.ID,
+		Type: event.EventType,
+		Data: event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	return w.conn.WriteText(msg)
+}
+
+func (w wsEventWriter) Heartbeat() error {
+	return w.conn.Ping()
+}
+
+func (w wsEventWriter) Done() <-chan struct{} {
+	return w.conn.Done()
+}
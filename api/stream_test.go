@@ -0,0 +1,348 @@
+package api_test
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/stream"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// streamTestTimeout limits the time a test waits for a stream to be closed.
+const streamTestTimeout = 5 * time.Second
+
+func accountEvent(accountID, id int64, eventType string) db.Outbox {
+	return db.Outbox{
+		ID:        id,
+		EventID:   util.RandomString(32),
+		EventType: eventType,
+		AccountID: accountID,
+		Payload:   json.RawMessage(fmt.Sprintf(`{"type":%q}`, eventType)),
+	}
+}
+
+// listEventsAfter stubs a single call of ListAccountEventsAfter.
+func listEventsAfter(store *mocks.Store, accountID, after int64, events ...db.Outbox) *mock.Call {
+	return store.On("ListAccountEventsAfter", mock.Anything, db.ListAccountEventsAfterParams{
+		AccountID: accountID,
+		AfterID:   after,
+		MaxEvents: 100,
+	}).Return(events, nil).Once()
+}
+
+// readSSEEvent reads the fields of the next server-sent event.
+func readSSEEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+
+	fields := make(map[string]string)
+
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return fields
+		}
+
+		kv := strings.SplitN(line, ": ", 2)
+		require.Len(t, kv, 2)
+		fields[kv[0]] = kv[1]
+	}
+}
+
+func TestServer_StreamAccountEvents_SSE(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	hub := stream.NewHub()
+	server := newTestServerWithBroker(t, mockStore, hub)
+	ts := httptest.NewServer(server.Srv.Handler)
+
+	mockStore.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+	listEventsAfter(mockStore, account.ID, 5,
+		accountEvent(account.ID, 6, db.EventEntryCreated),
+		accountEvent(account.ID, 7, db.EventBalanceChanged))
+	listEventsAfter(mockStore, account.ID, 7, accountEvent(account.ID, 9, db.EventEntryCreated))
+	mockStore.On("ListAccountEventsAfter", mock.Anything, mock.Anything).Return([]db.Outbox{}, nil)
+
+	// prepare request resuming after the event 5
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/accounts/%d/events", ts.URL, account.ID), nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "5")
+	addAuthorization(t, req, account.Owner)
+
+	// serve
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	// check response
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(resp.Body)
+	assert.Equal(t, map[string]string{
+		"id":    "6",
+		"event": db.EventEntryCreated,
+		"data":  `{"type":"entry.created"}`,
+	}, readSSEEvent(t, r))
+	assert.Equal(t, "7", readSSEEvent(t, r)["id"])
+
+	// a committed event wakes the stream up
+	hub.Notify(account.ID)
+	assert.Equal(t, "9", readSSEEvent(t, r)["id"])
+
+	_ = resp.Body.Close()
+	ts.Close()
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_StreamAccountEvents_WebSocket(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	ts := httptest.NewServer(server.Srv.Handler)
+
+	defer ts.Close()
+
+	// without Last-Event-ID the stream starts after the latest event
+	mockStore.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+	mockStore.On("GetLatestAccountEventID", mock.Anything, account.ID).Return(int64(41), nil)
+	listEventsAfter(mockStore, account.ID, 41, accountEvent(account.ID, 42, db.EventTransferCreated))
+	mockStore.On("ListAccountEventsAfter", mock.Anything, mock.Anything).Return([]db.Outbox{}, nil)
+
+	// prepare the handshake
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/accounts/%d/events", ts.URL, account.ID), nil)
+	require.NoError(t, err)
+	addAuthorization(t, req, account.Owner)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	client, err := net.Dial("tcp", strings.TrimPrefix(ts.URL, "http://"))
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	_ = client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// serve
+	require.NoError(t, req.Write(client))
+
+	// check response
+	r := bufio.NewReader(client)
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	var header [2]byte
+	_, err = io.ReadFull(r, header[:])
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x81), header[0])
+
+	size := int(header[1])
+	if size == 126 {
+		var ext [2]byte
+		_, err = io.ReadFull(r, ext[:])
+		require.NoError(t, err)
+
+		size = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(r, payload)
+	require.NoError(t, err)
+
+	var msg api.AccountEventMessage
+	require.NoError(t, json.Unmarshal(payload, &msg))
+	assert.Equal(t, int64(42), msg.ID)
+	assert.Equal(t, db.EventTransferCreated, msg.Type)
+	assert.JSONEq(t, `{"type":"transfer.created"}`, string(msg.Data))
+}
+
+func TestServer_StreamAccountEvents_Revoked(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+	holder := util.RandomOwner()
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	hub := stream.NewHub()
+	server := newTestServerWithBroker(t, mockStore, hub)
+	ts := httptest.NewServer(server.Srv.Handler)
+
+	defer ts.Close()
+
+	// the holder is removed while the stream is open
+	mockStore.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+	mockStore.On("GetAccountHolder", mock.Anything, mock.Anything).Return(db.AccountHolder{
+		AccountID: account.ID,
+		Username:  holder,
+		Role:      db.HolderRoleViewer,
+		Status:    db.HolderStatusActive,
+	}, nil).Once()
+	mockStore.On("GetAccountHolder", mock.Anything, mock.Anything).Return(db.AccountHolder{}, sql.ErrNoRows)
+	listEventsAfter(mockStore, account.ID, 5)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/accounts/%d/events", ts.URL, account.ID), nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "5")
+	addAuthorization(t, req, holder)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the next wake-up closes the stream
+	hub.Notify(account.ID)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, body)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_StreamAccountEvents_Expired(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	ts := httptest.NewServer(server.Srv.Handler)
+
+	defer ts.Close()
+
+	mockStore.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+	listEventsAfter(mockStore, account.ID, 5)
+
+	// sign a token which expires shortly
+	maker, err := token.NewJWTMaker(testConfig.TokenSymmetricKey)
+	require.NoError(t, err)
+	tkn, err := maker.CreateToken(account.Owner, db.UserRoleCustomer, "", time.Second)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/accounts/%d/events", ts.URL, account.ID), nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "5")
+	req.Header.Set("Authorization", "Bearer "+tkn)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the stream is closed once the token expires
+	start := time.Now()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Empty(t, body)
+	assert.Less(t, time.Since(start), streamTestTimeout)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_StreamAccountEvents(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 2048),
+		Owner: util.RandomOwner(),
+	}
+
+	tests := []struct {
+		name          string
+		lastEventID   string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "InvalidLastEventID",
+			lastEventID: "abc",
+			username:    account.Owner,
+			buildStub:   func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotHolder",
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, mock.Anything).Return(db.AccountHolder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetLatestAccountEventID", mock.Anything, account.ID).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/events?last_event_id=%s", account.ID, test.lastEventID)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
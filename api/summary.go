@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/chutommy/simple-bank/rate"
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserSummaryRequest holds parameters for getUserSummary handler.
+type GetUserSummaryRequest struct {
+	Base string `form:"base" binding:"required,uppercase"`
+}
+
+// SummaryAccount is an account of the user with its balance converted into the base currency.
+// Accounts in currencies without an exchange rate are unconverted and not included in the total.
+type SummaryAccount struct {
+	ID          int64  `json:"id"`
+	Number      string `json:"number"`
+	Currency    string `json:"currency"`
+	Balance     int64  `json:"balance"`
+	Converted   int64  `json:"converted"`
+	Unconverted bool   `json:"unconverted"`
+}
+
+// SummarySubtotal is the total balance of the accounts of the user in one currency.
+type SummarySubtotal struct {
+	Currency    string `json:"currency"`
+	Balance     int64  `json:"balance"`
+	Converted   int64  `json:"converted"`
+	Unconverted bool   `json:"unconverted"`
+}
+
+// GetUserSummaryResponse holds the consolidated balances returned by getUserSummary handler.
+type GetUserSummaryResponse struct {
+	Base           string            `json:"base"`
+	RatesTimestamp time.Time         `json:"rates_timestamp"`
+	Accounts       []SummaryAccount  `json:"accounts"`
+	Subtotals      []SummarySubtotal `json:"subtotals"`
+	Total          int64             `json:"total"`
+}
+
+// getUserSummary lists the accounts owned by the user and consolidates their balances
+// into the base currency by the latest exchange rates.
+func (s *Server) getUserSummary(c *gin.Context) {
+	var req GetUserSummaryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	rates, err := s.rates.Rates(c, req.Base)
+	if err != nil {
+		if errors.Is(err, rate.ErrUnknownCurrency) {
+			c.JSON(http.StatusBadRequest, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	accounts, err := s.store.ListOwnerAccounts(c, authPayload(c).Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp := GetUserSummaryResponse{
+		Base:           rates.Base,
+		RatesTimestamp: rates.Timestamp,
+		Accounts:       []SummaryAccount{},
+		Subtotals:      []SummarySubtotal{},
+	}
+
+	// the accounts are ordered by their currencies
+	for _, account := range accounts {
+		// the rates may lack a currency the bank holds accounts in
+		converted, err := rates.Convert(account.Balance, account.Currency)
+		unconverted := errors.Is(err, rate.ErrUnknownCurrency)
+		if err != nil && !unconverted {
+			c.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+
+			return
+		}
+
+		resp.Accounts = append(resp.Accounts, SummaryAccount{
+			ID:          account.ID,
+			Number:      account.Number,
+			Currency:    account.Currency,
+			Balance:     account.Balance,
+			Converted:   converted,
+			Unconverted: unconverted,
+		})
+
+		if n := len(resp.Subtotals); n == 0 || resp.Subtotals[n-1].Currency != account.Currency {
+			resp.Subtotals = append(resp.Subtotals, SummarySubtotal{
+				Currency:    account.Currency,
+				Unconverted: unconverted,
+			})
+		}
+
+		subtotal := &resp.Subtotals[len(resp.Subtotals)-1]
+		subtotal.Balance += account.Balance
+		subtotal.Converted += converted
+		resp.Total += converted
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
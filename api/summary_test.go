@@ -0,0 +1,138 @@
+package api_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_GetUserSummary(t *testing.T) {
+	owner := util.RandomOwner()
+	accounts := []db.Account{
+		{ID: 1, Owner: owner, Currency: "BTC", Balance: 211},
+		{ID: 2, Owner: owner, Currency: "CZK", Balance: 25916},
+		{ID: 3, Owner: owner, Currency: "CZK", Balance: 51832},
+		{ID: 4, Owner: owner, Currency: "EUR", Balance: 1000},
+	}
+
+	tests := []struct {
+		name          string
+		base          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			base: "EUR",
+			buildStub: func(store *mocks.Store) {
+				store.On("ListOwnerAccounts", mock.Anything, owner).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.GetUserSummaryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, "EUR", result.Base)
+				assert.Equal(t, time.Date(2021, time.May, 3, 0, 0, 0, 0, time.UTC), result.RatesTimestamp)
+				require.Len(t, result.Accounts, len(accounts))
+				assert.Equal(t, int64(1000), result.Accounts[1].Converted)
+				assert.Equal(t, []api.SummarySubtotal{
+					{Currency: "BTC", Balance: 211, Converted: 10000000},
+					{Currency: "CZK", Balance: 77748, Converted: 3000},
+					{Currency: "EUR", Balance: 1000, Converted: 1000},
+				}, result.Subtotals)
+				assert.Equal(t, int64(10004000), result.Total)
+			},
+		},
+		{
+			name: "NoAccounts",
+			base: "CZK",
+			buildStub: func(store *mocks.Store) {
+				store.On("ListOwnerAccounts", mock.Anything, owner).Return([]db.Account{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.GetUserSummaryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, "CZK", result.Base)
+				assert.Empty(t, result.Accounts)
+				assert.Zero(t, result.Total)
+			},
+		},
+		{
+			name:      "UnknownBase",
+			base:      "KZT",
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingRate",
+			base: "EUR",
+			buildStub: func(store *mocks.Store) {
+				store.On("ListOwnerAccounts", mock.Anything, owner).Return([]db.Account{
+					{ID: 4, Owner: owner, Currency: "EUR", Balance: 1000},
+					{ID: 5, Owner: owner, Currency: "KZT", Balance: 10},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.GetUserSummaryResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				require.Len(t, result.Accounts, 2)
+				assert.False(t, result.Accounts[0].Unconverted)
+				assert.True(t, result.Accounts[1].Unconverted)
+				assert.Equal(t, []api.SummarySubtotal{
+					{Currency: "EUR", Balance: 1000, Converted: 1000},
+					{Currency: "KZT", Balance: 10, Unconverted: true},
+				}, result.Subtotals)
+				assert.Equal(t, int64(1000), result.Total)
+			},
+		},
+		{
+			name: "InternalError",
+			base: "EUR",
+			buildStub: func(store *mocks.Store) {
+				store.On("ListOwnerAccounts", mock.Anything, owner).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			req := httptest.NewRequest(http.MethodGet, "/users/me/summary?base="+test.base, nil)
+			addAuthorization(t, req, owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
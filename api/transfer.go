@@ -1,17 +1,43 @@
 package api
 
 import (
+	"bytes"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"time"
+
 	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
 	"github.com/gin-gonic/gin"
-	"net/http"
 )
 
+// ErrAccountReference is returned when an account of a transfer is referenced
+// by both or neither of its ID and number.
+var ErrAccountReference = errors.New("exactly one of the account ID and the account number is required")
+
+// ErrInvalidRemittanceInfo is returned when the remittance information is not a JSON object.
+var ErrInvalidRemittanceInfo = errors.New("remittance info must be a JSON object")
+
+// MakeTransferRequest holds parameters for makeTransfer handler. Each account
+// is referenced either by its ID or by its account number, the recipient
+// can be also referenced by a payee of the authenticated user. The reference
+// defaults to the one of the payee.
 type MakeTransferRequest struct {
-	FromAccountID int64 `json:"from_account_id" binding:"required,min=0"`
-	ToAccountID   int64 `json:"to_account_id" binding:"required,min=0"`
-	Amount        int64 `json:"amount" binding:"required"`
+	FromAccountID     int64  `json:"from_account_id" binding:"min=0"`
+	FromAccountNumber string `json:"from_account_number"`
+	ToAccountID       int64  `json:"to_account_id" binding:"min=0"`
+	ToAccountNumber   string `json:"to_account_number"`
+	PayeeID           int64  `json:"payee_id" binding:"min=0"`
+	Amount            int64  `json:"amount" binding:"required,min=1"`
+	Instant           bool   `json:"instant"`
+	Reference         string `json:"reference" binding:"max=140"`
+	Memo              string `json:"memo" binding:"max=500"`
+	// RemittanceInfo is a structured JSON object describing the payment.
+	RemittanceInfo json.RawMessage `json:"remittance_info"`
+	// OTP is the TOTP code confirming a transfer above the step-up amount.
+	OTP string `json:"otp"`
 }
 
 func (s *Server) makeTransfer(c *gin.Context) {
@@ -22,11 +48,102 @@ func (s *Server) makeTransfer(c *gin.Context) {
 		return
 	}
 
+	var err error
+	if req.RemittanceInfo, err = remittanceInfo(req.RemittanceInfo); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var ok bool
+	if req.FromAccountID, ok = s.resolveAccountID(c, req.FromAccountID, req.FromAccountNumber); !ok {
+		return
+	}
+
+	if req.PayeeID != 0 {
+		if req.ToAccountID != 0 || req.ToAccountNumber != "" {
+			c.JSON(http.StatusBadRequest, errorResponse(ErrAccountReference))
+
+			return
+		}
+
+		payee, ok := s.ownPayee(c, req.PayeeID)
+		if !ok {
+			return
+		}
+
+		req.ToAccountID = payee.AccountID
+		if req.Reference == "" {
+			req.Reference = payee.Reference.String
+		}
+	} else if req.ToAccountID, ok = s.resolveAccountID(c, req.ToAccountID, req.ToAccountNumber); !ok {
+		return
+	}
+
+	if !s.authorizeSpend(c, req.FromAccountID, req.Amount) {
+		return
+	}
+
+	if !s.stepUpTransfer(c, req.Amount, req.OTP) {
+		return
+	}
+
+	// transfers above the threshold of the approval policy wait for an approval
+	policy, err := s.store.GetApprovalPolicy(c, req.FromAccountID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if err == nil && req.Amount > policy.Threshold {
+		s.createPendingTransfer(c, req)
+
+		return
+	}
+
 	result, err := s.store.TransferTx(c, db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+		FromAccountID:  req.FromAccountID,
+		ToAccountID:    req.ToAccountID,
+		Amount:         req.Amount,
+		Instant:        req.Instant,
+		PayeeID:        sql.NullInt64{Int64: req.PayeeID, Valid: req.PayeeID != 0},
+		PayeeCap:       s.payeeCap(),
+		Reference:      req.Reference,
+		Memo:           req.Memo,
+		RemittanceInfo: req.RemittanceInfo,
 	})
+	if err != nil {
+		c.JSON(transferErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// resolveAccountID returns the ID of the account referenced either by the ID or
+// by the account number. The check digits of the number are validated before
+// the database is queried. It writes the error response and returns false if
+// the account can not be resolved.
+func (s *Server) resolveAccountID(c *gin.Context, id int64, number string) (int64, bool) {
+	if (id == 0) == (number == "") {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrAccountReference))
+
+		return 0, false
+	}
+
+	if id != 0 {
+		return id, true
+	}
+
+	if !util.ValidAccountNumber(number) {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidAccountNumber))
+
+		return 0, false
+	}
+
+	account, err := s.store.GetAccountByNumber(c, number)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			c.JSON(http.StatusNotFound, errorResponse(err))
@@ -34,8 +151,164 @@ func (s *Server) makeTransfer(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, errorResponse(err))
 		}
 
+		return 0, false
+	}
+
+	return account.ID, true
+}
+
+// remittanceInfo validates that the remittance information is a JSON object.
+// An omitted value is returned as nil.
+func remittanceInfo(info json.RawMessage) (json.RawMessage, error) {
+	info = bytes.TrimSpace(info)
+	if len(info) == 0 || bytes.Equal(info, []byte("null")) {
+		return nil, nil
+	}
+
+	var fields map[string]interface{}
+	if info[0] != '{' || json.Unmarshal(info, &fields) != nil {
+		return nil, ErrInvalidRemittanceInfo
+	}
+
+	return info, nil
+}
+
+// transferErrorStatus maps errors of the transfer transaction to HTTP status codes.
+func transferErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrNonPositiveAmount):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrPayeeCoolingOff):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrUnbalancedJournal),
+		errors.Is(err, db.ErrCurrencyMismatch),
+		errors.Is(err, db.ErrFeeOverflow),
+		errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrWithdrawalLimitExceeded),
+		errors.Is(err, db.ErrAccountFrozen):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// createPendingTransfer stores the transfer until it is approved by an eligible approver.
+func (s *Server) createPendingTransfer(c *gin.Context, req MakeTransferRequest) {
+	if req.RemittanceInfo == nil {
+		req.RemittanceInfo = json.RawMessage("{}")
+	}
+
+	pending, err := s.store.CreatePendingTransfer(c, db.CreatePendingTransferParams{
+		FromAccountID:  req.FromAccountID,
+		ToAccountID:    req.ToAccountID,
+		Amount:         req.Amount,
+		Initiator:      authPayload(c).Username,
+		ExpiresAt:      time.Now().Add(s.config.ApprovalExpiry),
+		Instant:        req.Instant,
+		PayeeID:        sql.NullInt64{Int64: req.PayeeID, Valid: req.PayeeID != 0},
+		Reference:      req.Reference,
+		Memo:           req.Memo,
+		RemittanceInfo: req.RemittanceInfo,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.JSON(http.StatusAccepted, pending)
+}
+
+// ListAccountTransfersRequestURI holds URI parameters for listAccountTransfers handler.
+type ListAccountTransfersRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// ListAccountTransfersRequestQuery holds query parameters for listAccountTransfers handler.
+type ListAccountTransfersRequestQuery struct {
+	PageNum  int32 `form:"page_num" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=1000"`
+	// Q filters the transfers by a full-text search over their
+	// references, memos and remittance information.
+	Q string `form:"q"`
+}
+
+func (s *Server) listAccountTransfers(c *gin.Context) {
+	var reqURI ListAccountTransfersRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqQuery ListAccountTransfersRequestQuery
+	if err := c.ShouldBindQuery(&reqQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.authorizeAccount(c, reqURI.ID, permView); !ok {
+		return
+	}
+
+	var transfers []db.Transfer
+	var err error
+	if reqQuery.Q != "" {
+		transfers, err = s.store.SearchTransfers(c, db.SearchTransfersParams{
+			AccountID:  reqURI.ID,
+			Query:      reqQuery.Q,
+			PageSize:   reqQuery.PageSize,
+			PageOffset: (reqQuery.PageNum - 1) * reqQuery.PageSize,
+		})
+	} else {
+		transfers, err = s.store.ListTransfers(c, db.ListTransfersParams{
+			FromAccountID: reqURI.ID,
+			ToAccountID:   reqURI.ID,
+			Limit:         reqQuery.PageSize,
+			Offset:        (reqQuery.PageNum - 1) * reqQuery.PageSize,
+		})
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// QuoteTransferRequest holds parameters for quoteTransfer handler.
+type QuoteTransferRequest struct {
+	FromAccountID int64 `form:"from_account_id" binding:"required,min=1"`
+	Amount        int64 `form:"amount" binding:"required,min=1"`
+	Instant       bool  `form:"instant"`
+}
+
+func (s *Server) quoteTransfer(c *gin.Context) {
+	var req QuoteTransferRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.authorizeAccount(c, req.FromAccountID, permView); !ok {
+		return
+	}
+
+	fee, err := s.store.QuoteTransferFee(c, db.TransferTxParams{
+		FromAccountID: req.FromAccountID,
+		Amount:        req.Amount,
+		Instant:       req.Instant,
+	})
+	if err != nil {
+		c.JSON(transferErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, fee)
 }
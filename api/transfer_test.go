@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/chutommy/simple-bank/api"
 	"github.com/chutommy/simple-bank/db/mocks"
@@ -30,16 +32,51 @@ func TestServer_MakeTransfer(t *testing.T) {
 		Balance:  util.RandomBalance(),
 		Currency: util.RandomCurrency(),
 	}
+	number2, err := util.NewAccountNumber()
+	require.NoError(t, err)
+	account2.Number = number2
+
+	// an established payee and one added recently
+	payee := db.Payee{
+		ID:        util.RandomInt(1, 1024),
+		Owner:     account1.Owner,
+		Nickname:  util.RandomOwner(),
+		AccountID: account2.ID,
+		Reference: sql.NullString{String: "rent", Valid: true},
+		CreatedAt: time.Now().Add(-2 * testConfig.PayeeCoolingOff),
+	}
+	newPayee := db.Payee{
+		ID:        util.RandomInt(1025, 2048),
+		Owner:     account1.Owner,
+		Nickname:  util.RandomOwner(),
+		AccountID: account2.ID,
+		CreatedAt: time.Now(),
+	}
+	payeeCap := db.PayeeCap{
+		CoolingOff: testConfig.PayeeCoolingOff,
+		Limit:      testConfig.PayeeCoolingOffLimit,
+	}
+
 	transfer := db.Transfer{
+		ID:             util.RandomInt(1, 2048),
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         util.RandomAmount(),
+		RemittanceInfo: json.RawMessage("{}"),
+	}
+	pending := db.PendingTransfer{
 		ID:            util.RandomInt(1, 2048),
-		FromAccountID: account1.ID,
-		ToAccountID:   account2.ID,
-		Amount:        util.RandomAmount(),
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Initiator:     account1.Owner,
+		Status:        db.PendingTransferStatusPending,
 	}
 
 	tests := []struct {
 		name          string
 		param         api.MakeTransferRequest
+		noAuth        bool
 		buildStub     func(store *mocks.Store)
 		checkResponse func(t *testing.T, resp *httptest.ResponseRecorder)
 	}{
@@ -51,16 +88,21 @@ func TestServer_MakeTransfer(t *testing.T) {
 				Amount:        transfer.Amount,
 			},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
 				store.On("TransferTx", mock.Anything, db.TransferTxParams{
 					FromAccountID: transfer.FromAccountID,
 					ToAccountID:   transfer.ToAccountID,
 					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
 				}).Return(db.TransferTxResult{
 					Transfer: db.Transfer{
-						ID:            transfer.ID,
-						FromAccountID: transfer.FromAccountID,
-						ToAccountID:   transfer.ToAccountID,
-						Amount:        transfer.Amount,
+						ID:             transfer.ID,
+						FromAccountID:  transfer.FromAccountID,
+						ToAccountID:    transfer.ToAccountID,
+						Amount:         transfer.Amount,
+						RemittanceInfo: transfer.RemittanceInfo,
 					},
 				}, nil)
 			},
@@ -69,6 +111,59 @@ func TestServer_MakeTransfer(t *testing.T) {
 				assert.Equal(t, transfer, bytesToTransfer(t, resp.Body.Bytes()))
 			},
 		},
+		{
+			name: "Description",
+			param: api.MakeTransferRequest{
+				FromAccountID:  transfer.FromAccountID,
+				ToAccountID:    transfer.ToAccountID,
+				Amount:         transfer.Amount,
+				Reference:      "INV-2021-042",
+				Memo:           "consulting in March",
+				RemittanceInfo: json.RawMessage(`{"invoice": "INV-2021-042", "vat": 21}`),
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID:  transfer.FromAccountID,
+					ToAccountID:    transfer.ToAccountID,
+					Amount:         transfer.Amount,
+					Reference:      "INV-2021-042",
+					Memo:           "consulting in March",
+					RemittanceInfo: json.RawMessage(`{"invoice":"INV-2021-042","vat":21}`),
+					PayeeCap:       payeeCap,
+				}).Return(db.TransferTxResult{Transfer: transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "InvalidRemittanceInfo",
+			param: api.MakeTransferRequest{
+				FromAccountID:  transfer.FromAccountID,
+				ToAccountID:    transfer.ToAccountID,
+				Amount:         transfer.Amount,
+				RemittanceInfo: json.RawMessage(`["INV-2021-042"]`),
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			name: "NegativeAmount",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        -transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
 		{
 			name: "InvalidID",
 			param: api.MakeTransferRequest{
@@ -81,6 +176,146 @@ func TestServer_MakeTransfer(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, resp.Code)
 			},
 		},
+		{
+			name: "ToAccountNumber",
+			param: api.MakeTransferRequest{
+				FromAccountID:   transfer.FromAccountID,
+				ToAccountNumber: account2.Number,
+				Amount:          transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccountByNumber", mock.Anything, account2.Number).Return(account2, nil)
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   account2.ID,
+					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
+				}).Return(db.TransferTxResult{Transfer: transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, transfer, bytesToTransfer(t, resp.Body.Bytes()))
+			},
+		},
+		{
+			name: "InvalidAccountNumber",
+			param: api.MakeTransferRequest{
+				FromAccountID:   transfer.FromAccountID,
+				ToAccountNumber: number2[:len(number2)-1] + string('0'+(number2[len(number2)-1]-'0'+1)%10),
+				Amount:          transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			name: "AccountNumberNotFound",
+			param: api.MakeTransferRequest{
+				FromAccountID:   transfer.FromAccountID,
+				ToAccountNumber: account2.Number,
+				Amount:          transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccountByNumber", mock.Anything, account2.Number).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, resp.Code)
+			},
+		},
+		{
+			name: "AmbiguousAccount",
+			param: api.MakeTransferRequest{
+				FromAccountID:   transfer.FromAccountID,
+				ToAccountID:     transfer.ToAccountID,
+				ToAccountNumber: account2.Number,
+				Amount:          transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			name: "Payee",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				PayeeID:       payee.ID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPayee", mock.Anything, payee.ID).Return(payee, nil)
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   payee.AccountID,
+					Amount:        transfer.Amount,
+					PayeeID:       sql.NullInt64{Int64: payee.ID, Valid: true},
+					PayeeCap:      payeeCap,
+					Reference:     payee.Reference.String,
+				}).Return(db.TransferTxResult{Transfer: transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "PayeeCoolingOffExceeded",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				PayeeID:       newPayee.ID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPayee", mock.Anything, newPayee.ID).Return(newPayee, nil)
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   newPayee.AccountID,
+					Amount:        transfer.Amount,
+					PayeeID:       sql.NullInt64{Int64: newPayee.ID, Valid: true},
+					PayeeCap:      payeeCap,
+				}).Return(db.TransferTxResult{}, fmt.Errorf("tx err: %w", db.ErrPayeeCoolingOff))
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "PayeeNotOwned",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				PayeeID:       payee.ID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPayee", mock.Anything, payee.ID).
+					Return(db.Payee{ID: payee.ID, Owner: util.RandomOwner(), AccountID: payee.AccountID}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "PayeeAndAccount",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				PayeeID:       payee.ID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
 		{
 			name: "AccountNotFound",
 			param: api.MakeTransferRequest{
@@ -89,16 +324,125 @@ func TestServer_MakeTransfer(t *testing.T) {
 				Amount:        transfer.Amount,
 			},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
 				store.On("TransferTx", mock.Anything, db.TransferTxParams{
 					FromAccountID: transfer.FromAccountID,
 					ToAccountID:   transfer.ToAccountID,
 					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
 				}).Return(db.TransferTxResult{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusNotFound, resp.Code)
 			},
 		},
+		{
+			name: "InstantWithFee",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+				Instant:       true,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   transfer.ToAccountID,
+					Amount:        transfer.Amount,
+					Instant:       true,
+					PayeeCap:      payeeCap,
+				}).Return(db.TransferTxResult{
+					Transfer: transfer,
+					Fee: db.Fee{
+						TransferKind: db.TransferKindInstant,
+						Method:       db.FeeMethodFlat,
+						Computed:     10,
+						Amount:       10,
+						Total:        transfer.Amount + 10,
+					},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+
+				var result db.TransferTxResult
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+				assert.Equal(t, transfer, result.Transfer)
+				assert.Equal(t, int64(10), result.Fee.Amount)
+				assert.Equal(t, transfer.Amount+10, result.Fee.Total)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   transfer.ToAccountID,
+					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
+				}).Return(db.TransferTxResult{}, fmt.Errorf("tx err: %w", db.ErrCurrencyMismatch))
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   transfer.ToAccountID,
+					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
+				}).Return(db.TransferTxResult{}, fmt.Errorf("tx err: %w", db.ErrInsufficientFunds))
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+			},
+		},
+		{
+			name: "WithdrawalLimitExceeded",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   transfer.ToAccountID,
+					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
+				}).Return(db.TransferTxResult{}, db.ErrWithdrawalLimitExceeded)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+			},
+		},
 		{
 			name: "InternalError",
 			param: api.MakeTransferRequest{
@@ -107,23 +451,185 @@ func TestServer_MakeTransfer(t *testing.T) {
 				Amount:        transfer.Amount,
 			},
 			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
 				store.On("TransferTx", mock.Anything, db.TransferTxParams{
 					FromAccountID: transfer.FromAccountID,
 					ToAccountID:   transfer.ToAccountID,
 					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
 				}).Return(db.TransferTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, resp.Code)
 			},
 		},
+		{
+			name: "BelowThreshold",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{AccountID: transfer.FromAccountID, Threshold: transfer.Amount}, nil)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   transfer.ToAccountID,
+					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
+				}).Return(db.TransferTxResult{Transfer: transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, transfer, bytesToTransfer(t, resp.Body.Bytes()))
+			},
+		},
+		{
+			name: "PendingApproval",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{AccountID: transfer.FromAccountID, Threshold: transfer.Amount - 1}, nil)
+				store.On("CreatePendingTransfer", mock.Anything, mock.MatchedBy(func(arg db.CreatePendingTransferParams) bool {
+					return arg.FromAccountID == transfer.FromAccountID &&
+						arg.ToAccountID == transfer.ToAccountID &&
+						arg.Amount == transfer.Amount &&
+						arg.Initiator == account1.Owner &&
+						arg.ExpiresAt.After(time.Now())
+				})).Return(pending, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, resp.Code)
+				assert.Equal(t, pending.ID, bytesToPendingTransfer(t, resp.Body.Bytes()).ID)
+			},
+		},
+		{
+			name: "PolicyInternalError",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).Return(account1, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "SignatoryWithinLimit",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).
+					Return(db.Account{ID: account1.ID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account1.ID,
+					Username:  account1.Owner,
+				}).Return(db.AccountHolder{
+					AccountID:  account1.ID,
+					Username:   account1.Owner,
+					Role:       db.HolderRoleSignatory,
+					SpendLimit: sql.NullInt64{Int64: transfer.Amount, Valid: true},
+					Status:     db.HolderStatusActive,
+				}, nil)
+				store.On("GetApprovalPolicy", mock.Anything, transfer.FromAccountID).
+					Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, db.TransferTxParams{
+					FromAccountID: transfer.FromAccountID,
+					ToAccountID:   transfer.ToAccountID,
+					Amount:        transfer.Amount,
+					PayeeCap:      payeeCap,
+				}).Return(db.TransferTxResult{Transfer: transfer}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "SpendLimitExceeded",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).
+					Return(db.Account{ID: account1.ID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account1.ID,
+					Username:  account1.Owner,
+				}).Return(db.AccountHolder{
+					AccountID:  account1.ID,
+					Username:   account1.Owner,
+					Role:       db.HolderRoleSignatory,
+					SpendLimit: sql.NullInt64{Int64: transfer.Amount - 1, Valid: true},
+					Status:     db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "Viewer",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account1.ID).
+					Return(db.Account{ID: account1.ID, Owner: util.RandomOwner()}, nil)
+				store.On("GetAccountHolder", mock.Anything, db.GetAccountHolderParams{
+					AccountID: account1.ID,
+					Username:  account1.Owner,
+				}).Return(db.AccountHolder{
+					AccountID: account1.ID,
+					Username:  account1.Owner,
+					Role:      db.HolderRoleViewer,
+					Status:    db.HolderStatusActive,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "Unauthorized",
+			param: api.MakeTransferRequest{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+			},
+			noAuth:    true,
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			// construct a server with a mock db.Store
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
@@ -131,6 +637,261 @@ func TestServer_MakeTransfer(t *testing.T) {
 			b, err := json.Marshal(test.param)
 			require.NoError(t, err)
 			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+			if !test.noAuth {
+				addAuthorization(t, req, account1.Owner)
+			}
+			resp := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(resp, req)
+
+			// check result
+			test.checkResponse(t, resp)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ListAccountTransfers(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 1024),
+		Owner: util.RandomOwner(),
+	}
+	transfers := []db.Transfer{
+		{
+			ID:             util.RandomInt(1, 1024),
+			FromAccountID:  account.ID,
+			ToAccountID:    util.RandomInt(1025, 2048),
+			Amount:         util.RandomAmount(),
+			Reference:      "rent",
+			RemittanceInfo: json.RawMessage("{}"),
+		},
+		{
+			ID:             util.RandomInt(1025, 2048),
+			FromAccountID:  util.RandomInt(1025, 2048),
+			ToAccountID:    account.ID,
+			Amount:         util.RandomAmount(),
+			RemittanceInfo: json.RawMessage("{}"),
+		},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		username      string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			query:    "page_num=1&page_size=10",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("ListTransfers", mock.Anything, db.ListTransfersParams{
+					FromAccountID: account.ID,
+					ToAccountID:   account.ID,
+					Limit:         10,
+					Offset:        0,
+				}).Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, transfers, bytesToTransfers(t, resp.Body.Bytes()))
+			},
+		},
+		{
+			name:     "Search",
+			query:    "page_num=2&page_size=5&q=rent",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("SearchTransfers", mock.Anything, db.SearchTransfersParams{
+					AccountID:  account.ID,
+					Query:      "rent",
+					PageSize:   5,
+					PageOffset: 5,
+				}).Return(transfers[:1], nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+				assert.Equal(t, transfers[:1], bytesToTransfers(t, resp.Body.Bytes()))
+			},
+		},
+		{
+			name:      "InvalidPageSize",
+			query:     "page_num=1&page_size=0",
+			username:  account.Owner,
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			name:     "NotOwned",
+			query:    "page_num=1&page_size=10",
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetAccountHolder", mock.Anything, mock.Anything).
+					Return(db.AccountHolder{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			query:    "page_num=1&page_size=10",
+			username: account.Owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("ListTransfers", mock.Anything, mock.Anything).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct a server with a mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/accounts/%d/transfers?%s", account.ID, test.query)
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, test.username)
+			resp := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(resp, req)
+
+			// check result
+			test.checkResponse(t, resp)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_QuoteTransfer(t *testing.T) {
+	account := db.Account{
+		ID:    util.RandomInt(1, 1024),
+		Owner: util.RandomOwner(),
+	}
+	accountID := account.ID
+	amount := util.RandomAmount()
+	fee := db.Fee{
+		TransferKind: db.TransferKindStandard,
+		ScheduleID:   util.RandomInt(1, 1024),
+		Method:       db.FeeMethodPercentage,
+		FeeAccountID: util.RandomInt(1025, 2048),
+		Computed:     5,
+		Amount:       5,
+		Total:        amount + 5,
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: fmt.Sprintf("from_account_id=%d&amount=%d", accountID, amount),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, accountID).Return(account, nil)
+				store.On("QuoteTransferFee", mock.Anything, db.TransferTxParams{
+					FromAccountID: accountID,
+					Amount:        amount,
+				}).Return(fee, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+
+				var got db.Fee
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &got))
+				assert.Equal(t, fee, got)
+			},
+		},
+		{
+			name:  "Instant",
+			query: fmt.Sprintf("from_account_id=%d&amount=%d&instant=true", accountID, amount),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, accountID).Return(account, nil)
+				store.On("QuoteTransferFee", mock.Anything, db.TransferTxParams{
+					FromAccountID: accountID,
+					Amount:        amount,
+					Instant:       true,
+				}).Return(db.Fee{TransferKind: db.TransferKindInstant, Total: amount}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name:      "InvalidAmount",
+			query:     fmt.Sprintf("from_account_id=%d&amount=0", accountID),
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			name:  "AccountNotFound",
+			query: fmt.Sprintf("from_account_id=%d&amount=%d", accountID, amount),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, accountID).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, resp.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: fmt.Sprintf("from_account_id=%d&amount=%d", accountID, amount),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, accountID).Return(account, nil)
+				store.On("QuoteTransferFee", mock.Anything, db.TransferTxParams{
+					FromAccountID: accountID,
+					Amount:        amount,
+				}).Return(db.Fee{}, db.ErrUnknownFeeMethod)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name:  "FeeOverflow",
+			query: fmt.Sprintf("from_account_id=%d&amount=%d", accountID, amount),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, accountID).Return(account, nil)
+				store.On("QuoteTransferFee", mock.Anything, db.TransferTxParams{
+					FromAccountID: accountID,
+					Amount:        amount,
+				}).Return(db.Fee{}, fmt.Errorf("quote err: %w", db.ErrFeeOverflow))
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct a server with a mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := "/transfers/quote?" + test.query
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			addAuthorization(t, req, account.Owner)
 			resp := httptest.NewRecorder()
 
 			// serve
@@ -152,3 +913,22 @@ func bytesToTransfer(t *testing.T, b []byte) db.Transfer {
 
 	return transfer.Transfer
 }
+
+func bytesToTransfers(t *testing.T, b []byte) []db.Transfer {
+	t.Helper()
+
+	var transfers []db.Transfer
+	require.NoError(t, json.Unmarshal(b, &transfers))
+
+	return transfers
+}
+
+func bytesToPendingTransfer(t *testing.T, b []byte) db.PendingTransfer {
+	t.Helper()
+
+	var pending db.PendingTransfer
+	err := json.Unmarshal(b, &pending)
+	require.NoError(t, err)
+
+	return pending
+}
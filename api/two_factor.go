@@ -0,0 +1,468 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/chutommy/simple-bank/twofactor"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// ErrTwoFactorEnabled is returned when the user enrolls a TOTP credential while one is already enabled.
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorNotEnrolled is returned when the user has no TOTP credential in the required state.
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication is not enrolled")
+	// ErrInvalidTOTPCode is returned when the TOTP code does not match the secret of the user.
+	ErrInvalidTOTPCode = errors.New("invalid two-factor code")
+	// ErrInvalidRecoveryCode is returned when the recovery code does not exist or has been used.
+	ErrInvalidRecoveryCode = errors.New("invalid recovery code")
+	// ErrInvalidLoginChallenge is returned when the login challenge does not exist, has been used or has expired.
+	ErrInvalidLoginChallenge = errors.New("invalid login challenge")
+	// ErrTwoFactorCodeReference is returned when the second login step contains both or neither of the codes.
+	ErrTwoFactorCodeReference = errors.New("exactly one of the code and the recovery code is required")
+	// ErrTwoFactorRequired is returned when an operation requires two-factor authentication
+	// which the user has not enabled.
+	ErrTwoFactorRequired = errors.New("two-factor authentication must be enabled")
+	// ErrStepUpRequired is returned when an operation must be confirmed by a TOTP code.
+	ErrStepUpRequired = errors.New("two-factor code is required")
+)
+
+// LoginChallengeResponse is returned by loginUser handler to the users with two-factor
+// authentication. The challenge token is exchanged for the tokens of a new session
+// by loginTwoFactor handler.
+type LoginChallengeResponse struct {
+	TwoFactorRequired  bool      `json:"two_factor_required"`
+	ChallengeToken     string    `json:"challenge_token"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at"`
+}
+
+// startLoginChallenge issues a single-use login challenge of the user and responds with it.
+func (s *Server) startLoginChallenge(c *gin.Context, user db.User) {
+	challengeToken, challengeTokenHash, err := token.NewOpaqueToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	challenge, err := s.store.CreateUserToken(c, db.CreateUserTokenParams{
+		Username:  user.Username,
+		Purpose:   db.UserTokenLoginChallenge,
+		TokenHash: challengeTokenHash,
+		ExpiresAt: time.Now().Add(s.config.TwoFactorChallengeExpiry),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginChallengeResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     challengeToken,
+		ChallengeExpiresAt: challenge.ExpiresAt,
+	})
+}
+
+// checkTOTPCode validates the code against the secret of the credential and returns
+// the time step of the code. It writes the error response and returns false if
+// the code is invalid.
+func checkTOTPCode(c *gin.Context, credential db.TotpCredential, code string) (int64, bool) {
+	step, ok := twofactor.Validate(credential.Secret, code, time.Now())
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidTOTPCode))
+
+		return 0, false
+	}
+
+	return step, true
+}
+
+// useTOTPCode validates the code and records its time step, so the code can not
+// be replayed. It writes the error response and returns false if the code
+// is invalid or has been used.
+func (s *Server) useTOTPCode(c *gin.Context, credential db.TotpCredential, code string) bool {
+	step, ok := checkTOTPCode(c, credential, code)
+	if !ok {
+		return false
+	}
+
+	n, err := s.store.UseTOTPStep(c, db.UseTOTPStepParams{
+		Username:     credential.Username,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return false
+	}
+
+	if n == 0 {
+		c.JSON(http.StatusUnauthorized, errorResponse(db.ErrTOTPCodeReused))
+
+		return false
+	}
+
+	return true
+}
+
+// enabledTOTPCredential returns the confirmed TOTP credential of the user. It writes
+// the error response and returns false if the user has not enabled two-factor authentication.
+func (s *Server) enabledTOTPCredential(c *gin.Context, username string) (db.TotpCredential, bool) {
+	credential, err := s.store.GetTOTPCredential(c, username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return db.TotpCredential{}, false
+	}
+
+	if err != nil || !credential.ConfirmedAt.Valid {
+		c.JSON(http.StatusNotFound, errorResponse(ErrTwoFactorNotEnrolled))
+
+		return db.TotpCredential{}, false
+	}
+
+	return credential, true
+}
+
+// useRecoveryCode marks the recovery code of the user as used. It writes the error
+// response and returns false if the code does not exist or has been used.
+func (s *Server) useRecoveryCode(c *gin.Context, username, code string) bool {
+	n, err := s.store.UseRecoveryCode(c, db.UseRecoveryCodeParams{
+		Username: username,
+		CodeHash: twofactor.HashRecoveryCode(code),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return false
+	}
+
+	if n == 0 {
+		c.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidRecoveryCode))
+
+		return false
+	}
+
+	return true
+}
+
+// stepUpTransfer requires the TOTP code of the authenticated user if the amount sent
+// is above the step-up amount. It writes the error response and returns false
+// if the transfer is not confirmed.
+func (s *Server) stepUpTransfer(c *gin.Context, amount int64, code string) bool {
+	if s.config.StepUpTransferAmount <= 0 || amount <= s.config.StepUpTransferAmount {
+		return true
+	}
+
+	return s.stepUp(c, code)
+}
+
+// stepUp checks that the authenticated user confirmed the operation by a TOTP code.
+// It writes the error response and returns false if not.
+func (s *Server) stepUp(c *gin.Context, code string) bool {
+	credential, err := s.store.GetTOTPCredential(c, authPayload(c).Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return false
+	}
+
+	if err != nil || !credential.ConfirmedAt.Valid {
+		c.JSON(http.StatusForbidden, errorResponse(ErrTwoFactorRequired))
+
+		return false
+	}
+
+	if code == "" {
+		c.JSON(http.StatusForbidden, errorResponse(ErrStepUpRequired))
+
+		return false
+	}
+
+	return s.useTOTPCode(c, credential, code)
+}
+
+// LoginTwoFactorRequest holds parameters for loginTwoFactor handler.
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// loginTwoFactor completes the login of a user with two-factor authentication
+// by a TOTP code or by a recovery code. The challenge is used up by the first
+// attempt, a failed attempt requires logging in with the password again.
+func (s *Server) loginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if (req.Code == "") == (req.RecoveryCode == "") {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrTwoFactorCodeReference))
+
+		return
+	}
+
+	challenge, err := s.store.UseUserTokenTx(c, token.HashOpaqueToken(req.ChallengeToken), db.UserTokenLoginChallenge)
+	if err != nil {
+		if userTokenErrorStatus(err) == http.StatusInternalServerError {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		} else {
+			c.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidLoginChallenge))
+		}
+
+		return
+	}
+
+	credential, ok := s.enabledTOTPCredential(c, challenge.Username)
+	if !ok {
+		return
+	}
+
+	if req.Code != "" {
+		ok = s.useTOTPCode(c, credential, req.Code)
+	} else {
+		ok = s.useRecoveryCode(c, challenge.Username, req.RecoveryCode)
+	}
+
+	if !ok {
+		return
+	}
+
+	user, err := s.store.GetUser(c, challenge.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	s.startSession(c, user)
+}
+
+// TwoFactorStatusResponse holds the two-factor authentication status of a user.
+type TwoFactorStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// getTwoFactorStatus returns whether the authenticated user has enabled two-factor
+// authentication and how many unused recovery codes are left.
+func (s *Server) getTwoFactorStatus(c *gin.Context) {
+	username := authPayload(c).Username
+
+	credential, err := s.store.GetTOTPCredential(c, username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp := TwoFactorStatusResponse{Enabled: err == nil && credential.ConfirmedAt.Valid}
+
+	if resp.Enabled {
+		if resp.RecoveryCodesLeft, err = s.store.CountUnusedRecoveryCodes(c, username); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// TOTPEnrollmentResponse holds a new TOTP secret of the user. The QR code is a PNG
+// image encoding the provisioning URI.
+type TOTPEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	QRCode          []byte `json:"qr_code"`
+}
+
+// enrollTOTP generates a new TOTP secret of the authenticated user. Two-factor
+// authentication is enabled once the first code of the secret is confirmed
+// by confirmTOTP handler, until then the enrollment can be started over.
+func (s *Server) enrollTOTP(c *gin.Context) {
+	username := authPayload(c).Username
+
+	enrollment, err := twofactor.NewEnrollment(s.config.TwoFactorIssuer, username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if _, err := s.store.UpsertTOTPCredential(c, db.UpsertTOTPCredentialParams{
+		Username: username,
+		Secret:   enrollment.Secret,
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusConflict, errorResponse(ErrTwoFactorEnabled))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, TOTPEnrollmentResponse{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.URI,
+		QRCode:          enrollment.QRCode,
+	})
+}
+
+// TOTPCodeRequest holds parameters for confirmTOTP, regenerateRecoveryCodes and disableTOTP handlers.
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse holds the recovery codes of a user. The codes are stored
+// hashed, so they are shown only once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP enables two-factor authentication of the authenticated user with
+// the first code of the enrolled secret and returns new recovery codes.
+func (s *Server) confirmTOTP(c *gin.Context) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	credential, err := s.store.GetTOTPCredential(c, authPayload(c).Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(ErrTwoFactorNotEnrolled))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	if credential.ConfirmedAt.Valid {
+		c.JSON(http.StatusConflict, errorResponse(ErrTwoFactorEnabled))
+
+		return
+	}
+
+	step, ok := checkTOTPCode(c, credential, req.Code)
+	if !ok {
+		return
+	}
+
+	codes, hashes, err := twofactor.NewRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if _, err := s.store.EnableTOTPTx(c, db.TOTPTxParams{
+		Username:           credential.Username,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	}); err != nil {
+		c.JSON(twoFactorErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// regenerateRecoveryCodes replaces the recovery codes of the authenticated user.
+// The change must be confirmed by a TOTP code.
+func (s *Server) regenerateRecoveryCodes(c *gin.Context) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	credential, ok := s.enabledTOTPCredential(c, authPayload(c).Username)
+	if !ok {
+		return
+	}
+
+	step, ok := checkTOTPCode(c, credential, req.Code)
+	if !ok {
+		return
+	}
+
+	codes, hashes, err := twofactor.NewRecoveryCodes()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if _, err := s.store.RegenerateRecoveryCodesTx(c, db.TOTPTxParams{
+		Username:           credential.Username,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	}); err != nil {
+		c.JSON(twoFactorErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// disableTOTP disables two-factor authentication of the authenticated user.
+// The change must be confirmed by a TOTP code.
+func (s *Server) disableTOTP(c *gin.Context) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	credential, ok := s.enabledTOTPCredential(c, authPayload(c).Username)
+	if !ok {
+		return
+	}
+
+	step, ok := checkTOTPCode(c, credential, req.Code)
+	if !ok {
+		return
+	}
+
+	if err := s.store.DisableTOTPTx(c, db.TOTPTxParams{
+		Username: credential.Username,
+		Step:     step,
+	}); err != nil {
+		c.JSON(twoFactorErrorStatus(err), errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// twoFactorErrorStatus maps errors of the two-factor authentication transactions to HTTP status codes.
+func twoFactorErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrTOTPCodeReused):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
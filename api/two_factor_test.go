@@ -0,0 +1,795 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/chutommy/simple-bank/token"
+	"github.com/chutommy/simple-bank/twofactor"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// randomTOTPCredential returns a credential with a new secret of the user.
+func randomTOTPCredential(t *testing.T, username string, confirmed bool) db.TotpCredential {
+	t.Helper()
+
+	enrollment, err := twofactor.NewEnrollment(testConfig.TwoFactorIssuer, username)
+	require.NoError(t, err)
+
+	return db.TotpCredential{
+		Username:    username,
+		Secret:      enrollment.Secret,
+		ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: confirmed},
+	}
+}
+
+// currentTOTPCode returns the current code of the credential.
+func currentTOTPCode(t *testing.T, credential db.TotpCredential) string {
+	t.Helper()
+
+	code, err := totp.GenerateCode(credential.Secret, time.Now())
+	require.NoError(t, err)
+
+	return code
+}
+
+func TestServer_LoginTwoFactor(t *testing.T) {
+	user := db.User{
+		Username: util.RandomOwner(),
+		Role:     db.UserRoleCustomer,
+	}
+	credential := randomTOTPCredential(t, user.Username, true)
+	session := db.Session{
+		ID:        util.RandomInt(1, 1024),
+		FamilyID:  util.RandomString(32),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	challenge := db.UserToken{
+		ID:        util.RandomInt(1, 1024),
+		Username:  user.Username,
+		Purpose:   db.UserTokenLoginChallenge,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	challengeToken, challengeTokenHash, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+
+	code := currentTOTPCode(t, credential)
+	recoveryCodes, _, err := twofactor.NewRecoveryCodes()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		params        api.LoginTwoFactorRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			params: api.LoginTwoFactorRequest{ChallengeToken: challengeToken, Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("UseUserTokenTx", mock.Anything, challengeTokenHash, db.UserTokenLoginChallenge).
+					Return(challenge, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(credential, nil)
+				store.On("UseTOTPStep", mock.Anything, mock.MatchedBy(func(arg db.UseTOTPStepParams) bool {
+					return arg.Username == user.Username && arg.LastUsedStep > 0
+				})).Return(int64(1), nil)
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("CreateSession", mock.Anything, mock.Anything).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.LoginUserResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, session.ID, body.SessionID)
+				assert.NotEmpty(t, body.AccessToken)
+				assert.NotEmpty(t, body.RefreshToken)
+			},
+		},
+		{
+			name:   "RecoveryCode",
+			params: api.LoginTwoFactorRequest{ChallengeToken: challengeToken, RecoveryCode: recoveryCodes[0]},
+			buildStub: func(store *mocks.Store) {
+				store.On("UseUserTokenTx", mock.Anything, challengeTokenHash, db.UserTokenLoginChallenge).
+					Return(challenge, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(credential, nil)
+				store.On("UseRecoveryCode", mock.Anything, db.UseRecoveryCodeParams{
+					Username: user.Username,
+					CodeHash: twofactor.HashRecoveryCode(recoveryCodes[0]),
+				}).Return(int64(1), nil)
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("CreateSession", mock.Anything, mock.Anything).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "UsedRecoveryCode",
+			params: api.LoginTwoFactorRequest{ChallengeToken: challengeToken, RecoveryCode: recoveryCodes[0]},
+			buildStub: func(store *mocks.Store) {
+				store.On("UseUserTokenTx", mock.Anything, challengeTokenHash, db.UserTokenLoginChallenge).
+					Return(challenge, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(credential, nil)
+				store.On("UseRecoveryCode", mock.Anything, mock.Anything).Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidCode",
+			params: api.LoginTwoFactorRequest{ChallengeToken: challengeToken, Code: "abcdef"},
+			buildStub: func(store *mocks.Store) {
+				store.On("UseUserTokenTx", mock.Anything, challengeTokenHash, db.UserTokenLoginChallenge).
+					Return(challenge, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "ReusedCode",
+			params: api.LoginTwoFactorRequest{ChallengeToken: challengeToken, Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("UseUserTokenTx", mock.Anything, challengeTokenHash, db.UserTokenLoginChallenge).
+					Return(challenge, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(credential, nil)
+				store.On("UseTOTPStep", mock.Anything, mock.Anything).Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "UnknownChallenge",
+			params: api.LoginTwoFactorRequest{ChallengeToken: challengeToken, Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("UseUserTokenTx", mock.Anything, challengeTokenHash, db.UserTokenLoginChallenge).
+					Return(db.UserToken{}, fmt.Errorf("can not use token: %w", sql.ErrNoRows))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "ExpiredChallenge",
+			params: api.LoginTwoFactorRequest{ChallengeToken: challengeToken, Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("UseUserTokenTx", mock.Anything, challengeTokenHash, db.UserTokenLoginChallenge).
+					Return(db.UserToken{}, fmt.Errorf("can not use token: %w", db.ErrUserTokenExpired))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "BothCodes",
+			params: api.LoginTwoFactorRequest{
+				ChallengeToken: challengeToken,
+				Code:           code,
+				RecoveryCode:   recoveryCodes[0],
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/login/2fa", bytes.NewReader(b))
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_GetTwoFactorStatus(t *testing.T) {
+	username := util.RandomOwner()
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	mockStore.On("GetTOTPCredential", mock.Anything, username).
+		Return(randomTOTPCredential(t, username, true), nil)
+	mockStore.On("CountUnusedRecoveryCodes", mock.Anything, username).Return(int64(7), nil)
+
+	// prepare request and response recorder
+	req := httptest.NewRequest(http.MethodGet, "/users/2fa", nil)
+	addAuthorization(t, req, username)
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	// check response
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var body api.TwoFactorStatusResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Equal(t, api.TwoFactorStatusResponse{Enabled: true, RecoveryCodesLeft: 7}, body)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_EnrollTOTP(t *testing.T) {
+	username := util.RandomOwner()
+
+	tests := []struct {
+		name          string
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStub: func(store *mocks.Store) {
+				store.On("UpsertTOTPCredential", mock.Anything, mock.MatchedBy(func(arg db.UpsertTOTPCredentialParams) bool {
+					return arg.Username == username && arg.Secret != ""
+				})).Return(db.TotpCredential{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.TOTPEnrollmentResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.NotEmpty(t, body.Secret)
+				assert.Contains(t, body.ProvisioningURI, "secret="+body.Secret)
+				assert.NotEmpty(t, body.QRCode)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStub: func(store *mocks.Store) {
+				store.On("UpsertTOTPCredential", mock.Anything, mock.Anything).
+					Return(db.TotpCredential{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			req := httptest.NewRequest(http.MethodPost, "/users/2fa/totp", nil)
+			addAuthorization(t, req, username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ConfirmTOTP(t *testing.T) {
+	username := util.RandomOwner()
+	credential := randomTOTPCredential(t, username, false)
+	code := currentTOTPCode(t, credential)
+
+	tests := []struct {
+		name          string
+		params        api.TOTPCodeRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			params: api.TOTPCodeRequest{Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetTOTPCredential", mock.Anything, username).Return(credential, nil)
+				store.On("EnableTOTPTx", mock.Anything, mock.MatchedBy(func(arg db.TOTPTxParams) bool {
+					return arg.Username == username && arg.Step > 0 &&
+						len(arg.RecoveryCodeHashes) == twofactor.RecoveryCodeCount
+				})).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body api.RecoveryCodesResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Len(t, body.RecoveryCodes, twofactor.RecoveryCodeCount)
+			},
+		},
+		{
+			name:   "InvalidCode",
+			params: api.TOTPCodeRequest{Code: "000000x"},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetTOTPCredential", mock.Anything, username).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "NotEnrolled",
+			params: api.TOTPCodeRequest{Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetTOTPCredential", mock.Anything, username).Return(db.TotpCredential{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyEnabled",
+			params: api.TOTPCodeRequest{Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetTOTPCredential", mock.Anything, username).
+					Return(randomTOTPCredential(t, username, true), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "ReusedCode",
+			params: api.TOTPCodeRequest{Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetTOTPCredential", mock.Anything, username).Return(credential, nil)
+				store.On("EnableTOTPTx", mock.Anything, mock.Anything).
+					Return(db.TotpCredential{}, fmt.Errorf("can not enable TOTP: %w", db.ErrTOTPCodeReused))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidRequest",
+			params:    api.TOTPCodeRequest{},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/2fa/totp/confirm", bytes.NewReader(b))
+			addAuthorization(t, req, username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_RegenerateRecoveryCodes(t *testing.T) {
+	username := util.RandomOwner()
+	credential := randomTOTPCredential(t, username, true)
+
+	// construct server with mock db.Store
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	mockStore.On("GetTOTPCredential", mock.Anything, username).Return(credential, nil)
+	mockStore.On("RegenerateRecoveryCodesTx", mock.Anything, mock.MatchedBy(func(arg db.TOTPTxParams) bool {
+		return arg.Username == username && len(arg.RecoveryCodeHashes) == twofactor.RecoveryCodeCount
+	})).Return([]db.RecoveryCode{}, nil)
+
+	// prepare request and response recorder
+	b, err := json.Marshal(api.TOTPCodeRequest{Code: currentTOTPCode(t, credential)})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/users/2fa/recovery-codes", bytes.NewReader(b))
+	addAuthorization(t, req, username)
+	recorder := httptest.NewRecorder()
+
+	// serve
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	// check response
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var body api.RecoveryCodesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Len(t, body.RecoveryCodes, twofactor.RecoveryCodeCount)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_DisableTOTP(t *testing.T) {
+	username := util.RandomOwner()
+	credential := randomTOTPCredential(t, username, true)
+	code := currentTOTPCode(t, credential)
+
+	tests := []struct {
+		name          string
+		params        api.TOTPCodeRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			params: api.TOTPCodeRequest{Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetTOTPCredential", mock.Anything, username).Return(credential, nil)
+				store.On("DisableTOTPTx", mock.Anything, mock.MatchedBy(func(arg db.TOTPTxParams) bool {
+					return arg.Username == username && arg.Step > 0
+				})).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotEnabled",
+			params: api.TOTPCodeRequest{Code: code},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetTOTPCredential", mock.Anything, username).
+					Return(randomTOTPCredential(t, username, false), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidCode",
+			params: api.TOTPCodeRequest{Code: "12345"},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetTOTPCredential", mock.Anything, username).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodDelete, "/users/2fa/totp", bytes.NewReader(b))
+			addAuthorization(t, req, username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_MakeTransfer_StepUp(t *testing.T) {
+	account := db.Account{
+		ID:       util.RandomInt(1, 1024),
+		Owner:    util.RandomOwner(),
+		Balance:  1000000,
+		Currency: util.RandomCurrency(),
+	}
+	toAccountID := util.RandomInt(1025, 2048)
+	credential := randomTOTPCredential(t, account.Owner, true)
+
+	cfg := *testConfig
+	cfg.StepUpTransferAmount = 1000
+
+	tests := []struct {
+		name          string
+		param         api.MakeTransferRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			param: api.MakeTransferRequest{
+				FromAccountID: account.ID,
+				ToAccountID:   toAccountID,
+				Amount:        5000,
+				OTP:           currentTOTPCode(t, credential),
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetTOTPCredential", mock.Anything, account.Owner).Return(credential, nil)
+				store.On("UseTOTPStep", mock.Anything, mock.Anything).Return(int64(1), nil)
+				store.On("GetApprovalPolicy", mock.Anything, account.ID).Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, mock.Anything).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "BelowAmount",
+			param: api.MakeTransferRequest{
+				FromAccountID: account.ID,
+				ToAccountID:   toAccountID,
+				Amount:        1000,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetApprovalPolicy", mock.Anything, account.ID).Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("TransferTx", mock.Anything, mock.Anything).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingCode",
+			param: api.MakeTransferRequest{
+				FromAccountID: account.ID,
+				ToAccountID:   toAccountID,
+				Amount:        5000,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetTOTPCredential", mock.Anything, account.Owner).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			param: api.MakeTransferRequest{
+				FromAccountID: account.ID,
+				ToAccountID:   toAccountID,
+				Amount:        5000,
+				OTP:           "abcdef",
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetTOTPCredential", mock.Anything, account.Owner).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "TwoFactorDisabled",
+			param: api.MakeTransferRequest{
+				FromAccountID: account.ID,
+				ToAccountID:   toAccountID,
+				Amount:        5000,
+				OTP:           currentTOTPCode(t, credential),
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetTOTPCredential", mock.Anything, account.Owner).
+					Return(db.TotpCredential{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServerWithMailer(t, mockStore, mail.NewMemoryMailer(), &cfg)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.param)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(b))
+			addAuthorization(t, req, account.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_MakeBatchTransfer_StepUp(t *testing.T) {
+	account := db.Account{
+		ID:       util.RandomInt(1, 1024),
+		Owner:    util.RandomOwner(),
+		Balance:  1000000,
+		Currency: util.RandomCurrency(),
+	}
+	credential := randomTOTPCredential(t, account.Owner, true)
+
+	cfg := *testConfig
+	cfg.StepUpTransferAmount = 1000
+
+	// the legs are below the step-up amount, their total is not
+	legs := []api.BatchTransferLeg{
+		{FromAccountID: account.ID, ToAccountID: util.RandomInt(1025, 2048), Amount: 600},
+		{FromAccountID: account.ID, ToAccountID: util.RandomInt(1025, 2048), Amount: 600},
+	}
+
+	tests := []struct {
+		name          string
+		param         api.MakeBatchTransferRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			param: api.MakeBatchTransferRequest{Mode: db.BatchModeAtomic, Legs: legs, OTP: currentTOTPCode(t, credential)},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetTOTPCredential", mock.Anything, account.Owner).Return(credential, nil)
+				store.On("UseTOTPStep", mock.Anything, mock.Anything).Return(int64(1), nil)
+				store.On("GetApprovalPolicy", mock.Anything, account.ID).Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("BatchTransferTx", mock.Anything, mock.Anything).Return(db.BatchTransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingCode",
+			param: api.MakeBatchTransferRequest{Mode: db.BatchModeAtomic, Legs: legs},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetTOTPCredential", mock.Anything, account.Owner).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServerWithMailer(t, mockStore, mail.NewMemoryMailer(), &cfg)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.param)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(b))
+			addAuthorization(t, req, account.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_PayPaymentRequest_StepUp(t *testing.T) {
+	account := db.Account{
+		ID:       util.RandomInt(1, 1024),
+		Owner:    util.RandomOwner(),
+		Balance:  1000000,
+		Currency: util.RandomCurrency(),
+	}
+	credential := randomTOTPCredential(t, account.Owner, true)
+	request := db.PaymentRequest{
+		ID:          util.RandomInt(1, 1024),
+		Requester:   util.RandomOwner(),
+		Payer:       account.Owner,
+		ToAccountID: util.RandomInt(1025, 2048),
+		Amount:      5000,
+		Status:      db.PaymentRequestStatusPending,
+	}
+
+	cfg := *testConfig
+	cfg.StepUpTransferAmount = 1000
+
+	tests := []struct {
+		name          string
+		param         api.PayPaymentRequestRequestJSON
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			param: api.PayPaymentRequestRequestJSON{FromAccountID: account.ID, OTP: currentTOTPCode(t, credential)},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPaymentRequest", mock.Anything, request.ID).Return(request, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetTOTPCredential", mock.Anything, account.Owner).Return(credential, nil)
+				store.On("UseTOTPStep", mock.Anything, mock.Anything).Return(int64(1), nil)
+				store.On("GetApprovalPolicy", mock.Anything, account.ID).Return(db.ApprovalPolicy{}, sql.ErrNoRows)
+				store.On("PayPaymentRequestTx", mock.Anything, mock.Anything).Return(db.PayPaymentRequestTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "MissingCode",
+			param: api.PayPaymentRequestRequestJSON{FromAccountID: account.ID},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetPaymentRequest", mock.Anything, request.ID).Return(request, nil)
+				store.On("GetAccount", mock.Anything, account.ID).Return(account, nil)
+				store.On("GetTOTPCredential", mock.Anything, account.Owner).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServerWithMailer(t, mockStore, mail.NewMemoryMailer(), &cfg)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.param)
+			require.NoError(t, err)
+			url := fmt.Sprintf("/users/payment-requests/%d/pay", request.ID)
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+			addAuthorization(t, req, account.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -1,36 +1,262 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/password"
 	"github.com/gin-gonic/gin"
 )
 
+// ErrInvalidCredentials is returned when the username or the password does not match.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// CreateUserRequest holds parameters for createUser handler.
 type CreateUserRequest struct {
-	Username  string
-	Password  string
-	FirstName string
-	LastName  string
-	Email     string
+	Username  string `json:"username" binding:"required,alphanum"`
+	Password  string `json:"password" binding:"required"`
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
+	Email     string `json:"email" binding:"required"`
 }
 
-func (s *Server) createUser(c *gin.Context) {}
+func (s *Server) createUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	hashedPassword, ok := s.hashNewPassword(c, req.Password)
+	if !ok {
+		return
+	}
+
+	user, err := s.store.CreateUser(c, db.CreateUserParams{
+		Username:       req.Username,
+		HashedPassword: hashedPassword,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		Email:          req.Email,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			c.JSON(http.StatusConflict, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	// the user can request another verification email if this one fails
+	if err := s.sendUserToken(c, user, db.UserTokenVerifyEmail, s.config.EmailVerificationExpiry); err != nil {
+		_ = c.Error(err)
+	}
 
-type GetUserRequest struct {
-	Username string
+	c.JSON(http.StatusOK, newUserResponse(user))
 }
 
-func (s *Server) getUser(c *gin.Context) {}
+// getUser returns the authenticated user.
+func (s *Server) getUser(c *gin.Context) {
+	user, err := s.store.GetUser(c, authPayload(c).Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
 
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// UpdateUserPasswordRequest holds parameters for updateUserPassword handler.
 type UpdateUserPasswordRequest struct {
-	Username     string
-	OldPassoword string
-	NewPassword  string
+	OldPassoword string `json:"old_password" binding:"required"`
+	NewPassword  string `json:"new_password" binding:"required"`
+	// OTP is the TOTP code required if two-factor authentication is enabled.
+	OTP string `json:"otp"`
 }
 
-func (s *Server) updateUserPassword(c *gin.Context) {}
+// updateUserPassword changes the password of the authenticated user and signs out
+// the other sessions of the user.
+func (s *Server) updateUserPassword(c *gin.Context) {
+	var req UpdateUserPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	user, err := s.store.GetUser(c, authPayload(c).Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
 
+	if !s.reauthenticate(c, user, req.OldPassoword, req.OTP) {
+		return
+	}
+
+	hashedPassword, ok := s.hashNewPassword(c, req.NewPassword)
+	if !ok {
+		return
+	}
+
+	// the other sessions are signed out, they may belong to whoever knew the old password
+	user, err = s.store.ChangePasswordTx(c, db.ChangePasswordTxParams{
+		Username:       user.Username,
+		HashedPassword: hashedPassword,
+		KeepFamilyID:   authPayload(c).FamilyID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserResponse(user))
+}
+
+// DeleteUserRequest holds parameters for deleteUser handler.
 type DeleteUserRequest struct {
-	Username string
-	Password string
+	Password string `json:"password" binding:"required"`
+	// OTP is the TOTP code required if two-factor authentication is enabled.
+	OTP string `json:"otp"`
 }
 
-func (s *Server) deleteUser(c *gin.Context) {}
+// deleteUser deletes the authenticated user.
+func (s *Server) deleteUser(c *gin.Context) {
+	var req DeleteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	user, err := s.store.GetUser(c, authPayload(c).Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	if !s.reauthenticate(c, user, req.Password, req.OTP) {
+		return
+	}
+
+	if err := s.store.DeleteUser(c, user.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// LoginUserRequest holds parameters for loginUser handler.
+type LoginUserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginUserResponse holds the tokens issued by loginUser, loginTwoFactor and renewToken
+// handlers. The refresh token is exchanged for new tokens by renewToken handler.
+type LoginUserResponse struct {
+	SessionID             int64     `json:"session_id"`
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+func (s *Server) loginUser(c *gin.Context) {
+	var req LoginUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if !s.reserveLoginAttempt(c, req.Username) {
+		return
+	}
+
+	user, err := s.store.GetUser(c, req.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// an unknown username must not be told apart by the response time
+			_ = password.Verify(req.Password, s.dummyHash)
+
+			s.recordLoginFailure(c, req.Username)
+			c.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidCredentials))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return
+	}
+
+	// check the password
+	if err := password.Verify(req.Password, user.HashedPassword); err != nil {
+		if !errors.Is(err, password.ErrMismatch) {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+			return
+		}
+
+		if throttle, locked := s.recordLoginFailure(c, user.Username); locked {
+			if err := s.sendLockoutNotice(c, user, throttle); err != nil {
+				_ = c.Error(err)
+			}
+		}
+
+		c.JSON(http.StatusUnauthorized, errorResponse(ErrInvalidCredentials))
+
+		return
+	}
+
+	s.resetLoginFailures(c, user.Username)
+	s.rehashPassword(c, user, req.Password)
+
+	if s.config.RequireVerifiedEmail && !user.EmailVerifiedAt.Valid {
+		c.JSON(http.StatusForbidden, errorResponse(ErrEmailNotVerified))
+
+		return
+	}
+
+	credential, err := s.store.GetTOTPCredential(c, user.Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	if err == nil && credential.ConfirmedAt.Valid {
+		s.startLoginChallenge(c, user)
+
+		return
+	}
+
+	s.startSession(c, user)
+}
@@ -9,20 +9,26 @@ import (
 	"github.com/stretchr/testify/mock"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/chutommy/simple-bank/api"
 	"github.com/chutommy/simple-bank/db/mocks"
 	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/chutommy/simple-bank/password"
+	"github.com/chutommy/simple-bank/token"
 	"github.com/chutommy/simple-bank/util"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestServer_CreateUser(t *testing.T) {
+	plainPassword := util.RandomOwner()
 	user := db.User{
 		Username:       util.RandomOwner(),
-		HashedPassword: util.RandomOwner(),
+		HashedPassword: hashPassword(t, plainPassword),
 		FirstName:      util.RandomOwner(),
 		LastName:       util.RandomOwner(),
 		Email:          util.RandomEmail(),
@@ -33,30 +39,67 @@ func TestServer_CreateUser(t *testing.T) {
 		param         api.CreateUserRequest
 		buildStub     func(store *mocks.Store)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+		// mails is the number of the sent verification emails
+		mails int
 	}{
 		{
 			name: "OK",
 			param: api.CreateUserRequest{
 				Username:  user.Username,
-				Password:  user.HashedPassword,
+				Password:  plainPassword,
 				FirstName: user.FirstName,
 				LastName:  user.LastName,
 				Email:     user.Email,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("CreateUser", mock.Anything, db.CreateUserParams{
-					Username:       user.Username,
-					HashedPassword: user.HashedPassword,
-					FirstName:      user.FirstName,
-					LastName:       user.LastName,
-					Email:          user.Email,
-				}).Return(user, nil)
+				store.On("CreateUser", mock.Anything, mock.MatchedBy(func(arg db.CreateUserParams) bool {
+					return arg.Username == user.Username && arg.FirstName == user.FirstName &&
+						arg.LastName == user.LastName && arg.Email == user.Email &&
+						matchPassword(plainPassword)(arg.HashedPassword)
+				})).Return(user, nil)
+				store.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(arg db.CreateUserTokenParams) bool {
+					return arg.Username == user.Username && arg.Purpose == db.UserTokenVerifyEmail
+				})).Return(db.UserToken{}, nil)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, resp.Code)
 
 				u := bytesToUser(t, resp.Body.Bytes())
-				assert.Equal(t, user, u)
+				assert.Equal(t, userResponse(user), u)
+			},
+			mails: 1,
+		},
+		{
+			name: "VerificationError",
+			param: api.CreateUserRequest{
+				Username:  user.Username,
+				Password:  plainPassword,
+				FirstName: user.FirstName,
+				LastName:  user.LastName,
+				Email:     user.Email,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("CreateUser", mock.Anything, mock.Anything).Return(user, nil)
+				store.On("CreateUserToken", mock.Anything, mock.Anything).Return(db.UserToken{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				// the user is created anyway
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "WeakPassword",
+			param: api.CreateUserRequest{
+				Username:  user.Username,
+				Password:  "short",
+				FirstName: user.FirstName,
+				LastName:  user.LastName,
+				Email:     user.Email,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+				assert.Contains(t, resp.Body.String(), "at least 6 characters")
 			},
 		},
 		{
@@ -71,44 +114,40 @@ func TestServer_CreateUser(t *testing.T) {
 			name: "UniqueKeyViolation",
 			param: api.CreateUserRequest{
 				Username:  user.Username,
-				Password:  user.HashedPassword,
+				Password:  plainPassword,
 				FirstName: user.FirstName,
 				LastName:  user.LastName,
 				Email:     user.Email,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("CreateUser", mock.Anything, db.CreateUserParams{
-					Username:       user.Username,
-					HashedPassword: user.HashedPassword,
-					FirstName:      user.FirstName,
-					LastName:       user.LastName,
-					Email:          user.Email,
-				}).Return(db.User{}, pq.Error{
+				store.On("CreateUser", mock.Anything, mock.MatchedBy(func(arg db.CreateUserParams) bool {
+					return arg.Username == user.Username && arg.FirstName == user.FirstName &&
+						arg.LastName == user.LastName && arg.Email == user.Email &&
+						matchPassword(plainPassword)(arg.HashedPassword)
+				})).Return(db.User{}, pq.Error{
 					Code:    "23505",
 					Message: "unique_violation",
 				})
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusForbidden, resp.Code)
+				assert.Equal(t, http.StatusConflict, resp.Code)
 			},
 		},
 		{
 			name: "InternalError",
 			param: api.CreateUserRequest{
 				Username:  user.Username,
-				Password:  user.HashedPassword,
+				Password:  plainPassword,
 				FirstName: user.FirstName,
 				LastName:  user.LastName,
 				Email:     user.Email,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("CreateUser", mock.Anything, db.CreateUserParams{
-					Username:       user.Username,
-					HashedPassword: user.HashedPassword,
-					FirstName:      user.FirstName,
-					LastName:       user.LastName,
-					Email:          user.Email,
-				}).Return(db.User{}, sql.ErrConnDone)
+				store.On("CreateUser", mock.Anything, mock.MatchedBy(func(arg db.CreateUserParams) bool {
+					return arg.Username == user.Username && arg.FirstName == user.FirstName &&
+						arg.LastName == user.LastName && arg.Email == user.Email &&
+						matchPassword(plainPassword)(arg.HashedPassword)
+				})).Return(db.User{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, resp.Code)
@@ -120,7 +159,8 @@ func TestServer_CreateUser(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct a server with a mock db.Store
 			store := new(mocks.Store)
-			server := api.NewServer(store)
+			mailer := mail.NewMemoryMailer()
+			server := newTestServerWithMailer(t, store, mailer, testConfig)
 			test.buildStub(store)
 
 			// construct request and response recorder
@@ -136,6 +176,12 @@ func TestServer_CreateUser(t *testing.T) {
 			// check response
 			test.checkResponse(t, recorder)
 			store.AssertExpectations(t)
+
+			messages := mailer.Messages()
+			require.Len(t, messages, test.mails)
+			for _, msg := range messages {
+				assert.Equal(t, user.Email, msg.To)
+			}
 		})
 	}
 }
@@ -147,21 +193,19 @@ func TestServer_GetUser(t *testing.T) {
 		FirstName:          util.RandomOwner(),
 		LastName:           util.RandomOwner(),
 		Email:              util.RandomEmail(),
-		PasswordModifiedAt: time.Now(),
-		CreatedAt:          time.Now(),
+		PasswordModifiedAt: time.Now().UTC(),
+		CreatedAt:          time.Now().UTC(),
 	}
 
 	tests := []struct {
 		name          string
-		param         api.GetUserRequest
+		authorize     bool
 		buildStub     func(store *mocks.Store)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name: "OK",
-			param: api.GetUserRequest{
-				Username: user.Username,
-			},
+			name:      "OK",
+			authorize: true,
 			buildStub: func(store *mocks.Store) {
 				store.On("GetUser", mock.Anything, user.Username).
 					Return(user, nil)
@@ -170,22 +214,19 @@ func TestServer_GetUser(t *testing.T) {
 				assert.Equal(t, http.StatusOK, resp.Code)
 
 				u := bytesToUser(t, resp.Body.Bytes())
-				assert.Equal(t, user, u)
+				assert.Equal(t, userResponse(user), u)
 			},
 		},
 		{
-			name:      "InvalidRequest",
-			param:     api.GetUserRequest{},
+			name:      "NoAuthorization",
 			buildStub: func(store *mocks.Store) {},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
-				assert.Equal(t, http.StatusBadRequest, resp.Code)
+				assert.Equal(t, http.StatusUnauthorized, resp.Code)
 			},
 		},
 		{
-			name: "NotFound",
-			param: api.GetUserRequest{
-				Username: user.Username,
-			},
+			name:      "NotFound",
+			authorize: true,
 			buildStub: func(store *mocks.Store) {
 				store.On("GetUser", mock.Anything, user.Username).
 					Return(db.User{}, sql.ErrNoRows)
@@ -195,10 +236,8 @@ func TestServer_GetUser(t *testing.T) {
 			},
 		},
 		{
-			name: "InternalError",
-			param: api.GetUserRequest{
-				Username: user.Username,
-			},
+			name:      "InternalError",
+			authorize: true,
 			buildStub: func(store *mocks.Store) {
 				store.On("GetUser", mock.Anything, user.Username).
 					Return(db.User{}, sql.ErrConnDone)
@@ -214,13 +253,14 @@ func TestServer_GetUser(t *testing.T) {
 			// construct server with mock db.Store
 			mockStore := new(mocks.Store)
 			test.buildStub(mockStore)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 
 			// construct a request and response recorder
 			url := "/users"
-			b, err := json.Marshal(test.param)
-			require.NoError(t, err)
-			req := httptest.NewRequest(http.MethodGet, url, bytes.NewReader(b))
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			if test.authorize {
+				addAuthorization(t, req, user.Username)
+			}
 			recorder := httptest.NewRecorder()
 
 			// serve
@@ -234,25 +274,36 @@ func TestServer_GetUser(t *testing.T) {
 }
 
 func TestServer_UpdateUserPassword(t *testing.T) {
+	oldPassword := util.RandomOwner()
+	newPassword := util.RandomOwner()
 	user1 := db.User{
 		Username:           util.RandomOwner(),
-		HashedPassword:     util.RandomOwner(),
+		HashedPassword:     hashPassword(t, oldPassword),
 		FirstName:          util.RandomOwner(),
 		LastName:           util.RandomOwner(),
 		Email:              util.RandomEmail(),
-		PasswordModifiedAt: time.Now().Add(-10 * time.Hour),
-		CreatedAt:          time.Now().Add(-10 * time.Hour),
+		PasswordModifiedAt: time.Now().Add(-10 * time.Hour).UTC(),
+		CreatedAt:          time.Now().Add(-10 * time.Hour).UTC(),
 	}
 	user2 := db.User{
-		Username:           util.RandomOwner(),
-		HashedPassword:     util.RandomOwner(),
-		FirstName:          util.RandomOwner(),
-		LastName:           util.RandomOwner(),
-		Email:              util.RandomEmail(),
-		PasswordModifiedAt: time.Now(),
-		CreatedAt:          time.Now(),
+		Username:           user1.Username,
+		HashedPassword:     hashPassword(t, newPassword),
+		FirstName:          user1.FirstName,
+		LastName:           user1.LastName,
+		Email:              user1.Email,
+		PasswordModifiedAt: time.Now().UTC(),
+		CreatedAt:          user1.CreatedAt,
 	}
 
+	familyID := util.RandomString(32)
+
+	// matchNewPassword matches the change of the password of user1 to newPassword
+	// which keeps only the current session
+	matchNewPassword := mock.MatchedBy(func(arg db.ChangePasswordTxParams) bool {
+		return arg.Username == user1.Username && arg.KeepFamilyID == familyID &&
+			matchPassword(newPassword)(arg.HashedPassword)
+	})
+
 	tests := []struct {
 		name          string
 		params        api.UpdateUserPasswordRequest
@@ -262,23 +313,19 @@ func TestServer_UpdateUserPassword(t *testing.T) {
 		{
 			name: "OK",
 			params: api.UpdateUserPasswordRequest{
-				Username:     user1.Username,
-				OldPassoword: user1.HashedPassword,
-				NewPassword:  user2.HashedPassword,
+				OldPassoword: oldPassword,
+				NewPassword:  newPassword,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("UpdateUserPassword", mock.Anything, db.UpdateUserPasswordParams{
-					Username:         user1.Username,
-					HashedPassword:   user1.HashedPassword,
-					HashedPassword_2: user2.HashedPassword,
-				}).
-					Return(user2, nil)
+				store.On("GetUser", mock.Anything, user1.Username).Return(user1, nil)
+				store.On("GetTOTPCredential", mock.Anything, user1.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+				store.On("ChangePasswordTx", mock.Anything, matchNewPassword).Return(user2, nil)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, resp.Code)
 
 				u := bytesToUser(t, resp.Body.Bytes())
-				assert.Equal(t, user2, u)
+				assert.Equal(t, userResponse(user2), u)
 			},
 		},
 		{
@@ -289,20 +336,41 @@ func TestServer_UpdateUserPassword(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, resp.Code)
 			},
 		},
+		{
+			name: "WrongPassword",
+			params: api.UpdateUserPasswordRequest{
+				OldPassoword: newPassword,
+				NewPassword:  newPassword,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user1.Username).Return(user1, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
+		{
+			name: "WeakPassword",
+			params: api.UpdateUserPasswordRequest{
+				OldPassoword: oldPassword,
+				NewPassword:  "short",
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user1.Username).Return(user1, nil)
+				store.On("GetTOTPCredential", mock.Anything, user1.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
 		{
 			name: "NotFound",
 			params: api.UpdateUserPasswordRequest{
-				Username:     user1.Username,
-				OldPassoword: user1.HashedPassword,
-				NewPassword:  user2.HashedPassword,
+				OldPassoword: oldPassword,
+				NewPassword:  newPassword,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("UpdateUserPassword", mock.Anything, db.UpdateUserPasswordParams{
-					Username:         user1.Username,
-					HashedPassword:   user1.HashedPassword,
-					HashedPassword_2: user2.HashedPassword,
-				}).
-					Return(db.User{}, sql.ErrNoRows)
+				store.On("GetUser", mock.Anything, user1.Username).Return(db.User{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusNotFound, resp.Code)
@@ -311,17 +379,13 @@ func TestServer_UpdateUserPassword(t *testing.T) {
 		{
 			name: "InternalError",
 			params: api.UpdateUserPasswordRequest{
-				Username:     user1.Username,
-				OldPassoword: user1.HashedPassword,
-				NewPassword:  user2.HashedPassword,
+				OldPassoword: oldPassword,
+				NewPassword:  newPassword,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("UpdateUserPassword", mock.Anything, db.UpdateUserPasswordParams{
-					Username:         user1.Username,
-					HashedPassword:   user1.HashedPassword,
-					HashedPassword_2: user2.HashedPassword,
-				}).
-					Return(db.User{}, sql.ErrConnDone)
+				store.On("GetUser", mock.Anything, user1.Username).Return(user1, nil)
+				store.On("GetTOTPCredential", mock.Anything, user1.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+				store.On("ChangePasswordTx", mock.Anything, matchNewPassword).Return(db.User{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, resp.Code)
@@ -333,7 +397,7 @@ func TestServer_UpdateUserPassword(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			// construct server with mock db.Store
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
@@ -341,6 +405,7 @@ func TestServer_UpdateUserPassword(t *testing.T) {
 			b, err := json.Marshal(test.params)
 			require.NoError(t, err)
 			req := httptest.NewRequest(http.MethodPut, url, bytes.NewReader(b))
+			addSessionAuthorization(t, req, user1.Username, db.UserRoleCustomer, familyID)
 			recorder := httptest.NewRecorder()
 
 			// server
@@ -354,15 +419,17 @@ func TestServer_UpdateUserPassword(t *testing.T) {
 }
 
 func TestServer_DeleteUser(t *testing.T) {
+	plainPassword := util.RandomOwner()
 	user := db.User{
 		Username:           util.RandomOwner(),
-		HashedPassword:     util.RandomOwner(),
+		HashedPassword:     hashPassword(t, plainPassword),
 		FirstName:          util.RandomOwner(),
 		LastName:           util.RandomOwner(),
 		Email:              util.RandomEmail(),
-		PasswordModifiedAt: time.Now(),
-		CreatedAt:          time.Now(),
+		PasswordModifiedAt: time.Now().UTC(),
+		CreatedAt:          time.Now().UTC(),
 	}
+	credential := randomTOTPCredential(t, user.Username, true)
 
 	tests := []struct {
 		name          string
@@ -373,26 +440,65 @@ func TestServer_DeleteUser(t *testing.T) {
 		{
 			name: "OK",
 			params: api.DeleteUserRequest{
-				Username: user.Username,
-				Password: user.HashedPassword,
+				Password: plainPassword,
 			},
 			buildStub: func(store *mocks.Store) {
-				store.On("DeleteUser", mock.Anything, db.DeleteUserParams{
-					Username:       user.Username,
-					HashedPassword: user.HashedPassword,
-				}).Return(nil)
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+				store.On("DeleteUser", mock.Anything, user.Username).Return(nil)
 			},
 			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, resp.Code)
 			},
 		},
+		{
+			name: "TwoFactor",
+			params: api.DeleteUserRequest{
+				Password: plainPassword,
+				OTP:      currentTOTPCode(t, credential),
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(credential, nil)
+				store.On("UseTOTPStep", mock.Anything, mock.Anything).Return(int64(1), nil)
+				store.On("DeleteUser", mock.Anything, user.Username).Return(nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "MissingCode",
+			params: api.DeleteUserRequest{
+				Password: plainPassword,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(credential, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, resp.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			params: api.DeleteUserRequest{
+				Password: util.RandomOwner(),
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			// construct a server with mock Store
 			mockStore := new(mocks.Store)
-			server := api.NewServer(mockStore)
+			server := newTestServer(t, mockStore)
 			test.buildStub(mockStore)
 
 			// prepare request and response recorder
@@ -400,6 +506,7 @@ func TestServer_DeleteUser(t *testing.T) {
 			b, err := json.Marshal(test.params)
 			require.NoError(t, err)
 			req := httptest.NewRequest(http.MethodDelete, url, bytes.NewReader(b))
+			addAuthorization(t, req, user.Username)
 			recorder := httptest.NewRecorder()
 
 			// server
@@ -412,10 +519,241 @@ func TestServer_DeleteUser(t *testing.T) {
 	}
 }
 
-func bytesToUser(t *testing.T, b []byte) db.User {
-	var u db.User
+func TestServer_LoginUser(t *testing.T) {
+	plainPassword := util.RandomOwner()
+	user := db.User{
+		Username:       util.RandomOwner(),
+		HashedPassword: hashPassword(t, plainPassword),
+		FirstName:      util.RandomOwner(),
+		LastName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+		Role:           db.UserRoleSupport,
+	}
+
+	session := db.Session{
+		ID:        util.RandomInt(1, 1000),
+		FamilyID:  util.RandomString(32),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	tests := []struct {
+		name          string
+		params        api.LoginUserRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			params: api.LoginUserRequest{
+				Username: user.Username,
+				Password: plainPassword,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+				store.On("CreateSession", mock.Anything, mock.MatchedBy(func(arg db.CreateSessionParams) bool {
+					return arg.Username == user.Username && arg.FamilyID != "" && arg.RefreshTokenHash != ""
+				})).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+
+				var body api.LoginUserResponse
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+				assert.NotEmpty(t, body.AccessToken)
+				assert.NotEmpty(t, body.RefreshToken)
+				assert.Equal(t, session.ID, body.SessionID)
+
+				// the role is embedded in the token
+				maker, err := token.NewJWTMaker(testConfig.TokenSymmetricKey)
+				require.NoError(t, err)
+
+				payload, err := maker.VerifyToken(body.AccessToken)
+				require.NoError(t, err)
+				assert.Equal(t, user.Role, payload.Role)
+			},
+		},
+		{
+			name: "WrongPassword",
+			params: api.LoginUserRequest{
+				Username: user.Username,
+				Password: util.RandomOwner(),
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
+		{
+			name: "Rehash",
+			params: api.LoginUserRequest{
+				Username: user.Username,
+				Password: plainPassword,
+			},
+			buildStub: func(store *mocks.Store) {
+				// the hash made with bcrypt is replaced by an Argon2id hash
+				hashedPassword, err := bcrypt.GenerateFromPassword([]byte(plainPassword), bcrypt.MinCost)
+				require.NoError(t, err)
+
+				outdated := user
+				outdated.HashedPassword = string(hashedPassword)
+
+				store.On("GetUser", mock.Anything, user.Username).Return(outdated, nil)
+				store.On("RehashUserPassword", mock.Anything, mock.MatchedBy(func(arg db.RehashUserPasswordParams) bool {
+					return arg.Username == user.Username && arg.OldHashedPassword == outdated.HashedPassword &&
+						strings.HasPrefix(arg.HashedPassword, "$argon2id$") &&
+						matchPassword(plainPassword)(arg.HashedPassword)
+				})).Return(int64(1), nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+				store.On("CreateSession", mock.Anything, mock.Anything).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "RehashError",
+			params: api.LoginUserRequest{
+				Username: user.Username,
+				Password: plainPassword,
+			},
+			buildStub: func(store *mocks.Store) {
+				// the hash made with other Argon2id parameters is replaced
+				hasher, err := password.NewHasher(password.Params{
+					Algorithm:     password.Argon2id,
+					Argon2Time:    testConfig.PasswordArgon2Time + 1,
+					Argon2Memory:  testConfig.PasswordArgon2Memory,
+					Argon2Threads: testConfig.PasswordArgon2Threads,
+				})
+				require.NoError(t, err)
+
+				outdated := user
+				outdated.HashedPassword, err = hasher.Hash(plainPassword)
+				require.NoError(t, err)
+
+				store.On("GetUser", mock.Anything, user.Username).Return(outdated, nil)
+				store.On("RehashUserPassword", mock.Anything, mock.Anything).Return(int64(0), sql.ErrConnDone)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+				store.On("CreateSession", mock.Anything, mock.Anything).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				// the old hash keeps working
+				assert.Equal(t, http.StatusOK, resp.Code)
+			},
+		},
+		{
+			name: "SessionError",
+			params: api.LoginUserRequest{
+				Username: user.Username,
+				Password: plainPassword,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(db.TotpCredential{}, sql.ErrNoRows)
+				store.On("CreateSession", mock.Anything, mock.Anything).Return(db.Session{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, resp.Code)
+			},
+		},
+		{
+			name: "TwoFactor",
+			params: api.LoginUserRequest{
+				Username: user.Username,
+				Password: plainPassword,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(user, nil)
+				store.On("GetTOTPCredential", mock.Anything, user.Username).Return(db.TotpCredential{
+					Username:    user.Username,
+					ConfirmedAt: sql.NullTime{Time: time.Now(), Valid: true},
+				}, nil)
+				store.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(arg db.CreateUserTokenParams) bool {
+					return arg.Username == user.Username && arg.Purpose == db.UserTokenLoginChallenge
+				})).Return(db.UserToken{ExpiresAt: time.Now().Add(5 * time.Minute)}, nil)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, resp.Code)
+
+				// no session is started before the second step
+				var body api.LoginChallengeResponse
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+				assert.True(t, body.TwoFactorRequired)
+				assert.NotEmpty(t, body.ChallengeToken)
+			},
+		},
+		{
+			name: "UserNotFound",
+			params: api.LoginUserRequest{
+				Username: user.Username,
+				Password: plainPassword,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("GetUser", mock.Anything, user.Username).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, resp.Code)
+			},
+		},
+		{
+			name:      "InvalidRequest",
+			params:    api.LoginUserRequest{},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct a server with mock Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := "/users/login"
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+			recorder := httptest.NewRecorder()
+
+			// server
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check result
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+// bytesToUser decodes the user from the response body and checks
+// that the body does not contain the password hash.
+func bytesToUser(t *testing.T, b []byte) api.UserResponse {
+	assert.NotContains(t, string(b), "hashed_password")
+
+	var u api.UserResponse
 	err := json.Unmarshal(b, &u)
 	require.NoError(t, err)
 
 	return u
 }
+
+// userResponse returns the expected response of the user.
+func userResponse(user db.User) api.UserResponse {
+	return api.UserResponse{
+		Username:           user.Username,
+		Role:               user.Role,
+		FirstName:          user.FirstName,
+		LastName:           user.LastName,
+		Email:              user.Email,
+		EmailVerified:      user.EmailVerifiedAt.Valid,
+		PasswordModifiedAt: user.PasswordModifiedAt,
+		CreatedAt:          user.CreatedAt,
+	}
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+// webhookSecretSize is the number of random bytes of a generated webhook secret.
+const webhookSecretSize = 32
+
+var (
+	// ErrWebhookNotOwned is returned when the webhook endpoint belongs to another user.
+	ErrWebhookNotOwned = errors.New("webhook endpoint belongs to another user")
+	// ErrInvalidWebhookURL is returned when the webhook URL is not an absolute HTTP(S) URL.
+	ErrInvalidWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+	// ErrWebhookDeliveryNotFound is returned when the delivery does not belong to the endpoint.
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// CreateWebhookEndpointRequest holds parameters for createWebhookEndpoint handler.
+// A random secret is generated if none is given.
+type CreateWebhookEndpointRequest struct {
+	URL    string   `json:"url" binding:"required,max=2048"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=transfer.created entry.created account.closed"`
+	Secret string   `json:"secret" binding:"omitempty,min=16,max=128"`
+}
+
+// WebhookEndpointResponse holds a webhook endpoint returned by the webhook handlers.
+// The secret is returned only when the endpoint is created.
+type WebhookEndpointResponse struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newWebhookEndpointResponse(endpoint db.WebhookEndpoint) WebhookEndpointResponse {
+	return WebhookEndpointResponse{
+		ID:        endpoint.ID,
+		URL:       endpoint.URL,
+		Events:    endpoint.Events,
+		CreatedAt: endpoint.CreatedAt,
+	}
+}
+
+func (s *Server) createWebhookEndpoint(c *gin.Context) {
+	var req CreateWebhookEndpointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	u, err := url.Parse(req.URL)
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") {
+		c.JSON(http.StatusBadRequest, errorResponse(ErrInvalidWebhookURL))
+
+		return
+	}
+
+	// host names are checked again by the sender once they are resolved
+	if !s.config.WebhookAllowInternal && isInternalHost(u.Hostname()) {
+		c.JSON(http.StatusBadRequest, errorResponse(webhook.ErrInternalAddress))
+
+		return
+	}
+
+	if req.Secret == "" {
+		secret := make([]byte, webhookSecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(fmt.Errorf("failed to generate secret: %w", err)))
+
+			return
+		}
+
+		req.Secret = hex.EncodeToString(secret)
+	}
+
+	endpoint, err := s.store.CreateWebhookEndpoint(c, db.CreateWebhookEndpointParams{
+		Owner:  authPayload(c).Username,
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: req.Secret,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp := newWebhookEndpointResponse(endpoint)
+	resp.Secret = endpoint.Secret
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// isInternalHost reports whether the host is localhost or an internal IP address.
+func isInternalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+
+	return ip != nil && webhook.IsInternalIP(ip)
+}
+
+func (s *Server) listWebhookEndpoints(c *gin.Context) {
+	endpoints, err := s.store.ListWebhookEndpoints(c, authPayload(c).Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	resp := make([]WebhookEndpointResponse, len(endpoints))
+	for i, endpoint := range endpoints {
+		resp[i] = newWebhookEndpointResponse(endpoint)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// WebhookEndpointRequestURI holds URI parameters for webhook endpoint handlers.
+type WebhookEndpointRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (s *Server) getWebhookEndpoint(c *gin.Context) {
+	var req WebhookEndpointRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	endpoint, ok := s.ownWebhookEndpoint(c, req.ID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newWebhookEndpointResponse(endpoint))
+}
+
+func (s *Server) deleteWebhookEndpoint(c *gin.Context) {
+	var req WebhookEndpointRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.ownWebhookEndpoint(c, req.ID); !ok {
+		return
+	}
+
+	if err := s.store.DeleteWebhookEndpoint(c, req.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
+
+// ListWebhookDeliveriesRequestQuery holds query parameters for listWebhookDeliveries handler.
+type ListWebhookDeliveriesRequestQuery struct {
+	PageNum  int32 `form:"page_num" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=1000"`
+}
+
+// listWebhookDeliveries lists the deliveries of the endpoint, the latest first.
+func (s *Server) listWebhookDeliveries(c *gin.Context) {
+	var reqURI WebhookEndpointRequestURI
+	if err := c.ShouldBindUri(&reqURI); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	var reqQuery ListWebhookDeliveriesRequestQuery
+	if err := c.ShouldBindQuery(&reqQuery); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.ownWebhookEndpoint(c, reqURI.ID); !ok {
+		return
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(c, db.ListWebhookDeliveriesParams{
+		EndpointID: reqURI.ID,
+		Limit:      reqQuery.PageSize,
+		Offset:     (reqQuery.PageNum - 1) * reqQuery.PageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// WebhookDeliveryRequestURI holds URI parameters for webhook delivery handlers.
+type WebhookDeliveryRequestURI struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// GetWebhookDeliveryResponse holds a delivery and its attempts returned by getWebhookDelivery handler.
+type GetWebhookDeliveryResponse struct {
+	Delivery db.WebhookDelivery  `json:"delivery"`
+	Attempts []db.WebhookAttempt `json:"attempts"`
+}
+
+// getWebhookDelivery returns the delivery with the log of its attempts.
+func (s *Server) getWebhookDelivery(c *gin.Context) {
+	var req WebhookDeliveryRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	delivery, ok := s.ownWebhookDelivery(c, req.ID, req.DeliveryID)
+	if !ok {
+		return
+	}
+
+	attempts, err := s.store.ListWebhookAttempts(c, delivery.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, GetWebhookDeliveryResponse{Delivery: delivery, Attempts: attempts})
+}
+
+// redeliverWebhookDelivery queues the delivery to be sent again immediately
+// with a fresh number of attempts, regardless of its current status.
+func (s *Server) redeliverWebhookDelivery(c *gin.Context) {
+	var req WebhookDeliveryRequestURI
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(err))
+
+		return
+	}
+
+	if _, ok := s.ownWebhookDelivery(c, req.ID, req.DeliveryID); !ok {
+		return
+	}
+
+	delivery, err := s.store.RedeliverWebhookDelivery(c, req.DeliveryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(err))
+
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// ownWebhookEndpoint returns the webhook endpoint with the given ID if it belongs to
+// the authenticated user. It writes the error response and returns false if not.
+func (s *Server) ownWebhookEndpoint(c *gin.Context, id int64) (db.WebhookEndpoint, bool) {
+	endpoint, err := s.store.GetWebhookEndpoint(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return endpoint, false
+	}
+
+	if endpoint.Owner != authPayload(c).Username {
+		c.JSON(http.StatusForbidden, errorResponse(ErrWebhookNotOwned))
+
+		return endpoint, false
+	}
+
+	return endpoint, true
+}
+
+// ownWebhookDelivery returns the delivery with the given ID if it belongs to the webhook
+// endpoint of the authenticated user. It writes the error response and returns false if not.
+func (s *Server) ownWebhookDelivery(c *gin.Context, endpointID, id int64) (db.WebhookDelivery, bool) {
+	if _, ok := s.ownWebhookEndpoint(c, endpointID); !ok {
+		return db.WebhookDelivery{}, false
+	}
+
+	delivery, err := s.store.GetWebhookDelivery(c, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, errorResponse(err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+
+		return delivery, false
+	}
+
+	if delivery.EndpointID != endpointID {
+		c.JSON(http.StatusNotFound, errorResponse(ErrWebhookDeliveryNotFound))
+
+		return delivery, false
+	}
+
+	return delivery, true
+}
@@ -0,0 +1,304 @@
+package api_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chutommy/simple-bank/api"
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomWebhookEndpoint(owner string) db.WebhookEndpoint {
+	return db.WebhookEndpoint{
+		ID:     util.RandomInt(1, 2048),
+		Owner:  owner,
+		URL:    "https://example.com/hooks",
+		Events: []string{db.EventTransferCreated, db.EventEntryCreated},
+		Secret: util.RandomString(32),
+	}
+}
+
+func TestServer_CreateWebhookEndpoint(t *testing.T) {
+	endpoint := randomWebhookEndpoint(util.RandomOwner())
+
+	tests := []struct {
+		name          string
+		params        api.CreateWebhookEndpointRequest
+		buildStub     func(store *mocks.Store)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			params: api.CreateWebhookEndpointRequest{
+				URL:    endpoint.URL,
+				Events: endpoint.Events,
+				Secret: endpoint.Secret,
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("CreateWebhookEndpoint", mock.Anything, db.CreateWebhookEndpointParams{
+					Owner:  endpoint.Owner,
+					URL:    endpoint.URL,
+					Events: endpoint.Events,
+					Secret: endpoint.Secret,
+				}).Return(endpoint, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var result api.WebhookEndpointResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+				assert.Equal(t, endpoint.ID, result.ID)
+				assert.Equal(t, endpoint.Secret, result.Secret)
+			},
+		},
+		{
+			name: "GeneratedSecret",
+			params: api.CreateWebhookEndpointRequest{
+				URL:    endpoint.URL,
+				Events: []string{db.EventAccountClosed},
+			},
+			buildStub: func(store *mocks.Store) {
+				store.On("CreateWebhookEndpoint", mock.Anything, mock.MatchedBy(
+					func(arg db.CreateWebhookEndpointParams) bool {
+						return len(arg.Secret) == 64
+					})).Return(endpoint, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidURL",
+			params: api.CreateWebhookEndpointRequest{
+				URL:    "ftp://example.com/hooks",
+				Events: endpoint.Events,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalURL",
+			params: api.CreateWebhookEndpointRequest{
+				URL:    "http://169.254.169.254/latest/meta-data",
+				Events: endpoint.Events,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "LocalhostURL",
+			params: api.CreateWebhookEndpointRequest{
+				URL:    "http://localhost:8080/hooks",
+				Events: endpoint.Events,
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnknownEvent",
+			params: api.CreateWebhookEndpointRequest{
+				URL:    endpoint.URL,
+				Events: []string{"account.opened"},
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ShortSecret",
+			params: api.CreateWebhookEndpointRequest{
+				URL:    endpoint.URL,
+				Events: endpoint.Events,
+				Secret: "secret",
+			},
+			buildStub: func(store *mocks.Store) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			b, err := json.Marshal(test.params)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, "/users/webhooks", bytes.NewReader(b))
+			addAuthorization(t, req, endpoint.Owner)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			test.checkResponse(t, recorder)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
+
+func TestServer_ListWebhookEndpoints(t *testing.T) {
+	endpoint := randomWebhookEndpoint(util.RandomOwner())
+
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	mockStore.On("ListWebhookEndpoints", mock.Anything, endpoint.Owner).
+		Return([]db.WebhookEndpoint{endpoint}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/webhooks", nil)
+	addAuthorization(t, req, endpoint.Owner)
+	recorder := httptest.NewRecorder()
+
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), endpoint.Secret)
+
+	var result []api.WebhookEndpointResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	require.Len(t, result, 1)
+	assert.Equal(t, endpoint.Events, result[0].Events)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_GetWebhookDelivery(t *testing.T) {
+	endpoint := randomWebhookEndpoint(util.RandomOwner())
+	delivery := db.WebhookDelivery{
+		ID:         util.RandomInt(1, 2048),
+		EndpointID: endpoint.ID,
+		EventType:  db.EventTransferCreated,
+		Payload:    []byte(`{}`),
+		Status:     db.WebhookDeliveryStatusDead,
+		Attempts:   2,
+	}
+	attempts := []db.WebhookAttempt{
+		{ID: 1, DeliveryID: delivery.ID, StatusCode: http.StatusInternalServerError, Error: "unexpected response status"},
+		{ID: 2, DeliveryID: delivery.ID, Error: "connection refused"},
+	}
+
+	mockStore := new(mocks.Store)
+	server := newTestServer(t, mockStore)
+	mockStore.On("GetWebhookEndpoint", mock.Anything, endpoint.ID).Return(endpoint, nil)
+	mockStore.On("GetWebhookDelivery", mock.Anything, delivery.ID).Return(delivery, nil)
+	mockStore.On("ListWebhookAttempts", mock.Anything, delivery.ID).Return(attempts, nil)
+
+	url := fmt.Sprintf("/users/webhooks/%d/deliveries/%d", endpoint.ID, delivery.ID)
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	addAuthorization(t, req, endpoint.Owner)
+	recorder := httptest.NewRecorder()
+
+	server.Srv.Handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var result api.GetWebhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	assert.Equal(t, delivery, result.Delivery)
+	assert.Equal(t, attempts, result.Attempts)
+	mockStore.AssertExpectations(t)
+}
+
+func TestServer_RedeliverWebhookDelivery(t *testing.T) {
+	owner := util.RandomOwner()
+	endpoint := randomWebhookEndpoint(owner)
+	delivery := db.WebhookDelivery{
+		ID:         util.RandomInt(1, 2048),
+		EndpointID: endpoint.ID,
+		Payload:    []byte(`{}`),
+		Status:     db.WebhookDeliveryStatusDead,
+	}
+
+	tests := []struct {
+		name      string
+		username  string
+		buildStub func(store *mocks.Store)
+		code      int
+	}{
+		{
+			name:     "OK",
+			username: owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetWebhookEndpoint", mock.Anything, endpoint.ID).Return(endpoint, nil)
+				store.On("GetWebhookDelivery", mock.Anything, delivery.ID).Return(delivery, nil)
+				store.On("RedeliverWebhookDelivery", mock.Anything, delivery.ID).Return(db.WebhookDelivery{
+					ID:         delivery.ID,
+					EndpointID: endpoint.ID,
+					Payload:    delivery.Payload,
+					Status:     db.WebhookDeliveryStatusPending,
+				}, nil)
+			},
+			code: http.StatusOK,
+		},
+		{
+			name:     "NotOwned",
+			username: util.RandomOwner(),
+			buildStub: func(store *mocks.Store) {
+				store.On("GetWebhookEndpoint", mock.Anything, endpoint.ID).Return(endpoint, nil)
+			},
+			code: http.StatusForbidden,
+		},
+		{
+			name:     "OtherEndpoint",
+			username: owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetWebhookEndpoint", mock.Anything, endpoint.ID).Return(endpoint, nil)
+				store.On("GetWebhookDelivery", mock.Anything, delivery.ID).
+					Return(db.WebhookDelivery{ID: delivery.ID, EndpointID: endpoint.ID + 1}, nil)
+			},
+			code: http.StatusNotFound,
+		},
+		{
+			name:     "NotFound",
+			username: owner,
+			buildStub: func(store *mocks.Store) {
+				store.On("GetWebhookEndpoint", mock.Anything, endpoint.ID).Return(endpoint, nil)
+				store.On("GetWebhookDelivery", mock.Anything, delivery.ID).Return(db.WebhookDelivery{}, sql.ErrNoRows)
+			},
+			code: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// construct server with mock db.Store
+			mockStore := new(mocks.Store)
+			server := newTestServer(t, mockStore)
+			test.buildStub(mockStore)
+
+			// prepare request and response recorder
+			url := fmt.Sprintf("/users/webhooks/%d/deliveries/%d/redeliver", endpoint.ID, delivery.ID)
+			req := httptest.NewRequest(http.MethodPost, url, nil)
+			addAuthorization(t, req, test.username)
+			recorder := httptest.NewRecorder()
+
+			// serve
+			server.Srv.Handler.ServeHTTP(recorder, req)
+
+			// check response
+			assert.Equal(t, test.code, recorder.Code)
+			mockStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+CREATE TABLE "approval_policies"
+(
+    "account_id" bigint PRIMARY KEY,
+    "threshold"  bigint      NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "approval_policy_approvers"
+(
+    "account_id" bigint  NOT NULL,
+    "username"   varchar NOT NULL,
+    PRIMARY KEY ("account_id", "username")
+);
+
+CREATE TABLE "pending_transfers"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "initiator"       varchar     NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "decided_by"      varchar,
+    "transfer_id"     bigint,
+    "expires_at"      timestamptz NOT NULL,
+    "decided_at"      timestamptz,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "approval_policies"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "approval_policy_approvers"
+    ADD FOREIGN KEY ("account_id") REFERENCES "approval_policies" ("account_id") ON DELETE CASCADE;
+
+ALTER TABLE "approval_policy_approvers"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("initiator") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("decided_by") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "pending_transfers" ("from_account_id");
+
+CREATE INDEX ON "pending_transfers" ("status", "expires_at");
+
+COMMENT ON COLUMN "approval_policies"."threshold" IS 'transfers above the threshold require an approval';
+
+COMMENT ON COLUMN "pending_transfers"."status" IS 'pending, approved, rejected or expired';
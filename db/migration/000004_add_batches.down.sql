@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS batch_legs;
+DROP TABLE IF EXISTS batches;
@@ -0,0 +1,43 @@
+CREATE TABLE "batches"
+(
+    "id"           bigserial PRIMARY KEY,
+    "initiator"    varchar     NOT NULL,
+    "mode"         varchar     NOT NULL,
+    "status"       varchar     NOT NULL DEFAULT 'processing',
+    "error"        varchar,
+    "created_at"   timestamptz NOT NULL DEFAULT (now()),
+    "completed_at" timestamptz
+);
+
+CREATE TABLE "batch_legs"
+(
+    "id"              bigserial PRIMARY KEY,
+    "batch_id"        bigint      NOT NULL,
+    "position"        integer     NOT NULL,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "status"          varchar     NOT NULL,
+    "transfer_id"     bigint,
+    "error"           varchar,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "batches"
+    ADD FOREIGN KEY ("initiator") REFERENCES "users" ("username");
+
+ALTER TABLE "batch_legs"
+    ADD FOREIGN KEY ("batch_id") REFERENCES "batches" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "batch_legs"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "batches" ("initiator");
+
+CREATE UNIQUE INDEX ON "batch_legs" ("batch_id", "position");
+
+COMMENT ON COLUMN "batches"."mode" IS 'atomic or best_effort';
+
+COMMENT ON COLUMN "batches"."status" IS 'processing, completed, partially_completed or failed';
+
+COMMENT ON COLUMN "batch_legs"."status" IS 'completed or failed';
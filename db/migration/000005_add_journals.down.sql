@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS entries
+    DROP COLUMN IF EXISTS journal_id;
+
+DROP TABLE IF EXISTS journals;
@@ -0,0 +1,15 @@
+CREATE TABLE "journals"
+(
+    "id"         bigserial PRIMARY KEY,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries"
+    ADD COLUMN "journal_id" bigint;
+
+ALTER TABLE "entries"
+    ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE INDEX ON "entries" ("journal_id");
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'the entries of a journal sum to zero per currency';
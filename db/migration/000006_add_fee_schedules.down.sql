@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS fee_tiers;
+DROP TABLE IF EXISTS fee_schedules;
+
+DROP INDEX IF EXISTS transfers_from_account_id_created_at_idx;
+
+ALTER TABLE IF EXISTS pending_transfers
+    DROP COLUMN IF EXISTS instant;
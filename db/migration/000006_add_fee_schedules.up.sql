@@ -0,0 +1,50 @@
+CREATE TABLE "fee_schedules"
+(
+    "id"                     bigserial PRIMARY KEY,
+    "account_type"           varchar     NOT NULL,
+    "transfer_kind"          varchar     NOT NULL,
+    "currency"               varchar     NOT NULL,
+    "method"                 varchar     NOT NULL,
+    "flat_fee"               bigint      NOT NULL DEFAULT 0,
+    "rate_bp"                integer     NOT NULL DEFAULT 0,
+    "min_fee"                bigint      NOT NULL DEFAULT 0,
+    "max_fee"                bigint,
+    "free_monthly_transfers" integer     NOT NULL DEFAULT 0,
+    "fee_account_id"         bigint      NOT NULL,
+    "created_at"             timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "fee_tiers"
+(
+    "fee_schedule_id" bigint NOT NULL,
+    "min_amount"      bigint NOT NULL,
+    "fee"             bigint NOT NULL,
+    PRIMARY KEY ("fee_schedule_id", "min_amount")
+);
+
+ALTER TABLE "fee_schedules"
+    ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "fee_tiers"
+    ADD FOREIGN KEY ("fee_schedule_id") REFERENCES "fee_schedules" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "pending_transfers"
+    ADD COLUMN "instant" boolean NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX ON "fee_schedules" ("account_type", "transfer_kind", "currency");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "fee_schedules"."transfer_kind" IS 'standard or instant';
+
+COMMENT ON COLUMN "fee_schedules"."method" IS 'flat, percentage or tiered';
+
+COMMENT ON COLUMN "fee_schedules"."rate_bp" IS 'percentage rate in basis points';
+
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS 'no upper bound if null';
+
+COMMENT ON COLUMN "fee_schedules"."free_monthly_transfers" IS 'number of transfers per calendar month without the fee';
+
+COMMENT ON COLUMN "fee_schedules"."fee_account_id" IS 'system account receiving the fees';
+
+COMMENT ON COLUMN "fee_tiers"."fee" IS 'flat fee of transfers with amount of at least min_amount';
@@ -0,0 +1,64 @@
+CREATE TABLE "interest_rates"
+(
+    "account_type"        varchar     NOT NULL,
+    "currency"            varchar     NOT NULL,
+    "rate_bp"             integer     NOT NULL,
+    "day_count"           varchar     NOT NULL DEFAULT 'act/365',
+    "interest_account_id" bigint      NOT NULL,
+    "created_at"          timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("account_type", "currency")
+);
+
+CREATE TABLE "interest_capitalizations"
+(
+    "id"         bigserial PRIMARY KEY,
+    "account_id" bigint      NOT NULL,
+    "period"     date        NOT NULL,
+    "amount"     bigint      NOT NULL,
+    "journal_id" bigint,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals"
+(
+    "account_id"        bigint      NOT NULL,
+    "accrual_date"      date        NOT NULL,
+    "balance"           bigint      NOT NULL,
+    "rate_bp"           integer     NOT NULL,
+    "day_count"         varchar     NOT NULL,
+    "amount_micros"     bigint      NOT NULL,
+    "capitalization_id" bigint,
+    "created_at"        timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("account_id", "accrual_date")
+);
+
+ALTER TABLE "interest_rates"
+    ADD FOREIGN KEY ("interest_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_capitalizations"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_capitalizations"
+    ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+ALTER TABLE "interest_accruals"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals"
+    ADD FOREIGN KEY ("capitalization_id") REFERENCES "interest_capitalizations" ("id");
+
+CREATE UNIQUE INDEX ON "interest_capitalizations" ("account_id", "period");
+
+CREATE INDEX ON "interest_accruals" ("account_id", "capitalization_id");
+
+COMMENT ON COLUMN "interest_rates"."rate_bp" IS 'annual rate in basis points';
+
+COMMENT ON COLUMN "interest_rates"."day_count" IS 'act/365 or 30/360';
+
+COMMENT ON COLUMN "interest_rates"."interest_account_id" IS 'system account paying the interest';
+
+COMMENT ON COLUMN "interest_capitalizations"."period" IS 'first day of the capitalized month';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'end-of-day balance';
+
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'millionths of the smallest currency unit';
@@ -0,0 +1,9 @@
+ALTER TABLE IF EXISTS accounts
+    DROP CONSTRAINT IF EXISTS accounts_account_type_fkey;
+
+ALTER TABLE IF EXISTS accounts
+    DROP COLUMN IF EXISTS account_type;
+
+DROP INDEX IF EXISTS entries_account_id_created_at_idx;
+
+DROP TABLE IF EXISTS account_types;
@@ -0,0 +1,31 @@
+CREATE TABLE "account_types"
+(
+    "name"                  varchar PRIMARY KEY,
+    "overdraft_allowed"     boolean     NOT NULL DEFAULT false,
+    "overdraft_limit"       bigint,
+    "min_balance"           bigint      NOT NULL DEFAULT 0,
+    "withdrawals_per_month" integer,
+    "created_at"            timestamptz NOT NULL DEFAULT (now())
+);
+
+INSERT INTO "account_types" ("name", "overdraft_allowed", "overdraft_limit", "min_balance", "withdrawals_per_month")
+VALUES ('checking', true, 50000, 0, NULL),
+       ('savings', false, NULL, 0, 6),
+       ('business', true, 500000, 0, NULL),
+       ('system', true, NULL, 0, NULL);
+
+ALTER TABLE "accounts"
+    ADD COLUMN "account_type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts"
+    ADD FOREIGN KEY ("account_type") REFERENCES "account_types" ("name");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "accounts"."account_type" IS 'checking, savings, business or system';
+
+COMMENT ON COLUMN "account_types"."overdraft_limit" IS 'no limit if null';
+
+COMMENT ON COLUMN "account_types"."min_balance" IS 'applies only if overdraft is not allowed';
+
+COMMENT ON COLUMN "account_types"."withdrawals_per_month" IS 'no limit if null';
@@ -0,0 +1,29 @@
+CREATE TABLE "account_holders"
+(
+    "account_id"  bigint      NOT NULL,
+    "username"    varchar     NOT NULL,
+    "role"        varchar     NOT NULL,
+    "spend_limit" bigint,
+    "status"      varchar     NOT NULL DEFAULT 'invited',
+    "invited_by"  varchar     NOT NULL,
+    "created_at"  timestamptz NOT NULL DEFAULT (now()),
+    "accepted_at" timestamptz,
+    PRIMARY KEY ("account_id", "username")
+);
+
+ALTER TABLE "account_holders"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_holders"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "account_holders"
+    ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username");
+
+CREATE INDEX ON "account_holders" ("username");
+
+COMMENT ON COLUMN "account_holders"."role" IS 'owner, co-owner, viewer or signatory';
+
+COMMENT ON COLUMN "account_holders"."spend_limit" IS 'maximal amount of a single transfer of a signatory';
+
+COMMENT ON COLUMN "account_holders"."status" IS 'invited or active';
@@ -0,0 +1,19 @@
+ALTER TABLE "accounts"
+    ADD COLUMN "number" varchar;
+
+-- backfill existing accounts with random numbers, the check digits are
+-- computed by mod-97 of the basic number followed by the country (X = 33, B = 11)
+UPDATE "accounts"
+SET "number" = 'XB' || lpad((98 - (("generated"."bban" || '331100')::numeric % 97))::text, 2, '0') ||
+               "generated"."bban"
+FROM (SELECT "id", '1000' || lpad(floor(random() * 1e12)::bigint::text, 12, '0') AS "bban"
+      FROM "accounts") AS "generated"
+WHERE "accounts"."id" = "generated"."id";
+
+ALTER TABLE "accounts"
+    ALTER COLUMN "number" SET NOT NULL;
+
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "accounts_number_key" UNIQUE ("number");
+
+COMMENT ON COLUMN "accounts"."number" IS 'IBAN-style number with mod-97 check digits';
@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS pending_transfers
+    DROP COLUMN IF EXISTS payee_id;
+
+ALTER TABLE IF EXISTS transfers
+    DROP COLUMN IF EXISTS payee_id;
+
+DROP TABLE IF EXISTS payees;
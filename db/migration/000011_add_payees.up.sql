@@ -0,0 +1,34 @@
+CREATE TABLE "payees"
+(
+    "id"         bigserial PRIMARY KEY,
+    "owner"      varchar     NOT NULL,
+    "nickname"   varchar     NOT NULL,
+    "account_id" bigint      NOT NULL,
+    "reference"  varchar,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    UNIQUE ("owner", "nickname")
+);
+
+ALTER TABLE "payees"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "payees"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "transfers"
+    ADD COLUMN "payee_id" bigint;
+
+ALTER TABLE "transfers"
+    ADD FOREIGN KEY ("payee_id") REFERENCES "payees" ("id") ON DELETE SET NULL;
+
+ALTER TABLE "pending_transfers"
+    ADD COLUMN "payee_id" bigint;
+
+ALTER TABLE "pending_transfers"
+    ADD FOREIGN KEY ("payee_id") REFERENCES "payees" ("id") ON DELETE SET NULL;
+
+CREATE INDEX ON "transfers" ("payee_id");
+
+COMMENT ON COLUMN "payees"."reference" IS 'default reference of transfers to the payee';
+
+COMMENT ON COLUMN "transfers"."payee_id" IS 'payee the transfer was sent to, if any';
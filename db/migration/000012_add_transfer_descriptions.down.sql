@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS entries_search_idx;
+
+DROP INDEX IF EXISTS transfers_search_idx;
+
+ALTER TABLE IF EXISTS entries
+    DROP COLUMN IF EXISTS description;
+
+ALTER TABLE IF EXISTS pending_transfers
+    DROP COLUMN IF EXISTS remittance_info,
+    DROP COLUMN IF EXISTS memo,
+    DROP COLUMN IF EXISTS reference;
+
+ALTER TABLE IF EXISTS transfers
+    DROP COLUMN IF EXISTS remittance_info,
+    DROP COLUMN IF EXISTS memo,
+    DROP COLUMN IF EXISTS reference;
@@ -0,0 +1,36 @@
+ALTER TABLE "transfers"
+    ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers"
+    ADD COLUMN "memo" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers"
+    ADD COLUMN "remittance_info" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "pending_transfers"
+    ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "pending_transfers"
+    ADD COLUMN "memo" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "pending_transfers"
+    ADD COLUMN "remittance_info" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "entries"
+    ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+-- the expressions must match the search queries for the indexes to be used
+CREATE INDEX "transfers_search_idx" ON "transfers" USING GIN (
+    (to_tsvector('simple', "reference" || ' ' || "memo") ||
+     jsonb_to_tsvector('simple', "remittance_info", '["string"]'))
+    );
+
+CREATE INDEX "entries_search_idx" ON "entries" USING GIN (to_tsvector('simple', "description"));
+
+COMMENT ON COLUMN "transfers"."reference" IS 'reference visible to both parties';
+
+COMMENT ON COLUMN "transfers"."memo" IS 'free text note of the sender';
+
+COMMENT ON COLUMN "transfers"."remittance_info" IS 'structured remittance information';
+
+COMMENT ON COLUMN "entries"."description" IS 'copied from the reference and the memo of the transfer';
@@ -0,0 +1,42 @@
+CREATE TABLE "payment_requests"
+(
+    "id"              bigserial PRIMARY KEY,
+    "requester"       varchar     NOT NULL,
+    "payer"           varchar     NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "memo"            varchar     NOT NULL DEFAULT '',
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "from_account_id" bigint,
+    "transfer_id"     bigint,
+    "expires_at"      timestamptz NOT NULL,
+    "decided_at"      timestamptz,
+    "created_at"      timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("amount" > 0),
+    CHECK ("requester" <> "payer")
+);
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("requester") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("payer") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "payment_requests" ("requester");
+
+CREATE INDEX ON "payment_requests" ("payer");
+
+CREATE INDEX ON "payment_requests" ("status", "expires_at");
+
+COMMENT ON COLUMN "payment_requests"."status" IS 'pending, paid, declined or expired';
+
+COMMENT ON COLUMN "payment_requests"."from_account_id" IS 'account of the payer the request was paid from';
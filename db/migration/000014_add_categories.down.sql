@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS entries_account_id_created_at_idx;
+
+ALTER TABLE IF EXISTS entries
+    DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS category_rules;
+DROP TABLE IF EXISTS categories;
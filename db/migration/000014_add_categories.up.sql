@@ -0,0 +1,64 @@
+CREATE TABLE "categories"
+(
+    "id"         bigserial PRIMARY KEY,
+    "owner"      varchar,
+    "name"       varchar     NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    UNIQUE ("owner", "name")
+);
+
+CREATE TABLE "category_rules"
+(
+    "id"                      bigserial PRIMARY KEY,
+    "owner"                   varchar     NOT NULL,
+    "category_id"             bigint      NOT NULL,
+    "counterparty_account_id" bigint,
+    "pattern"                 varchar,
+    "priority"                int         NOT NULL DEFAULT 0,
+    "created_at"              timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("counterparty_account_id" IS NOT NULL OR "pattern" IS NOT NULL)
+);
+
+ALTER TABLE "categories"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "category_rules"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "category_rules"
+    ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "category_rules"
+    ADD FOREIGN KEY ("counterparty_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "entries"
+    ADD COLUMN "category_id" bigint;
+
+ALTER TABLE "entries"
+    ADD FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE SET NULL;
+
+-- the names of the system categories are unique as well
+CREATE UNIQUE INDEX ON "categories" ("name") WHERE "owner" IS NULL;
+
+CREATE INDEX ON "category_rules" ("owner");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+INSERT INTO "categories" ("name")
+VALUES ('salary'),
+       ('rent'),
+       ('groceries'),
+       ('utilities'),
+       ('transport'),
+       ('entertainment'),
+       ('savings'),
+       ('fees'),
+       ('interest');
+
+COMMENT ON COLUMN "categories"."owner" IS 'system categories have no owner';
+
+COMMENT ON COLUMN "category_rules"."counterparty_account_id" IS 'matches entries of journals moving money from or to the account';
+
+COMMENT ON COLUMN "category_rules"."pattern" IS 'case-insensitive substring of the entry description';
+
+COMMENT ON COLUMN "category_rules"."priority" IS 'the matching rule with the highest priority wins';
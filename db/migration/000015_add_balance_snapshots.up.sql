@@ -0,0 +1,18 @@
+CREATE TABLE "balance_snapshots"
+(
+    "account_id"    bigint      NOT NULL,
+    "snapshot_date" date        NOT NULL,
+    "snapshot_at"   timestamptz NOT NULL,
+    "balance"       bigint      NOT NULL,
+    "created_at"    timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("account_id", "snapshot_date")
+);
+
+ALTER TABLE "balance_snapshots"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "balance_snapshots" ("account_id", "snapshot_at");
+
+COMMENT ON COLUMN "balance_snapshots"."snapshot_at" IS 'end of the day, entries created since are not included';
+
+COMMENT ON COLUMN "balance_snapshots"."balance" IS 'end-of-day balance';
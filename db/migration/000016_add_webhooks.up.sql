@@ -0,0 +1,60 @@
+CREATE TABLE "webhook_endpoints"
+(
+    "id"         bigserial PRIMARY KEY,
+    "owner"      varchar     NOT NULL,
+    "url"        varchar     NOT NULL,
+    "events"     varchar[]   NOT NULL,
+    "secret"     varchar     NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries"
+(
+    "id"              bigserial PRIMARY KEY,
+    "endpoint_id"     bigint      NOT NULL,
+    "event_id"        varchar     NOT NULL,
+    "event_type"      varchar     NOT NULL,
+    "payload"         jsonb       NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "attempts"        integer     NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+    "delivered_at"    timestamptz,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_attempts"
+(
+    "id"          bigserial PRIMARY KEY,
+    "delivery_id" bigint      NOT NULL,
+    "status_code" integer     NOT NULL,
+    "error"       varchar     NOT NULL DEFAULT '',
+    "duration_ms" bigint      NOT NULL,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "webhook_endpoints"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_deliveries"
+    ADD FOREIGN KEY ("endpoint_id") REFERENCES "webhook_endpoints" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "webhook_attempts"
+    ADD FOREIGN KEY ("delivery_id") REFERENCES "webhook_deliveries" ("id") ON DELETE CASCADE;
+
+CREATE INDEX ON "webhook_endpoints" ("owner");
+
+CREATE INDEX ON "webhook_deliveries" ("endpoint_id");
+
+CREATE INDEX ON "webhook_deliveries" ("status", "next_attempt_at");
+
+CREATE INDEX ON "webhook_attempts" ("delivery_id");
+
+COMMENT ON COLUMN "webhook_endpoints"."events" IS 'subscribed event types';
+
+COMMENT ON COLUMN "webhook_endpoints"."secret" IS 'key of the HMAC-SHA256 payload signatures';
+
+COMMENT ON COLUMN "webhook_deliveries"."event_id" IS 'shared by the deliveries of the same event';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, delivered or dead';
+
+COMMENT ON COLUMN "webhook_attempts"."status_code" IS 'HTTP status of the response, 0 if none was received';
@@ -0,0 +1,18 @@
+CREATE TABLE "outbox"
+(
+    "id"            bigserial PRIMARY KEY,
+    "event_id"      varchar     NOT NULL,
+    "event_type"    varchar     NOT NULL,
+    "account_id"    bigint      NOT NULL,
+    "payload"       jsonb       NOT NULL,
+    "created_at"    timestamptz NOT NULL DEFAULT (now()),
+    "dispatched_at" timestamptz
+);
+
+CREATE INDEX ON "outbox" ("account_id", "id") WHERE "dispatched_at" IS NULL;
+
+CREATE INDEX ON "outbox" ("id") WHERE "dispatched_at" IS NULL;
+
+COMMENT ON COLUMN "outbox"."event_id" IS 'shared by the rows of an event affecting more accounts';
+
+COMMENT ON COLUMN "outbox"."account_id" IS 'events of an account are dispatched in the order of their IDs';
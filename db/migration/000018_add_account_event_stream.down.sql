@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS outbox_account_id_id_idx;
+
+CREATE INDEX ON "outbox" ("account_id", "id") WHERE "dispatched_at" IS NULL;
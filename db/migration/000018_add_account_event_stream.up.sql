@@ -0,0 +1,4 @@
+-- the events of an account are kept to be replayed to its event streams
+DROP INDEX IF EXISTS outbox_account_id_id_idx;
+
+CREATE INDEX ON "outbox" ("account_id", "id");
@@ -0,0 +1,45 @@
+CREATE TABLE "audit_log"
+(
+    "id"         bigserial PRIMARY KEY,
+    "actor"      varchar     NOT NULL,
+    "ip"         varchar     NOT NULL,
+    "route"      varchar     NOT NULL,
+    "request_id" varchar     NOT NULL,
+    "action"     varchar     NOT NULL,
+    "entity"     varchar     NOT NULL,
+    "entity_id"  varchar     NOT NULL,
+    "before"     json        NOT NULL,
+    "after"      json        NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    "prev_hash"  varchar     NOT NULL,
+    "hash"       varchar     NOT NULL UNIQUE
+);
+
+CREATE INDEX ON "audit_log" ("entity", "entity_id");
+
+CREATE INDEX ON "audit_log" ("actor", "created_at");
+
+CREATE INDEX ON "audit_log" ("request_id");
+
+CREATE INDEX ON "audit_log" ("created_at");
+
+COMMENT ON COLUMN "audit_log"."actor" IS 'username of the authenticated user, empty if anonymous';
+
+COMMENT ON COLUMN "audit_log"."before" IS 'snapshot of the entity before the change, null if created';
+
+COMMENT ON COLUMN "audit_log"."after" IS 'snapshot of the entity after the change, null if deleted';
+
+COMMENT ON COLUMN "audit_log"."hash" IS 'SHA-256 of the previous hash and the content of the row';
+
+CREATE FUNCTION "audit_log_append_only"() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_append_only"
+    BEFORE UPDATE OR DELETE
+    ON "audit_log"
+    FOR EACH ROW
+EXECUTE PROCEDURE "audit_log_append_only"();
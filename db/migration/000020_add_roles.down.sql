@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS adjustments;
+
+ALTER TABLE IF EXISTS accounts
+    DROP COLUMN IF EXISTS frozen_reason,
+    DROP COLUMN IF EXISTS frozen_at;
+
+DROP INDEX IF EXISTS users_role_idx;
+
+ALTER TABLE IF EXISTS users
+    DROP COLUMN IF EXISTS role;
@@ -0,0 +1,38 @@
+ALTER TABLE "users"
+    ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer',
+    ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('customer', 'support', 'admin', 'auditor'));
+
+ALTER TABLE "accounts"
+    ADD COLUMN "frozen_at"     timestamptz,
+    ADD COLUMN "frozen_reason" varchar NOT NULL DEFAULT '';
+
+CREATE TABLE "adjustments"
+(
+    "id"         bigserial PRIMARY KEY,
+    "account_id" bigint      NOT NULL,
+    "journal_id" bigint      NOT NULL,
+    "amount"     bigint      NOT NULL,
+    "reason"     varchar     NOT NULL,
+    "created_by" varchar     NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("amount" <> 0),
+    CHECK ("reason" <> '')
+);
+
+ALTER TABLE "adjustments"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "adjustments"
+    ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
+CREATE INDEX ON "adjustments" ("account_id");
+
+CREATE INDEX ON "users" ("role");
+
+COMMENT ON COLUMN "users"."role" IS 'customer, support, admin or auditor';
+
+COMMENT ON COLUMN "accounts"."frozen_at" IS 'frozen accounts can not be debited, not frozen if null';
+
+COMMENT ON COLUMN "adjustments"."amount" IS 'credits the account if positive, debits it if negative';
+
+COMMENT ON COLUMN "adjustments"."created_by" IS 'administrator who posted the adjustment';
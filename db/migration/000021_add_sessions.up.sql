@@ -0,0 +1,29 @@
+CREATE TABLE "sessions"
+(
+    "id"                 bigserial PRIMARY KEY,
+    "family_id"          varchar     NOT NULL,
+    "username"           varchar     NOT NULL,
+    "refresh_token_hash" varchar     NOT NULL,
+    "user_agent"         varchar     NOT NULL,
+    "client_ip"          varchar     NOT NULL,
+    "expires_at"         timestamptz NOT NULL,
+    "rotated_at"         timestamptz,
+    "revoked"            boolean     NOT NULL DEFAULT false,
+    "created_at"         timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "sessions"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "sessions"
+    ADD CONSTRAINT "sessions_refresh_token_hash_key" UNIQUE ("refresh_token_hash");
+
+CREATE INDEX ON "sessions" ("family_id");
+
+CREATE INDEX ON "sessions" ("username", "expires_at");
+
+COMMENT ON COLUMN "sessions"."family_id" IS 'shared by the sessions rotated from the same login';
+
+COMMENT ON COLUMN "sessions"."refresh_token_hash" IS 'SHA-256 of the refresh token';
+
+COMMENT ON COLUMN "sessions"."rotated_at" IS 'the refresh token was exchanged for a new one, its reuse revokes the family';
@@ -0,0 +1,30 @@
+ALTER TABLE "users"
+    ADD COLUMN "email_verified_at" timestamptz;
+
+CREATE TABLE "user_tokens"
+(
+    "id"         bigserial PRIMARY KEY,
+    "username"   varchar     NOT NULL,
+    "purpose"    varchar     NOT NULL,
+    "token_hash" varchar     NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at"    timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CONSTRAINT "user_tokens_purpose_check" CHECK ("purpose" IN ('verify_email', 'reset_password'))
+);
+
+ALTER TABLE "user_tokens"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "user_tokens"
+    ADD CONSTRAINT "user_tokens_token_hash_key" UNIQUE ("token_hash");
+
+CREATE INDEX ON "user_tokens" ("expires_at");
+
+COMMENT ON COLUMN "users"."email_verified_at" IS 'the email address was confirmed by a verification token';
+
+COMMENT ON COLUMN "user_tokens"."purpose" IS 'verify_email or reset_password';
+
+COMMENT ON COLUMN "user_tokens"."token_hash" IS 'SHA-256 of the token sent by email';
+
+COMMENT ON COLUMN "user_tokens"."used_at" IS 'the token can be used only once';
@@ -0,0 +1,13 @@
+DELETE
+FROM user_tokens
+WHERE purpose = 'login_challenge';
+
+ALTER TABLE IF EXISTS "user_tokens"
+    DROP CONSTRAINT IF EXISTS "user_tokens_purpose_check";
+
+ALTER TABLE IF EXISTS "user_tokens"
+    ADD CONSTRAINT "user_tokens_purpose_check" CHECK ("purpose" IN ('verify_email', 'reset_password'));
+
+DROP TABLE IF EXISTS recovery_codes;
+
+DROP TABLE IF EXISTS totp_credentials;
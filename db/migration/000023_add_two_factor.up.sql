@@ -0,0 +1,42 @@
+CREATE TABLE "totp_credentials"
+(
+    "username"       varchar PRIMARY KEY,
+    "secret"         varchar     NOT NULL,
+    "confirmed_at"   timestamptz,
+    "last_used_step" bigint      NOT NULL DEFAULT 0,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "totp_credentials"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+CREATE TABLE "recovery_codes"
+(
+    "id"         bigserial PRIMARY KEY,
+    "username"   varchar     NOT NULL,
+    "code_hash"  varchar     NOT NULL,
+    "used_at"    timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "recovery_codes"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "recovery_codes"
+    ADD CONSTRAINT "recovery_codes_username_code_hash_key" UNIQUE ("username", "code_hash");
+
+ALTER TABLE "user_tokens"
+    DROP CONSTRAINT "user_tokens_purpose_check";
+
+ALTER TABLE "user_tokens"
+    ADD CONSTRAINT "user_tokens_purpose_check" CHECK ("purpose" IN ('verify_email', 'reset_password', 'login_challenge'));
+
+COMMENT ON COLUMN "totp_credentials"."secret" IS 'base32 TOTP secret shared with the authenticator app';
+
+COMMENT ON COLUMN "totp_credentials"."confirmed_at" IS 'two-factor authentication is enabled once the first code is confirmed';
+
+COMMENT ON COLUMN "totp_credentials"."last_used_step" IS 'time step of the last accepted code, older codes are rejected as replays';
+
+COMMENT ON COLUMN "recovery_codes"."code_hash" IS 'SHA-256 of the normalized recovery code';
+
+COMMENT ON COLUMN "user_tokens"."purpose" IS 'verify_email, reset_password or login_challenge';
@@ -0,0 +1,20 @@
+CREATE TABLE "login_throttles"
+(
+    "scope"          varchar     NOT NULL,
+    "subject"        varchar     NOT NULL,
+    "failures"       int         NOT NULL DEFAULT 0,
+    "last_failed_at" timestamptz NOT NULL DEFAULT (now()),
+    "locked_until"   timestamptz,
+    PRIMARY KEY ("scope", "subject"),
+    CONSTRAINT "login_throttles_scope_check" CHECK ("scope" IN ('username', 'ip'))
+);
+
+CREATE INDEX ON "login_throttles" ("last_failed_at");
+
+COMMENT ON COLUMN "login_throttles"."scope" IS 'username or ip';
+
+COMMENT ON COLUMN "login_throttles"."subject" IS 'the attempted username or the client IP, not necessarily an existing user';
+
+COMMENT ON COLUMN "login_throttles"."failures" IS 'consecutive failed logins since the last lockout';
+
+COMMENT ON COLUMN "login_throttles"."locked_until" IS 'logins are rejected until then';
@@ -0,0 +1,32 @@
+CREATE TABLE "api_keys"
+(
+    "id"           bigserial PRIMARY KEY,
+    "username"     varchar     NOT NULL,
+    "name"         varchar     NOT NULL,
+    "prefix"       varchar     NOT NULL,
+    "secret_hash"  varchar     NOT NULL,
+    "scopes"       varchar[]   NOT NULL,
+    "allowed_ips"  varchar[]   NOT NULL DEFAULT '{}',
+    "expires_at"   timestamptz,
+    "last_used_at" timestamptz,
+    "revoked_at"   timestamptz,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "api_keys"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON DELETE CASCADE;
+
+ALTER TABLE "api_keys"
+    ADD CONSTRAINT "api_keys_prefix_key" UNIQUE ("prefix");
+
+CREATE INDEX ON "api_keys" ("username");
+
+COMMENT ON COLUMN "api_keys"."username" IS 'the key acts on behalf of the user, a service has a user of its own';
+
+COMMENT ON COLUMN "api_keys"."prefix" IS 'public identifier of the key, part of the key itself';
+
+COMMENT ON COLUMN "api_keys"."secret_hash" IS 'SHA-256 of the secret part of the key';
+
+COMMENT ON COLUMN "api_keys"."scopes" IS 'granted scopes such as accounts:read or transfers:write';
+
+COMMENT ON COLUMN "api_keys"."allowed_ips" IS 'IP addresses and CIDR ranges the key may be used from, empty allows any';
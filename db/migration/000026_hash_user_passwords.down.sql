@@ -0,0 +1,2 @@
+-- the hashed passwords can not be restored
+COMMENT ON COLUMN "users"."hashed_password" IS NULL;
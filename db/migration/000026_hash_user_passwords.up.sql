@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pgcrypto;
+
+-- the passwords have been stored as they are, hash them with bcrypt,
+-- they are rehashed with the configured algorithm on the next login;
+-- a plaintext password may start with $ too, so only the real hash prefixes are skipped
+UPDATE "users"
+SET "hashed_password" = crypt("hashed_password", gen_salt('bf', 12))
+WHERE "hashed_password" !~ '^\$(2[aby]|argon2id)\$';
+
+COMMENT ON COLUMN "users"."hashed_password" IS 'PHC string of the Argon2id hash or bcrypt hash, with the parameters and the salt';
@@ -0,0 +1,29 @@
+CREATE OR REPLACE FUNCTION "audit_log_append_only"() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+-- the records not chained yet can not be kept without a hash
+ALTER TABLE "audit_log"
+    DISABLE TRIGGER "audit_log_append_only";
+
+DELETE
+FROM "audit_log"
+WHERE "chain_seq" = 0;
+
+ALTER TABLE "audit_log"
+    ENABLE TRIGGER "audit_log_append_only";
+
+DROP INDEX IF EXISTS "audit_log_chain_seq_idx";
+
+DROP INDEX IF EXISTS "audit_log_hash_idx";
+
+DROP INDEX IF EXISTS "audit_log_id_idx";
+
+ALTER TABLE "audit_log"
+    DROP COLUMN "chain_seq",
+    ALTER COLUMN "prev_hash" DROP DEFAULT,
+    ALTER COLUMN "hash" DROP DEFAULT,
+    ADD CONSTRAINT "audit_log_hash_key" UNIQUE ("hash");
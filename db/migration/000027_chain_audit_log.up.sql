@@ -0,0 +1,45 @@
+-- the audit records are appended without a hash and chained later by a single job,
+-- so the audited writers do not wait for each other
+ALTER TABLE "audit_log"
+    ADD COLUMN "chain_seq" bigint NOT NULL DEFAULT 0,
+    ALTER COLUMN "prev_hash" SET DEFAULT '',
+    ALTER COLUMN "hash" SET DEFAULT '',
+    DROP CONSTRAINT "audit_log_hash_key";
+
+ALTER TABLE "audit_log"
+    DISABLE TRIGGER "audit_log_append_only";
+
+-- the existing records are chained in the order of their IDs
+UPDATE "audit_log"
+SET "chain_seq" = "id";
+
+ALTER TABLE "audit_log"
+    ENABLE TRIGGER "audit_log_append_only";
+
+CREATE UNIQUE INDEX ON "audit_log" ("chain_seq") WHERE "chain_seq" > 0;
+
+CREATE UNIQUE INDEX ON "audit_log" ("hash") WHERE "hash" <> '';
+
+CREATE INDEX ON "audit_log" ("id") WHERE "chain_seq" = 0;
+
+COMMENT ON COLUMN "audit_log"."chain_seq" IS 'position of the record in the hash chain, 0 until chained';
+
+-- a record can be chained once, its content can never be changed
+CREATE OR REPLACE FUNCTION "audit_log_append_only"() RETURNS trigger AS
+$$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND OLD."chain_seq" = 0
+        AND NEW."chain_seq" > 0
+        AND NEW."hash" <> ''
+        AND (NEW."id", NEW."actor", NEW."ip", NEW."route", NEW."request_id", NEW."action", NEW."entity",
+             NEW."entity_id", NEW."before"::text, NEW."after"::text, NEW."created_at")
+            IS NOT DISTINCT FROM
+            (OLD."id", OLD."actor", OLD."ip", OLD."route", OLD."request_id", OLD."action", OLD."entity",
+             OLD."entity_id", OLD."before"::text, OLD."after"::text, OLD."created_at") THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
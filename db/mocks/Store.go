@@ -4,6 +4,8 @@ package mocks
 
 import (
 	context "context"
+	sql "database/sql"
+	time "time"
 
 	db "github.com/chutommy/simple-bank/db/sqlc"
 	mock "github.com/stretchr/testify/mock"
@@ -14,19 +16,3726 @@ type Store struct {
 	mock.Mock
 }
 
+// AcceptAccountHolder provides a mock function with given fields: ctx, arg
+func (_m *Store) AcceptAccountHolder(ctx context.Context, arg db.AcceptAccountHolderParams) (db.AccountHolder, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.AccountHolder
+	if rf, ok := ret.Get(0).(func(context.Context, db.AcceptAccountHolderParams) db.AccountHolder); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.AccountHolder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.AcceptAccountHolderParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccrueInterestTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) AccrueInterestTx(_a0 context.Context, _a1 time.Time) (db.AccrueInterestTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.AccrueInterestTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) db.AccrueInterestTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.AccrueInterestTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AddAccountBalance provides a mock function with given fields: ctx, arg
 func (_m *Store) AddAccountBalance(ctx context.Context, arg db.AddAccountBalanceParams) (db.Account, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 db.Account
-	if rf, ok := ret.Get(0).(func(context.Context, db.AddAccountBalanceParams) db.Account); ok {
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, db.AddAccountBalanceParams) db.Account); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.AddAccountBalanceParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AdjustAccountTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) AdjustAccountTx(_a0 context.Context, _a1 db.AdjustAccountTxParams) (db.AdjustAccountTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.AdjustAccountTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, db.AdjustAccountTxParams) db.AdjustAccountTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.AdjustAccountTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.AdjustAccountTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApprovePendingTransferTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) ApprovePendingTransferTx(_a0 context.Context, _a1 db.DecidePendingTransferTxParams) (db.ApprovePendingTransferTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.ApprovePendingTransferTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, db.DecidePendingTransferTxParams) db.ApprovePendingTransferTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.ApprovePendingTransferTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.DecidePendingTransferTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BatchTransferTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) BatchTransferTx(_a0 context.Context, _a1 db.BatchTransferTxParams) (db.BatchTransferTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.BatchTransferTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, db.BatchTransferTxParams) db.BatchTransferTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.BatchTransferTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.BatchTransferTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CapitalizeInterestTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) CapitalizeInterestTx(_a0 context.Context, _a1 time.Time) (db.CapitalizeInterestTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.CapitalizeInterestTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) db.CapitalizeInterestTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.CapitalizeInterestTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CategorizeJournalEntries provides a mock function with given fields: ctx, journalID
+func (_m *Store) CategorizeJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]db.Entry, error) {
+	ret := _m.Called(ctx, journalID)
+
+	var r0 []db.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, sql.NullInt64) []db.Entry); ok {
+		r0 = rf(ctx, journalID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, sql.NullInt64) error); ok {
+		r1 = rf(ctx, journalID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChainAuditLog provides a mock function with given fields: ctx, arg
+func (_m *Store) ChainAuditLog(ctx context.Context, arg db.ChainAuditLogParams) (db.AuditLog, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.AuditLog
+	if rf, ok := ret.Get(0).(func(context.Context, db.ChainAuditLogParams) db.AuditLog); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.AuditLog)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ChainAuditLogParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChainAuditLogTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) ChainAuditLogTx(_a0 context.Context, _a1 int32) (int, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, int32) int); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangePasswordTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) ChangePasswordTx(_a0 context.Context, _a1 db.ChangePasswordTxParams) (db.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.User
+	if rf, ok := ret.Get(0).(func(context.Context, db.ChangePasswordTxParams) db.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ChangePasswordTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimOutboxEvents provides a mock function with given fields: ctx, limit
+func (_m *Store) ClaimOutboxEvents(ctx context.Context, limit int32) ([]db.Outbox, error) {
+	ret := _m.Called(ctx, limit)
+
+	var r0 []db.Outbox
+	if rf, ok := ret.Get(0).(func(context.Context, int32) []db.Outbox); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Outbox)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimWebhookDeliveries provides a mock function with given fields: ctx, arg
+func (_m *Store) ClaimWebhookDeliveries(ctx context.Context, arg db.ClaimWebhookDeliveriesParams) ([]db.ClaimWebhookDeliveriesRow, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.ClaimWebhookDeliveriesRow
+	if rf, ok := ret.Get(0).(func(context.Context, db.ClaimWebhookDeliveriesParams) []db.ClaimWebhookDeliveriesRow); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ClaimWebhookDeliveriesRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ClaimWebhookDeliveriesParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CloseAccountTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) CloseAccountTx(_a0 context.Context, _a1 int64) (db.Account, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Account); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompleteBatch provides a mock function with given fields: ctx, arg
+func (_m *Store) CompleteBatch(ctx context.Context, arg db.CompleteBatchParams) (db.Batch, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Batch
+	if rf, ok := ret.Get(0).(func(context.Context, db.CompleteBatchParams) db.Batch); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Batch)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CompleteBatchParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConfirmTOTPCredential provides a mock function with given fields: ctx, username
+func (_m *Store) ConfirmTOTPCredential(ctx context.Context, username string) (db.TotpCredential, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 db.TotpCredential
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.TotpCredential); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(db.TotpCredential)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountAccountDebitsSince provides a mock function with given fields: ctx, arg
+func (_m *Store) CountAccountDebitsSince(ctx context.Context, arg db.CountAccountDebitsSinceParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.CountAccountDebitsSinceParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CountAccountDebitsSinceParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountTransfersSince provides a mock function with given fields: ctx, arg
+func (_m *Store) CountTransfersSince(ctx context.Context, arg db.CountTransfersSinceParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.CountTransfersSinceParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CountTransfersSinceParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountUnusedRecoveryCodes provides a mock function with given fields: ctx, username
+func (_m *Store) CountUnusedRecoveryCodes(ctx context.Context, username string) (int64, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAPIKey provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateAPIKey(ctx context.Context, arg db.CreateAPIKeyParams) (db.APIKey, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.APIKey
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateAPIKeyParams) db.APIKey); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.APIKey)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateAPIKeyParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAccount provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateAccount(ctx context.Context, arg db.CreateAccountParams) (db.Account, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateAccountParams) db.Account); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateAccountParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAccountHolder provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateAccountHolder(ctx context.Context, arg db.CreateAccountHolderParams) (db.AccountHolder, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.AccountHolder
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateAccountHolderParams) db.AccountHolder); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.AccountHolder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateAccountHolderParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAdjustment provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateAdjustment(ctx context.Context, arg db.CreateAdjustmentParams) (db.Adjustment, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Adjustment
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateAdjustmentParams) db.Adjustment); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Adjustment)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateAdjustmentParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateApprovalPolicyApprover provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateApprovalPolicyApprover(ctx context.Context, arg db.CreateApprovalPolicyApproverParams) (db.ApprovalPolicyApprover, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.ApprovalPolicyApprover
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateApprovalPolicyApproverParams) db.ApprovalPolicyApprover); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.ApprovalPolicyApprover)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateApprovalPolicyApproverParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAuditLog provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateAuditLog(ctx context.Context, arg db.CreateAuditLogParams) (db.AuditLog, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.AuditLog
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateAuditLogParams) db.AuditLog); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.AuditLog)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateAuditLogParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateBalanceSnapshots provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateBalanceSnapshots(ctx context.Context, arg db.CreateBalanceSnapshotsParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateBalanceSnapshotsParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateBalanceSnapshotsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateBatch provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateBatch(ctx context.Context, arg db.CreateBatchParams) (db.Batch, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Batch
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateBatchParams) db.Batch); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Batch)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateBatchParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateBatchLeg provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateBatchLeg(ctx context.Context, arg db.CreateBatchLegParams) (db.BatchLeg, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.BatchLeg
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateBatchLegParams) db.BatchLeg); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.BatchLeg)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateBatchLegParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateCategory provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateCategory(ctx context.Context, arg db.CreateCategoryParams) (db.Category, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Category
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateCategoryParams) db.Category); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Category)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateCategoryParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateCategoryRule provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateCategoryRule(ctx context.Context, arg db.CreateCategoryRuleParams) (db.CategoryRule, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.CategoryRule
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateCategoryRuleParams) db.CategoryRule); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.CategoryRule)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateCategoryRuleParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateEntry provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateEntry(ctx context.Context, arg db.CreateEntryParams) (db.Entry, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateEntryParams) db.Entry); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Entry)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateEntryParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFeeSchedule provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateFeeSchedule(ctx context.Context, arg db.CreateFeeScheduleParams) (db.FeeSchedule, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.FeeSchedule
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateFeeScheduleParams) db.FeeSchedule); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.FeeSchedule)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateFeeScheduleParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFeeTier provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateFeeTier(ctx context.Context, arg db.CreateFeeTierParams) (db.FeeTier, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.FeeTier
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateFeeTierParams) db.FeeTier); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.FeeTier)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateFeeTierParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateInterestAccrual provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateInterestAccrual(ctx context.Context, arg db.CreateInterestAccrualParams) (db.InterestAccrual, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.InterestAccrual
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateInterestAccrualParams) db.InterestAccrual); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.InterestAccrual)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateInterestAccrualParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateInterestCapitalization provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateInterestCapitalization(ctx context.Context, arg db.CreateInterestCapitalizationParams) (db.InterestCapitalization, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.InterestCapitalization
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateInterestCapitalizationParams) db.InterestCapitalization); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.InterestCapitalization)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateInterestCapitalizationParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateJournal provides a mock function with given fields: ctx
+func (_m *Store) CreateJournal(ctx context.Context) (db.Journal, error) {
+	ret := _m.Called(ctx)
+
+	var r0 db.Journal
+	if rf, ok := ret.Get(0).(func(context.Context) db.Journal); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(db.Journal)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateLoginThrottle provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateLoginThrottle(ctx context.Context, arg db.CreateLoginThrottleParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateLoginThrottleParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateOutboxEvent provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) (db.Outbox, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Outbox
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateOutboxEventParams) db.Outbox); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Outbox)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateOutboxEventParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePayee provides a mock function with given fields: ctx, arg
+func (_m *Store) CreatePayee(ctx context.Context, arg db.CreatePayeeParams) (db.Payee, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Payee
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreatePayeeParams) db.Payee); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Payee)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreatePayeeParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePaymentRequest provides a mock function with given fields: ctx, arg
+func (_m *Store) CreatePaymentRequest(ctx context.Context, arg db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.PaymentRequest
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreatePaymentRequestParams) db.PaymentRequest); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.PaymentRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreatePaymentRequestParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePendingTransfer provides a mock function with given fields: ctx, arg
+func (_m *Store) CreatePendingTransfer(ctx context.Context, arg db.CreatePendingTransferParams) (db.PendingTransfer, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreatePendingTransferParams) db.PendingTransfer); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.PendingTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreatePendingTransferParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateRecoveryCode provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateRecoveryCode(ctx context.Context, arg db.CreateRecoveryCodeParams) (db.RecoveryCode, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.RecoveryCode
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateRecoveryCodeParams) db.RecoveryCode); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.RecoveryCode)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateRecoveryCodeParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateSession provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateSession(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Session
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateSessionParams) db.Session); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Session)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateSessionParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateTransfer provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateTransfer(ctx context.Context, arg db.CreateTransferParams) (db.Transfer, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Transfer
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateTransferParams) db.Transfer); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Transfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateTransferParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUser provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.User
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateUserParams) db.User); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateUserParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUserToken provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateUserToken(ctx context.Context, arg db.CreateUserTokenParams) (db.UserToken, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.UserToken
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateUserTokenParams) db.UserToken); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.UserToken)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateUserTokenParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWebhookAttempt provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateWebhookAttempt(ctx context.Context, arg db.CreateWebhookAttemptParams) (db.WebhookAttempt, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.WebhookAttempt
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateWebhookAttemptParams) db.WebhookAttempt); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.WebhookAttempt)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateWebhookAttemptParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWebhookDeliveries provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateWebhookDeliveries(ctx context.Context, arg db.CreateWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateWebhookDeliveriesParams) []db.WebhookDelivery); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WebhookDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateWebhookDeliveriesParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWebhookEndpoint provides a mock function with given fields: ctx, arg
+func (_m *Store) CreateWebhookEndpoint(ctx context.Context, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.WebhookEndpoint
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateWebhookEndpointParams) db.WebhookEndpoint); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.WebhookEndpoint)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateWebhookEndpointParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DecidePaymentRequest provides a mock function with given fields: ctx, arg
+func (_m *Store) DecidePaymentRequest(ctx context.Context, arg db.DecidePaymentRequestParams) (db.PaymentRequest, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.PaymentRequest
+	if rf, ok := ret.Get(0).(func(context.Context, db.DecidePaymentRequestParams) db.PaymentRequest); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.PaymentRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.DecidePaymentRequestParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DecidePendingTransfer provides a mock function with given fields: ctx, arg
+func (_m *Store) DecidePendingTransfer(ctx context.Context, arg db.DecidePendingTransferParams) (db.PendingTransfer, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, db.DecidePendingTransferParams) db.PendingTransfer); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.PendingTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.DecidePendingTransferParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeclinePaymentRequestTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) DeclinePaymentRequestTx(_a0 context.Context, _a1 db.DeclinePaymentRequestTxParams) (db.PaymentRequest, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.PaymentRequest
+	if rf, ok := ret.Get(0).(func(context.Context, db.DeclinePaymentRequestTxParams) db.PaymentRequest); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.PaymentRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.DeclinePaymentRequestTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteAccount provides a mock function with given fields: ctx, id
+func (_m *Store) DeleteAccount(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteAccountHolder provides a mock function with given fields: ctx, arg
+func (_m *Store) DeleteAccountHolder(ctx context.Context, arg db.DeleteAccountHolderParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.DeleteAccountHolderParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.DeleteAccountHolderParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteApprovalPolicy provides a mock function with given fields: ctx, accountID
+func (_m *Store) DeleteApprovalPolicy(ctx context.Context, accountID int64) error {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteApprovalPolicyApprovers provides a mock function with given fields: ctx, accountID
+func (_m *Store) DeleteApprovalPolicyApprovers(ctx context.Context, accountID int64) error {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteCategory provides a mock function with given fields: ctx, id
+func (_m *Store) DeleteCategory(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteCategoryRule provides a mock function with given fields: ctx, id
+func (_m *Store) DeleteCategoryRule(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteEntry provides a mock function with given fields: ctx, id
+func (_m *Store) DeleteEntry(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteExpiredSessions provides a mock function with given fields: ctx, expiresAt
+func (_m *Store) DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) (int64, error) {
+	ret := _m.Called(ctx, expiresAt)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, expiresAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExpiredUserTokens provides a mock function with given fields: ctx, expiresAt
+func (_m *Store) DeleteExpiredUserTokens(ctx context.Context, expiresAt time.Time) (int64, error) {
+	ret := _m.Called(ctx, expiresAt)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, expiresAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteFeeSchedule provides a mock function with given fields: ctx, id
+func (_m *Store) DeleteFeeSchedule(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteInterestRate provides a mock function with given fields: ctx, arg
+func (_m *Store) DeleteInterestRate(ctx context.Context, arg db.DeleteInterestRateParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, db.DeleteInterestRateParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteLoginThrottle provides a mock function with given fields: ctx, arg
+func (_m *Store) DeleteLoginThrottle(ctx context.Context, arg db.DeleteLoginThrottleParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, db.DeleteLoginThrottleParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePayee provides a mock function with given fields: ctx, id
+func (_m *Store) DeletePayee(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRecoveryCodes provides a mock function with given fields: ctx, username
+func (_m *Store) DeleteRecoveryCodes(ctx context.Context, username string) (int64, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteStaleLoginThrottles provides a mock function with given fields: ctx, lastFailedAt
+func (_m *Store) DeleteStaleLoginThrottles(ctx context.Context, lastFailedAt time.Time) (int64, error) {
+	ret := _m.Called(ctx, lastFailedAt)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, lastFailedAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, lastFailedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteTOTPCredential provides a mock function with given fields: ctx, username
+func (_m *Store) DeleteTOTPCredential(ctx context.Context, username string) (int64, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteTransfer provides a mock function with given fields: ctx, id
+func (_m *Store) DeleteTransfer(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUser provides a mock function with given fields: ctx, username
+func (_m *Store) DeleteUser(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteWebhookEndpoint provides a mock function with given fields: ctx, id
+func (_m *Store) DeleteWebhookEndpoint(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DisableTOTPTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) DisableTOTPTx(_a0 context.Context, _a1 db.TOTPTxParams) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, db.TOTPTxParams) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DispatchOutboxTx provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Store) DispatchOutboxTx(_a0 context.Context, _a1 int32, _a2 db.DispatchFunc) (int, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, int32, db.DispatchFunc) int); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32, db.DispatchFunc) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnableTOTPTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) EnableTOTPTx(_a0 context.Context, _a1 db.TOTPTxParams) (db.TotpCredential, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.TotpCredential
+	if rf, ok := ret.Get(0).(func(context.Context, db.TOTPTxParams) db.TotpCredential); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.TotpCredential)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.TOTPTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpirePaymentRequests provides a mock function with given fields: ctx
+func (_m *Store) ExpirePaymentRequests(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpirePendingTransfers provides a mock function with given fields: ctx
+func (_m *Store) ExpirePendingTransfers(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FreezeAccount provides a mock function with given fields: ctx, arg
+func (_m *Store) FreezeAccount(ctx context.Context, arg db.FreezeAccountParams) (db.Account, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, db.FreezeAccountParams) db.Account); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.FreezeAccountParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAPIKey provides a mock function with given fields: ctx, id
+func (_m *Store) GetAPIKey(ctx context.Context, id int64) (db.APIKey, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.APIKey
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.APIKey); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.APIKey)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAPIKeyByPrefix provides a mock function with given fields: ctx, prefix
+func (_m *Store) GetAPIKeyByPrefix(ctx context.Context, prefix string) (db.APIKey, error) {
+	ret := _m.Called(ctx, prefix)
+
+	var r0 db.APIKey
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.APIKey); ok {
+		r0 = rf(ctx, prefix)
+	} else {
+		r0 = ret.Get(0).(db.APIKey)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccount provides a mock function with given fields: ctx, id
+func (_m *Store) GetAccount(ctx context.Context, id int64) (db.Account, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Account); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountAnalyticsByCategory provides a mock function with given fields: ctx, arg
+func (_m *Store) GetAccountAnalyticsByCategory(ctx context.Context, arg db.GetAccountAnalyticsByCategoryParams) ([]db.GetAccountAnalyticsByCategoryRow, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.GetAccountAnalyticsByCategoryRow
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetAccountAnalyticsByCategoryParams) []db.GetAccountAnalyticsByCategoryRow); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.GetAccountAnalyticsByCategoryRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetAccountAnalyticsByCategoryParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountAnalyticsByMonth provides a mock function with given fields: ctx, arg
+func (_m *Store) GetAccountAnalyticsByMonth(ctx context.Context, arg db.GetAccountAnalyticsByMonthParams) ([]db.GetAccountAnalyticsByMonthRow, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.GetAccountAnalyticsByMonthRow
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetAccountAnalyticsByMonthParams) []db.GetAccountAnalyticsByMonthRow); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.GetAccountAnalyticsByMonthRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetAccountAnalyticsByMonthParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountBalanceAsOf provides a mock function with given fields: ctx, arg
+func (_m *Store) GetAccountBalanceAsOf(ctx context.Context, arg db.GetAccountBalanceAsOfParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetAccountBalanceAsOfParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetAccountBalanceAsOfParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountBalanceHistory provides a mock function with given fields: ctx, arg
+func (_m *Store) GetAccountBalanceHistory(ctx context.Context, arg db.GetAccountBalanceHistoryParams) ([]db.GetAccountBalanceHistoryRow, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.GetAccountBalanceHistoryRow
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetAccountBalanceHistoryParams) []db.GetAccountBalanceHistoryRow); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.GetAccountBalanceHistoryRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetAccountBalanceHistoryParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountByNumber provides a mock function with given fields: ctx, number
+func (_m *Store) GetAccountByNumber(ctx context.Context, number string) (db.Account, error) {
+	ret := _m.Called(ctx, number)
+
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.Account); ok {
+		r0 = rf(ctx, number)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountForUpdate provides a mock function with given fields: ctx, id
+func (_m *Store) GetAccountForUpdate(ctx context.Context, id int64) (db.Account, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Account); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountHolder provides a mock function with given fields: ctx, arg
+func (_m *Store) GetAccountHolder(ctx context.Context, arg db.GetAccountHolderParams) (db.AccountHolder, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.AccountHolder
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetAccountHolderParams) db.AccountHolder); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.AccountHolder)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetAccountHolderParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAccountType provides a mock function with given fields: ctx, name
+func (_m *Store) GetAccountType(ctx context.Context, name string) (db.AccountType, error) {
+	ret := _m.Called(ctx, name)
+
+	var r0 db.AccountType
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.AccountType); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Get(0).(db.AccountType)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApprovalPolicy provides a mock function with given fields: ctx, accountID
+func (_m *Store) GetApprovalPolicy(ctx context.Context, accountID int64) (db.ApprovalPolicy, error) {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 db.ApprovalPolicy
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.ApprovalPolicy); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		r0 = ret.Get(0).(db.ApprovalPolicy)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetApprovalPolicyApprover provides a mock function with given fields: ctx, arg
+func (_m *Store) GetApprovalPolicyApprover(ctx context.Context, arg db.GetApprovalPolicyApproverParams) (db.ApprovalPolicyApprover, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.ApprovalPolicyApprover
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetApprovalPolicyApproverParams) db.ApprovalPolicyApprover); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.ApprovalPolicyApprover)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetApprovalPolicyApproverParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBatch provides a mock function with given fields: ctx, id
+func (_m *Store) GetBatch(ctx context.Context, id int64) (db.Batch, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Batch
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Batch); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Batch)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCategory provides a mock function with given fields: ctx, id
+func (_m *Store) GetCategory(ctx context.Context, id int64) (db.Category, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Category
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Category); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Category)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCategoryRule provides a mock function with given fields: ctx, id
+func (_m *Store) GetCategoryRule(ctx context.Context, id int64) (db.CategoryRule, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.CategoryRule
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.CategoryRule); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.CategoryRule)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEntry provides a mock function with given fields: ctx, id
+func (_m *Store) GetEntry(ctx context.Context, id int64) (db.Entry, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Entry); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Entry)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFeeSchedule provides a mock function with given fields: ctx, arg
+func (_m *Store) GetFeeSchedule(ctx context.Context, arg db.GetFeeScheduleParams) (db.FeeSchedule, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.FeeSchedule
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetFeeScheduleParams) db.FeeSchedule); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.FeeSchedule)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetFeeScheduleParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetInterestRate provides a mock function with given fields: ctx, arg
+func (_m *Store) GetInterestRate(ctx context.Context, arg db.GetInterestRateParams) (db.InterestRate, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.InterestRate
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetInterestRateParams) db.InterestRate); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.InterestRate)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetInterestRateParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetJournal provides a mock function with given fields: ctx, id
+func (_m *Store) GetJournal(ctx context.Context, id int64) (db.Journal, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Journal
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Journal); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Journal)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLastChainedAuditLog provides a mock function with given fields: ctx
+func (_m *Store) GetLastChainedAuditLog(ctx context.Context) (db.AuditLog, error) {
+	ret := _m.Called(ctx)
+
+	var r0 db.AuditLog
+	if rf, ok := ret.Get(0).(func(context.Context) db.AuditLog); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(db.AuditLog)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLatestAccountEventID provides a mock function with given fields: ctx, accountID
+func (_m *Store) GetLatestAccountEventID(ctx context.Context, accountID int64) (int64, error) {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, int64) int64); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoginThrottle provides a mock function with given fields: ctx, arg
+func (_m *Store) GetLoginThrottle(ctx context.Context, arg db.GetLoginThrottleParams) (db.LoginThrottle, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.LoginThrottle
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetLoginThrottleParams) db.LoginThrottle); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.LoginThrottle)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetLoginThrottleParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLoginThrottleForUpdate provides a mock function with given fields: ctx, arg
+func (_m *Store) GetLoginThrottleForUpdate(ctx context.Context, arg db.GetLoginThrottleForUpdateParams) (db.LoginThrottle, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.LoginThrottle
+	if rf, ok := ret.Get(0).(func(context.Context, db.GetLoginThrottleForUpdateParams) db.LoginThrottle); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.LoginThrottle)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.GetLoginThrottleForUpdateParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPayee provides a mock function with given fields: ctx, id
+func (_m *Store) GetPayee(ctx context.Context, id int64) (db.Payee, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Payee
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Payee); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Payee)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentRequest provides a mock function with given fields: ctx, id
+func (_m *Store) GetPaymentRequest(ctx context.Context, id int64) (db.PaymentRequest, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.PaymentRequest
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.PaymentRequest); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.PaymentRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPaymentRequestForUpdate provides a mock function with given fields: ctx, id
+func (_m *Store) GetPaymentRequestForUpdate(ctx context.Context, id int64) (db.PaymentRequest, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.PaymentRequest
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.PaymentRequest); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.PaymentRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingTransfer provides a mock function with given fields: ctx, id
+func (_m *Store) GetPendingTransfer(ctx context.Context, id int64) (db.PendingTransfer, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.PendingTransfer); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.PendingTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPendingTransferForUpdate provides a mock function with given fields: ctx, id
+func (_m *Store) GetPendingTransferForUpdate(ctx context.Context, id int64) (db.PendingTransfer, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.PendingTransfer); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.PendingTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSession provides a mock function with given fields: ctx, id
+func (_m *Store) GetSession(ctx context.Context, id int64) (db.Session, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Session
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Session); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Session)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSessionByRefreshToken provides a mock function with given fields: ctx, refreshTokenHash
+func (_m *Store) GetSessionByRefreshToken(ctx context.Context, refreshTokenHash string) (db.Session, error) {
+	ret := _m.Called(ctx, refreshTokenHash)
+
+	var r0 db.Session
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.Session); ok {
+		r0 = rf(ctx, refreshTokenHash)
+	} else {
+		r0 = ret.Get(0).(db.Session)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshTokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSessionByRefreshTokenForUpdate provides a mock function with given fields: ctx, refreshTokenHash
+func (_m *Store) GetSessionByRefreshTokenForUpdate(ctx context.Context, refreshTokenHash string) (db.Session, error) {
+	ret := _m.Called(ctx, refreshTokenHash)
+
+	var r0 db.Session
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.Session); ok {
+		r0 = rf(ctx, refreshTokenHash)
+	} else {
+		r0 = ret.Get(0).(db.Session)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshTokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSystemAccount provides a mock function with given fields: ctx, currency
+func (_m *Store) GetSystemAccount(ctx context.Context, currency string) (db.Account, error) {
+	ret := _m.Called(ctx, currency)
+
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.Account); ok {
+		r0 = rf(ctx, currency)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, currency)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTOTPCredential provides a mock function with given fields: ctx, username
+func (_m *Store) GetTOTPCredential(ctx context.Context, username string) (db.TotpCredential, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 db.TotpCredential
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.TotpCredential); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(db.TotpCredential)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTransfer provides a mock function with given fields: ctx, id
+func (_m *Store) GetTransfer(ctx context.Context, id int64) (db.Transfer, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.Transfer
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Transfer); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.Transfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUser provides a mock function with given fields: ctx, username
+func (_m *Store) GetUser(ctx context.Context, username string) (db.User, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 db.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(db.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserByEmail provides a mock function with given fields: ctx, email
+func (_m *Store) GetUserByEmail(ctx context.Context, email string) (db.User, error) {
+	ret := _m.Called(ctx, email)
+
+	var r0 db.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Get(0).(db.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserTokenForUpdate provides a mock function with given fields: ctx, tokenHash
+func (_m *Store) GetUserTokenForUpdate(ctx context.Context, tokenHash string) (db.UserToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	var r0 db.UserToken
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.UserToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(db.UserToken)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhookDelivery provides a mock function with given fields: ctx, id
+func (_m *Store) GetWebhookDelivery(ctx context.Context, id int64) (db.WebhookDelivery, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.WebhookDelivery); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.WebhookDelivery)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWebhookEndpoint provides a mock function with given fields: ctx, id
+func (_m *Store) GetWebhookEndpoint(ctx context.Context, id int64) (db.WebhookEndpoint, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.WebhookEndpoint
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.WebhookEndpoint); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.WebhookEndpoint)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// JournalTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) JournalTx(_a0 context.Context, _a1 []db.Leg) (db.JournalTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.JournalTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, []db.Leg) db.JournalTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.JournalTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []db.Leg) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAPIKeys provides a mock function with given fields: ctx, username
+func (_m *Store) ListAPIKeys(ctx context.Context, username string) ([]db.APIKey, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 []db.APIKey
+	if rf, ok := ret.Get(0).(func(context.Context, string) []db.APIKey); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.APIKey)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAccountAdjustments provides a mock function with given fields: ctx, arg
+func (_m *Store) ListAccountAdjustments(ctx context.Context, arg db.ListAccountAdjustmentsParams) ([]db.Adjustment, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Adjustment
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListAccountAdjustmentsParams) []db.Adjustment); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Adjustment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListAccountAdjustmentsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAccountEventsAfter provides a mock function with given fields: ctx, arg
+func (_m *Store) ListAccountEventsAfter(ctx context.Context, arg db.ListAccountEventsAfterParams) ([]db.Outbox, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Outbox
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListAccountEventsAfterParams) []db.Outbox); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Outbox)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListAccountEventsAfterParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAccountHolders provides a mock function with given fields: ctx, accountID
+func (_m *Store) ListAccountHolders(ctx context.Context, accountID int64) ([]db.AccountHolder, error) {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 []db.AccountHolder
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []db.AccountHolder); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.AccountHolder)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAccountOutboxEvents provides a mock function with given fields: ctx, arg
+func (_m *Store) ListAccountOutboxEvents(ctx context.Context, arg db.ListAccountOutboxEventsParams) ([]db.Outbox, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Outbox
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListAccountOutboxEventsParams) []db.Outbox); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Outbox)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListAccountOutboxEventsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAccountTypes provides a mock function with given fields: ctx
+func (_m *Store) ListAccountTypes(ctx context.Context) ([]db.AccountType, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []db.AccountType
+	if rf, ok := ret.Get(0).(func(context.Context) []db.AccountType); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.AccountType)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAccounts provides a mock function with given fields: ctx, arg
+func (_m *Store) ListAccounts(ctx context.Context, arg db.ListAccountsParams) ([]db.Account, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListAccountsParams) []db.Account); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListAccountsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListActiveSessions provides a mock function with given fields: ctx, username
+func (_m *Store) ListActiveSessions(ctx context.Context, username string) ([]db.Session, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 []db.Session
+	if rf, ok := ret.Get(0).(func(context.Context, string) []db.Session); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Session)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListApprovalPolicyApprovers provides a mock function with given fields: ctx, accountID
+func (_m *Store) ListApprovalPolicyApprovers(ctx context.Context, accountID int64) ([]string, error) {
+	ret := _m.Called(ctx, accountID)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []string); ok {
+		r0 = rf(ctx, accountID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, accountID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAuditLog provides a mock function with given fields: ctx, arg
+func (_m *Store) ListAuditLog(ctx context.Context, arg db.ListAuditLogParams) ([]db.AuditLog, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.AuditLog
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListAuditLogParams) []db.AuditLog); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.AuditLog)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListAuditLogParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAuditLogAfter provides a mock function with given fields: ctx, arg
+func (_m *Store) ListAuditLogAfter(ctx context.Context, arg db.ListAuditLogAfterParams) ([]db.AuditLog, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.AuditLog
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListAuditLogAfterParams) []db.AuditLog); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.AuditLog)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListAuditLogAfterParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListBalanceSnapshots provides a mock function with given fields: ctx, arg
+func (_m *Store) ListBalanceSnapshots(ctx context.Context, arg db.ListBalanceSnapshotsParams) ([]db.BalanceSnapshot, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.BalanceSnapshot
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListBalanceSnapshotsParams) []db.BalanceSnapshot); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.BalanceSnapshot)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListBalanceSnapshotsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListBatchLegs provides a mock function with given fields: ctx, batchID
+func (_m *Store) ListBatchLegs(ctx context.Context, batchID int64) ([]db.BatchLeg, error) {
+	ret := _m.Called(ctx, batchID)
+
+	var r0 []db.BatchLeg
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []db.BatchLeg); ok {
+		r0 = rf(ctx, batchID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.BatchLeg)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, batchID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCategories provides a mock function with given fields: ctx, owner
+func (_m *Store) ListCategories(ctx context.Context, owner sql.NullString) ([]db.Category, error) {
+	ret := _m.Called(ctx, owner)
+
+	var r0 []db.Category
+	if rf, ok := ret.Get(0).(func(context.Context, sql.NullString) []db.Category); ok {
+		r0 = rf(ctx, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Category)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, sql.NullString) error); ok {
+		r1 = rf(ctx, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCategoryRules provides a mock function with given fields: ctx, owner
+func (_m *Store) ListCategoryRules(ctx context.Context, owner string) ([]db.CategoryRule, error) {
+	ret := _m.Called(ctx, owner)
+
+	var r0 []db.CategoryRule
+	if rf, ok := ret.Get(0).(func(context.Context, string) []db.CategoryRule); ok {
+		r0 = rf(ctx, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.CategoryRule)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListEntries provides a mock function with given fields: ctx, arg
+func (_m *Store) ListEntries(ctx context.Context, arg db.ListEntriesParams) ([]db.Entry, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListEntriesParams) []db.Entry); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListEntriesParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFeeTiers provides a mock function with given fields: ctx, feeScheduleID
+func (_m *Store) ListFeeTiers(ctx context.Context, feeScheduleID int64) ([]db.FeeTier, error) {
+	ret := _m.Called(ctx, feeScheduleID)
+
+	var r0 []db.FeeTier
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []db.FeeTier); ok {
+		r0 = rf(ctx, feeScheduleID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.FeeTier)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, feeScheduleID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListIncomingPaymentRequests provides a mock function with given fields: ctx, arg
+func (_m *Store) ListIncomingPaymentRequests(ctx context.Context, arg db.ListIncomingPaymentRequestsParams) ([]db.PaymentRequest, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.PaymentRequest
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListIncomingPaymentRequestsParams) []db.PaymentRequest); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.PaymentRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListIncomingPaymentRequestsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListInterestAccruals provides a mock function with given fields: ctx, arg
+func (_m *Store) ListInterestAccruals(ctx context.Context, arg db.ListInterestAccrualsParams) ([]db.InterestAccrual, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.InterestAccrual
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListInterestAccrualsParams) []db.InterestAccrual); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.InterestAccrual)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListInterestAccrualsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListInterestBearingAccounts provides a mock function with given fields: ctx, endOfDay
+func (_m *Store) ListInterestBearingAccounts(ctx context.Context, endOfDay time.Time) ([]db.ListInterestBearingAccountsRow, error) {
+	ret := _m.Called(ctx, endOfDay)
+
+	var r0 []db.ListInterestBearingAccountsRow
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []db.ListInterestBearingAccountsRow); ok {
+		r0 = rf(ctx, endOfDay)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ListInterestBearingAccountsRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, endOfDay)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListJournalEntries provides a mock function with given fields: ctx, journalID
+func (_m *Store) ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]db.Entry, error) {
+	ret := _m.Called(ctx, journalID)
+
+	var r0 []db.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, sql.NullInt64) []db.Entry); ok {
+		r0 = rf(ctx, journalID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, sql.NullInt64) error); ok {
+		r1 = rf(ctx, journalID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListLoginThrottles provides a mock function with given fields: ctx, arg
+func (_m *Store) ListLoginThrottles(ctx context.Context, arg db.ListLoginThrottlesParams) ([]db.LoginThrottle, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.LoginThrottle
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListLoginThrottlesParams) []db.LoginThrottle); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.LoginThrottle)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListLoginThrottlesParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOutgoingPaymentRequests provides a mock function with given fields: ctx, arg
+func (_m *Store) ListOutgoingPaymentRequests(ctx context.Context, arg db.ListOutgoingPaymentRequestsParams) ([]db.PaymentRequest, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.PaymentRequest
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListOutgoingPaymentRequestsParams) []db.PaymentRequest); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.PaymentRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListOutgoingPaymentRequestsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOwnerAccounts provides a mock function with given fields: ctx, owner
+func (_m *Store) ListOwnerAccounts(ctx context.Context, owner string) ([]db.Account, error) {
+	ret := _m.Called(ctx, owner)
+
+	var r0 []db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, string) []db.Account); ok {
+		r0 = rf(ctx, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPayees provides a mock function with given fields: ctx, arg
+func (_m *Store) ListPayees(ctx context.Context, arg db.ListPayeesParams) ([]db.Payee, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Payee
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListPayeesParams) []db.Payee); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Payee)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListPayeesParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRecentPayeesForUpdate provides a mock function with given fields: ctx, arg
+func (_m *Store) ListRecentPayeesForUpdate(ctx context.Context, arg db.ListRecentPayeesForUpdateParams) ([]db.Payee, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Payee
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListRecentPayeesForUpdateParams) []db.Payee); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Payee)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListRecentPayeesForUpdateParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTransfers provides a mock function with given fields: ctx, arg
+func (_m *Store) ListTransfers(ctx context.Context, arg db.ListTransfersParams) ([]db.Transfer, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Transfer
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListTransfersParams) []db.Transfer); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Transfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListTransfersParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUncapitalizedInterest provides a mock function with given fields: ctx, accrualDate
+func (_m *Store) ListUncapitalizedInterest(ctx context.Context, accrualDate time.Time) ([]db.ListUncapitalizedInterestRow, error) {
+	ret := _m.Called(ctx, accrualDate)
+
+	var r0 []db.ListUncapitalizedInterestRow
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []db.ListUncapitalizedInterestRow); ok {
+		r0 = rf(ctx, accrualDate)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.ListUncapitalizedInterestRow)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, accrualDate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListUnchainedAuditLog provides a mock function with given fields: ctx, limit
+func (_m *Store) ListUnchainedAuditLog(ctx context.Context, limit int32) ([]db.AuditLog, error) {
+	ret := _m.Called(ctx, limit)
+
+	var r0 []db.AuditLog
+	if rf, ok := ret.Get(0).(func(context.Context, int32) []db.AuditLog); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.AuditLog)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int32) error); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWebhookAttempts provides a mock function with given fields: ctx, deliveryID
+func (_m *Store) ListWebhookAttempts(ctx context.Context, deliveryID int64) ([]db.WebhookAttempt, error) {
+	ret := _m.Called(ctx, deliveryID)
+
+	var r0 []db.WebhookAttempt
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []db.WebhookAttempt); ok {
+		r0 = rf(ctx, deliveryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WebhookAttempt)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, deliveryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWebhookDeliveries provides a mock function with given fields: ctx, arg
+func (_m *Store) ListWebhookDeliveries(ctx context.Context, arg db.ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, db.ListWebhookDeliveriesParams) []db.WebhookDelivery); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WebhookDelivery)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ListWebhookDeliveriesParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListWebhookEndpoints provides a mock function with given fields: ctx, owner
+func (_m *Store) ListWebhookEndpoints(ctx context.Context, owner string) ([]db.WebhookEndpoint, error) {
+	ret := _m.Called(ctx, owner)
+
+	var r0 []db.WebhookEndpoint
+	if rf, ok := ret.Get(0).(func(context.Context, string) []db.WebhookEndpoint); ok {
+		r0 = rf(ctx, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.WebhookEndpoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LockAuditLog provides a mock function with given fields: ctx
+func (_m *Store) LockAuditLog(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LockLoginThrottle provides a mock function with given fields: ctx, arg
+func (_m *Store) LockLoginThrottle(ctx context.Context, arg db.LockLoginThrottleParams) (db.LoginThrottle, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.LoginThrottle
+	if rf, ok := ret.Get(0).(func(context.Context, db.LockLoginThrottleParams) db.LoginThrottle); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.LoginThrottle)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.LockLoginThrottleParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkInterestAccrualsCapitalized provides a mock function with given fields: ctx, arg
+func (_m *Store) MarkInterestAccrualsCapitalized(ctx context.Context, arg db.MarkInterestAccrualsCapitalizedParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.MarkInterestAccrualsCapitalizedParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.MarkInterestAccrualsCapitalizedParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkOutboxEventsDispatched provides a mock function with given fields: ctx, ids
+func (_m *Store) MarkOutboxEventsDispatched(ctx context.Context, ids []int64) (int64, error) {
+	ret := _m.Called(ctx, ids)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) int64); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MarkSessionRotated provides a mock function with given fields: ctx, id
+func (_m *Store) MarkSessionRotated(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MarkUserTokenUsed provides a mock function with given fields: ctx, id
+func (_m *Store) MarkUserTokenUsed(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NotifyAccountEvent provides a mock function with given fields: ctx, notification
+func (_m *Store) NotifyAccountEvent(ctx context.Context, notification string) error {
+	ret := _m.Called(ctx, notification)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, notification)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// OpenAccountTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) OpenAccountTx(_a0 context.Context, _a1 db.CreateAccountParams) (db.Account, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, db.CreateAccountParams) db.Account); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.CreateAccountParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PayPaymentRequestTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) PayPaymentRequestTx(_a0 context.Context, _a1 db.PayPaymentRequestTxParams) (db.PayPaymentRequestTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.PayPaymentRequestTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, db.PayPaymentRequestTxParams) db.PayPaymentRequestTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.PayPaymentRequestTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.PayPaymentRequestTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QuoteTransferFee provides a mock function with given fields: _a0, _a1
+func (_m *Store) QuoteTransferFee(_a0 context.Context, _a1 db.TransferTxParams) (db.Fee, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.Fee
+	if rf, ok := ret.Get(0).(func(context.Context, db.TransferTxParams) db.Fee); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.Fee)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.TransferTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordLoginFailure provides a mock function with given fields: ctx, arg
+func (_m *Store) RecordLoginFailure(ctx context.Context, arg db.RecordLoginFailureParams) (db.LoginThrottle, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.LoginThrottle
+	if rf, ok := ret.Get(0).(func(context.Context, db.RecordLoginFailureParams) db.LoginThrottle); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.LoginThrottle)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.RecordLoginFailureParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordWebhookAttemptTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) RecordWebhookAttemptTx(_a0 context.Context, _a1 db.RecordWebhookAttemptTxParams) (db.WebhookDelivery, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, db.RecordWebhookAttemptTxParams) db.WebhookDelivery); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.WebhookDelivery)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.RecordWebhookAttemptTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RedeliverWebhookDelivery provides a mock function with given fields: ctx, id
+func (_m *Store) RedeliverWebhookDelivery(ctx context.Context, id int64) (db.WebhookDelivery, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.WebhookDelivery); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.WebhookDelivery)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RegenerateRecoveryCodesTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) RegenerateRecoveryCodesTx(_a0 context.Context, _a1 db.TOTPTxParams) ([]db.RecoveryCode, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []db.RecoveryCode
+	if rf, ok := ret.Get(0).(func(context.Context, db.TOTPTxParams) []db.RecoveryCode); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.RecoveryCode)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.TOTPTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RehashUserPassword provides a mock function with given fields: ctx, arg
+func (_m *Store) RehashUserPassword(ctx context.Context, arg db.RehashUserPasswordParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.RehashUserPasswordParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.RehashUserPasswordParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RejectPendingTransferTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) RejectPendingTransferTx(_a0 context.Context, _a1 db.DecidePendingTransferTxParams) (db.PendingTransfer, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, db.DecidePendingTransferTxParams) db.PendingTransfer); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.PendingTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.DecidePendingTransferTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReleaseLoginAttempt provides a mock function with given fields: ctx, arg
+func (_m *Store) ReleaseLoginAttempt(ctx context.Context, arg db.ReleaseLoginAttemptParams) error {
+	ret := _m.Called(ctx, arg)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, db.ReleaseLoginAttemptParams) error); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReserveLoginAttemptTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) ReserveLoginAttemptTx(_a0 context.Context, _a1 db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.ReserveLoginAttemptTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, db.ReserveLoginAttemptTxParams) db.ReserveLoginAttemptTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.ReserveLoginAttemptTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ReserveLoginAttemptTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetPasswordTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) ResetPasswordTx(_a0 context.Context, _a1 db.ResetPasswordTxParams) (db.User, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.User
+	if rf, ok := ret.Get(0).(func(context.Context, db.ResetPasswordTxParams) db.User); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.ResetPasswordTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAPIKey provides a mock function with given fields: ctx, id
+func (_m *Store) RevokeAPIKey(ctx context.Context, id int64) (db.APIKey, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 db.APIKey
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.APIKey); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(db.APIKey)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSessionFamily provides a mock function with given fields: ctx, familyID
+func (_m *Store) RevokeSessionFamily(ctx context.Context, familyID string) (int64, error) {
+	ret := _m.Called(ctx, familyID)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, familyID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, familyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeUserSessions provides a mock function with given fields: ctx, arg
+func (_m *Store) RevokeUserSessions(ctx context.Context, arg db.RevokeUserSessionsParams) (int64, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.RevokeUserSessionsParams) int64); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.RevokeUserSessionsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RotateAPIKey provides a mock function with given fields: ctx, arg
+func (_m *Store) RotateAPIKey(ctx context.Context, arg db.RotateAPIKeyParams) (db.APIKey, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.APIKey
+	if rf, ok := ret.Get(0).(func(context.Context, db.RotateAPIKeyParams) db.APIKey); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.APIKey)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.RotateAPIKeyParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RotateSessionTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) RotateSessionTx(_a0 context.Context, _a1 db.RotateSessionTxParams) (db.RotateSessionTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.RotateSessionTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, db.RotateSessionTxParams) db.RotateSessionTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.RotateSessionTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.RotateSessionTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchAccounts provides a mock function with given fields: ctx, arg
+func (_m *Store) SearchAccounts(ctx context.Context, arg db.SearchAccountsParams) ([]db.Account, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, db.SearchAccountsParams) []db.Account); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.SearchAccountsParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchEntries provides a mock function with given fields: ctx, arg
+func (_m *Store) SearchEntries(ctx context.Context, arg db.SearchEntriesParams) ([]db.Entry, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, db.SearchEntriesParams) []db.Entry); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Entry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.SearchEntriesParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchTransfers provides a mock function with given fields: ctx, arg
+func (_m *Store) SearchTransfers(ctx context.Context, arg db.SearchTransfersParams) ([]db.Transfer, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.Transfer
+	if rf, ok := ret.Get(0).(func(context.Context, db.SearchTransfersParams) []db.Transfer); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Transfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.SearchTransfersParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SearchUsers provides a mock function with given fields: ctx, arg
+func (_m *Store) SearchUsers(ctx context.Context, arg db.SearchUsersParams) ([]db.User, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 []db.User
+	if rf, ok := ret.Get(0).(func(context.Context, db.SearchUsersParams) []db.User); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.User)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.SearchUsersParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetApprovalPolicyTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) SetApprovalPolicyTx(_a0 context.Context, _a1 db.SetApprovalPolicyTxParams) (db.SetApprovalPolicyTxResult, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 db.SetApprovalPolicyTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, db.SetApprovalPolicyTxParams) db.SetApprovalPolicyTxResult); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(db.SetApprovalPolicyTxResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.SetApprovalPolicyTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetEntryCategory provides a mock function with given fields: ctx, arg
+func (_m *Store) SetEntryCategory(ctx context.Context, arg db.SetEntryCategoryParams) (db.Entry, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Entry
+	if rf, ok := ret.Get(0).(func(context.Context, db.SetEntryCategoryParams) db.Entry); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(db.Account)
+		r0 = ret.Get(0).(db.Entry)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.AddAccountBalanceParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.SetEntryCategoryParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -35,19 +3744,19 @@ func (_m *Store) AddAccountBalance(ctx context.Context, arg db.AddAccountBalance
 	return r0, r1
 }
 
-// CreateAccount provides a mock function with given fields: ctx, arg
-func (_m *Store) CreateAccount(ctx context.Context, arg db.CreateAccountParams) (db.Account, error) {
+// SetInterestCapitalizationJournal provides a mock function with given fields: ctx, arg
+func (_m *Store) SetInterestCapitalizationJournal(ctx context.Context, arg db.SetInterestCapitalizationJournalParams) (db.InterestCapitalization, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 db.Account
-	if rf, ok := ret.Get(0).(func(context.Context, db.CreateAccountParams) db.Account); ok {
+	var r0 db.InterestCapitalization
+	if rf, ok := ret.Get(0).(func(context.Context, db.SetInterestCapitalizationJournalParams) db.InterestCapitalization); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(db.Account)
+		r0 = ret.Get(0).(db.InterestCapitalization)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.CreateAccountParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.SetInterestCapitalizationJournalParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -56,20 +3765,20 @@ func (_m *Store) CreateAccount(ctx context.Context, arg db.CreateAccountParams)
 	return r0, r1
 }
 
-// CreateEntry provides a mock function with given fields: ctx, arg
-func (_m *Store) CreateEntry(ctx context.Context, arg db.CreateEntryParams) (db.Entry, error) {
-	ret := _m.Called(ctx, arg)
+// SetUserEmailVerified provides a mock function with given fields: ctx, username
+func (_m *Store) SetUserEmailVerified(ctx context.Context, username string) (db.User, error) {
+	ret := _m.Called(ctx, username)
 
-	var r0 db.Entry
-	if rf, ok := ret.Get(0).(func(context.Context, db.CreateEntryParams) db.Entry); ok {
-		r0 = rf(ctx, arg)
+	var r0 db.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.User); ok {
+		r0 = rf(ctx, username)
 	} else {
-		r0 = ret.Get(0).(db.Entry)
+		r0 = ret.Get(0).(db.User)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.CreateEntryParams) error); ok {
-		r1 = rf(ctx, arg)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -77,19 +3786,19 @@ func (_m *Store) CreateEntry(ctx context.Context, arg db.CreateEntryParams) (db.
 	return r0, r1
 }
 
-// CreateTransfer provides a mock function with given fields: ctx, arg
-func (_m *Store) CreateTransfer(ctx context.Context, arg db.CreateTransferParams) (db.Transfer, error) {
+// SetUserRole provides a mock function with given fields: ctx, arg
+func (_m *Store) SetUserRole(ctx context.Context, arg db.SetUserRoleParams) (db.User, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 db.Transfer
-	if rf, ok := ret.Get(0).(func(context.Context, db.CreateTransferParams) db.Transfer); ok {
+	var r0 db.User
+	if rf, ok := ret.Get(0).(func(context.Context, db.SetUserRoleParams) db.User); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(db.Transfer)
+		r0 = ret.Get(0).(db.User)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.CreateTransferParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.SetUserRoleParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -98,19 +3807,19 @@ func (_m *Store) CreateTransfer(ctx context.Context, arg db.CreateTransferParams
 	return r0, r1
 }
 
-// CreateUser provides a mock function with given fields: ctx, arg
-func (_m *Store) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+// SumPayeeAccountTransfers provides a mock function with given fields: ctx, arg
+func (_m *Store) SumPayeeAccountTransfers(ctx context.Context, arg db.SumPayeeAccountTransfersParams) (int64, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 db.User
-	if rf, ok := ret.Get(0).(func(context.Context, db.CreateUserParams) db.User); ok {
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.SumPayeeAccountTransfersParams) int64); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(db.User)
+		r0 = ret.Get(0).(int64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.CreateUserParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.SumPayeeAccountTransfersParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -119,8 +3828,8 @@ func (_m *Store) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.Us
 	return r0, r1
 }
 
-// DeleteAccount provides a mock function with given fields: ctx, id
-func (_m *Store) DeleteAccount(ctx context.Context, id int64) error {
+// TouchAPIKey provides a mock function with given fields: ctx, id
+func (_m *Store) TouchAPIKey(ctx context.Context, id int64) error {
 	ret := _m.Called(ctx, id)
 
 	var r0 error
@@ -133,62 +3842,83 @@ func (_m *Store) DeleteAccount(ctx context.Context, id int64) error {
 	return r0
 }
 
-// DeleteEntry provides a mock function with given fields: ctx, id
-func (_m *Store) DeleteEntry(ctx context.Context, id int64) error {
-	ret := _m.Called(ctx, id)
+// TransferTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) TransferTx(_a0 context.Context, _a1 db.TransferTxParams) (db.TransferTxResult, error) {
+	ret := _m.Called(_a0, _a1)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
-		r0 = rf(ctx, id)
+	var r0 db.TransferTxResult
+	if rf, ok := ret.Get(0).(func(context.Context, db.TransferTxParams) db.TransferTxResult); ok {
+		r0 = rf(_a0, _a1)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(db.TransferTxResult)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.TransferTxParams) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// DeleteTransfer provides a mock function with given fields: ctx, id
-func (_m *Store) DeleteTransfer(ctx context.Context, id int64) error {
+// UnfreezeAccount provides a mock function with given fields: ctx, id
+func (_m *Store) UnfreezeAccount(ctx context.Context, id int64) (db.Account, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+	var r0 db.Account
+	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Account); ok {
 		r0 = rf(ctx, id)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(db.Account)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// DeleteUser provides a mock function with given fields: ctx, arg
-func (_m *Store) DeleteUser(ctx context.Context, arg db.DeleteUserParams) error {
+// UnlockLoginThrottle provides a mock function with given fields: ctx, arg
+func (_m *Store) UnlockLoginThrottle(ctx context.Context, arg db.UnlockLoginThrottleParams) (db.LoginThrottle, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, db.DeleteUserParams) error); ok {
+	var r0 db.LoginThrottle
+	if rf, ok := ret.Get(0).(func(context.Context, db.UnlockLoginThrottleParams) db.LoginThrottle); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(db.LoginThrottle)
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.UnlockLoginThrottleParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// GetAccount provides a mock function with given fields: ctx, id
-func (_m *Store) GetAccount(ctx context.Context, id int64) (db.Account, error) {
-	ret := _m.Called(ctx, id)
+// UpdateAccountBalance provides a mock function with given fields: ctx, arg
+func (_m *Store) UpdateAccountBalance(ctx context.Context, arg db.UpdateAccountBalanceParams) (db.Account, error) {
+	ret := _m.Called(ctx, arg)
 
 	var r0 db.Account
-	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Account); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateAccountBalanceParams) db.Account); ok {
+		r0 = rf(ctx, arg)
 	} else {
 		r0 = ret.Get(0).(db.Account)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateAccountBalanceParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -196,20 +3926,20 @@ func (_m *Store) GetAccount(ctx context.Context, id int64) (db.Account, error) {
 	return r0, r1
 }
 
-// GetAccountForUpdate provides a mock function with given fields: ctx, id
-func (_m *Store) GetAccountForUpdate(ctx context.Context, id int64) (db.Account, error) {
-	ret := _m.Called(ctx, id)
+// UpdateAccountType provides a mock function with given fields: ctx, arg
+func (_m *Store) UpdateAccountType(ctx context.Context, arg db.UpdateAccountTypeParams) (db.AccountType, error) {
+	ret := _m.Called(ctx, arg)
 
-	var r0 db.Account
-	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Account); ok {
-		r0 = rf(ctx, id)
+	var r0 db.AccountType
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateAccountTypeParams) db.AccountType); ok {
+		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(db.Account)
+		r0 = ret.Get(0).(db.AccountType)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateAccountTypeParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -217,20 +3947,20 @@ func (_m *Store) GetAccountForUpdate(ctx context.Context, id int64) (db.Account,
 	return r0, r1
 }
 
-// GetEntry provides a mock function with given fields: ctx, id
-func (_m *Store) GetEntry(ctx context.Context, id int64) (db.Entry, error) {
-	ret := _m.Called(ctx, id)
+// UpdateEntryAmount provides a mock function with given fields: ctx, arg
+func (_m *Store) UpdateEntryAmount(ctx context.Context, arg db.UpdateEntryAmountParams) (db.Entry, error) {
+	ret := _m.Called(ctx, arg)
 
 	var r0 db.Entry
-	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Entry); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateEntryAmountParams) db.Entry); ok {
+		r0 = rf(ctx, arg)
 	} else {
 		r0 = ret.Get(0).(db.Entry)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateEntryAmountParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -238,20 +3968,41 @@ func (_m *Store) GetEntry(ctx context.Context, id int64) (db.Entry, error) {
 	return r0, r1
 }
 
-// GetTransfer provides a mock function with given fields: ctx, id
-func (_m *Store) GetTransfer(ctx context.Context, id int64) (db.Transfer, error) {
-	ret := _m.Called(ctx, id)
+// UpdatePayee provides a mock function with given fields: ctx, arg
+func (_m *Store) UpdatePayee(ctx context.Context, arg db.UpdatePayeeParams) (db.Payee, error) {
+	ret := _m.Called(ctx, arg)
+
+	var r0 db.Payee
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpdatePayeeParams) db.Payee); ok {
+		r0 = rf(ctx, arg)
+	} else {
+		r0 = ret.Get(0).(db.Payee)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpdatePayeeParams) error); ok {
+		r1 = rf(ctx, arg)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateTransfer provides a mock function with given fields: ctx, arg
+func (_m *Store) UpdateTransfer(ctx context.Context, arg db.UpdateTransferParams) (db.Transfer, error) {
+	ret := _m.Called(ctx, arg)
 
 	var r0 db.Transfer
-	if rf, ok := ret.Get(0).(func(context.Context, int64) db.Transfer); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateTransferParams) db.Transfer); ok {
+		r0 = rf(ctx, arg)
 	} else {
 		r0 = ret.Get(0).(db.Transfer)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateTransferParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -259,20 +4010,20 @@ func (_m *Store) GetTransfer(ctx context.Context, id int64) (db.Transfer, error)
 	return r0, r1
 }
 
-// GetUser provides a mock function with given fields: ctx, username
-func (_m *Store) GetUser(ctx context.Context, username string) (db.User, error) {
-	ret := _m.Called(ctx, username)
+// UpdateUserPassword provides a mock function with given fields: ctx, arg
+func (_m *Store) UpdateUserPassword(ctx context.Context, arg db.UpdateUserPasswordParams) (db.User, error) {
+	ret := _m.Called(ctx, arg)
 
 	var r0 db.User
-	if rf, ok := ret.Get(0).(func(context.Context, string) db.User); ok {
-		r0 = rf(ctx, username)
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateUserPasswordParams) db.User); ok {
+		r0 = rf(ctx, arg)
 	} else {
 		r0 = ret.Get(0).(db.User)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, username)
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateUserPasswordParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -280,21 +4031,19 @@ func (_m *Store) GetUser(ctx context.Context, username string) (db.User, error)
 	return r0, r1
 }
 
-// ListAccounts provides a mock function with given fields: ctx, arg
-func (_m *Store) ListAccounts(ctx context.Context, arg db.ListAccountsParams) ([]db.Account, error) {
+// UpdateWebhookDelivery provides a mock function with given fields: ctx, arg
+func (_m *Store) UpdateWebhookDelivery(ctx context.Context, arg db.UpdateWebhookDeliveryParams) (db.WebhookDelivery, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 []db.Account
-	if rf, ok := ret.Get(0).(func(context.Context, db.ListAccountsParams) []db.Account); ok {
+	var r0 db.WebhookDelivery
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateWebhookDeliveryParams) db.WebhookDelivery); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]db.Account)
-		}
+		r0 = ret.Get(0).(db.WebhookDelivery)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.ListAccountsParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateWebhookDeliveryParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -303,21 +4052,19 @@ func (_m *Store) ListAccounts(ctx context.Context, arg db.ListAccountsParams) ([
 	return r0, r1
 }
 
-// ListEntries provides a mock function with given fields: ctx, arg
-func (_m *Store) ListEntries(ctx context.Context, arg db.ListEntriesParams) ([]db.Entry, error) {
+// UpsertApprovalPolicy provides a mock function with given fields: ctx, arg
+func (_m *Store) UpsertApprovalPolicy(ctx context.Context, arg db.UpsertApprovalPolicyParams) (db.ApprovalPolicy, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 []db.Entry
-	if rf, ok := ret.Get(0).(func(context.Context, db.ListEntriesParams) []db.Entry); ok {
+	var r0 db.ApprovalPolicy
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpsertApprovalPolicyParams) db.ApprovalPolicy); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]db.Entry)
-		}
+		r0 = ret.Get(0).(db.ApprovalPolicy)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.ListEntriesParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpsertApprovalPolicyParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -326,21 +4073,19 @@ func (_m *Store) ListEntries(ctx context.Context, arg db.ListEntriesParams) ([]d
 	return r0, r1
 }
 
-// ListTransfers provides a mock function with given fields: ctx, arg
-func (_m *Store) ListTransfers(ctx context.Context, arg db.ListTransfersParams) ([]db.Transfer, error) {
+// UpsertInterestRate provides a mock function with given fields: ctx, arg
+func (_m *Store) UpsertInterestRate(ctx context.Context, arg db.UpsertInterestRateParams) (db.InterestRate, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 []db.Transfer
-	if rf, ok := ret.Get(0).(func(context.Context, db.ListTransfersParams) []db.Transfer); ok {
+	var r0 db.InterestRate
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpsertInterestRateParams) db.InterestRate); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]db.Transfer)
-		}
+		r0 = ret.Get(0).(db.InterestRate)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.ListTransfersParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpsertInterestRateParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -349,20 +4094,20 @@ func (_m *Store) ListTransfers(ctx context.Context, arg db.ListTransfersParams)
 	return r0, r1
 }
 
-// TransferTx provides a mock function with given fields: _a0, _a1
-func (_m *Store) TransferTx(_a0 context.Context, _a1 db.TransferTxParams) (db.TransferTxResult, error) {
-	ret := _m.Called(_a0, _a1)
+// UpsertTOTPCredential provides a mock function with given fields: ctx, arg
+func (_m *Store) UpsertTOTPCredential(ctx context.Context, arg db.UpsertTOTPCredentialParams) (db.TotpCredential, error) {
+	ret := _m.Called(ctx, arg)
 
-	var r0 db.TransferTxResult
-	if rf, ok := ret.Get(0).(func(context.Context, db.TransferTxParams) db.TransferTxResult); ok {
-		r0 = rf(_a0, _a1)
+	var r0 db.TotpCredential
+	if rf, ok := ret.Get(0).(func(context.Context, db.UpsertTOTPCredentialParams) db.TotpCredential); ok {
+		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(db.TransferTxResult)
+		r0 = ret.Get(0).(db.TotpCredential)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.TransferTxParams) error); ok {
-		r1 = rf(_a0, _a1)
+	if rf, ok := ret.Get(1).(func(context.Context, db.UpsertTOTPCredentialParams) error); ok {
+		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -370,19 +4115,19 @@ func (_m *Store) TransferTx(_a0 context.Context, _a1 db.TransferTxParams) (db.Tr
 	return r0, r1
 }
 
-// UpdateAccountBalance provides a mock function with given fields: ctx, arg
-func (_m *Store) UpdateAccountBalance(ctx context.Context, arg db.UpdateAccountBalanceParams) (db.Account, error) {
+// UseRecoveryCode provides a mock function with given fields: ctx, arg
+func (_m *Store) UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (int64, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 db.Account
-	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateAccountBalanceParams) db.Account); ok {
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.UseRecoveryCodeParams) int64); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(db.Account)
+		r0 = ret.Get(0).(int64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateAccountBalanceParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.UseRecoveryCodeParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -391,19 +4136,19 @@ func (_m *Store) UpdateAccountBalance(ctx context.Context, arg db.UpdateAccountB
 	return r0, r1
 }
 
-// UpdateEntryAmount provides a mock function with given fields: ctx, arg
-func (_m *Store) UpdateEntryAmount(ctx context.Context, arg db.UpdateEntryAmountParams) (db.Entry, error) {
+// UseTOTPStep provides a mock function with given fields: ctx, arg
+func (_m *Store) UseTOTPStep(ctx context.Context, arg db.UseTOTPStepParams) (int64, error) {
 	ret := _m.Called(ctx, arg)
 
-	var r0 db.Entry
-	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateEntryAmountParams) db.Entry); ok {
+	var r0 int64
+	if rf, ok := ret.Get(0).(func(context.Context, db.UseTOTPStepParams) int64); ok {
 		r0 = rf(ctx, arg)
 	} else {
-		r0 = ret.Get(0).(db.Entry)
+		r0 = ret.Get(0).(int64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateEntryAmountParams) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, db.UseTOTPStepParams) error); ok {
 		r1 = rf(ctx, arg)
 	} else {
 		r1 = ret.Error(1)
@@ -412,20 +4157,20 @@ func (_m *Store) UpdateEntryAmount(ctx context.Context, arg db.UpdateEntryAmount
 	return r0, r1
 }
 
-// UpdateTransfer provides a mock function with given fields: ctx, arg
-func (_m *Store) UpdateTransfer(ctx context.Context, arg db.UpdateTransferParams) (db.Transfer, error) {
-	ret := _m.Called(ctx, arg)
+// UseUserTokenTx provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Store) UseUserTokenTx(_a0 context.Context, _a1 string, _a2 string) (db.UserToken, error) {
+	ret := _m.Called(_a0, _a1, _a2)
 
-	var r0 db.Transfer
-	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateTransferParams) db.Transfer); ok {
-		r0 = rf(ctx, arg)
+	var r0 db.UserToken
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) db.UserToken); ok {
+		r0 = rf(_a0, _a1, _a2)
 	} else {
-		r0 = ret.Get(0).(db.Transfer)
+		r0 = ret.Get(0).(db.UserToken)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateTransferParams) error); ok {
-		r1 = rf(ctx, arg)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -433,20 +4178,20 @@ func (_m *Store) UpdateTransfer(ctx context.Context, arg db.UpdateTransferParams
 	return r0, r1
 }
 
-// UpdateUserPassword provides a mock function with given fields: ctx, arg
-func (_m *Store) UpdateUserPassword(ctx context.Context, arg db.UpdateUserPasswordParams) (db.User, error) {
-	ret := _m.Called(ctx, arg)
+// VerifyEmailTx provides a mock function with given fields: _a0, _a1
+func (_m *Store) VerifyEmailTx(_a0 context.Context, _a1 string) (db.User, error) {
+	ret := _m.Called(_a0, _a1)
 
 	var r0 db.User
-	if rf, ok := ret.Get(0).(func(context.Context, db.UpdateUserPasswordParams) db.User); ok {
-		r0 = rf(ctx, arg)
+	if rf, ok := ret.Get(0).(func(context.Context, string) db.User); ok {
+		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Get(0).(db.User)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, db.UpdateUserPasswordParams) error); ok {
-		r1 = rf(ctx, arg)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1,6 +1,6 @@
 -- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, currency)
-VALUES ($1, $2, $3)
+INSERT INTO accounts (owner, balance, currency, account_type, number)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: GetAccount :one
@@ -9,6 +9,12 @@ FROM accounts
 WHERE id = $1
 LIMIT 1;
 
+-- name: GetAccountByNumber :one
+SELECT *
+FROM accounts
+WHERE number = $1
+LIMIT 1;
+
 -- name: GetAccountForUpdate :one
 SELECT *
 FROM accounts
@@ -19,8 +25,19 @@ FOR NO KEY UPDATE;
 -- name: ListAccounts :many
 SELECT *
 FROM accounts
+WHERE owner = $1
+   OR id IN (SELECT account_id
+             FROM account_holders
+             WHERE username = $1
+               AND status = 'active')
 ORDER BY id
-LIMIT $1 OFFSET $2;
+LIMIT $2 OFFSET $3;
+
+-- name: ListOwnerAccounts :many
+SELECT *
+FROM accounts
+WHERE owner = $1
+ORDER BY currency, id;
 
 -- name: UpdateAccountBalance :one
 UPDATE accounts
@@ -38,3 +55,35 @@ RETURNING *;
 DELETE
 FROM accounts
 WHERE id = $1;
+
+-- name: SearchAccounts :many
+SELECT *
+FROM accounts
+WHERE (sqlc.arg(owner)::text = '' OR owner = sqlc.arg(owner)::text)
+  AND (sqlc.arg(number)::text = '' OR number = sqlc.arg(number)::text)
+  AND (sqlc.arg(currency)::text = '' OR currency = sqlc.arg(currency)::text)
+  AND (NOT sqlc.arg(frozen_only)::boolean OR frozen_at IS NOT NULL)
+ORDER BY id
+LIMIT sqlc.arg(max_accounts) OFFSET sqlc.arg(skip_accounts);
+
+-- name: GetSystemAccount :one
+SELECT *
+FROM accounts
+WHERE account_type = 'system'
+  AND currency = $1
+ORDER BY id
+LIMIT 1;
+
+-- name: FreezeAccount :one
+UPDATE accounts
+SET frozen_at     = coalesce(frozen_at, now()),
+    frozen_reason = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UnfreezeAccount :one
+UPDATE accounts
+SET frozen_at     = NULL,
+    frozen_reason = ''
+WHERE id = $1
+RETURNING *;
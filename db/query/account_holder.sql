@@ -0,0 +1,32 @@
+-- name: CreateAccountHolder :one
+INSERT INTO account_holders (account_id, username, role, spend_limit, invited_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAccountHolder :one
+SELECT *
+FROM account_holders
+WHERE account_id = $1
+  AND username = $2
+LIMIT 1;
+
+-- name: ListAccountHolders :many
+SELECT *
+FROM account_holders
+WHERE account_id = $1
+ORDER BY created_at;
+
+-- name: AcceptAccountHolder :one
+UPDATE account_holders
+SET status      = 'active',
+    accepted_at = now()
+WHERE account_id = $1
+  AND username = $2
+  AND status = 'invited'
+RETURNING *;
+
+-- name: DeleteAccountHolder :execrows
+DELETE
+FROM account_holders
+WHERE account_id = $1
+  AND username = $2;
@@ -0,0 +1,19 @@
+-- name: GetAccountType :one
+SELECT *
+FROM account_types
+WHERE name = $1
+LIMIT 1;
+
+-- name: ListAccountTypes :many
+SELECT *
+FROM account_types
+ORDER BY name;
+
+-- name: UpdateAccountType :one
+UPDATE account_types
+SET overdraft_allowed     = $2,
+    overdraft_limit       = $3,
+    min_balance           = $4,
+    withdrawals_per_month = $5
+WHERE name = $1
+RETURNING *;
@@ -0,0 +1,11 @@
+-- name: CreateAdjustment :one
+INSERT INTO adjustments (account_id, journal_id, amount, reason, created_by)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListAccountAdjustments :many
+SELECT *
+FROM adjustments
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,40 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (username, name, prefix, secret_hash, scopes, allowed_ips, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetAPIKey :one
+SELECT *
+FROM api_keys
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetAPIKeyByPrefix :one
+SELECT *
+FROM api_keys
+WHERE prefix = $1
+LIMIT 1;
+
+-- name: ListAPIKeys :many
+SELECT *
+FROM api_keys
+WHERE username = $1
+ORDER BY id;
+
+-- name: RotateAPIKey :one
+UPDATE api_keys
+SET secret_hash = $2
+WHERE id = $1
+  AND revoked_at IS NULL
+RETURNING *;
+
+-- name: RevokeAPIKey :one
+UPDATE api_keys
+SET revoked_at = coalesce(revoked_at, now())
+WHERE id = $1
+RETURNING *;
+
+-- name: TouchAPIKey :exec
+UPDATE api_keys
+SET last_used_at = now()
+WHERE id = $1;
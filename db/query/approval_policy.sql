@@ -0,0 +1,40 @@
+-- name: UpsertApprovalPolicy :one
+INSERT INTO approval_policies (account_id, threshold)
+VALUES ($1, $2)
+ON CONFLICT (account_id) DO UPDATE
+    SET threshold = EXCLUDED.threshold
+RETURNING *;
+
+-- name: GetApprovalPolicy :one
+SELECT *
+FROM approval_policies
+WHERE account_id = $1
+LIMIT 1;
+
+-- name: DeleteApprovalPolicy :exec
+DELETE
+FROM approval_policies
+WHERE account_id = $1;
+
+-- name: CreateApprovalPolicyApprover :one
+INSERT INTO approval_policy_approvers (account_id, username)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetApprovalPolicyApprover :one
+SELECT *
+FROM approval_policy_approvers
+WHERE account_id = $1
+  AND username = $2
+LIMIT 1;
+
+-- name: ListApprovalPolicyApprovers :many
+SELECT username
+FROM approval_policy_approvers
+WHERE account_id = $1
+ORDER BY username;
+
+-- name: DeleteApprovalPolicyApprovers :exec
+DELETE
+FROM approval_policy_approvers
+WHERE account_id = $1;
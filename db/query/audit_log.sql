@@ -0,0 +1,49 @@
+-- name: LockAuditLog :exec
+SELECT pg_advisory_xact_lock(hashtext('audit_log'));
+
+-- name: GetLastChainedAuditLog :one
+SELECT *
+FROM audit_log
+WHERE chain_seq > 0
+ORDER BY chain_seq DESC
+LIMIT 1;
+
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (actor, ip, route, request_id, action, entity, entity_id, before, after, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: ListUnchainedAuditLog :many
+SELECT *
+FROM audit_log
+WHERE chain_seq = 0
+ORDER BY id
+LIMIT $1;
+
+-- name: ChainAuditLog :one
+UPDATE audit_log
+SET chain_seq = $2,
+    prev_hash = $3,
+    hash      = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: ListAuditLog :many
+SELECT *
+FROM audit_log
+WHERE (sqlc.arg(actor)::varchar = '' OR actor = sqlc.arg(actor))
+  AND (sqlc.arg(action)::varchar = '' OR action = sqlc.arg(action))
+  AND (sqlc.arg(entity)::varchar = '' OR entity = sqlc.arg(entity))
+  AND (sqlc.arg(entity_id)::varchar = '' OR entity_id = sqlc.arg(entity_id))
+  AND (sqlc.arg(request_id)::varchar = '' OR request_id = sqlc.arg(request_id))
+  AND created_at >= sqlc.arg(from_time)
+  AND created_at < sqlc.arg(to_time)
+ORDER BY id DESC
+LIMIT sqlc.arg(max_records) OFFSET sqlc.arg(skip_records);
+
+-- name: ListAuditLogAfter :many
+SELECT *
+FROM audit_log
+WHERE chain_seq > $1
+ORDER BY chain_seq
+LIMIT $2;
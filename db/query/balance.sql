@@ -0,0 +1,71 @@
+-- name: CreateBalanceSnapshots :execrows
+INSERT INTO balance_snapshots (account_id, snapshot_date, snapshot_at, balance)
+SELECT a.id,
+       sqlc.arg(snapshot_date)::date,
+       sqlc.arg(end_of_day)::timestamptz,
+       (a.balance - COALESCE((SELECT sum(e.amount)
+                              FROM entries e
+                              WHERE e.account_id = a.id
+                                AND e.created_at >= sqlc.arg(end_of_day)), 0))::bigint
+FROM accounts a
+WHERE a.created_at < sqlc.arg(end_of_day)
+ON CONFLICT (account_id, snapshot_date) DO NOTHING;
+
+-- name: ListBalanceSnapshots :many
+SELECT *
+FROM balance_snapshots
+WHERE account_id = $1
+ORDER BY snapshot_date
+LIMIT $2 OFFSET $3;
+
+-- name: GetAccountBalanceAsOf :one
+SELECT (CASE
+            WHEN s.snapshot_at IS NULL
+                THEN a.balance - COALESCE((SELECT sum(e.amount)
+                                           FROM entries e
+                                           WHERE e.account_id = a.id
+                                             AND e.created_at >= sqlc.arg(as_of)), 0)
+            ELSE s.balance + COALESCE((SELECT sum(e.amount)
+                                       FROM entries e
+                                       WHERE e.account_id = a.id
+                                         AND e.created_at >= s.snapshot_at
+                                         AND e.created_at < sqlc.arg(as_of)), 0)
+    END)::bigint AS balance
+FROM accounts a
+         LEFT JOIN LATERAL (SELECT bs.balance, bs.snapshot_at
+                            FROM balance_snapshots bs
+                            WHERE bs.account_id = a.id
+                              AND bs.snapshot_at <= sqlc.arg(as_of)
+                            ORDER BY bs.snapshot_at DESC
+                            LIMIT 1) s ON TRUE
+WHERE a.id = sqlc.arg(account_id);
+
+-- name: GetAccountBalanceHistory :many
+SELECT p.period::timestamptz AS period,
+       (CASE
+            WHEN s.snapshot_at IS NULL
+                THEN a.balance - COALESCE((SELECT sum(e.amount)
+                                           FROM entries e
+                                           WHERE e.account_id = a.id
+                                             AND e.created_at >= p.closing), 0)
+            ELSE s.balance + COALESCE((SELECT sum(e.amount)
+                                       FROM entries e
+                                       WHERE e.account_id = a.id
+                                         AND e.created_at >= s.snapshot_at
+                                         AND e.created_at < p.closing), 0)
+           END)::bigint          AS balance
+FROM accounts a
+         CROSS JOIN LATERAL (SELECT g.period,
+                                    least(g.period + ('1 ' || sqlc.arg(step)::text)::interval,
+                                          sqlc.arg(to_time)::timestamptz) AS closing
+                             FROM generate_series(date_trunc(sqlc.arg(step)::text, sqlc.arg(from_time)::timestamptz),
+                                                  sqlc.arg(to_time)::timestamptz - interval '1 microsecond',
+                                                  ('1 ' || sqlc.arg(step)::text)::interval) g(period)) p
+         LEFT JOIN LATERAL (SELECT bs.balance, bs.snapshot_at
+                            FROM balance_snapshots bs
+                            WHERE bs.account_id = a.id
+                              AND bs.snapshot_at <= p.closing
+                            ORDER BY bs.snapshot_at DESC
+                            LIMIT 1) s ON TRUE
+WHERE a.id = sqlc.arg(account_id)
+ORDER BY p.period;
@@ -0,0 +1,29 @@
+-- name: CreateBatch :one
+INSERT INTO batches (initiator, mode)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetBatch :one
+SELECT *
+FROM batches
+WHERE id = $1
+LIMIT 1;
+
+-- name: CompleteBatch :one
+UPDATE batches
+SET status       = $2,
+    error        = $3,
+    completed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateBatchLeg :one
+INSERT INTO batch_legs (batch_id, position, from_account_id, to_account_id, amount, status, transfer_id, error)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListBatchLegs :many
+SELECT *
+FROM batch_legs
+WHERE batch_id = $1
+ORDER BY position;
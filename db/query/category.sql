@@ -0,0 +1,94 @@
+-- name: CreateCategory :one
+INSERT INTO categories (owner, name)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: GetCategory :one
+SELECT *
+FROM categories
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListCategories :many
+SELECT *
+FROM categories
+WHERE owner IS NULL
+   OR owner = $1
+ORDER BY owner NULLS FIRST, name;
+
+-- name: DeleteCategory :exec
+DELETE
+FROM categories
+WHERE id = $1;
+
+-- name: CreateCategoryRule :one
+INSERT INTO category_rules (owner, category_id, counterparty_account_id, pattern, priority)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetCategoryRule :one
+SELECT *
+FROM category_rules
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListCategoryRules :many
+SELECT *
+FROM category_rules
+WHERE owner = $1
+ORDER BY priority DESC, id;
+
+-- name: DeleteCategoryRule :exec
+DELETE
+FROM category_rules
+WHERE id = $1;
+
+-- name: SetEntryCategory :one
+UPDATE entries
+SET category_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CategorizeJournalEntries :many
+UPDATE entries e
+SET category_id = (SELECT r.category_id
+                   FROM category_rules r
+                            JOIN accounts a ON a.owner = r.owner
+                   WHERE a.id = e.account_id
+                     AND (r.counterparty_account_id IS NULL OR EXISTS(SELECT 1
+                                                                     FROM entries o
+                                                                     WHERE o.journal_id = e.journal_id
+                                                                       AND o.account_id = r.counterparty_account_id
+                                                                       AND o.account_id <> e.account_id))
+                     AND (r.pattern IS NULL OR strpos(lower(e.description), lower(r.pattern)) > 0)
+                   ORDER BY r.priority DESC, r.id
+                   LIMIT 1)
+WHERE e.journal_id = $1
+  AND e.category_id IS NULL
+RETURNING *;
+
+-- name: GetAccountAnalyticsByCategory :many
+SELECT e.category_id                                                    AS category_id,
+       coalesce(c.name, '')::text                                       AS category,
+       coalesce(sum(e.amount) FILTER (WHERE e.amount > 0), 0)::bigint  AS inflow,
+       coalesce(-sum(e.amount) FILTER (WHERE e.amount < 0), 0)::bigint AS outflow,
+       count(*)                                                         AS entries
+FROM entries e
+         LEFT JOIN categories c ON c.id = e.category_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+GROUP BY e.category_id, c.name
+ORDER BY outflow DESC, inflow DESC, category;
+
+-- name: GetAccountAnalyticsByMonth :many
+SELECT date_trunc('month', e.created_at)::timestamptz                   AS month,
+       coalesce(sum(e.amount) FILTER (WHERE e.amount > 0), 0)::bigint  AS inflow,
+       coalesce(-sum(e.amount) FILTER (WHERE e.amount < 0), 0)::bigint AS outflow,
+       count(*)                                                         AS entries
+FROM entries e
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+GROUP BY month
+ORDER BY month;
@@ -1,6 +1,6 @@
 -- name: CreateEntry :one
-INSERT INTO entries (account_id, amount)
-VALUES ($1, $2)
+INSERT INTO entries (account_id, amount, journal_id, description)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetEntry :one
@@ -16,6 +16,14 @@ WHERE account_id = $1
 ORDER BY id
 LIMIT $2 OFFSET $3;
 
+-- name: SearchEntries :many
+SELECT *
+FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND to_tsvector('simple', description) @@ websearch_to_tsquery('simple', sqlc.arg(query)::text)
+ORDER BY id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
 -- name: UpdateEntryAmount :one
 UPDATE entries
 SET amount = $2
@@ -26,3 +34,10 @@ RETURNING *;
 DELETE
 FROM entries
 WHERE id = $1;
+
+-- name: CountAccountDebitsSince :one
+SELECT count(DISTINCT journal_id)
+FROM entries
+WHERE account_id = $1
+  AND amount < 0
+  AND created_at >= $2;
@@ -0,0 +1,29 @@
+-- name: CreateFeeSchedule :one
+INSERT INTO fee_schedules (account_type, transfer_kind, currency, method, flat_fee, rate_bp, min_fee, max_fee,
+                           free_monthly_transfers, fee_account_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetFeeSchedule :one
+SELECT *
+FROM fee_schedules
+WHERE account_type = $1
+  AND transfer_kind = $2
+  AND currency = $3
+LIMIT 1;
+
+-- name: DeleteFeeSchedule :exec
+DELETE
+FROM fee_schedules
+WHERE id = $1;
+
+-- name: CreateFeeTier :one
+INSERT INTO fee_tiers (fee_schedule_id, min_amount, fee)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListFeeTiers :many
+SELECT *
+FROM fee_tiers
+WHERE fee_schedule_id = $1
+ORDER BY min_amount;
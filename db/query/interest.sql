@@ -0,0 +1,76 @@
+-- name: UpsertInterestRate :one
+INSERT INTO interest_rates (account_type, currency, rate_bp, day_count, interest_account_id)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (account_type, currency) DO UPDATE
+    SET rate_bp             = excluded.rate_bp,
+        day_count           = excluded.day_count,
+        interest_account_id = excluded.interest_account_id
+RETURNING *;
+
+-- name: GetInterestRate :one
+SELECT *
+FROM interest_rates
+WHERE account_type = $1
+  AND currency = $2
+LIMIT 1;
+
+-- name: DeleteInterestRate :exec
+DELETE
+FROM interest_rates
+WHERE account_type = $1
+  AND currency = $2;
+
+-- name: ListInterestBearingAccounts :many
+SELECT a.id,
+       r.rate_bp,
+       r.day_count,
+       (a.balance - COALESCE((SELECT sum(e.amount)
+                              FROM entries e
+                              WHERE e.account_id = a.id
+                                AND e.created_at >= sqlc.arg(end_of_day)), 0))::bigint AS balance
+FROM accounts a
+         JOIN interest_rates r ON r.account_type = a.account_type AND r.currency = a.currency
+WHERE a.created_at < sqlc.arg(end_of_day)
+ORDER BY a.id;
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (account_id, accrual_date, balance, rate_bp, day_count, amount_micros)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (account_id, accrual_date) DO NOTHING
+RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT *
+FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date
+LIMIT $2 OFFSET $3;
+
+-- name: ListUncapitalizedInterest :many
+SELECT i.account_id, r.interest_account_id, sum(i.amount_micros)::bigint AS amount_micros
+FROM interest_accruals i
+         JOIN accounts a ON a.id = i.account_id
+         JOIN interest_rates r ON r.account_type = a.account_type AND r.currency = a.currency
+WHERE i.accrual_date < $1
+  AND i.capitalization_id IS NULL
+GROUP BY i.account_id, r.interest_account_id
+ORDER BY i.account_id;
+
+-- name: CreateInterestCapitalization :one
+INSERT INTO interest_capitalizations (account_id, period, amount)
+VALUES ($1, $2, $3)
+ON CONFLICT (account_id, period) DO NOTHING
+RETURNING *;
+
+-- name: SetInterestCapitalizationJournal :one
+UPDATE interest_capitalizations
+SET journal_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkInterestAccrualsCapitalized :execrows
+UPDATE interest_accruals
+SET capitalization_id = $2
+WHERE account_id = $1
+  AND accrual_date < $3
+  AND capitalization_id IS NULL;
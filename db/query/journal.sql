@@ -0,0 +1,15 @@
+-- name: CreateJournal :one
+INSERT INTO journals DEFAULT VALUES
+RETURNING *;
+
+-- name: GetJournal :one
+SELECT *
+FROM journals
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListJournalEntries :many
+SELECT *
+FROM entries
+WHERE journal_id = $1
+ORDER BY id;
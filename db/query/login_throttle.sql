@@ -0,0 +1,70 @@
+-- name: ListLoginThrottles :many
+SELECT *
+FROM login_throttles
+WHERE (scope = 'username' AND subject = sqlc.arg(username)::text)
+   OR (scope = 'ip' AND subject = sqlc.arg(client_ip)::text);
+
+-- name: GetLoginThrottle :one
+SELECT *
+FROM login_throttles
+WHERE scope = $1
+  AND subject = $2
+LIMIT 1;
+
+-- name: GetLoginThrottleForUpdate :one
+SELECT *
+FROM login_throttles
+WHERE scope = $1
+  AND subject = $2
+LIMIT 1 FOR NO KEY UPDATE;
+
+-- name: CreateLoginThrottle :exec
+INSERT INTO login_throttles (scope, subject, failures, last_failed_at)
+VALUES ($1, $2, 0, now())
+ON CONFLICT (scope, subject) DO NOTHING;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_throttles (scope, subject, failures, last_failed_at)
+VALUES (sqlc.arg(scope), sqlc.arg(subject), 1, now())
+ON CONFLICT (scope, subject) DO UPDATE
+    SET failures       = CASE
+                             WHEN login_throttles.last_failed_at < sqlc.arg(reset_before)::timestamptz THEN 1
+                             ELSE login_throttles.failures + 1
+        END,
+        last_failed_at = now()
+RETURNING *;
+
+-- name: LockLoginThrottle :one
+UPDATE login_throttles
+SET failures     = 0,
+    locked_until = sqlc.arg(locked_until)
+WHERE scope = sqlc.arg(scope)
+  AND subject = sqlc.arg(subject)
+  AND failures >= sqlc.arg(max_failures)::int
+RETURNING *;
+
+-- name: ReleaseLoginAttempt :exec
+UPDATE login_throttles
+SET failures = GREATEST(failures - 1, 0)
+WHERE scope = $1
+  AND subject = $2;
+
+-- name: UnlockLoginThrottle :one
+UPDATE login_throttles
+SET failures     = 0,
+    locked_until = NULL
+WHERE scope = $1
+  AND subject = $2
+RETURNING *;
+
+-- name: DeleteLoginThrottle :exec
+DELETE
+FROM login_throttles
+WHERE scope = $1
+  AND subject = $2;
+
+-- name: DeleteStaleLoginThrottles :execrows
+DELETE
+FROM login_throttles
+WHERE last_failed_at < $1
+  AND (locked_until IS NULL OR locked_until < now());
@@ -0,0 +1,44 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (event_id, event_type, account_id, payload)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ClaimOutboxEvents :many
+SELECT *
+FROM outbox o
+WHERE o.dispatched_at IS NULL
+  AND NOT EXISTS(SELECT 1
+                 FROM outbox p
+                 WHERE p.account_id = o.account_id
+                   AND p.dispatched_at IS NULL
+                   AND p.id < o.id)
+ORDER BY o.id
+LIMIT $1 FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventsDispatched :execrows
+UPDATE outbox
+SET dispatched_at = now()
+WHERE id = ANY (sqlc.arg(ids)::bigint[]);
+
+-- name: ListAccountOutboxEvents :many
+SELECT *
+FROM outbox
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: ListAccountEventsAfter :many
+SELECT *
+FROM outbox
+WHERE account_id = sqlc.arg(account_id)
+  AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(max_events);
+
+-- name: GetLatestAccountEventID :one
+SELECT COALESCE(MAX(id), 0)::bigint
+FROM outbox
+WHERE account_id = $1;
+
+-- name: NotifyAccountEvent :exec
+SELECT pg_notify('account_events', sqlc.arg(notification)::text);
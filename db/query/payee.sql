@@ -0,0 +1,57 @@
+-- name: CreatePayee :one
+INSERT INTO payees (owner, nickname, account_id, reference)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetPayee :one
+SELECT *
+FROM payees
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListPayees :many
+SELECT *
+FROM payees
+WHERE owner = $1
+ORDER BY nickname
+LIMIT $2 OFFSET $3;
+
+-- name: UpdatePayee :one
+UPDATE payees
+SET nickname  = $2,
+    reference = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeletePayee :exec
+DELETE
+FROM payees
+WHERE id = $1;
+
+-- name: ListRecentPayeesForUpdate :many
+SELECT *
+FROM payees
+WHERE account_id = sqlc.arg(to_account_id)
+  AND created_at > sqlc.arg(added_after)
+  AND owner IN (SELECT owner
+                FROM accounts
+                WHERE id = sqlc.arg(from_account_id)
+                UNION
+                SELECT username
+                FROM account_holders
+                WHERE account_id = sqlc.arg(from_account_id))
+ORDER BY id
+FOR NO KEY UPDATE;
+
+-- name: SumPayeeAccountTransfers :one
+SELECT COALESCE(sum(amount), 0)::bigint
+FROM transfers
+WHERE to_account_id = sqlc.arg(to_account_id)
+  AND created_at >= sqlc.arg(since)
+  AND from_account_id IN (SELECT id
+                          FROM accounts
+                          WHERE owner = sqlc.arg(owner)
+                          UNION
+                          SELECT account_id
+                          FROM account_holders
+                          WHERE username = sqlc.arg(owner));
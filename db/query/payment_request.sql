@@ -0,0 +1,46 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (requester, payer, to_account_id, amount, memo, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT *
+FROM payment_requests
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT *
+FROM payment_requests
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListIncomingPaymentRequests :many
+SELECT *
+FROM payment_requests
+WHERE payer = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: ListOutgoingPaymentRequests :many
+SELECT *
+FROM payment_requests
+WHERE requester = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: DecidePaymentRequest :one
+UPDATE payment_requests
+SET status          = $2,
+    from_account_id = $3,
+    transfer_id     = $4,
+    decided_at      = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ExpirePaymentRequests :execrows
+UPDATE payment_requests
+SET status = 'expired'
+WHERE status = 'pending'
+  AND expires_at <= now();
@@ -0,0 +1,33 @@
+-- name: CreatePendingTransfer :one
+INSERT INTO pending_transfers (from_account_id, to_account_id, amount, initiator, expires_at, instant, payee_id,
+                               reference, memo, remittance_info)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetPendingTransfer :one
+SELECT *
+FROM pending_transfers
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetPendingTransferForUpdate :one
+SELECT *
+FROM pending_transfers
+WHERE id = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: DecidePendingTransfer :one
+UPDATE pending_transfers
+SET status      = $2,
+    decided_by  = $3,
+    transfer_id = $4,
+    decided_at  = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ExpirePendingTransfers :execrows
+UPDATE pending_transfers
+SET status = 'expired'
+WHERE status = 'pending'
+  AND expires_at <= now();
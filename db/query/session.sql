@@ -0,0 +1,55 @@
+-- name: CreateSession :one
+INSERT INTO sessions (family_id, username, refresh_token_hash, user_agent, client_ip, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetSession :one
+SELECT *
+FROM sessions
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetSessionByRefreshToken :one
+SELECT *
+FROM sessions
+WHERE refresh_token_hash = $1
+LIMIT 1;
+
+-- name: GetSessionByRefreshTokenForUpdate :one
+SELECT *
+FROM sessions
+WHERE refresh_token_hash = $1
+LIMIT 1
+FOR UPDATE;
+
+-- name: ListActiveSessions :many
+SELECT *
+FROM sessions
+WHERE username = $1
+  AND NOT revoked
+  AND rotated_at IS NULL
+  AND expires_at > now()
+ORDER BY id DESC;
+
+-- name: MarkSessionRotated :exec
+UPDATE sessions
+SET rotated_at = now()
+WHERE id = $1;
+
+-- name: RevokeSessionFamily :execrows
+UPDATE sessions
+SET revoked = true
+WHERE family_id = $1
+  AND NOT revoked;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE
+FROM sessions
+WHERE expires_at < $1;
+
+-- name: RevokeUserSessions :execrows
+UPDATE sessions
+SET revoked = true
+WHERE username = $1
+  AND family_id <> sqlc.arg(keep_family_id)
+  AND NOT revoked;
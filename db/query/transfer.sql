@@ -1,6 +1,6 @@
 -- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
+INSERT INTO transfers (from_account_id, to_account_id, amount, payee_id, reference, memo, remittance_info)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
 RETURNING *;
 
 -- name: GetTransfer :one
@@ -17,6 +17,15 @@ WHERE from_account_id = $1
 ORDER BY id
 LIMIT $3 OFFSET $4;
 
+-- name: SearchTransfers :many
+SELECT *
+FROM transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND to_tsvector('simple', reference || ' ' || memo) ||
+      jsonb_to_tsvector('simple', remittance_info, '["string"]') @@ websearch_to_tsquery('simple', sqlc.arg(query)::text)
+ORDER BY id
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
 -- name: UpdateTransfer :one
 UPDATE transfers
 SET amount = $2
@@ -27,3 +36,9 @@ RETURNING *;
 DELETE
 FROM transfers
 WHERE id = $1;
+
+-- name: CountTransfersSince :one
+SELECT count(*)
+FROM transfers
+WHERE from_account_id = $1
+  AND created_at >= $2;
@@ -0,0 +1,55 @@
+-- name: UpsertTOTPCredential :one
+INSERT INTO totp_credentials (username, secret)
+VALUES ($1, $2)
+ON CONFLICT (username) DO UPDATE
+    SET secret         = excluded.secret,
+        last_used_step = 0,
+        created_at     = now()
+WHERE totp_credentials.confirmed_at IS NULL
+RETURNING *;
+
+-- name: GetTOTPCredential :one
+SELECT *
+FROM totp_credentials
+WHERE username = $1
+LIMIT 1;
+
+-- name: ConfirmTOTPCredential :one
+UPDATE totp_credentials
+SET confirmed_at = coalesce(confirmed_at, now())
+WHERE username = $1
+RETURNING *;
+
+-- name: UseTOTPStep :execrows
+UPDATE totp_credentials
+SET last_used_step = $2
+WHERE username = $1
+  AND last_used_step < $2;
+
+-- name: DeleteTOTPCredential :execrows
+DELETE
+FROM totp_credentials
+WHERE username = $1;
+
+-- name: CreateRecoveryCode :one
+INSERT INTO recovery_codes (username, code_hash)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: UseRecoveryCode :execrows
+UPDATE recovery_codes
+SET used_at = now()
+WHERE username = $1
+  AND code_hash = $2
+  AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT count(*)
+FROM recovery_codes
+WHERE username = $1
+  AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :execrows
+DELETE
+FROM recovery_codes
+WHERE username = $1;
@@ -11,13 +11,47 @@ LIMIT 1;
 
 -- name: UpdateUserPassword :one
 UPDATE users
-SET hashed_password = $3
+SET hashed_password      = $2,
+    password_modified_at = now()
 WHERE username = $1
-  AND hashed_password = $2
 RETURNING *;
 
+-- name: RehashUserPassword :execrows
+UPDATE users
+SET hashed_password = sqlc.arg(hashed_password)
+WHERE username = sqlc.arg(username)
+  AND hashed_password = sqlc.arg(old_hashed_password);
+
 -- name: DeleteUser :exec
 DELETE
 FROM users
+WHERE username = $1;
+
+-- name: SetUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING *;
+
+-- name: SearchUsers :many
+SELECT *
+FROM users
+WHERE (sqlc.arg(query)::text = ''
+    OR username ILIKE '%' || sqlc.arg(query)::text || '%'
+    OR email ILIKE '%' || sqlc.arg(query)::text || '%'
+    OR first_name || ' ' || last_name ILIKE '%' || sqlc.arg(query)::text || '%')
+  AND (sqlc.arg(role)::text = '' OR role = sqlc.arg(role)::text)
+ORDER BY username
+LIMIT sqlc.arg(max_users) OFFSET sqlc.arg(skip_users);
+
+-- name: GetUserByEmail :one
+SELECT *
+FROM users
+WHERE email = $1
+LIMIT 1;
+
+-- name: SetUserEmailVerified :one
+UPDATE users
+SET email_verified_at = coalesce(email_verified_at, now())
 WHERE username = $1
-  AND hashed_password = $2;
+RETURNING *;
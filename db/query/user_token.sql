@@ -0,0 +1,21 @@
+-- name: CreateUserToken :one
+INSERT INTO user_tokens (username, purpose, token_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetUserTokenForUpdate :one
+SELECT *
+FROM user_tokens
+WHERE token_hash = $1
+LIMIT 1
+FOR UPDATE;
+
+-- name: MarkUserTokenUsed :exec
+UPDATE user_tokens
+SET used_at = now()
+WHERE id = $1;
+
+-- name: DeleteExpiredUserTokens :execrows
+DELETE
+FROM user_tokens
+WHERE expires_at < $1;
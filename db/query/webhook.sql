@@ -0,0 +1,86 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (owner, url, events, secret)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhookEndpoint :one
+SELECT *
+FROM webhook_endpoints
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListWebhookEndpoints :many
+SELECT *
+FROM webhook_endpoints
+WHERE owner = $1
+ORDER BY id;
+
+-- name: DeleteWebhookEndpoint :exec
+DELETE
+FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveries :many
+INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+SELECT w.id, sqlc.arg(event_id)::varchar, sqlc.arg(event_type)::varchar, sqlc.arg(payload)::jsonb
+FROM webhook_endpoints w
+WHERE sqlc.arg(event_type) = ANY (w.events)
+  AND w.owner IN (SELECT a.owner
+                  FROM accounts a
+                  WHERE a.id = ANY (sqlc.arg(account_ids)::bigint[]))
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT *
+FROM webhook_deliveries
+WHERE id = $1
+LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE endpoint_id = $1
+ORDER BY id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries d
+SET next_attempt_at = sqlc.arg(lease_until)
+FROM webhook_endpoints w
+WHERE w.id = d.endpoint_id
+  AND d.id IN (SELECT id
+               FROM webhook_deliveries
+               WHERE status = 'pending'
+                 AND next_attempt_at <= now()
+               ORDER BY next_attempt_at
+               LIMIT sqlc.arg(max_deliveries) FOR UPDATE SKIP LOCKED)
+RETURNING d.*, w.url, w.secret;
+
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status          = $2,
+    attempts        = attempts + 1,
+    next_attempt_at = $3,
+    delivered_at    = CASE WHEN $2 = 'delivered' THEN now() END
+WHERE id = $1
+RETURNING *;
+
+-- name: RedeliverWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status          = 'pending',
+    attempts        = 0,
+    next_attempt_at = now(),
+    delivered_at    = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateWebhookAttempt :one
+INSERT INTO webhook_attempts (delivery_id, status_code, error, duration_ms)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListWebhookAttempts :many
+SELECT *
+FROM webhook_attempts
+WHERE delivery_id = $1
+ORDER BY id;
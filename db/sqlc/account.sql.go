@@ -11,7 +11,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
 `
 
 type AddAccountBalanceParams struct {
@@ -28,24 +28,36 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (owner, balance, currency)
-VALUES ($1, $2, $3)
-RETURNING id, owner, balance, currency, created_at
+INSERT INTO accounts (owner, balance, currency, account_type, number)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
 `
 
 type CreateAccountParams struct {
-	Owner    string `json:"owner"`
-	Balance  int64  `json:"balance"`
-	Currency string `json:"currency"`
+	Owner       string `json:"owner"`
+	Balance     int64  `json:"balance"`
+	Currency    string `json:"currency"`
+	AccountType string `json:"account_type"`
+	Number      string `json:"number"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.AccountType,
+		arg.Number,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -53,6 +65,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
 	)
 	return i, err
 }
@@ -68,8 +84,38 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 	return err
 }
 
+const freezeAccount = `-- name: FreezeAccount :one
+UPDATE accounts
+SET frozen_at     = coalesce(frozen_at, now()),
+    frozen_reason = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
+`
+
+type FreezeAccountParams struct {
+	ID           int64  `json:"id"`
+	FrozenReason string `json:"frozen_reason"`
+}
+
+func (q *Queries) FreezeAccount(ctx context.Context, arg FreezeAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, freezeAccount, arg.ID, arg.FrozenReason)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
+	)
+	return i, err
+}
+
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
 FROM accounts
 WHERE id = $1
 LIMIT 1
@@ -84,12 +130,40 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
+	)
+	return i, err
+}
+
+const getAccountByNumber = `-- name: GetAccountByNumber :one
+SELECT id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
+FROM accounts
+WHERE number = $1
+LIMIT 1
+`
+
+func (q *Queries) GetAccountByNumber(ctx context.Context, number string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountByNumber, number)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
 FROM accounts
 WHERE id = $1
 LIMIT 1
@@ -105,24 +179,160 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
+	)
+	return i, err
+}
+
+const getSystemAccount = `-- name: GetSystemAccount :one
+SELECT id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
+FROM accounts
+WHERE account_type = 'system'
+  AND currency = $1
+ORDER BY id
+LIMIT 1
+`
+
+func (q *Queries) GetSystemAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getSystemAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
 FROM accounts
+WHERE owner = $1
+   OR id IN (SELECT account_id
+             FROM account_holders
+             WHERE username = $1
+               AND status = 'active')
 ORDER BY id
-LIMIT $1 OFFSET $2
+LIMIT $2 OFFSET $3
 `
 
 type ListAccountsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AccountType,
+			&i.Number,
+			&i.FrozenAt,
+			&i.FrozenReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnerAccounts = `-- name: ListOwnerAccounts :many
+SELECT id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
+FROM accounts
+WHERE owner = $1
+ORDER BY currency, id
+`
+
+func (q *Queries) ListOwnerAccounts(ctx context.Context, owner string) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listOwnerAccounts, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.AccountType,
+			&i.Number,
+			&i.FrozenAt,
+			&i.FrozenReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchAccounts = `-- name: SearchAccounts :many
+SELECT id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
+FROM accounts
+WHERE ($1::text = '' OR owner = $1::text)
+  AND ($2::text = '' OR number = $2::text)
+  AND ($3::text = '' OR currency = $3::text)
+  AND (NOT $4::boolean OR frozen_at IS NOT NULL)
+ORDER BY id
+LIMIT $5 OFFSET $6
+`
+
+type SearchAccountsParams struct {
+	Owner        string `json:"owner"`
+	Number       string `json:"number"`
+	Currency     string `json:"currency"`
+	FrozenOnly   bool   `json:"frozen_only"`
+	MaxAccounts  int32  `json:"max_accounts"`
+	SkipAccounts int32  `json:"skip_accounts"`
+}
+
+func (q *Queries) SearchAccounts(ctx context.Context, arg SearchAccountsParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, searchAccounts,
+		arg.Owner,
+		arg.Number,
+		arg.Currency,
+		arg.FrozenOnly,
+		arg.MaxAccounts,
+		arg.SkipAccounts,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -136,6 +346,10 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.AccountType,
+			&i.Number,
+			&i.FrozenAt,
+			&i.FrozenReason,
 		); err != nil {
 			return nil, err
 		}
@@ -150,11 +364,36 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
+const unfreezeAccount = `-- name: UnfreezeAccount :one
+UPDATE accounts
+SET frozen_at     = NULL,
+    frozen_reason = ''
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
+`
+
+func (q *Queries) UnfreezeAccount(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, unfreezeAccount, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
+	)
+	return i, err
+}
+
 const updateAccountBalance = `-- name: UpdateAccountBalance :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, account_type, number, frozen_at, frozen_reason
 `
 
 type UpdateAccountBalanceParams struct {
@@ -171,6 +410,10 @@ func (q *Queries) UpdateAccountBalance(ctx context.Context, arg UpdateAccountBal
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.AccountType,
+		&i.Number,
+		&i.FrozenAt,
+		&i.FrozenReason,
 	)
 	return i, err
 }
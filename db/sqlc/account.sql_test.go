@@ -11,16 +11,33 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func createRandomAccount(t *testing.T) db.Account {
+func createRandomAccount(t testing.TB) db.Account {
+	t.Helper()
+
+	return createRandomAccountWithCurrency(t, util.RandomCurrency())
+}
+
+func createRandomAccountWithCurrency(t testing.TB, currency string) db.Account {
+	t.Helper()
+
+	return createRandomAccountWithType(t, db.AccountTypeChecking, currency)
+}
+
+func createRandomAccountWithType(t testing.TB, accountType, currency string) db.Account {
 	t.Helper()
 
 	user := createRandomUser(t)
 
+	number, err := util.NewAccountNumber()
+	require.NoError(t, err)
+
 	// construct params
 	arg := db.CreateAccountParams{
-		Owner:    user.Username,
-		Balance:  util.RandomBalance(),
-		Currency: util.RandomCurrency(),
+		Owner:       user.Username,
+		Balance:     util.RandomBalance(),
+		Currency:    currency,
+		AccountType: accountType,
+		Number:      number,
 	}
 
 	// create account
@@ -32,6 +49,8 @@ func createRandomAccount(t *testing.T) db.Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.AccountType, account.AccountType)
+	require.Equal(t, arg.Number, account.Number)
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
 
@@ -59,6 +78,29 @@ func TestQueries_GetAccount(t *testing.T) {
 	}
 }
 
+func TestQueries_GetAccountByNumber(t *testing.T) {
+	acc1 := createRandomAccount(t)
+
+	// get account
+	acc2, err := testQueries.GetAccountByNumber(context.Background(), acc1.Number)
+	require.NoError(t, err)
+
+	// compare values
+	if assert.NotEmpty(t, acc2) {
+		assert.Equal(t, acc1.ID, acc2.ID)
+		assert.Equal(t, acc1.Number, acc2.Number)
+	}
+
+	// the number is unique
+	_, err = testQueries.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:       acc1.Owner,
+		Currency:    acc1.Currency,
+		AccountType: acc1.AccountType,
+		Number:      acc1.Number,
+	})
+	assert.Error(t, err)
+}
+
 func TestQueries_GetAccountForUpdate(t *testing.T) {
 	acc1 := createRandomAccount(t)
 
@@ -77,24 +119,63 @@ func TestQueries_GetAccountForUpdate(t *testing.T) {
 }
 
 func TestQueries_ListAccounts(t *testing.T) {
-	for i := 0; i < 10; i++ {
-		createRandomAccount(t)
-	}
+	owned := createRandomAccount(t)
+	joint := createRandomAccount(t)
+	invited := createRandomAccount(t)
+	createRandomAccount(t)
 
-	arg := db.ListAccountsParams{
-		Limit:  5,
-		Offset: 5,
+	// the owner of the first account holds the joint account
+	// and is only invited to the other one
+	for _, account := range []db.Account{joint, invited} {
+		_, err := testQueries.CreateAccountHolder(context.Background(), db.CreateAccountHolderParams{
+			AccountID: account.ID,
+			Username:  owned.Owner,
+			Role:      db.HolderRoleViewer,
+			InvitedBy: account.Owner,
+		})
+		require.NoError(t, err)
 	}
 
+	_, err := testQueries.AcceptAccountHolder(context.Background(), db.AcceptAccountHolderParams{
+		AccountID: joint.ID,
+		Username:  owned.Owner,
+	})
+	require.NoError(t, err)
+
 	// list accounts
-	accounts, err := testQueries.ListAccounts(context.Background(), arg)
+	accounts, err := testQueries.ListAccounts(context.Background(), db.ListAccountsParams{
+		Owner:  owned.Owner,
+		Limit:  5,
+		Offset: 0,
+	})
 	require.NoError(t, err)
 
-	assert.Len(t, accounts, int(arg.Limit))
+	require.Len(t, accounts, 2)
+	assert.Equal(t, owned.ID, accounts[0].ID)
+	assert.Equal(t, joint.ID, accounts[1].ID)
 
-	for _, account := range accounts {
-		assert.NotEmpty(t, account)
-	}
+	// pages
+	accounts, err = testQueries.ListAccounts(context.Background(), db.ListAccountsParams{
+		Owner:  owned.Owner,
+		Limit:  5,
+		Offset: 1,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, accounts, 1)
+	assert.Equal(t, joint.ID, accounts[0].ID)
+}
+
+func TestQueries_ListOwnerAccounts(t *testing.T) {
+	acc1 := createRandomAccount(t)
+	createRandomAccount(t)
+
+	// list accounts of the owner
+	accounts, err := testQueries.ListOwnerAccounts(context.Background(), acc1.Owner)
+	require.NoError(t, err)
+
+	require.Len(t, accounts, 1)
+	assert.Equal(t, acc1, accounts[0])
 }
 
 func TestQueries_UpdateAccountBalance(t *testing.T) {
@@ -0,0 +1,15 @@
+package db
+
+// Roles of an AccountHolder.
+const (
+	HolderRoleOwner     = "owner"
+	HolderRoleCoOwner   = "co-owner"
+	HolderRoleViewer    = "viewer"
+	HolderRoleSignatory = "signatory"
+)
+
+// Statuses of an AccountHolder.
+const (
+	HolderStatusInvited = "invited"
+	HolderStatusActive  = "active"
+)
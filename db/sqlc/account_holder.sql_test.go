@@ -0,0 +1,95 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccountHolder(t *testing.T, account db.Account, role string) db.AccountHolder {
+	t.Helper()
+
+	user := createRandomUser(t)
+
+	arg := db.CreateAccountHolderParams{
+		AccountID: account.ID,
+		Username:  user.Username,
+		Role:      role,
+		InvitedBy: account.Owner,
+	}
+
+	if role == db.HolderRoleSignatory {
+		arg.SpendLimit = sql.NullInt64{Int64: 100, Valid: true}
+	}
+
+	holder, err := testQueries.CreateAccountHolder(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.AccountID, holder.AccountID)
+	require.Equal(t, arg.Username, holder.Username)
+	require.Equal(t, arg.Role, holder.Role)
+	require.Equal(t, arg.SpendLimit, holder.SpendLimit)
+	require.Equal(t, db.HolderStatusInvited, holder.Status)
+	require.False(t, holder.AcceptedAt.Valid)
+
+	return holder
+}
+
+func createActiveAccountHolder(t *testing.T, account db.Account, role string) db.AccountHolder {
+	t.Helper()
+
+	holder := createRandomAccountHolder(t, account, role)
+
+	holder, err := testQueries.AcceptAccountHolder(context.Background(), db.AcceptAccountHolderParams{
+		AccountID: account.ID,
+		Username:  holder.Username,
+	})
+	require.NoError(t, err)
+
+	return holder
+}
+
+func TestQueries_AccountHolders(t *testing.T) {
+	account := createRandomAccount(t)
+	viewer := createRandomAccountHolder(t, account, db.HolderRoleViewer)
+	signatory := createRandomAccountHolder(t, account, db.HolderRoleSignatory)
+
+	// accept
+	accepted, err := testQueries.AcceptAccountHolder(context.Background(), db.AcceptAccountHolderParams{
+		AccountID: account.ID,
+		Username:  viewer.Username,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.HolderStatusActive, accepted.Status)
+	assert.True(t, accepted.AcceptedAt.Valid)
+
+	// an invitation is accepted only once
+	_, err = testQueries.AcceptAccountHolder(context.Background(), db.AcceptAccountHolderParams{
+		AccountID: account.ID,
+		Username:  viewer.Username,
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+
+	// list
+	holders, err := testQueries.ListAccountHolders(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.Len(t, holders, 2)
+
+	// remove
+	n, err := testQueries.DeleteAccountHolder(context.Background(), db.DeleteAccountHolderParams{
+		AccountID: account.ID,
+		Username:  signatory.Username,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = testQueries.GetAccountHolder(context.Background(), db.GetAccountHolderParams{
+		AccountID: account.ID,
+		Username:  signatory.Username,
+	})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Types of an Account.
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+	AccountTypeBusiness = "business"
+	AccountTypeSystem   = "system"
+)
+
+var (
+	// ErrInsufficientFunds is returned when a debit would bring the balance of an account
+	// below its minimum balance or over its overdraft limit.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrWithdrawalLimitExceeded is returned when an account has used up its withdrawals for the month.
+	ErrWithdrawalLimitExceeded = errors.New("monthly withdrawal limit exceeded")
+	// ErrAccountFrozen is returned when a frozen account is about to be debited.
+	ErrAccountFrozen = errors.New("account is frozen")
+)
+
+// MinAllowedBalance returns the lowest balance an account of the type may reach
+// by a debit. The second return value is false if there is no such limit.
+func (t AccountType) MinAllowedBalance() (int64, bool) {
+	if !t.OverdraftAllowed {
+		return t.MinBalance, true
+	}
+
+	if !t.OverdraftLimit.Valid {
+		return 0, false
+	}
+
+	return -t.OverdraftLimit.Int64, true
+}
+
+// checkDebit checks whether the locked account can be debited by the amount
+// according to the rules of its type. Frozen accounts can not be debited at all.
+func checkDebit(ctx context.Context, q *Queries, account Account, amount int64) error {
+	if account.FrozenAt.Valid {
+		return fmt.Errorf("%w: account %d", ErrAccountFrozen, account.ID)
+	}
+
+	accountType, err := q.GetAccountType(ctx, account.AccountType)
+	if err != nil {
+		return fmt.Errorf("failed to get account type %s: %w", account.AccountType, err)
+	}
+
+	if min, ok := accountType.MinAllowedBalance(); ok && account.Balance-amount < min {
+		return fmt.Errorf("%w: account %d", ErrInsufficientFunds, account.ID)
+	}
+
+	if accountType.WithdrawalsPerMonth.Valid {
+		now := time.Now()
+
+		count, err := q.CountAccountDebitsSince(ctx, CountAccountDebitsSinceParams{
+			AccountID: account.ID,
+			CreatedAt: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to count withdrawals: %w", err)
+		}
+
+		if count >= int64(accountType.WithdrawalsPerMonth.Int32) {
+			return fmt.Errorf("%w: account %d", ErrWithdrawalLimitExceeded, account.ID)
+		}
+	}
+
+	return nil
+}
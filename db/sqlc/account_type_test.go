@@ -0,0 +1,112 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountType_MinAllowedBalance(t *testing.T) {
+	checking, err := testQueries.GetAccountType(context.Background(), db.AccountTypeChecking)
+	require.NoError(t, err)
+
+	min, ok := checking.MinAllowedBalance()
+	assert.True(t, ok)
+	assert.Equal(t, -checking.OverdraftLimit.Int64, min)
+
+	savings, err := testQueries.GetAccountType(context.Background(), db.AccountTypeSavings)
+	require.NoError(t, err)
+
+	min, ok = savings.MinAllowedBalance()
+	assert.True(t, ok)
+	assert.Equal(t, savings.MinBalance, min)
+
+	system, err := testQueries.GetAccountType(context.Background(), db.AccountTypeSystem)
+	require.NoError(t, err)
+
+	_, ok = system.MinAllowedBalance()
+	assert.False(t, ok)
+}
+
+func TestStore_TransferTxOverdraft(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	checking, err := testQueries.GetAccountType(context.Background(), db.AccountTypeChecking)
+	require.NoError(t, err)
+	require.True(t, checking.OverdraftLimit.Valid)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	// up to the overdraft limit
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + checking.OverdraftLimit.Int64,
+	})
+	require.NoError(t, err)
+
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	assert.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	// a credit is always allowed
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        1,
+	})
+	assert.NoError(t, err)
+}
+
+func TestStore_TransferTxSavings(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	savings, err := testQueries.GetAccountType(context.Background(), db.AccountTypeSavings)
+	require.NoError(t, err)
+	require.True(t, savings.WithdrawalsPerMonth.Valid)
+
+	account1 := createRandomAccountWithType(t, db.AccountTypeSavings, "EUR")
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	// no overdraft
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance - savings.MinBalance + 1,
+	})
+	assert.ErrorIs(t, err, db.ErrInsufficientFunds)
+
+	// deposits are not limited
+	for i := 0; i < int(savings.WithdrawalsPerMonth.Int32)+1; i++ {
+		_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: account2.ID,
+			ToAccountID:   account1.ID,
+			Amount:        1,
+		})
+		require.NoError(t, err)
+	}
+
+	// limited number of withdrawals
+	for i := 0; i < int(savings.WithdrawalsPerMonth.Int32); i++ {
+		_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        1,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	assert.ErrorIs(t, err, db.ErrWithdrawalLimitExceeded)
+}
@@ -0,0 +1,61 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueries_APIKey(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := db.CreateAPIKeyParams{
+		Username:   user.Username,
+		Name:       util.RandomOwner(),
+		Prefix:     util.RandomString(16),
+		SecretHash: util.RandomString(64),
+		Scopes:     []string{"accounts:read", "transfers:write"},
+		AllowedIps: []string{},
+	}
+
+	apiKey, err := testQueries.CreateAPIKey(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Equal(t, arg.Scopes, apiKey.Scopes)
+	assert.Empty(t, apiKey.AllowedIps)
+	assert.False(t, apiKey.ExpiresAt.Valid)
+
+	found, err := testQueries.GetAPIKeyByPrefix(context.Background(), arg.Prefix)
+	require.NoError(t, err)
+	assert.Equal(t, apiKey.ID, found.ID)
+
+	keys, err := testQueries.ListAPIKeys(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	rotated, err := testQueries.RotateAPIKey(context.Background(), db.RotateAPIKeyParams{
+		ID:         apiKey.ID,
+		SecretHash: util.RandomString(64),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, apiKey.Prefix, rotated.Prefix)
+	assert.NotEqual(t, apiKey.SecretHash, rotated.SecretHash)
+
+	require.NoError(t, testQueries.TouchAPIKey(context.Background(), apiKey.ID))
+
+	revoked, err := testQueries.RevokeAPIKey(context.Background(), apiKey.ID)
+	require.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Valid)
+	assert.True(t, revoked.LastUsedAt.Valid)
+
+	// a revoked key can not be rotated
+	_, err = testQueries.RotateAPIKey(context.Background(), db.RotateAPIKeyParams{
+		ID:         apiKey.ID,
+		SecretHash: util.RandomString(64),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
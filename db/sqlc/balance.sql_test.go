@@ -0,0 +1,101 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueries_CreateBalanceSnapshots(t *testing.T) {
+	account := createRandomAccount(t)
+
+	now := time.Now().UTC()
+
+	_, err := testQueries.CreateEntry(context.Background(), db.CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    30,
+	})
+	require.NoError(t, err)
+
+	arg := db.CreateBalanceSnapshotsParams{
+		SnapshotDate: now,
+		EndOfDay:     now.Add(time.Hour),
+	}
+	n, err := testQueries.CreateBalanceSnapshots(context.Background(), arg)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, int64(1))
+
+	// snapshots are taken once per day
+	n, err = testQueries.CreateBalanceSnapshots(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	snapshots, err := testQueries.ListBalanceSnapshots(context.Background(), db.ListBalanceSnapshotsParams{
+		AccountID: account.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, account.Balance, snapshots[0].Balance)
+}
+
+func TestQueries_GetAccountBalanceAsOf(t *testing.T) {
+	account := createRandomAccount(t)
+
+	// the balance of the account already contains the entries created below
+	before := time.Now()
+
+	for _, amount := range []int64{10, -4} {
+		_, err := testQueries.CreateEntry(context.Background(), db.CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+	}
+
+	after := time.Now().Add(time.Second)
+
+	balance, err := testQueries.GetAccountBalanceAsOf(context.Background(), db.GetAccountBalanceAsOfParams{
+		AsOf:      before,
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, account.Balance-6, balance)
+
+	history, err := testQueries.GetAccountBalanceHistory(context.Background(), db.GetAccountBalanceHistoryParams{
+		Step:      "day",
+		ToTime:    after,
+		FromTime:  before.AddDate(0, 0, -2),
+		AccountID: account.ID,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, history)
+	assert.Equal(t, account.Balance-6, history[0].Balance)
+	assert.Equal(t, account.Balance, history[len(history)-1].Balance)
+}
+
+func TestStore_CloseAccountTxWithBalanceSnapshots(t *testing.T) {
+	account := createRandomAccount(t)
+
+	now := time.Now().UTC()
+	_, err := testQueries.CreateBalanceSnapshots(context.Background(), db.CreateBalanceSnapshotsParams{
+		SnapshotDate: now,
+		EndOfDay:     now,
+	})
+	require.NoError(t, err)
+
+	// the snapshots are deleted together with the account
+	_, err = db.NewStore(testDB).CloseAccountTx(context.Background(), account.ID)
+	require.NoError(t, err)
+
+	snapshots, err := testQueries.ListBalanceSnapshots(context.Background(), db.ListBalanceSnapshotsParams{
+		AccountID: account.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, snapshots)
+}
@@ -0,0 +1,152 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCategory(t *testing.T, owner string) db.Category {
+	t.Helper()
+
+	arg := db.CreateCategoryParams{
+		Owner: sql.NullString{String: owner, Valid: true},
+		Name:  util.RandomOwner(),
+	}
+
+	category, err := testQueries.CreateCategory(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, category.Owner)
+	require.Equal(t, arg.Name, category.Name)
+	require.NotZero(t, category.ID)
+
+	return category
+}
+
+func TestQueries_ListCategories(t *testing.T) {
+	account := createRandomAccount(t)
+	own := createRandomCategory(t, account.Owner)
+	createRandomCategory(t, createRandomAccount(t).Owner)
+
+	categories, err := testQueries.ListCategories(context.Background(), own.Owner)
+	require.NoError(t, err)
+
+	var system int
+	for _, category := range categories {
+		if !category.Owner.Valid {
+			system++
+		} else {
+			assert.Equal(t, own, category)
+		}
+	}
+	assert.GreaterOrEqual(t, system, 1)
+	assert.Len(t, categories, system+1)
+
+	// category names are unique per user
+	_, err = testQueries.CreateCategory(context.Background(), db.CreateCategoryParams{
+		Owner: own.Owner,
+		Name:  own.Name,
+	})
+	assert.Error(t, err)
+}
+
+func TestStore_TransferTx_Categorize(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+
+	byPattern := createRandomCategory(t, from.Owner)
+	byCounterparty := createRandomCategory(t, to.Owner)
+
+	_, err := testQueries.CreateCategoryRule(context.Background(), db.CreateCategoryRuleParams{
+		Owner:      from.Owner,
+		CategoryID: byPattern.ID,
+		Pattern:    sql.NullString{String: "RENT", Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.CreateCategoryRule(context.Background(), db.CreateCategoryRuleParams{
+		Owner:                 to.Owner,
+		CategoryID:            byCounterparty.ID,
+		CounterpartyAccountID: sql.NullInt64{Int64: from.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	result, err := s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Memo:          "rent for May",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, byPattern.ID, result.FromEntry.CategoryID.Int64)
+	assert.Equal(t, byCounterparty.ID, result.ToEntry.CategoryID.Int64)
+
+	// a transfer not matching any rule stays uncategorized
+	result, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: to.ID,
+		ToAccountID:   from.ID,
+		Amount:        5,
+	})
+	require.NoError(t, err)
+	assert.False(t, result.FromEntry.CategoryID.Valid)
+	assert.False(t, result.ToEntry.CategoryID.Valid)
+}
+
+func TestQueries_GetAccountAnalytics(t *testing.T) {
+	account := createRandomAccount(t)
+	category := createRandomCategory(t, account.Owner)
+
+	entries := []int64{100, -30, -20}
+	for i, amount := range entries {
+		entry, err := testQueries.CreateEntry(context.Background(), db.CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+		require.NoError(t, err)
+
+		if i > 0 {
+			_, err = testQueries.SetEntryCategory(context.Background(), db.SetEntryCategoryParams{
+				ID:         entry.ID,
+				CategoryID: sql.NullInt64{Int64: category.ID, Valid: true},
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	byCategory, err := testQueries.GetAccountAnalyticsByCategory(context.Background(), db.GetAccountAnalyticsByCategoryParams{
+		AccountID: account.ID,
+		FromTime:  time.Now().Add(-time.Hour),
+		ToTime:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, byCategory, 2)
+	assert.Equal(t, category.Name, byCategory[0].Category)
+	assert.Equal(t, int64(50), byCategory[0].Outflow)
+	assert.Equal(t, int64(2), byCategory[0].Entries)
+	assert.False(t, byCategory[1].CategoryID.Valid)
+	assert.Equal(t, int64(100), byCategory[1].Inflow)
+
+	byMonth, err := testQueries.GetAccountAnalyticsByMonth(context.Background(), db.GetAccountAnalyticsByMonthParams{
+		AccountID: account.ID,
+		FromTime:  time.Now().Add(-time.Hour),
+		ToTime:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, byMonth)
+
+	var inflow, outflow int64
+	for _, month := range byMonth {
+		inflow += month.Inflow
+		outflow += month.Outflow
+	}
+	assert.Equal(t, int64(100), inflow)
+	assert.Equal(t, int64(50), outflow)
+}
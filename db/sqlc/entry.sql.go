@@ -5,27 +5,59 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const countAccountDebitsSince = `-- name: CountAccountDebitsSince :one
+SELECT count(DISTINCT journal_id)
+FROM entries
+WHERE account_id = $1
+  AND amount < 0
+  AND created_at >= $2
+`
+
+type CountAccountDebitsSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) CountAccountDebitsSince(ctx context.Context, arg CountAccountDebitsSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccountDebitsSince, arg.AccountID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createEntry = `-- name: CreateEntry :one
-INSERT INTO entries (account_id, amount)
-VALUES ($1, $2)
-RETURNING id, account_id, amount, created_at
+INSERT INTO entries (account_id, amount, journal_id, description)
+VALUES ($1, $2, $3, $4)
+RETURNING id, account_id, amount, created_at, journal_id, description, category_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID   int64         `json:"account_id"`
+	Amount      int64         `json:"amount"`
+	JournalID   sql.NullInt64 `json:"journal_id"`
+	Description string        `json:"description"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.JournalID,
+		arg.Description,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.JournalID,
+		&i.Description,
+		&i.CategoryID,
 	)
 	return i, err
 }
@@ -42,7 +74,7 @@ func (q *Queries) DeleteEntry(ctx context.Context, id int64) error {
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at
+SELECT id, account_id, amount, created_at, journal_id, description, category_id
 FROM entries
 WHERE id = $1
 LIMIT 1
@@ -56,12 +88,15 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.JournalID,
+		&i.Description,
+		&i.CategoryID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at
+SELECT id, account_id, amount, created_at, journal_id, description, category_id
 FROM entries
 WHERE account_id = $1
 ORDER BY id
@@ -88,6 +123,61 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.JournalID,
+			&i.Description,
+			&i.CategoryID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntries = `-- name: SearchEntries :many
+SELECT id, account_id, amount, created_at, journal_id, description, category_id
+FROM entries
+WHERE account_id = $1
+  AND to_tsvector('simple', description) @@ websearch_to_tsquery('simple', $2::text)
+ORDER BY id
+LIMIT $3 OFFSET $4
+`
+
+type SearchEntriesParams struct {
+	AccountID  int64  `json:"account_id"`
+	Query      string `json:"query"`
+	PageSize   int32  `json:"page_size"`
+	PageOffset int32  `json:"page_offset"`
+}
+
+func (q *Queries) SearchEntries(ctx context.Context, arg SearchEntriesParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntries,
+		arg.AccountID,
+		arg.Query,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.JournalID,
+			&i.Description,
+			&i.CategoryID,
 		); err != nil {
 			return nil, err
 		}
@@ -106,7 +196,7 @@ const updateEntryAmount = `-- name: UpdateEntryAmount :one
 UPDATE entries
 SET amount = $2
 WHERE id = $1
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, journal_id, description, category_id
 `
 
 type UpdateEntryAmountParams struct {
@@ -122,6 +212,9 @@ func (q *Queries) UpdateEntryAmount(ctx context.Context, arg UpdateEntryAmountPa
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.JournalID,
+		&i.Description,
+		&i.CategoryID,
 	)
 	return i, err
 }
@@ -15,8 +15,9 @@ func createRandomEntry(t *testing.T, account db.Account) db.Entry {
 	t.Helper()
 
 	arg := db.CreateEntryParams{
-		AccountID: account.ID,
-		Amount:    util.RandomAmount(),
+		AccountID:   account.ID,
+		Amount:      util.RandomAmount(),
+		Description: util.RandomOwner(),
 	}
 
 	// create a new entry
@@ -27,6 +28,7 @@ func createRandomEntry(t *testing.T, account db.Account) db.Entry {
 	// check values
 	require.Equal(t, arg.AccountID, entry.AccountID)
 	require.Equal(t, arg.Amount, entry.Amount)
+	require.Equal(t, arg.Description, entry.Description)
 	require.NotZero(t, entry.ID)
 	require.NotZero(t, entry.CreatedAt)
 
@@ -80,6 +82,26 @@ func TestQueries_ListEntries(t *testing.T) {
 	}
 }
 
+func TestQueries_SearchEntries(t *testing.T) {
+	acc1 := createRandomAccount(t)
+
+	createRandomEntry(t, acc1)
+	entry1 := createRandomEntry(t, acc1)
+
+	entries, err := testQueries.SearchEntries(context.Background(), db.SearchEntriesParams{
+		AccountID:  acc1.ID,
+		Query:      entry1.Description,
+		PageSize:   10,
+		PageOffset: 0,
+	})
+	require.NoError(t, err)
+
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, entry1.ID, entries[0].ID)
+		assert.Equal(t, entry1.Description, entries[0].Description)
+	}
+}
+
 func TestQueries_UpdateEntryAmount(t *testing.T) {
 	acc1 := createRandomAccount(t)
 	entry1 := createRandomEntry(t, acc1)
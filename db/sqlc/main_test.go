@@ -14,6 +14,7 @@ import (
 var (
 	testQueries *db.Queries
 	testDB      *sql.DB
+	testSource  string
 )
 
 // TestMain connects to the database and initializes testQueries.
@@ -30,6 +31,7 @@ func TestMain(m *testing.M) {
 	}
 
 	testQueries = db.New(testDB)
+	testSource = cfg.DBSource
 
 	os.Exit(m.Run())
 }
@@ -3,15 +3,172 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type APIKey struct {
+	ID int64 `json:"id"`
+	// the key acts on behalf of the user, a service has a user of its own
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	// public identifier of the key, part of the key itself
+	Prefix string `json:"prefix"`
+	// SHA-256 of the secret part of the key
+	SecretHash string `json:"secret_hash"`
+	// granted scopes such as accounts:read or transfers:write
+	Scopes []string `json:"scopes"`
+	// IP addresses and CIDR ranges the key may be used from, empty allows any
+	AllowedIps []string     `json:"allowed_ips"`
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
 type Account struct {
 	ID        int64     `json:"id"`
 	Owner     string    `json:"owner"`
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// checking, savings, business or system
+	AccountType string `json:"account_type"`
+	// IBAN-style number with mod-97 check digits
+	Number string `json:"number"`
+	// frozen accounts can not be debited, not frozen if null
+	FrozenAt     sql.NullTime `json:"frozen_at"`
+	FrozenReason string       `json:"frozen_reason"`
+}
+
+type AccountHolder struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	// owner, co-owner, viewer or signatory
+	Role string `json:"role"`
+	// maximal amount of a single transfer of a signatory
+	SpendLimit sql.NullInt64 `json:"spend_limit"`
+	// invited or active
+	Status     string       `json:"status"`
+	InvitedBy  string       `json:"invited_by"`
+	CreatedAt  time.Time    `json:"created_at"`
+	AcceptedAt sql.NullTime `json:"accepted_at"`
+}
+
+type AccountType struct {
+	Name             string `json:"name"`
+	OverdraftAllowed bool   `json:"overdraft_allowed"`
+	// no limit if null
+	OverdraftLimit sql.NullInt64 `json:"overdraft_limit"`
+	// applies only if overdraft is not allowed
+	MinBalance int64 `json:"min_balance"`
+	// no limit if null
+	WithdrawalsPerMonth sql.NullInt32 `json:"withdrawals_per_month"`
+	CreatedAt           time.Time     `json:"created_at"`
+}
+
+type Adjustment struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	JournalID int64 `json:"journal_id"`
+	// credits the account if positive, debits it if negative
+	Amount int64  `json:"amount"`
+	Reason string `json:"reason"`
+	// administrator who posted the adjustment
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ApprovalPolicy struct {
+	AccountID int64 `json:"account_id"`
+	// transfers above the threshold require an approval
+	Threshold int64     `json:"threshold"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ApprovalPolicyApprover struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+}
+
+type AuditLog struct {
+	ID int64 `json:"id"`
+	// username of the authenticated user, empty if anonymous
+	Actor     string `json:"actor"`
+	IP        string `json:"ip"`
+	Route     string `json:"route"`
+	RequestID string `json:"request_id"`
+	Action    string `json:"action"`
+	Entity    string `json:"entity"`
+	EntityID  string `json:"entity_id"`
+	// snapshot of the entity before the change, null if created
+	Before json.RawMessage `json:"before"`
+	// snapshot of the entity after the change, null if deleted
+	After     json.RawMessage `json:"after"`
+	CreatedAt time.Time       `json:"created_at"`
+	PrevHash  string          `json:"prev_hash"`
+	// SHA-256 of the previous hash and the content of the row
+	Hash string `json:"hash"`
+	// position of the record in the hash chain, 0 until chained
+	ChainSeq int64 `json:"chain_seq"`
+}
+
+type BalanceSnapshot struct {
+	AccountID    int64     `json:"account_id"`
+	SnapshotDate time.Time `json:"snapshot_date"`
+	// end of the day, entries created since are not included
+	SnapshotAt time.Time `json:"snapshot_at"`
+	// end-of-day balance
+	Balance   int64     `json:"balance"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Batch struct {
+	ID        int64  `json:"id"`
+	Initiator string `json:"initiator"`
+	// atomic or best_effort
+	Mode string `json:"mode"`
+	// processing, completed, partially_completed or failed
+	Status      string         `json:"status"`
+	Error       sql.NullString `json:"error"`
+	CreatedAt   time.Time      `json:"created_at"`
+	CompletedAt sql.NullTime   `json:"completed_at"`
+}
+
+type BatchLeg struct {
+	ID            int64 `json:"id"`
+	BatchID       int64 `json:"batch_id"`
+	Position      int32 `json:"position"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	// completed or failed
+	Status     string         `json:"status"`
+	TransferID sql.NullInt64  `json:"transfer_id"`
+	Error      sql.NullString `json:"error"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type Category struct {
+	ID int64 `json:"id"`
+	// system categories have no owner
+	Owner     sql.NullString `json:"owner"`
+	Name      string         `json:"name"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type CategoryRule struct {
+	ID         int64  `json:"id"`
+	Owner      string `json:"owner"`
+	CategoryID int64  `json:"category_id"`
+	// matches entries of journals moving money from or to the account
+	CounterpartyAccountID sql.NullInt64 `json:"counterparty_account_id"`
+	// case-insensitive substring of the entry description
+	Pattern sql.NullString `json:"pattern"`
+	// the matching rule with the highest priority wins
+	Priority  int32     `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -20,6 +177,186 @@ type Entry struct {
 	// can be both negative and positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// the entries of a journal sum to zero per currency
+	JournalID sql.NullInt64 `json:"journal_id"`
+	// copied from the reference and the memo of the transfer
+	Description string        `json:"description"`
+	CategoryID  sql.NullInt64 `json:"category_id"`
+}
+
+type FeeSchedule struct {
+	ID          int64  `json:"id"`
+	AccountType string `json:"account_type"`
+	// standard or instant
+	TransferKind string `json:"transfer_kind"`
+	Currency     string `json:"currency"`
+	// flat, percentage or tiered
+	Method  string `json:"method"`
+	FlatFee int64  `json:"flat_fee"`
+	// percentage rate in basis points
+	RateBp int32 `json:"rate_bp"`
+	MinFee int64 `json:"min_fee"`
+	// no upper bound if null
+	MaxFee sql.NullInt64 `json:"max_fee"`
+	// number of transfers per calendar month without the fee
+	FreeMonthlyTransfers int32 `json:"free_monthly_transfers"`
+	// system account receiving the fees
+	FeeAccountID int64     `json:"fee_account_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type FeeTier struct {
+	FeeScheduleID int64 `json:"fee_schedule_id"`
+	MinAmount     int64 `json:"min_amount"`
+	// flat fee of transfers with amount of at least min_amount
+	Fee int64 `json:"fee"`
+}
+
+type InterestAccrual struct {
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// end-of-day balance
+	Balance  int64  `json:"balance"`
+	RateBp   int32  `json:"rate_bp"`
+	DayCount string `json:"day_count"`
+	// millionths of the smallest currency unit
+	AmountMicros     int64         `json:"amount_micros"`
+	CapitalizationID sql.NullInt64 `json:"capitalization_id"`
+	CreatedAt        time.Time     `json:"created_at"`
+}
+
+type InterestCapitalization struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// first day of the capitalized month
+	Period    time.Time     `json:"period"`
+	Amount    int64         `json:"amount"`
+	JournalID sql.NullInt64 `json:"journal_id"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type InterestRate struct {
+	AccountType string `json:"account_type"`
+	Currency    string `json:"currency"`
+	// annual rate in basis points
+	RateBp int32 `json:"rate_bp"`
+	// act/365 or 30/360
+	DayCount string `json:"day_count"`
+	// system account paying the interest
+	InterestAccountID int64     `json:"interest_account_id"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type Journal struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoginThrottle struct {
+	// username or ip
+	Scope string `json:"scope"`
+	// the attempted username or the client IP, not necessarily an existing user
+	Subject string `json:"subject"`
+	// consecutive failed logins since the last lockout
+	Failures     int32     `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	// logins are rejected until then
+	LockedUntil sql.NullTime `json:"locked_until"`
+}
+
+type Outbox struct {
+	ID int64 `json:"id"`
+	// shared by the rows of an event affecting more accounts
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	// events of an account are dispatched in the order of their IDs
+	AccountID    int64           `json:"account_id"`
+	Payload      json.RawMessage `json:"payload"`
+	CreatedAt    time.Time       `json:"created_at"`
+	DispatchedAt sql.NullTime    `json:"dispatched_at"`
+}
+
+type Payee struct {
+	ID        int64  `json:"id"`
+	Owner     string `json:"owner"`
+	Nickname  string `json:"nickname"`
+	AccountID int64  `json:"account_id"`
+	// default reference of transfers to the payee
+	Reference sql.NullString `json:"reference"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type PaymentRequest struct {
+	ID          int64  `json:"id"`
+	Requester   string `json:"requester"`
+	Payer       string `json:"payer"`
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Memo        string `json:"memo"`
+	// pending, paid, declined or expired
+	Status string `json:"status"`
+	// account of the payer the request was paid from
+	FromAccountID sql.NullInt64 `json:"from_account_id"`
+	TransferID    sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt     time.Time     `json:"expires_at"`
+	DecidedAt     sql.NullTime  `json:"decided_at"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type PendingTransfer struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Initiator     string `json:"initiator"`
+	// pending, approved, rejected or expired
+	Status         string          `json:"status"`
+	DecidedBy      sql.NullString  `json:"decided_by"`
+	TransferID     sql.NullInt64   `json:"transfer_id"`
+	ExpiresAt      time.Time       `json:"expires_at"`
+	DecidedAt      sql.NullTime    `json:"decided_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Instant        bool            `json:"instant"`
+	PayeeID        sql.NullInt64   `json:"payee_id"`
+	Reference      string          `json:"reference"`
+	Memo           string          `json:"memo"`
+	RemittanceInfo json.RawMessage `json:"remittance_info"`
+}
+
+type RecoveryCode struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// SHA-256 of the normalized recovery code
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type Session struct {
+	ID int64 `json:"id"`
+	// shared by the sessions rotated from the same login
+	FamilyID string `json:"family_id"`
+	Username string `json:"username"`
+	// SHA-256 of the refresh token
+	RefreshTokenHash string    `json:"refresh_token_hash"`
+	UserAgent        string    `json:"user_agent"`
+	ClientIP         string    `json:"client_ip"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	// the refresh token was exchanged for a new one, its reuse revokes the family
+	RotatedAt sql.NullTime `json:"rotated_at"`
+	Revoked   bool         `json:"revoked"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type TotpCredential struct {
+	Username string `json:"username"`
+	// base32 TOTP secret shared with the authenticator app
+	Secret string `json:"secret"`
+	// two-factor authentication is enabled once the first code is confirmed
+	ConfirmedAt sql.NullTime `json:"confirmed_at"`
+	// time step of the last accepted code, older codes are rejected as replays
+	LastUsedStep int64     `json:"last_used_step"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type Transfer struct {
@@ -29,14 +366,76 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// payee the transfer was sent to, if any
+	PayeeID sql.NullInt64 `json:"payee_id"`
+	// reference visible to both parties
+	Reference string `json:"reference"`
+	// free text note of the sender
+	Memo string `json:"memo"`
+	// structured remittance information
+	RemittanceInfo json.RawMessage `json:"remittance_info"`
 }
 
 type User struct {
-	Username           string    `json:"username"`
+	Username string `json:"username"`
+	// PHC string of the Argon2id hash or bcrypt hash, with the parameters and the salt
 	HashedPassword     string    `json:"hashed_password"`
 	FirstName          string    `json:"first_name"`
 	LastName           string    `json:"last_name"`
 	Email              string    `json:"email"`
 	PasswordModifiedAt time.Time `json:"password_modified_at"`
 	CreatedAt          time.Time `json:"created_at"`
+	// customer, support, admin or auditor
+	Role string `json:"role"`
+	// the email address was confirmed by a verification token
+	EmailVerifiedAt sql.NullTime `json:"email_verified_at"`
+}
+
+type UserToken struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// verify_email, reset_password or login_challenge
+	Purpose string `json:"purpose"`
+	// SHA-256 of the token sent by email
+	TokenHash string    `json:"token_hash"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// the token can be used only once
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type WebhookAttempt struct {
+	ID         int64 `json:"id"`
+	DeliveryID int64 `json:"delivery_id"`
+	// HTTP status of the response, 0 if none was received
+	StatusCode int32     `json:"status_code"`
+	Error      string    `json:"error"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID         int64 `json:"id"`
+	EndpointID int64 `json:"endpoint_id"`
+	// shared by the deliveries of the same event
+	EventID   string          `json:"event_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	// pending, delivered or dead
+	Status        string       `json:"status"`
+	Attempts      int32        `json:"attempts"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	DeliveredAt   sql.NullTime `json:"delivered_at"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+type WebhookEndpoint struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	URL   string `json:"url"`
+	// subscribed event types
+	Events []string `json:"events"`
+	// key of the HMAC-SHA256 payload signatures
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"created_at"`
 }
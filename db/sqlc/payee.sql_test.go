@@ -0,0 +1,176 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPayee(t *testing.T, owner string, account db.Account) db.Payee {
+	t.Helper()
+
+	arg := db.CreatePayeeParams{
+		Owner:     owner,
+		Nickname:  util.RandomOwner(),
+		AccountID: account.ID,
+		Reference: sql.NullString{String: util.RandomString(10), Valid: true},
+	}
+
+	payee, err := testQueries.CreatePayee(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Owner, payee.Owner)
+	require.Equal(t, arg.Nickname, payee.Nickname)
+	require.Equal(t, arg.AccountID, payee.AccountID)
+	require.Equal(t, arg.Reference, payee.Reference)
+	require.NotZero(t, payee.ID)
+	require.NotZero(t, payee.CreatedAt)
+
+	return payee
+}
+
+func TestQueries_Payees(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	payee := createRandomPayee(t, account1.Owner, account2)
+
+	// nicknames are unique per owner
+	_, err := testQueries.CreatePayee(context.Background(), db.CreatePayeeParams{
+		Owner:     payee.Owner,
+		Nickname:  payee.Nickname,
+		AccountID: account2.ID,
+	})
+	assert.Error(t, err)
+
+	// get
+	got, err := testQueries.GetPayee(context.Background(), payee.ID)
+	require.NoError(t, err)
+	assert.Equal(t, payee, got)
+
+	// list
+	payees, err := testQueries.ListPayees(context.Background(), db.ListPayeesParams{
+		Owner: payee.Owner,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []db.Payee{payee}, payees)
+
+	// update
+	updated, err := testQueries.UpdatePayee(context.Background(), db.UpdatePayeeParams{
+		ID:       payee.ID,
+		Nickname: util.RandomOwner(),
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, payee.Nickname, updated.Nickname)
+	assert.False(t, updated.Reference.Valid)
+
+	// transfers to the account of the payee are summed up
+	sumArg := db.SumPayeeAccountTransfersParams{
+		ToAccountID: payee.AccountID,
+		Since:       payee.CreatedAt,
+		Owner:       payee.Owner,
+	}
+
+	sum, err := testQueries.SumPayeeAccountTransfers(context.Background(), sumArg)
+	require.NoError(t, err)
+	assert.Zero(t, sum)
+
+	amount := util.RandomAmount()
+	result, err := db.NewStore(testDB).TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		PayeeID:       sql.NullInt64{Int64: payee.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, payee.ID, result.Transfer.PayeeID.Int64)
+
+	sum, err = testQueries.SumPayeeAccountTransfers(context.Background(), sumArg)
+	require.NoError(t, err)
+	assert.Equal(t, amount, sum)
+
+	// delete
+	require.NoError(t, testQueries.DeletePayee(context.Background(), payee.ID))
+
+	_, err = testQueries.GetPayee(context.Background(), payee.ID)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestStore_TransferTxPayeeCap(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	payee := createRandomPayee(t, account1.Owner, account2)
+
+	amount := util.RandomAmount()
+	arg := db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		PayeeID:       sql.NullInt64{Int64: payee.ID, Valid: true},
+		PayeeCap:      db.PayeeCap{CoolingOff: time.Hour, Limit: amount},
+	}
+
+	_, err := s.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the cap is used up
+	_, err = s.TransferTx(context.Background(), arg)
+	assert.ErrorIs(t, err, db.ErrPayeeCoolingOff)
+
+	// the transfers to the account of the payee are capped as well
+	arg.PayeeID = sql.NullInt64{}
+	_, err = s.TransferTx(context.Background(), arg)
+	assert.ErrorIs(t, err, db.ErrPayeeCoolingOff)
+
+	// no cap without the cooling-off period
+	arg.PayeeCap = db.PayeeCap{}
+	_, err = s.TransferTx(context.Background(), arg)
+	assert.NoError(t, err)
+}
+
+func TestStore_TransferTxPayeeCapAccount(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	amount := util.RandomAmount()
+
+	arg := db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		PayeeCap:      db.PayeeCap{CoolingOff: time.Hour, Limit: amount},
+	}
+
+	// the transfers made before the account is added as a payee do not count
+	_, err := s.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	payee := createRandomPayee(t, account1.Owner, account2)
+
+	// the transfers by the account ID count towards the cap of the new payee
+	_, err = s.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = s.TransferTx(context.Background(), arg)
+	assert.ErrorIs(t, err, db.ErrPayeeCoolingOff)
+
+	arg.PayeeID = sql.NullInt64{Int64: payee.ID, Valid: true}
+	_, err = s.TransferTx(context.Background(), arg)
+	assert.ErrorIs(t, err, db.ErrPayeeCoolingOff)
+
+	// the payees of other users do not cap the account
+	other := createRandomAccountWithCurrency(t, account1.Currency)
+	arg.FromAccountID = other.ID
+	arg.PayeeID = sql.NullInt64{}
+	_, err = s.TransferTx(context.Background(), arg)
+	assert.NoError(t, err)
+}
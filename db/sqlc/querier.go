@@ -4,30 +4,187 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 type Querier interface {
+	AcceptAccountHolder(ctx context.Context, arg AcceptAccountHolderParams) (AccountHolder, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	CategorizeJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error)
+	ChainAuditLog(ctx context.Context, arg ChainAuditLogParams) (AuditLog, error)
+	ClaimOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	ClaimWebhookDeliveries(ctx context.Context, arg ClaimWebhookDeliveriesParams) ([]ClaimWebhookDeliveriesRow, error)
+	CompleteBatch(ctx context.Context, arg CompleteBatchParams) (Batch, error)
+	ConfirmTOTPCredential(ctx context.Context, username string) (TotpCredential, error)
+	CountAccountDebitsSince(ctx context.Context, arg CountAccountDebitsSinceParams) (int64, error)
+	CountTransfersSince(ctx context.Context, arg CountTransfersSinceParams) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, username string) (int64, error)
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountHolder(ctx context.Context, arg CreateAccountHolderParams) (AccountHolder, error)
+	CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error)
+	CreateApprovalPolicyApprover(ctx context.Context, arg CreateApprovalPolicyApproverParams) (ApprovalPolicyApprover, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateBalanceSnapshots(ctx context.Context, arg CreateBalanceSnapshotsParams) (int64, error)
+	CreateBatch(ctx context.Context, arg CreateBatchParams) (Batch, error)
+	CreateBatchLeg(ctx context.Context, arg CreateBatchLegParams) (BatchLeg, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateCategoryRule(ctx context.Context, arg CreateCategoryRuleParams) (CategoryRule, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateFeeSchedule(ctx context.Context, arg CreateFeeScheduleParams) (FeeSchedule, error)
+	CreateFeeTier(ctx context.Context, arg CreateFeeTierParams) (FeeTier, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestCapitalization(ctx context.Context, arg CreateInterestCapitalizationParams) (InterestCapitalization, error)
+	CreateJournal(ctx context.Context) (Journal, error)
+	CreateLoginThrottle(ctx context.Context, arg CreateLoginThrottleParams) error
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) (RecoveryCode, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
+	CreateWebhookAttempt(ctx context.Context, arg CreateWebhookAttemptParams) (WebhookAttempt, error)
+	CreateWebhookDeliveries(ctx context.Context, arg CreateWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error)
+	DecidePaymentRequest(ctx context.Context, arg DecidePaymentRequestParams) (PaymentRequest, error)
+	DecidePendingTransfer(ctx context.Context, arg DecidePendingTransferParams) (PendingTransfer, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountHolder(ctx context.Context, arg DeleteAccountHolderParams) (int64, error)
+	DeleteApprovalPolicy(ctx context.Context, accountID int64) error
+	DeleteApprovalPolicyApprovers(ctx context.Context, accountID int64) error
+	DeleteCategory(ctx context.Context, id int64) error
+	DeleteCategoryRule(ctx context.Context, id int64) error
 	DeleteEntry(ctx context.Context, id int64) error
+	DeleteExpiredSessions(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeleteExpiredUserTokens(ctx context.Context, expiresAt time.Time) (int64, error)
+	DeleteFeeSchedule(ctx context.Context, id int64) error
+	DeleteInterestRate(ctx context.Context, arg DeleteInterestRateParams) error
+	DeleteLoginThrottle(ctx context.Context, arg DeleteLoginThrottleParams) error
+	DeletePayee(ctx context.Context, id int64) error
+	DeleteRecoveryCodes(ctx context.Context, username string) (int64, error)
+	DeleteStaleLoginThrottles(ctx context.Context, lastFailedAt time.Time) (int64, error)
+	DeleteTOTPCredential(ctx context.Context, username string) (int64, error)
 	DeleteTransfer(ctx context.Context, id int64) error
-	DeleteUser(ctx context.Context, arg DeleteUserParams) error
+	DeleteUser(ctx context.Context, username string) error
+	DeleteWebhookEndpoint(ctx context.Context, id int64) error
+	ExpirePaymentRequests(ctx context.Context) (int64, error)
+	ExpirePendingTransfers(ctx context.Context) (int64, error)
+	FreezeAccount(ctx context.Context, arg FreezeAccountParams) (Account, error)
+	GetAPIKey(ctx context.Context, id int64) (APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (APIKey, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountAnalyticsByCategory(ctx context.Context, arg GetAccountAnalyticsByCategoryParams) ([]GetAccountAnalyticsByCategoryRow, error)
+	GetAccountAnalyticsByMonth(ctx context.Context, arg GetAccountAnalyticsByMonthParams) ([]GetAccountAnalyticsByMonthRow, error)
+	GetAccountBalanceAsOf(ctx context.Context, arg GetAccountBalanceAsOfParams) (int64, error)
+	GetAccountBalanceHistory(ctx context.Context, arg GetAccountBalanceHistoryParams) ([]GetAccountBalanceHistoryRow, error)
+	GetAccountByNumber(ctx context.Context, number string) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountHolder(ctx context.Context, arg GetAccountHolderParams) (AccountHolder, error)
+	GetAccountType(ctx context.Context, name string) (AccountType, error)
+	GetApprovalPolicy(ctx context.Context, accountID int64) (ApprovalPolicy, error)
+	GetApprovalPolicyApprover(ctx context.Context, arg GetApprovalPolicyApproverParams) (ApprovalPolicyApprover, error)
+	GetBatch(ctx context.Context, id int64) (Batch, error)
+	GetCategory(ctx context.Context, id int64) (Category, error)
+	GetCategoryRule(ctx context.Context, id int64) (CategoryRule, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetInterestRate(ctx context.Context, arg GetInterestRateParams) (InterestRate, error)
+	GetJournal(ctx context.Context, id int64) (Journal, error)
+	GetLastChainedAuditLog(ctx context.Context) (AuditLog, error)
+	GetLatestAccountEventID(ctx context.Context, accountID int64) (int64, error)
+	GetLoginThrottle(ctx context.Context, arg GetLoginThrottleParams) (LoginThrottle, error)
+	GetLoginThrottleForUpdate(ctx context.Context, arg GetLoginThrottleForUpdateParams) (LoginThrottle, error)
+	GetPayee(ctx context.Context, id int64) (Payee, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPendingTransfer(ctx context.Context, id int64) (PendingTransfer, error)
+	GetPendingTransferForUpdate(ctx context.Context, id int64) (PendingTransfer, error)
+	GetSession(ctx context.Context, id int64) (Session, error)
+	GetSessionByRefreshToken(ctx context.Context, refreshTokenHash string) (Session, error)
+	GetSessionByRefreshTokenForUpdate(ctx context.Context, refreshTokenHash string) (Session, error)
+	GetSystemAccount(ctx context.Context, currency string) (Account, error)
+	GetTOTPCredential(ctx context.Context, username string) (TotpCredential, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserTokenForUpdate(ctx context.Context, tokenHash string) (UserToken, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookEndpoint(ctx context.Context, id int64) (WebhookEndpoint, error)
+	ListAPIKeys(ctx context.Context, username string) ([]APIKey, error)
+	ListAccountAdjustments(ctx context.Context, arg ListAccountAdjustmentsParams) ([]Adjustment, error)
+	ListAccountEventsAfter(ctx context.Context, arg ListAccountEventsAfterParams) ([]Outbox, error)
+	ListAccountHolders(ctx context.Context, accountID int64) ([]AccountHolder, error)
+	ListAccountOutboxEvents(ctx context.Context, arg ListAccountOutboxEventsParams) ([]Outbox, error)
+	ListAccountTypes(ctx context.Context) ([]AccountType, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListActiveSessions(ctx context.Context, username string) ([]Session, error)
+	ListApprovalPolicyApprovers(ctx context.Context, accountID int64) ([]string, error)
+	ListAuditLog(ctx context.Context, arg ListAuditLogParams) ([]AuditLog, error)
+	ListAuditLogAfter(ctx context.Context, arg ListAuditLogAfterParams) ([]AuditLog, error)
+	ListBalanceSnapshots(ctx context.Context, arg ListBalanceSnapshotsParams) ([]BalanceSnapshot, error)
+	ListBatchLegs(ctx context.Context, batchID int64) ([]BatchLeg, error)
+	ListCategories(ctx context.Context, owner sql.NullString) ([]Category, error)
+	ListCategoryRules(ctx context.Context, owner string) ([]CategoryRule, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListFeeTiers(ctx context.Context, feeScheduleID int64) ([]FeeTier, error)
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListInterestBearingAccounts(ctx context.Context, endOfDay time.Time) ([]ListInterestBearingAccountsRow, error)
+	ListJournalEntries(ctx context.Context, journalID sql.NullInt64) ([]Entry, error)
+	ListLoginThrottles(ctx context.Context, arg ListLoginThrottlesParams) ([]LoginThrottle, error)
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListOwnerAccounts(ctx context.Context, owner string) ([]Account, error)
+	ListPayees(ctx context.Context, arg ListPayeesParams) ([]Payee, error)
+	ListRecentPayeesForUpdate(ctx context.Context, arg ListRecentPayeesForUpdateParams) ([]Payee, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUncapitalizedInterest(ctx context.Context, accrualDate time.Time) ([]ListUncapitalizedInterestRow, error)
+	ListUnchainedAuditLog(ctx context.Context, limit int32) ([]AuditLog, error)
+	ListWebhookAttempts(ctx context.Context, deliveryID int64) ([]WebhookAttempt, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookEndpoints(ctx context.Context, owner string) ([]WebhookEndpoint, error)
+	LockAuditLog(ctx context.Context) error
+	LockLoginThrottle(ctx context.Context, arg LockLoginThrottleParams) (LoginThrottle, error)
+	MarkInterestAccrualsCapitalized(ctx context.Context, arg MarkInterestAccrualsCapitalizedParams) (int64, error)
+	MarkOutboxEventsDispatched(ctx context.Context, ids []int64) (int64, error)
+	MarkSessionRotated(ctx context.Context, id int64) error
+	MarkUserTokenUsed(ctx context.Context, id int64) error
+	NotifyAccountEvent(ctx context.Context, notification string) error
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error)
+	RedeliverWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
+	ReleaseLoginAttempt(ctx context.Context, arg ReleaseLoginAttemptParams) error
+	RevokeAPIKey(ctx context.Context, id int64) (APIKey, error)
+	RevokeSessionFamily(ctx context.Context, familyID string) (int64, error)
+	RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) (int64, error)
+	RotateAPIKey(ctx context.Context, arg RotateAPIKeyParams) (APIKey, error)
+	SearchAccounts(ctx context.Context, arg SearchAccountsParams) ([]Account, error)
+	SearchEntries(ctx context.Context, arg SearchEntriesParams) ([]Entry, error)
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error)
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error)
+	SetEntryCategory(ctx context.Context, arg SetEntryCategoryParams) (Entry, error)
+	SetInterestCapitalizationJournal(ctx context.Context, arg SetInterestCapitalizationJournalParams) (InterestCapitalization, error)
+	SetUserEmailVerified(ctx context.Context, username string) (User, error)
+	SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error)
+	SumPayeeAccountTransfers(ctx context.Context, arg SumPayeeAccountTransfersParams) (int64, error)
+	TouchAPIKey(ctx context.Context, id int64) error
+	UnfreezeAccount(ctx context.Context, id int64) (Account, error)
+	UnlockLoginThrottle(ctx context.Context, arg UnlockLoginThrottleParams) (LoginThrottle, error)
 	UpdateAccountBalance(ctx context.Context, arg UpdateAccountBalanceParams) (Account, error)
+	UpdateAccountType(ctx context.Context, arg UpdateAccountTypeParams) (AccountType, error)
 	UpdateEntryAmount(ctx context.Context, arg UpdateEntryAmountParams) (Entry, error)
+	UpdatePayee(ctx context.Context, arg UpdatePayeeParams) (Payee, error)
 	UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error)
+	UpsertApprovalPolicy(ctx context.Context, arg UpsertApprovalPolicyParams) (ApprovalPolicy, error)
+	UpsertInterestRate(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error)
+	UpsertTOTPCredential(ctx context.Context, arg UpsertTOTPCredentialParams) (TotpCredential, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
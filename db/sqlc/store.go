@@ -3,7 +3,23 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+)
+
+// feeDescription is the description of the entries booking a transfer fee.
+const feeDescription = "transfer fee"
+
+var (
+	// ErrNonPositiveAmount is returned when the amount of a transfer is not positive.
+	ErrNonPositiveAmount = errors.New("amount of a transfer must be positive")
+	// ErrPayeeCoolingOff is returned when a transfer exceeds the cap of a newly added payee.
+	ErrPayeeCoolingOff = errors.New("transfers to the payee exceed the cap of the cooling-off period")
+	// ErrCurrencyMismatch is returned when a transfer is sent to an account of another currency.
+	ErrCurrencyMismatch = errors.New("transfers between accounts of different currencies are not supported")
 )
 
 // Store represents a endpoint which provides all database transaction
@@ -11,6 +27,31 @@ import (
 type Store interface {
 	Querier
 	TransferTx(context.Context, TransferTxParams) (TransferTxResult, error)
+	JournalTx(context.Context, []Leg) (JournalTxResult, error)
+	QuoteTransferFee(context.Context, TransferTxParams) (Fee, error)
+	AccrueInterestTx(context.Context, time.Time) (AccrueInterestTxResult, error)
+	CapitalizeInterestTx(context.Context, time.Time) (CapitalizeInterestTxResult, error)
+	SetApprovalPolicyTx(context.Context, SetApprovalPolicyTxParams) (SetApprovalPolicyTxResult, error)
+	ApprovePendingTransferTx(context.Context, DecidePendingTransferTxParams) (ApprovePendingTransferTxResult, error)
+	RejectPendingTransferTx(context.Context, DecidePendingTransferTxParams) (PendingTransfer, error)
+	BatchTransferTx(context.Context, BatchTransferTxParams) (BatchTransferTxResult, error)
+	PayPaymentRequestTx(context.Context, PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error)
+	DeclinePaymentRequestTx(context.Context, DeclinePaymentRequestTxParams) (PaymentRequest, error)
+	RecordWebhookAttemptTx(context.Context, RecordWebhookAttemptTxParams) (WebhookDelivery, error)
+	OpenAccountTx(context.Context, CreateAccountParams) (Account, error)
+	CloseAccountTx(context.Context, int64) (Account, error)
+	DispatchOutboxTx(context.Context, int32, DispatchFunc) (int, error)
+	AdjustAccountTx(context.Context, AdjustAccountTxParams) (AdjustAccountTxResult, error)
+	RotateSessionTx(context.Context, RotateSessionTxParams) (RotateSessionTxResult, error)
+	VerifyEmailTx(context.Context, string) (User, error)
+	ResetPasswordTx(context.Context, ResetPasswordTxParams) (User, error)
+	ChangePasswordTx(context.Context, ChangePasswordTxParams) (User, error)
+	UseUserTokenTx(context.Context, string, string) (UserToken, error)
+	EnableTOTPTx(context.Context, TOTPTxParams) (TotpCredential, error)
+	RegenerateRecoveryCodesTx(context.Context, TOTPTxParams) ([]RecoveryCode, error)
+	DisableTOTPTx(context.Context, TOTPTxParams) error
+	ReserveLoginAttemptTx(context.Context, ReserveLoginAttemptTxParams) (ReserveLoginAttemptTxResult, error)
+	ChainAuditLogTx(context.Context, int32) (int, error)
 }
 
 // store provides all functions to execute db queries and transactions.
@@ -21,23 +62,74 @@ type store struct {
 
 // NewStore constructs a new store.
 func NewStore(db *sql.DB) Store {
+	return newStore(db)
+}
+
+func newStore(db *sql.DB) *store {
 	return &store{
-		Queries: New(db),
+		Queries: New(ambientDB{db: db}),
 		db:      db,
 	}
 }
 
+// txKey is the context key of the transaction the operations of the store join.
+type txKey struct{}
+
+// savepoint is the name of the savepoints of the nested transactions.
+const savepoint = "nested_tx"
+
+// ambientDB runs the queries in the transaction of the context, if there is one.
+type ambientDB struct {
+	db *sql.DB
+}
+
+func (a ambientDB) conn(ctx context.Context) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return a.db
+}
+
+func (a ambientDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return a.conn(ctx).ExecContext(ctx, query, args...)
+}
+
+func (a ambientDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return a.conn(ctx).PrepareContext(ctx, query)
+}
+
+func (a ambientDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return a.conn(ctx).QueryContext(ctx, query, args...)
+}
+
+func (a ambientDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return a.conn(ctx).QueryRowContext(ctx, query, args...)
+}
+
 // execTx safely executes a function with a database transaction.
 func (s *store) execTx(ctx context.Context, fn func(*Queries) error) error {
+	return s.inTx(ctx, func(_ context.Context, tx *sql.Tx) error {
+		return fn(New(tx))
+	})
+}
+
+// inTx executes a function with a database transaction carried by the context it
+// is given, so the operations of the store called with the context join the transaction.
+// If the context already carries a transaction, the function is executed within
+// a savepoint of it and its failure rolls back only the changes it made.
+func (s *store) inTx(ctx context.Context, fn func(context.Context, *sql.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return nestedTx(ctx, tx, fn)
+	}
+
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("can not begin a transaction: %w", err)
 	}
 
 	// execute code
-	q := New(tx)
-
-	err = fn(q)
+	err = fn(context.WithValue(ctx, txKey{}, tx), tx)
 	if err != nil {
 		if errRb := tx.Rollback(); errRb != nil {
 			return fmt.Errorf("tx error: %v, rolback error: %w", err, errRb)
@@ -55,11 +147,62 @@ func (s *store) execTx(ctx context.Context, fn func(*Queries) error) error {
 	return nil
 }
 
+// nestedTx executes a function within a savepoint of the transaction.
+func nestedTx(ctx context.Context, tx *sql.Tx, fn func(context.Context, *sql.Tx) error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("can not create a savepoint: %w", err)
+	}
+
+	if err := fn(ctx, tx); err != nil {
+		if _, errRb := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); errRb != nil {
+			return fmt.Errorf("tx error: %v, rolback error: %w", err, errRb)
+		}
+
+		return fmt.Errorf("tx err: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("can not release a savepoint: %w", err)
+	}
+
+	return nil
+}
+
 // TransferTxParams contains parameters of the transfer transaction.
 type TransferTxParams struct {
 	FromAccountID int64
 	ToAccountID   int64
 	Amount        int64
+	// Instant transfers may be charged by a different fee schedule.
+	Instant bool
+	// PayeeID is the payee of the sender the transfer is sent to, if any.
+	PayeeID sql.NullInt64
+	// PayeeCap caps the transfers to the accounts added as payees recently.
+	PayeeCap PayeeCap
+	// Reference and Memo describe the transfer, they are copied onto the entries.
+	Reference string
+	Memo      string
+	// RemittanceInfo is a structured JSON object describing the payment.
+	RemittanceInfo json.RawMessage
+}
+
+// PayeeCap caps the total of the transfers to the account of a payee during the
+// cooling-off period after the payee is added. Zero CoolingOff disables the cap.
+type PayeeCap struct {
+	CoolingOff time.Duration
+	Limit      int64
+}
+
+// Description returns the description of the entries of the transfer.
+func (arg TransferTxParams) Description() string {
+	var parts []string
+	for _, s := range []string{arg.Reference, arg.Memo} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+
+	return strings.Join(parts, " - ")
 }
 
 // TransferTxResult contains result of the transfer transaction.
@@ -69,83 +212,166 @@ type TransferTxResult struct {
 	ToAccount   Account
 	FromEntry   Entry
 	ToEntry     Entry
+	Fee         Fee
+	// FeeEntry is the entry debiting the fee from the sender,
+	// it is empty if no fee is charged.
+	FeeEntry Entry
 }
 
 // TransferTx performs a money transfer from the account to the another.
 // It creates a new Transfer record with entries for both affected accounts and
-// update their balances within a single database transaction.
+// update their balances within a single database transaction. The fee given
+// by the fee schedule of the sending account is booked to the fee account
+// and a transfer.created event is published to both parties.
 func (s *store) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := s.execTx(ctx, func(q *Queries) error {
 		var err error
+		result, err = transferTx(ctx, q, arg)
 
-		// transfer
-		if result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams(arg)); err != nil {
-			return fmt.Errorf("failed to create a new transaction: %w", err)
-		}
+		return err
+	})
+	if err != nil {
+		return TransferTxResult{}, fmt.Errorf("can not make a transaction: %w", err)
+	}
 
-		// entries
-		if result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		}); err != nil {
-			return fmt.Errorf("failed to create an entry for the sender: %w", err)
-		}
+	return result, nil
+}
 
-		if result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		}); err != nil {
-			return fmt.Errorf("failed to create an entry for the receiver: %w", err)
-		}
+// transferTx executes the queries of the transfer transaction using the given Queries,
+// so it can be a part of a larger database transaction.
+// The money is moved by a journal with a debit and a credit leg
+// and the legs of the fee if there is any.
+func transferTx(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
 
-		// accounts
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = transferMoney(
-				ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount,
-			)
-		} else {
-			result.ToAccount, result.FromAccount, err = transferMoney(
-				ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount,
-			)
-		}
-		if err != nil {
-			return fmt.Errorf("failed to transfer money: %w", err)
-		}
+	// a negative amount would move the money the other way round
+	if arg.Amount <= 0 {
+		return result, ErrNonPositiveAmount
+	}
 
-		return nil
-	})
+	if err = checkCurrencies(ctx, q, arg); err != nil {
+		return result, err
+	}
+
+	if err = checkPayeeCap(ctx, q, arg); err != nil {
+		return result, err
+	}
+
+	// fee
+	if result.Fee, err = quoteFee(ctx, q, arg); err != nil {
+		return result, fmt.Errorf("failed to compute the fee: %w", err)
+	}
+
+	// transfer
+	remittance := arg.RemittanceInfo
+	if len(remittance) == 0 {
+		remittance = json.RawMessage("{}")
+	}
+
+	if result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:  arg.FromAccountID,
+		ToAccountID:    arg.ToAccountID,
+		Amount:         arg.Amount,
+		PayeeID:        arg.PayeeID,
+		Reference:      arg.Reference,
+		Memo:           arg.Memo,
+		RemittanceInfo: remittance,
+	}); err != nil {
+		return result, fmt.Errorf("failed to create a new transaction: %w", err)
+	}
+
+	// entries and accounts
+	description := arg.Description()
+	legs := []Leg{
+		{AccountID: arg.FromAccountID, Amount: -arg.Amount, Description: description},
+		{AccountID: arg.ToAccountID, Amount: arg.Amount, Description: description},
+	}
+
+	if result.Fee.Amount > 0 {
+		legs = append(legs,
+			Leg{AccountID: arg.FromAccountID, Amount: -result.Fee.Amount, Description: feeDescription},
+			Leg{AccountID: result.Fee.FeeAccountID, Amount: result.Fee.Amount, Description: feeDescription},
+		)
+	}
+
+	journal, err := journalTx(ctx, q, legs)
 	if err != nil {
-		return TransferTxResult{}, fmt.Errorf("can not make a transaction: %w", err)
+		return result, fmt.Errorf("failed to transfer money: %w", err)
+	}
+
+	result.FromEntry = journal.Entries[0]
+	result.ToEntry = journal.Entries[1]
+	result.FromAccount = journal.Account(arg.FromAccountID)
+	result.ToAccount = journal.Account(arg.ToAccountID)
+
+	if len(journal.Entries) > 2 {
+		result.FeeEntry = journal.Entries[2]
+	}
+
+	if err := publishEvent(ctx, q, EventTransferCreated, result.Transfer, arg.FromAccountID, arg.ToAccountID); err != nil {
+		return result, err
 	}
 
 	return result, nil
 }
 
-// transferMoney adds amount1 to the balance of the account with id account1ID and then amount2
-// to the balance of the account with id account2ID.
-func transferMoney(
-	ctx context.Context,
-	q *Queries,
-	account1ID int64,
-	amount1 int64,
-	account2ID int64,
-	amount2 int64,
-) (account1, account2 Account, err error) {
-	if account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		Amount: amount1,
-		ID:     account1ID,
-	}); err != nil {
-		return
+// checkCurrencies checks whether both accounts of the transfer have the same currency.
+// There is no currency conversion, so there is no cross-currency transfer kind either.
+func checkCurrencies(ctx context.Context, q *Queries, arg TransferTxParams) error {
+	from, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account %d: %w", arg.FromAccountID, err)
 	}
 
-	if account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		Amount: amount2,
-		ID:     account2ID,
-	}); err != nil {
-		return
+	to, err := q.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account %d: %w", arg.ToAccountID, err)
+	}
+
+	if from.Currency != to.Currency {
+		return fmt.Errorf("%w: %s to %s", ErrCurrencyMismatch, from.Currency, to.Currency)
 	}
 
-	return
+	return nil
+}
+
+// checkPayeeCap checks whether the transfer fits into the caps of the payees of the
+// receiving account added recently by the owner or a holder of the sending account.
+// Every transfer to the account counts, whether it is sent to the payee, to the ID
+// or to the number of the account. The payees are locked first, so the concurrent
+// transfers to the account are checked one after another and can not exceed the cap together.
+func checkPayeeCap(ctx context.Context, q *Queries, arg TransferTxParams) error {
+	if arg.PayeeCap.CoolingOff <= 0 {
+		return nil
+	}
+
+	payees, err := q.ListRecentPayeesForUpdate(ctx, ListRecentPayeesForUpdateParams{
+		ToAccountID:   arg.ToAccountID,
+		AddedAfter:    time.Now().Add(-arg.PayeeCap.CoolingOff),
+		FromAccountID: arg.FromAccountID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to lock the payees of account %d: %w", arg.ToAccountID, err)
+	}
+
+	for _, payee := range payees {
+		sent, err := q.SumPayeeAccountTransfers(ctx, SumPayeeAccountTransfersParams{
+			ToAccountID: payee.AccountID,
+			Since:       payee.CreatedAt,
+			Owner:       payee.Owner,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to sum the transfers to payee %d: %w", payee.ID, err)
+		}
+
+		if sent+arg.Amount > arg.PayeeCap.Limit {
+			return fmt.Errorf("%w: %d of %d left until %s", ErrPayeeCoolingOff, arg.PayeeCap.Limit-sent,
+				arg.PayeeCap.Limit, payee.CreatedAt.Add(arg.PayeeCap.CoolingOff).Format(time.RFC3339))
+		}
+	}
+
+	return nil
 }
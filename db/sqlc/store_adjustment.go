@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// adjustmentDescription prefixes the description of the entries of an adjustment.
+const adjustmentDescription = "adjustment: "
+
+// ErrNoSystemAccount is returned when there is no system account of a currency.
+var ErrNoSystemAccount = errors.New("no system account of the currency")
+
+// AdjustAccountTxParams contains parameters of the adjustment transaction.
+type AdjustAccountTxParams struct {
+	AccountID int64
+	// Amount credits the account if positive and debits it if negative.
+	Amount    int64
+	Reason    string
+	CreatedBy string
+}
+
+// AdjustAccountTxResult contains result of the adjustment transaction.
+type AdjustAccountTxResult struct {
+	Adjustment Adjustment
+	Account    Account
+	Entry      Entry
+}
+
+// AdjustAccountTx posts a manual adjustment of the account balance. The adjustment
+// is booked as a journal against the system account of the account currency, so
+// the books stay balanced, and the debits follow the rules of the account type.
+func (s *store) AdjustAccountTx(ctx context.Context, arg AdjustAccountTxParams) (AdjustAccountTxResult, error) {
+	var result AdjustAccountTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to get the account: %w", err)
+		}
+
+		system, err := q.GetSystemAccount(ctx, account.Currency)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: %s", ErrNoSystemAccount, account.Currency)
+			}
+
+			return fmt.Errorf("failed to get the system account: %w", err)
+		}
+
+		description := adjustmentDescription + arg.Reason
+		journal, err := journalTx(ctx, q, []Leg{
+			{AccountID: account.ID, Amount: arg.Amount, Description: description},
+			{AccountID: system.ID, Amount: -arg.Amount, Description: description},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to post the adjustment: %w", err)
+		}
+
+		result.Entry = journal.Entries[0]
+		result.Account = journal.Account(account.ID)
+
+		if result.Adjustment, err = q.CreateAdjustment(ctx, CreateAdjustmentParams{
+			AccountID: account.ID,
+			JournalID: journal.Journal.ID,
+			Amount:    arg.Amount,
+			Reason:    arg.Reason,
+			CreatedBy: arg.CreatedBy,
+		}); err != nil {
+			return fmt.Errorf("failed to create the adjustment: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return AdjustAccountTxResult{}, fmt.Errorf("can not adjust account: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package db_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_AdjustAccountTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account := createRandomAccount(t)
+	createRandomAccountWithType(t, db.AccountTypeSystem, account.Currency)
+
+	system, err := testQueries.GetSystemAccount(context.Background(), account.Currency)
+	require.NoError(t, err)
+
+	arg := db.AdjustAccountTxParams{
+		AccountID: account.ID,
+		Amount:    -10,
+		Reason:    "duplicate card payment",
+		CreatedBy: util.RandomOwner(),
+	}
+
+	result, err := s.AdjustAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	assert.Equal(t, account.ID, result.Adjustment.AccountID)
+	assert.Equal(t, arg.Amount, result.Adjustment.Amount)
+	assert.Equal(t, arg.Reason, result.Adjustment.Reason)
+	assert.Equal(t, arg.CreatedBy, result.Adjustment.CreatedBy)
+	assert.Equal(t, account.Balance+arg.Amount, result.Account.Balance)
+	assert.Equal(t, arg.Amount, result.Entry.Amount)
+	assert.Contains(t, result.Entry.Description, arg.Reason)
+
+	// the adjustment is balanced by the system account
+	entries, err := testQueries.ListJournalEntries(context.Background(), result.Entry.JournalID)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, system.ID, entries[1].AccountID)
+	assert.Equal(t, -arg.Amount, entries[1].Amount)
+
+	adjustments, err := testQueries.ListAccountAdjustments(context.Background(), db.ListAccountAdjustmentsParams{
+		AccountID: account.ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	assert.Equal(t, result.Adjustment, adjustments[0])
+}
+
+func TestStore_AdjustAccountTx_NoSystemAccount(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	// no system account holds a made-up currency
+	account := createRandomAccountWithCurrency(t, strings.ToUpper(util.RandomString(3)))
+
+	_, err := s.AdjustAccountTx(context.Background(), db.AdjustAccountTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+		Reason:    "goodwill",
+		CreatedBy: util.RandomOwner(),
+	})
+	assert.ErrorIs(t, err, db.ErrNoSystemAccount)
+}
+
+func TestStore_FrozenAccount(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	frozen, err := testQueries.FreezeAccount(context.Background(), db.FreezeAccountParams{
+		ID:           account1.ID,
+		FrozenReason: "reported stolen card",
+	})
+	require.NoError(t, err)
+	assert.True(t, frozen.FrozenAt.Valid)
+	assert.Equal(t, "reported stolen card", frozen.FrozenReason)
+
+	// frozen accounts can not be debited
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	assert.ErrorIs(t, err, db.ErrAccountFrozen)
+
+	_, err = s.CloseAccountTx(context.Background(), account1.ID)
+	assert.ErrorIs(t, err, db.ErrAccountFrozen)
+
+	// but they can be credited
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+
+	accounts, err := testQueries.SearchAccounts(context.Background(), db.SearchAccountsParams{
+		Owner:       account1.Owner,
+		FrozenOnly:  true,
+		MaxAccounts: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, account1.ID, accounts[0].ID)
+
+	unfrozen, err := testQueries.UnfreezeAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	assert.False(t, unfrozen.FrozenAt.Valid)
+	assert.Empty(t, unfrozen.FrozenReason)
+
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,275 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Statuses of a PendingTransfer.
+const (
+	PendingTransferStatusPending  = "pending"
+	PendingTransferStatusApproved = "approved"
+	PendingTransferStatusRejected = "rejected"
+	PendingTransferStatusExpired  = "expired"
+)
+
+var (
+	// ErrPendingTransferDecided is returned when the pending transfer has already been decided.
+	ErrPendingTransferDecided = errors.New("pending transfer has already been decided")
+	// ErrPendingTransferExpired is returned when the pending transfer is past its expiry.
+	ErrPendingTransferExpired = errors.New("pending transfer has expired")
+	// ErrSelfApproval is returned when the initiator tries to decide its own transfer.
+	ErrSelfApproval = errors.New("initiator can not decide its own transfer")
+	// ErrIneligibleApprover is returned when the user is not listed in the approval policy.
+	ErrIneligibleApprover = errors.New("user is not an eligible approver")
+	// ErrApproverNotHolder is returned when an approver of the policy is not an active holder of the account.
+	ErrApproverNotHolder = errors.New("approver is not an active holder of the account")
+	// ErrInitiatorNotPermitted is returned when the initiator may no longer send the pending transfer.
+	ErrInitiatorNotPermitted = errors.New("initiator is no longer permitted to send the transfer")
+)
+
+// SetApprovalPolicyTxParams contains parameters of the approval policy transaction.
+type SetApprovalPolicyTxParams struct {
+	AccountID int64
+	Threshold int64
+	Approvers []string
+}
+
+// SetApprovalPolicyTxResult contains result of the approval policy transaction.
+type SetApprovalPolicyTxResult struct {
+	Policy    ApprovalPolicy `json:"policy"`
+	Approvers []string       `json:"approvers"`
+}
+
+// SetApprovalPolicyTx creates or replaces the approval policy of the account
+// together with its list of eligible approvers. Every approver must be an active
+// holder of the account.
+func (s *store) SetApprovalPolicyTx(ctx context.Context, arg SetApprovalPolicyTxParams) (SetApprovalPolicyTxResult, error) {
+	var result SetApprovalPolicyTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return fmt.Errorf("failed to get the account: %w", err)
+		}
+
+		for _, username := range arg.Approvers {
+			_, ok, err := activeHolder(ctx, q, account, username)
+			if err != nil {
+				return fmt.Errorf("failed to check approver %s: %w", username, err)
+			}
+
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrApproverNotHolder, username)
+			}
+		}
+
+		if result.Policy, err = q.UpsertApprovalPolicy(ctx, UpsertApprovalPolicyParams{
+			AccountID: arg.AccountID,
+			Threshold: arg.Threshold,
+		}); err != nil {
+			return fmt.Errorf("failed to store the policy: %w", err)
+		}
+
+		if err = q.DeleteApprovalPolicyApprovers(ctx, arg.AccountID); err != nil {
+			return fmt.Errorf("failed to clear the approvers: %w", err)
+		}
+
+		result.Approvers = make([]string, 0, len(arg.Approvers))
+		for _, username := range arg.Approvers {
+			approver, err := q.CreateApprovalPolicyApprover(ctx, CreateApprovalPolicyApproverParams{
+				AccountID: arg.AccountID,
+				Username:  username,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to add approver %s: %w", username, err)
+			}
+
+			result.Approvers = append(result.Approvers, approver.Username)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return SetApprovalPolicyTxResult{}, fmt.Errorf("can not set an approval policy: %w", err)
+	}
+
+	return result, nil
+}
+
+// DecidePendingTransferTxParams contains parameters of the approve and reject transactions.
+type DecidePendingTransferTxParams struct {
+	PendingTransferID int64
+	Approver          string
+	// PayeeCap caps the approved transfer to an account added as a payee recently.
+	PayeeCap PayeeCap
+}
+
+// ApprovePendingTransferTxResult contains result of the approve transaction.
+type ApprovePendingTransferTxResult struct {
+	PendingTransfer PendingTransfer  `json:"pending_transfer"`
+	Transfer        TransferTxResult `json:"transfer"`
+}
+
+// ApprovePendingTransferTx approves the pending transfer and executes it the same way
+// TransferTx does. The status change and the transfer itself are committed within
+// a single database transaction, so a pending transfer is never executed twice.
+// The initiator must still be permitted to send the transfer when it is approved.
+func (s *store) ApprovePendingTransferTx(
+	ctx context.Context,
+	arg DecidePendingTransferTxParams,
+) (ApprovePendingTransferTxResult, error) {
+	var result ApprovePendingTransferTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		pending, err := lockPendingTransfer(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		if err = checkInitiator(ctx, q, pending); err != nil {
+			return err
+		}
+
+		if result.Transfer, err = transferTx(ctx, q, TransferTxParams{
+			FromAccountID:  pending.FromAccountID,
+			ToAccountID:    pending.ToAccountID,
+			Amount:         pending.Amount,
+			Instant:        pending.Instant,
+			PayeeID:        pending.PayeeID,
+			PayeeCap:       arg.PayeeCap,
+			Reference:      pending.Reference,
+			Memo:           pending.Memo,
+			RemittanceInfo: pending.RemittanceInfo,
+		}); err != nil {
+			return err
+		}
+
+		if result.PendingTransfer, err = q.DecidePendingTransfer(ctx, DecidePendingTransferParams{
+			ID:         pending.ID,
+			Status:     PendingTransferStatusApproved,
+			DecidedBy:  sql.NullString{String: arg.Approver, Valid: true},
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to approve the pending transfer: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return ApprovePendingTransferTxResult{}, fmt.Errorf("can not approve a transfer: %w", err)
+	}
+
+	return result, nil
+}
+
+// RejectPendingTransferTx rejects the pending transfer.
+func (s *store) RejectPendingTransferTx(ctx context.Context, arg DecidePendingTransferTxParams) (PendingTransfer, error) {
+	var result PendingTransfer
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		pending, err := lockPendingTransfer(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		if result, err = q.DecidePendingTransfer(ctx, DecidePendingTransferParams{
+			ID:        pending.ID,
+			Status:    PendingTransferStatusRejected,
+			DecidedBy: sql.NullString{String: arg.Approver, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to reject the pending transfer: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return PendingTransfer{}, fmt.Errorf("can not reject a transfer: %w", err)
+	}
+
+	return result, nil
+}
+
+// lockPendingTransfer locks the pending transfer and checks whether it can be
+// decided by the approver.
+func lockPendingTransfer(ctx context.Context, q *Queries, arg DecidePendingTransferTxParams) (PendingTransfer, error) {
+	pending, err := q.GetPendingTransferForUpdate(ctx, arg.PendingTransferID)
+	if err != nil {
+		return PendingTransfer{}, fmt.Errorf("failed to get the pending transfer: %w", err)
+	}
+
+	switch {
+	case pending.Status != PendingTransferStatusPending:
+		return PendingTransfer{}, ErrPendingTransferDecided
+	case !time.Now().Before(pending.ExpiresAt):
+		return PendingTransfer{}, ErrPendingTransferExpired
+	case pending.Initiator == arg.Approver:
+		return PendingTransfer{}, ErrSelfApproval
+	}
+
+	if _, err = q.GetApprovalPolicyApprover(ctx, GetApprovalPolicyApproverParams{
+		AccountID: pending.FromAccountID,
+		Username:  arg.Approver,
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PendingTransfer{}, ErrIneligibleApprover
+		}
+
+		return PendingTransfer{}, fmt.Errorf("failed to check the approver: %w", err)
+	}
+
+	return pending, nil
+}
+
+// checkInitiator checks whether the initiator of the pending transfer is still
+// an active holder of the account who may send the amount.
+func checkInitiator(ctx context.Context, q *Queries, pending PendingTransfer) error {
+	account, err := q.GetAccount(ctx, pending.FromAccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get the account: %w", err)
+	}
+
+	holder, ok, err := activeHolder(ctx, q, account, pending.Initiator)
+	if err != nil {
+		return fmt.Errorf("failed to check the initiator: %w", err)
+	}
+
+	switch {
+	case !ok, holder.Role == HolderRoleViewer:
+		return ErrInitiatorNotPermitted
+	case holder.Role == HolderRoleSignatory && holder.SpendLimit.Valid && pending.Amount > holder.SpendLimit.Int64:
+		return ErrInitiatorNotPermitted
+	}
+
+	return nil
+}
+
+// activeHolder returns the holder record of the user and whether the user is an active
+// holder of the account. The primary holder in the Owner column is always an owner.
+func activeHolder(ctx context.Context, q *Queries, account Account, username string) (AccountHolder, bool, error) {
+	if account.Owner == username {
+		return AccountHolder{
+			AccountID: account.ID,
+			Username:  username,
+			Role:      HolderRoleOwner,
+			Status:    HolderStatusActive,
+		}, true, nil
+	}
+
+	holder, err := q.GetAccountHolder(ctx, GetAccountHolderParams{
+		AccountID: account.ID,
+		Username:  username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return holder, false, nil
+		}
+
+		return holder, false, err
+	}
+
+	return holder, holder.Status == HolderStatusActive, nil
+}
@@ -0,0 +1,269 @@
+package db_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPendingTransfer(t *testing.T, s db.Store, expiresAt time.Time) (db.PendingTransfer, string) {
+	t.Helper()
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	approver := createActiveAccountHolder(t, account1, db.HolderRoleCoOwner)
+
+	_, err := s.SetApprovalPolicyTx(context.Background(), db.SetApprovalPolicyTxParams{
+		AccountID: account1.ID,
+		Threshold: 1,
+		Approvers: []string{approver.Username},
+	})
+	require.NoError(t, err)
+
+	pending, err := testQueries.CreatePendingTransfer(context.Background(), db.CreatePendingTransferParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         util.RandomAmount(),
+		Initiator:      account1.Owner,
+		ExpiresAt:      expiresAt,
+		Reference:      util.RandomOwner(),
+		RemittanceInfo: json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.PendingTransferStatusPending, pending.Status)
+
+	return pending, approver.Username
+}
+
+func TestStore_SetApprovalPolicyTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account := createRandomAccount(t)
+	user1 := createActiveAccountHolder(t, account, db.HolderRoleViewer)
+	user2 := createActiveAccountHolder(t, account, db.HolderRoleCoOwner)
+	invited := createRandomAccountHolder(t, account, db.HolderRoleCoOwner)
+
+	// the approvers must be active holders of the account
+	for _, username := range []string{createRandomUser(t).Username, invited.Username} {
+		_, err := s.SetApprovalPolicyTx(context.Background(), db.SetApprovalPolicyTxParams{
+			AccountID: account.ID,
+			Threshold: 100,
+			Approvers: []string{username},
+		})
+		assert.ErrorIs(t, err, db.ErrApproverNotHolder)
+	}
+
+	// replace the approvers of the policy
+	_, err := s.SetApprovalPolicyTx(context.Background(), db.SetApprovalPolicyTxParams{
+		AccountID: account.ID,
+		Threshold: 100,
+		Approvers: []string{user1.Username},
+	})
+	require.NoError(t, err)
+
+	result, err := s.SetApprovalPolicyTx(context.Background(), db.SetApprovalPolicyTxParams{
+		AccountID: account.ID,
+		Threshold: 200,
+		Approvers: []string{user2.Username},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(200), result.Policy.Threshold)
+	assert.Equal(t, []string{user2.Username}, result.Approvers)
+
+	approvers, err := testQueries.ListApprovalPolicyApprovers(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{user2.Username}, approvers)
+}
+
+func TestStore_ApprovePendingTransferTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	pending, approver := createRandomPendingTransfer(t, s, time.Now().Add(time.Hour))
+
+	// the initiator can not approve its own transfer
+	_, err := s.ApprovePendingTransferTx(context.Background(), db.DecidePendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approver:          pending.Initiator,
+	})
+	assert.ErrorIs(t, err, db.ErrSelfApproval)
+
+	// concurrent approvals execute the transfer only once
+	n := 5
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := s.ApprovePendingTransferTx(context.Background(), db.DecidePendingTransferTxParams{
+				PendingTransferID: pending.ID,
+				Approver:          approver,
+			})
+
+			errs <- err
+		}()
+	}
+
+	var succeeded int
+	for i := 0; i < n; i++ {
+		if err := <-errs; err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, db.ErrPendingTransferDecided)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	approved, err := testQueries.GetPendingTransfer(context.Background(), pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.PendingTransferStatusApproved, approved.Status)
+	assert.Equal(t, approver, approved.DecidedBy.String)
+	require.True(t, approved.TransferID.Valid)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), approved.TransferID.Int64)
+	require.NoError(t, err)
+	assert.Equal(t, pending.Amount, transfer.Amount)
+}
+
+func TestStore_RejectPendingTransferTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	pending, approver := createRandomPendingTransfer(t, s, time.Now().Add(time.Hour))
+
+	// only the listed approvers can decide
+	_, err := s.RejectPendingTransferTx(context.Background(), db.DecidePendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approver:          createRandomUser(t).Username,
+	})
+	assert.ErrorIs(t, err, db.ErrIneligibleApprover)
+
+	rejected, err := s.RejectPendingTransferTx(context.Background(), db.DecidePendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approver:          approver,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.PendingTransferStatusRejected, rejected.Status)
+	assert.False(t, rejected.TransferID.Valid)
+}
+
+func TestQueries_ExpirePendingTransfers(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	pending, approver := createRandomPendingTransfer(t, s, time.Now().Add(-time.Minute))
+
+	_, err := s.ApprovePendingTransferTx(context.Background(), db.DecidePendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approver:          approver,
+	})
+	assert.ErrorIs(t, err, db.ErrPendingTransferExpired)
+
+	n, err := testQueries.ExpirePendingTransfers(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, int64(1))
+
+	expired, err := testQueries.GetPendingTransfer(context.Background(), pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.PendingTransferStatusExpired, expired.Status)
+}
+
+func TestStore_ApprovePendingTransferTxInstant(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	approver := createActiveAccountHolder(t, account1, db.HolderRoleCoOwner)
+	createRandomFeeSchedule(t, account1, db.CreateFeeScheduleParams{
+		TransferKind: db.TransferKindInstant,
+		Method:       db.FeeMethodFlat,
+		FlatFee:      30,
+	})
+
+	_, err := s.SetApprovalPolicyTx(context.Background(), db.SetApprovalPolicyTxParams{
+		AccountID: account1.ID,
+		Threshold: 1,
+		Approvers: []string{approver.Username},
+	})
+	require.NoError(t, err)
+
+	pending, err := testQueries.CreatePendingTransfer(context.Background(), db.CreatePendingTransferParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         util.RandomAmount(),
+		Initiator:      account1.Owner,
+		ExpiresAt:      time.Now().Add(time.Hour),
+		Instant:        true,
+		RemittanceInfo: json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+	assert.True(t, pending.Instant)
+
+	// the approved transfer is charged by the instant schedule
+	result, err := s.ApprovePendingTransferTx(context.Background(), db.DecidePendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approver:          approver.Username,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.TransferKindInstant, result.Transfer.Fee.TransferKind)
+	assert.Equal(t, int64(30), result.Transfer.Fee.Amount)
+}
+
+func TestStore_ApprovePendingTransferTxInitiator(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	approver := createActiveAccountHolder(t, account1, db.HolderRoleCoOwner)
+	signatory := createActiveAccountHolder(t, account1, db.HolderRoleSignatory)
+
+	_, err := s.SetApprovalPolicyTx(context.Background(), db.SetApprovalPolicyTxParams{
+		AccountID: account1.ID,
+		Threshold: 1,
+		Approvers: []string{approver.Username},
+	})
+	require.NoError(t, err)
+
+	createPending := func(amount int64) db.PendingTransfer {
+		pending, err := testQueries.CreatePendingTransfer(context.Background(), db.CreatePendingTransferParams{
+			FromAccountID:  account1.ID,
+			ToAccountID:    account2.ID,
+			Amount:         amount,
+			Initiator:      signatory.Username,
+			ExpiresAt:      time.Now().Add(time.Hour),
+			RemittanceInfo: json.RawMessage("{}"),
+		})
+		require.NoError(t, err)
+
+		return pending
+	}
+
+	// the amount exceeds the spend limit of the signatory
+	_, err = s.ApprovePendingTransferTx(context.Background(), db.DecidePendingTransferTxParams{
+		PendingTransferID: createPending(signatory.SpendLimit.Int64 + 1).ID,
+		Approver:          approver.Username,
+	})
+	assert.ErrorIs(t, err, db.ErrInitiatorNotPermitted)
+
+	// the signatory is removed before the approval
+	pending := createPending(signatory.SpendLimit.Int64)
+
+	_, err = testQueries.DeleteAccountHolder(context.Background(), db.DeleteAccountHolderParams{
+		AccountID: account1.ID,
+		Username:  signatory.Username,
+	})
+	require.NoError(t, err)
+
+	_, err = s.ApprovePendingTransferTx(context.Background(), db.DecidePendingTransferTxParams{
+		PendingTransferID: pending.ID,
+		Approver:          approver.Username,
+	})
+	assert.ErrorIs(t, err, db.ErrInitiatorNotPermitted)
+
+	// the pending transfer stays pending
+	pending, err = testQueries.GetPendingTransfer(context.Background(), pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.PendingTransferStatusPending, pending.Status)
+}
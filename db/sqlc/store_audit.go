@@ -0,0 +1,1236 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AuditActorKey is the context key of the AuditActor. It is a string, so it can be
+// set on a gin.Context as well.
+const AuditActorKey = "audit_actor"
+
+// Actions of the audit records.
+const (
+	AuditActionCreate    = "create"
+	AuditActionUpdate    = "update"
+	AuditActionDelete    = "delete"
+	AuditActionAccept    = "accept"
+	AuditActionApprove   = "approve"
+	AuditActionReject    = "reject"
+	AuditActionPay       = "pay"
+	AuditActionDecline   = "decline"
+	AuditActionRedeliver = "redeliver"
+	AuditActionFreeze    = "freeze"
+	AuditActionUnfreeze  = "unfreeze"
+	AuditActionRevoke    = "revoke"
+	AuditActionUnlock    = "unlock"
+	AuditActionRotate    = "rotate"
+)
+
+// ErrAuditChainBroken is returned when an audit record does not match the hash chain.
+var ErrAuditChainBroken = errors.New("audit log hash chain is broken")
+
+// AuditActor describes who makes the changes audited by the store wrapped by NewAuditStore.
+type AuditActor struct {
+	// Username is empty if the request is not authenticated.
+	Username  string
+	IP        string
+	Route     string
+	RequestID string
+}
+
+// WithAuditActor returns a copy of the context carrying the actor.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, AuditActorKey, actor)
+}
+
+// auditChange is a change of an entity made by an audited operation.
+type auditChange struct {
+	action   string
+	entity   string
+	entityID string
+	before   interface{}
+	after    interface{}
+}
+
+// AuditHash computes the hash of the audit record chained to its PrevHash.
+// The ID, the ChainSeq and the Hash of the record are not hashed.
+func AuditHash(record AuditLog) (string, error) {
+	content, err := json.Marshal([]interface{}{
+		record.PrevHash,
+		record.CreatedAt.UTC().Format(time.RFC3339Nano),
+		record.Actor,
+		record.IP,
+		record.Route,
+		record.RequestID,
+		record.Action,
+		record.Entity,
+		record.EntityID,
+		record.Before,
+		record.After,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// VerifyAuditChain checks that the consecutive audit records are chained to prevHash,
+// the hash of the record preceding them, and that none of them has been altered.
+func VerifyAuditChain(prevHash string, records []AuditLog) error {
+	for _, record := range records {
+		if record.PrevHash != prevHash {
+			return fmt.Errorf("%w: record %d does not follow the previous one", ErrAuditChainBroken, record.ID)
+		}
+
+		hash, err := AuditHash(record)
+		if err != nil {
+			return err
+		}
+
+		if hash != record.Hash {
+			return fmt.Errorf("%w: record %d has been altered", ErrAuditChainBroken, record.ID)
+		}
+
+		prevHash = record.Hash
+	}
+
+	return nil
+}
+
+// auditSnapshot encodes the entity into a snapshot without its secrets.
+func auditSnapshot(entity interface{}) (json.RawMessage, error) {
+	switch e := entity.(type) {
+	case User:
+		e.HashedPassword = ""
+		entity = e
+	case WebhookEndpoint:
+		e.Secret = ""
+		entity = e
+	case Session:
+		e.RefreshTokenHash = ""
+		entity = e
+	case TotpCredential:
+		e.Secret = ""
+		entity = e
+	case APIKey:
+		e.SecretHash = ""
+		entity = e
+	}
+
+	return json.Marshal(entity)
+}
+
+// appendAuditLog appends the record of the change to the audit log. The record is
+// written without a hash and without taking any lock, so the audited writes do not wait
+// for each other. ChainAuditLogTx chains it after its transaction commits.
+func appendAuditLog(ctx context.Context, q *Queries, actor AuditActor, change auditChange) error {
+	arg := CreateAuditLogParams{
+		Actor:     actor.Username,
+		IP:        actor.IP,
+		Route:     actor.Route,
+		RequestID: actor.RequestID,
+		Action:    change.action,
+		Entity:    change.entity,
+		EntityID:  change.entityID,
+		// timestamps are stored with microsecond precision
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	var err error
+	if arg.Before, err = auditSnapshot(change.before); err != nil {
+		return fmt.Errorf("failed to encode the snapshot: %w", err)
+	}
+
+	if arg.After, err = auditSnapshot(change.after); err != nil {
+		return fmt.Errorf("failed to encode the snapshot: %w", err)
+	}
+
+	if _, err := q.CreateAuditLog(ctx, arg); err != nil {
+		return fmt.Errorf("failed to write the audit record: %w", err)
+	}
+
+	return nil
+}
+
+// ChainAuditLogTx appends up to limit committed audit records which are not chained yet
+// to the hash chain, in the order of their IDs. Only the chaining takes the lock of the
+// audit log, so concurrent chaining jobs do not fork the chain. A record committed after
+// a record with a greater ID is chained after it, the chain follows ChainSeq, not the ID.
+// It returns the number of chained records.
+func (s *store) ChainAuditLogTx(ctx context.Context, limit int32) (int, error) {
+	var n int
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		if err := q.LockAuditLog(ctx); err != nil {
+			return fmt.Errorf("failed to lock the audit log: %w", err)
+		}
+
+		last, err := q.GetLastChainedAuditLog(ctx)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get the last chained audit record: %w", err)
+		}
+
+		records, err := q.ListUnchainedAuditLog(ctx, limit)
+		if err != nil {
+			return fmt.Errorf("failed to list unchained audit records: %w", err)
+		}
+
+		for _, record := range records {
+			record.PrevHash = last.Hash
+			record.ChainSeq = last.ChainSeq + 1
+
+			if record.Hash, err = AuditHash(record); err != nil {
+				return err
+			}
+
+			if last, err = q.ChainAuditLog(ctx, ChainAuditLogParams{
+				ID:       record.ID,
+				ChainSeq: record.ChainSeq,
+				PrevHash: record.PrevHash,
+				Hash:     record.Hash,
+			}); err != nil {
+				return fmt.Errorf("failed to chain audit record %d: %w", record.ID, err)
+			}
+		}
+
+		n = len(records)
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("can not chain the audit log: %w", err)
+	}
+
+	return n, nil
+}
+
+// snapshot returns the entity, or nil if it does not exist.
+func snapshot(entity interface{}, err error) (interface{}, error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// auditStore is a Store recording the changes made by its mutating operations in the audit log.
+type auditStore struct {
+	*store
+}
+
+// NewAuditStore constructs a new Store which writes an audit record of every change made
+// on behalf of an AuditActor carried by the context. The record is written in the same
+// database transaction as the change, after the change, and chained later by
+// ChainAuditLogTx, so concurrent audited changes do not wait for each other.
+// Changes made without an actor, e.g. by the jobs, are not audited.
+func NewAuditStore(db *sql.DB) Store {
+	return &auditStore{store: newStore(db)}
+}
+
+// audit executes the operation and records the change it reports.
+func (s *auditStore) audit(ctx context.Context, op func(context.Context, *auditChange) error) error {
+	actor, ok := ctx.Value(AuditActorKey).(AuditActor)
+	if !ok {
+		return op(ctx, &auditChange{})
+	}
+
+	return s.inTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var change auditChange
+		if err := op(ctx, &change); err != nil {
+			return err
+		}
+
+		return appendAuditLog(ctx, New(tx), actor, change)
+	})
+}
+
+func (s *auditStore) OpenAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if account, err = s.store.OpenAccountTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "accounts", formatID(account.ID), nil, account}
+
+		return nil
+	})
+
+	return account, err
+}
+
+func (s *auditStore) UpdateAccountBalance(ctx context.Context, arg UpdateAccountBalanceParams) (Account, error) {
+	var account Account
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetAccount(ctx, arg.ID))
+		if err != nil {
+			return err
+		}
+
+		if account, err = s.store.UpdateAccountBalance(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "accounts", formatID(arg.ID), before, account}
+
+		return nil
+	})
+
+	return account, err
+}
+
+func (s *auditStore) CloseAccountTx(ctx context.Context, id int64) (Account, error) {
+	var account Account
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if account, err = s.store.CloseAccountTx(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "accounts", formatID(id), account, nil}
+
+		return nil
+	})
+
+	return account, err
+}
+
+func (s *auditStore) FreezeAccount(ctx context.Context, arg FreezeAccountParams) (Account, error) {
+	var account Account
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetAccount(ctx, arg.ID))
+		if err != nil {
+			return err
+		}
+
+		if account, err = s.store.FreezeAccount(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionFreeze, "accounts", formatID(arg.ID), before, account}
+
+		return nil
+	})
+
+	return account, err
+}
+
+func (s *auditStore) UnfreezeAccount(ctx context.Context, id int64) (Account, error) {
+	var account Account
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetAccount(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		if account, err = s.store.UnfreezeAccount(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUnfreeze, "accounts", formatID(id), before, account}
+
+		return nil
+	})
+
+	return account, err
+}
+
+func (s *auditStore) AdjustAccountTx(ctx context.Context, arg AdjustAccountTxParams) (AdjustAccountTxResult, error) {
+	var result AdjustAccountTxResult
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if result, err = s.store.AdjustAccountTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "adjustments", formatID(result.Adjustment.ID), nil, result.Adjustment}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *auditStore) CreateAccountHolder(ctx context.Context, arg CreateAccountHolderParams) (AccountHolder, error) {
+	var holder AccountHolder
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if holder, err = s.store.CreateAccountHolder(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "account_holders", holderID(holder.AccountID, holder.Username), nil, holder}
+
+		return nil
+	})
+
+	return holder, err
+}
+
+func (s *auditStore) AcceptAccountHolder(ctx context.Context, arg AcceptAccountHolderParams) (AccountHolder, error) {
+	var holder AccountHolder
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetAccountHolder(ctx, GetAccountHolderParams(arg)))
+		if err != nil {
+			return err
+		}
+
+		if holder, err = s.store.AcceptAccountHolder(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionAccept, "account_holders", holderID(arg.AccountID, arg.Username), before, holder}
+
+		return nil
+	})
+
+	return holder, err
+}
+
+func (s *auditStore) DeleteAccountHolder(ctx context.Context, arg DeleteAccountHolderParams) (int64, error) {
+	var n int64
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetAccountHolder(ctx, GetAccountHolderParams(arg)))
+		if err != nil {
+			return err
+		}
+
+		if n, err = s.store.DeleteAccountHolder(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "account_holders", holderID(arg.AccountID, arg.Username), before, nil}
+
+		return nil
+	})
+
+	return n, err
+}
+
+func holderID(accountID int64, username string) string {
+	return formatID(accountID) + "/" + username
+}
+
+func (s *auditStore) SetApprovalPolicyTx(ctx context.Context, arg SetApprovalPolicyTxParams) (SetApprovalPolicyTxResult, error) {
+	var result SetApprovalPolicyTxResult
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetApprovalPolicy(ctx, arg.AccountID))
+		if err != nil {
+			return err
+		}
+
+		if result, err = s.store.SetApprovalPolicyTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "approval_policies", formatID(arg.AccountID), before, result}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *auditStore) DeleteApprovalPolicy(ctx context.Context, accountID int64) error {
+	return s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetApprovalPolicy(ctx, accountID))
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.DeleteApprovalPolicy(ctx, accountID); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "approval_policies", formatID(accountID), before, nil}
+
+		return nil
+	})
+}
+
+func (s *auditStore) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	var entry Entry
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if entry, err = s.store.CreateEntry(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "entries", formatID(entry.ID), nil, entry}
+
+		return nil
+	})
+
+	return entry, err
+}
+
+func (s *auditStore) UpdateEntryAmount(ctx context.Context, arg UpdateEntryAmountParams) (Entry, error) {
+	var entry Entry
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetEntry(ctx, arg.ID))
+		if err != nil {
+			return err
+		}
+
+		if entry, err = s.store.UpdateEntryAmount(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "entries", formatID(arg.ID), before, entry}
+
+		return nil
+	})
+
+	return entry, err
+}
+
+func (s *auditStore) SetEntryCategory(ctx context.Context, arg SetEntryCategoryParams) (Entry, error) {
+	var entry Entry
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetEntry(ctx, arg.ID))
+		if err != nil {
+			return err
+		}
+
+		if entry, err = s.store.SetEntryCategory(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "entries", formatID(arg.ID), before, entry}
+
+		return nil
+	})
+
+	return entry, err
+}
+
+func (s *auditStore) DeleteEntry(ctx context.Context, id int64) error {
+	return s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetEntry(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.DeleteEntry(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "entries", formatID(id), before, nil}
+
+		return nil
+	})
+}
+
+func (s *auditStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if result, err = s.store.TransferTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "transfers", formatID(result.Transfer.ID), nil, result}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *auditStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if result, err = s.store.BatchTransferTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "batches", formatID(result.Batch.ID), nil, result}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *auditStore) CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error) {
+	var pending PendingTransfer
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if pending, err = s.store.CreatePendingTransfer(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "pending_transfers", formatID(pending.ID), nil, pending}
+
+		return nil
+	})
+
+	return pending, err
+}
+
+func (s *auditStore) ApprovePendingTransferTx(
+	ctx context.Context,
+	arg DecidePendingTransferTxParams,
+) (ApprovePendingTransferTxResult, error) {
+	var result ApprovePendingTransferTxResult
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetPendingTransfer(ctx, arg.PendingTransferID))
+		if err != nil {
+			return err
+		}
+
+		if result, err = s.store.ApprovePendingTransferTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionApprove, "pending_transfers", formatID(arg.PendingTransferID), before, result}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *auditStore) RejectPendingTransferTx(ctx context.Context, arg DecidePendingTransferTxParams) (PendingTransfer, error) {
+	var pending PendingTransfer
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetPendingTransfer(ctx, arg.PendingTransferID))
+		if err != nil {
+			return err
+		}
+
+		if pending, err = s.store.RejectPendingTransferTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionReject, "pending_transfers", formatID(arg.PendingTransferID), before, pending}
+
+		return nil
+	})
+
+	return pending, err
+}
+
+func (s *auditStore) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
+	var category Category
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if category, err = s.store.CreateCategory(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "categories", formatID(category.ID), nil, category}
+
+		return nil
+	})
+
+	return category, err
+}
+
+func (s *auditStore) DeleteCategory(ctx context.Context, id int64) error {
+	return s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetCategory(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.DeleteCategory(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "categories", formatID(id), before, nil}
+
+		return nil
+	})
+}
+
+func (s *auditStore) CreateCategoryRule(ctx context.Context, arg CreateCategoryRuleParams) (CategoryRule, error) {
+	var rule CategoryRule
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if rule, err = s.store.CreateCategoryRule(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "category_rules", formatID(rule.ID), nil, rule}
+
+		return nil
+	})
+
+	return rule, err
+}
+
+func (s *auditStore) DeleteCategoryRule(ctx context.Context, id int64) error {
+	return s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetCategoryRule(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.DeleteCategoryRule(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "category_rules", formatID(id), before, nil}
+
+		return nil
+	})
+}
+
+func (s *auditStore) CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error) {
+	var payee Payee
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if payee, err = s.store.CreatePayee(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "payees", formatID(payee.ID), nil, payee}
+
+		return nil
+	})
+
+	return payee, err
+}
+
+func (s *auditStore) UpdatePayee(ctx context.Context, arg UpdatePayeeParams) (Payee, error) {
+	var payee Payee
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetPayee(ctx, arg.ID))
+		if err != nil {
+			return err
+		}
+
+		if payee, err = s.store.UpdatePayee(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "payees", formatID(arg.ID), before, payee}
+
+		return nil
+	})
+
+	return payee, err
+}
+
+func (s *auditStore) DeletePayee(ctx context.Context, id int64) error {
+	return s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetPayee(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.DeletePayee(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "payees", formatID(id), before, nil}
+
+		return nil
+	})
+}
+
+func (s *auditStore) CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error) {
+	var request PaymentRequest
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if request, err = s.store.CreatePaymentRequest(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "payment_requests", formatID(request.ID), nil, request}
+
+		return nil
+	})
+
+	return request, err
+}
+
+func (s *auditStore) PayPaymentRequestTx(ctx context.Context, arg PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error) {
+	var result PayPaymentRequestTxResult
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetPaymentRequest(ctx, arg.PaymentRequestID))
+		if err != nil {
+			return err
+		}
+
+		if result, err = s.store.PayPaymentRequestTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionPay, "payment_requests", formatID(arg.PaymentRequestID), before, result}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func (s *auditStore) DeclinePaymentRequestTx(ctx context.Context, arg DeclinePaymentRequestTxParams) (PaymentRequest, error) {
+	var request PaymentRequest
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetPaymentRequest(ctx, arg.PaymentRequestID))
+		if err != nil {
+			return err
+		}
+
+		if request, err = s.store.DeclinePaymentRequestTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDecline, "payment_requests", formatID(arg.PaymentRequestID), before, request}
+
+		return nil
+	})
+
+	return request, err
+}
+
+func (s *auditStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if user, err = s.store.CreateUser(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "users", user.Username, nil, user}
+
+		return nil
+	})
+
+	return user, err
+}
+
+func (s *auditStore) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	var user User
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetUser(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		if user, err = s.store.UpdateUserPassword(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "users", arg.Username, before, user}
+
+		return nil
+	})
+
+	return user, err
+}
+
+func (s *auditStore) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	var n int64
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetUser(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		if n, err = s.store.RehashUserPassword(ctx, arg); err != nil {
+			return err
+		}
+
+		after, err := snapshot(s.store.GetUser(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "users", arg.Username, before, after}
+
+		return nil
+	})
+
+	return n, err
+}
+
+func (s *auditStore) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	var user User
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetUser(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		if user, err = s.store.SetUserRole(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "users", arg.Username, before, user}
+
+		return nil
+	})
+
+	return user, err
+}
+
+func (s *auditStore) DeleteUser(ctx context.Context, username string) error {
+	return s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetUser(ctx, username))
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.DeleteUser(ctx, username); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "users", username, before, nil}
+
+		return nil
+	})
+}
+
+func (s *auditStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	var session Session
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if session, err = s.store.CreateSession(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "sessions", formatID(session.ID), nil, session}
+
+		return nil
+	})
+
+	return session, err
+}
+
+func (s *auditStore) RevokeSessionFamily(ctx context.Context, familyID string) (int64, error) {
+	var revoked int64
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if revoked, err = s.store.RevokeSessionFamily(ctx, familyID); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionRevoke, "sessions", familyID, nil, map[string]int64{"revoked": revoked}}
+
+		return nil
+	})
+
+	return revoked, err
+}
+
+func (s *auditStore) VerifyEmailTx(ctx context.Context, tokenHash string) (User, error) {
+	var user User
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if user, err = s.store.VerifyEmailTx(ctx, tokenHash); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "users", user.Username, nil, user}
+
+		return nil
+	})
+
+	return user, err
+}
+
+func (s *auditStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if user, err = s.store.ResetPasswordTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "users", user.Username, nil, user}
+
+		return nil
+	})
+
+	return user, err
+}
+
+func (s *auditStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	var user User
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetUser(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		if user, err = s.store.ChangePasswordTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "users", arg.Username, before, user}
+
+		return nil
+	})
+
+	return user, err
+}
+
+func (s *auditStore) UpsertTOTPCredential(ctx context.Context, arg UpsertTOTPCredentialParams) (TotpCredential, error) {
+	var credential TotpCredential
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetTOTPCredential(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		if credential, err = s.store.UpsertTOTPCredential(ctx, arg); err != nil {
+			return err
+		}
+
+		action := AuditActionCreate
+		if before != nil {
+			action = AuditActionUpdate
+		}
+
+		*c = auditChange{action, "totp_credentials", arg.Username, before, credential}
+
+		return nil
+	})
+
+	return credential, err
+}
+
+func (s *auditStore) EnableTOTPTx(ctx context.Context, arg TOTPTxParams) (TotpCredential, error) {
+	var credential TotpCredential
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if credential, err = s.store.EnableTOTPTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "totp_credentials", arg.Username, nil, credential}
+
+		return nil
+	})
+
+	return credential, err
+}
+
+func (s *auditStore) RegenerateRecoveryCodesTx(ctx context.Context, arg TOTPTxParams) ([]RecoveryCode, error) {
+	var codes []RecoveryCode
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if codes, err = s.store.RegenerateRecoveryCodesTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUpdate, "recovery_codes", arg.Username, nil, map[string]int{"issued": len(codes)}}
+
+		return nil
+	})
+
+	return codes, err
+}
+
+func (s *auditStore) DisableTOTPTx(ctx context.Context, arg TOTPTxParams) error {
+	return s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetTOTPCredential(ctx, arg.Username))
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.DisableTOTPTx(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "totp_credentials", arg.Username, before, nil}
+
+		return nil
+	})
+}
+
+func (s *auditStore) UnlockLoginThrottle(ctx context.Context, arg UnlockLoginThrottleParams) (LoginThrottle, error) {
+	var throttle LoginThrottle
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetLoginThrottle(ctx, GetLoginThrottleParams(arg)))
+		if err != nil {
+			return err
+		}
+
+		if throttle, err = s.store.UnlockLoginThrottle(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionUnlock, "login_throttles", arg.Scope + "/" + arg.Subject, before, throttle}
+
+		return nil
+	})
+
+	return throttle, err
+}
+
+func (s *auditStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	var key APIKey
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if key, err = s.store.CreateAPIKey(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "api_keys", formatID(key.ID), nil, key}
+
+		return nil
+	})
+
+	return key, err
+}
+
+func (s *auditStore) RotateAPIKey(ctx context.Context, arg RotateAPIKeyParams) (APIKey, error) {
+	var key APIKey
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetAPIKey(ctx, arg.ID))
+		if err != nil {
+			return err
+		}
+
+		if key, err = s.store.RotateAPIKey(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionRotate, "api_keys", formatID(arg.ID), before, key}
+
+		return nil
+	})
+
+	return key, err
+}
+
+func (s *auditStore) RevokeAPIKey(ctx context.Context, id int64) (APIKey, error) {
+	var key APIKey
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetAPIKey(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		if key, err = s.store.RevokeAPIKey(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionRevoke, "api_keys", formatID(id), before, key}
+
+		return nil
+	})
+
+	return key, err
+}
+
+func (s *auditStore) CreateWebhookEndpoint(ctx context.Context, arg CreateWebhookEndpointParams) (WebhookEndpoint, error) {
+	var endpoint WebhookEndpoint
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		var err error
+		if endpoint, err = s.store.CreateWebhookEndpoint(ctx, arg); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionCreate, "webhook_endpoints", formatID(endpoint.ID), nil, endpoint}
+
+		return nil
+	})
+
+	return endpoint, err
+}
+
+func (s *auditStore) DeleteWebhookEndpoint(ctx context.Context, id int64) error {
+	return s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetWebhookEndpoint(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		if err := s.store.DeleteWebhookEndpoint(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionDelete, "webhook_endpoints", formatID(id), before, nil}
+
+		return nil
+	})
+}
+
+func (s *auditStore) RedeliverWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+
+	err := s.audit(ctx, func(ctx context.Context, c *auditChange) error {
+		before, err := snapshot(s.store.GetWebhookDelivery(ctx, id))
+		if err != nil {
+			return err
+		}
+
+		if delivery, err = s.store.RedeliverWebhookDelivery(ctx, id); err != nil {
+			return err
+		}
+
+		*c = auditChange{AuditActionRedeliver, "webhook_deliveries", formatID(id), before, delivery}
+
+		return nil
+	})
+
+	return delivery, err
+}
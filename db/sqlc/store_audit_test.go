@@ -0,0 +1,377 @@
+package db_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAuditActor(t *testing.T) db.AuditActor {
+	t.Helper()
+
+	return db.AuditActor{
+		Username:  util.RandomOwner(),
+		IP:        "192.0.2.1",
+		Route:     "PUT /accounts/:id",
+		RequestID: util.RandomString(32),
+	}
+}
+
+func listRequestAuditLog(t *testing.T, requestID string) []db.AuditLog {
+	t.Helper()
+
+	records, err := testQueries.ListAuditLog(context.Background(), db.ListAuditLogParams{
+		RequestID:  requestID,
+		ToTime:     time.Now().Add(time.Hour),
+		MaxRecords: 100,
+	})
+	require.NoError(t, err)
+
+	return records
+}
+
+// chainAuditLog chains all committed audit records and returns the given record chained.
+func chainAuditLog(t *testing.T, record db.AuditLog) db.AuditLog {
+	t.Helper()
+
+	s := db.NewStore(testDB)
+
+	for {
+		n, err := s.ChainAuditLogTx(context.Background(), 1000)
+		require.NoError(t, err)
+
+		if n == 0 {
+			break
+		}
+	}
+
+	records, err := testQueries.ListAuditLog(context.Background(), db.ListAuditLogParams{
+		RequestID:  record.RequestID,
+		Entity:     record.Entity,
+		EntityID:   record.EntityID,
+		ToTime:     time.Now().Add(time.Hour),
+		MaxRecords: 100,
+	})
+	require.NoError(t, err)
+
+	for _, r := range records {
+		if r.ID == record.ID {
+			require.NotZero(t, r.ChainSeq)
+
+			return r
+		}
+	}
+
+	t.Fatalf("audit record %d not found", record.ID)
+
+	return db.AuditLog{}
+}
+
+func TestAuditStore_UpdateAccountBalance(t *testing.T) {
+	s := db.NewAuditStore(testDB)
+	account := createRandomAccount(t)
+	actor := randomAuditActor(t)
+
+	updated, err := s.UpdateAccountBalance(db.WithAuditActor(context.Background(), actor), db.UpdateAccountBalanceParams{
+		ID:      account.ID,
+		Balance: account.Balance + 10,
+	})
+	require.NoError(t, err)
+
+	records := listRequestAuditLog(t, actor.RequestID)
+	require.Len(t, records, 1)
+
+	record := records[0]
+	assert.Equal(t, actor.Username, record.Actor)
+	assert.Equal(t, actor.IP, record.IP)
+	assert.Equal(t, actor.Route, record.Route)
+	assert.Equal(t, db.AuditActionUpdate, record.Action)
+	assert.Equal(t, "accounts", record.Entity)
+	assert.Equal(t, strconv.FormatInt(account.ID, 10), record.EntityID)
+
+	var before, after db.Account
+	require.NoError(t, json.Unmarshal(record.Before, &before))
+	require.NoError(t, json.Unmarshal(record.After, &after))
+	assert.Equal(t, account.Balance, before.Balance)
+	assert.Equal(t, updated.Balance, after.Balance)
+
+	// the record is chained after it is written
+	assert.Zero(t, record.ChainSeq)
+	assert.Empty(t, record.Hash)
+
+	record = chainAuditLog(t, record)
+
+	hash, err := db.AuditHash(record)
+	require.NoError(t, err)
+	assert.Equal(t, record.Hash, hash)
+
+	// without an actor nothing is audited
+	ctx := context.Background()
+	_, err = s.UpdateAccountBalance(ctx, db.UpdateAccountBalanceParams{ID: account.ID, Balance: account.Balance})
+	require.NoError(t, err)
+	assert.Len(t, listRequestAuditLog(t, actor.RequestID), 1)
+}
+
+func TestAuditStore_CreateUser_Redacted(t *testing.T) {
+	s := db.NewAuditStore(testDB)
+	actor := randomAuditActor(t)
+
+	hashedPassword := util.RandomString(32)
+
+	user, err := s.CreateUser(db.WithAuditActor(context.Background(), actor), db.CreateUserParams{
+		Username:       util.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FirstName:      util.RandomOwner(),
+		LastName:       util.RandomOwner(),
+		Email:          util.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	records := listRequestAuditLog(t, actor.RequestID)
+	require.Len(t, records, 1)
+	assert.Equal(t, user.Username, records[0].EntityID)
+	assert.JSONEq(t, "null", string(records[0].Before))
+	assert.NotContains(t, string(records[0].After), hashedPassword)
+}
+
+func TestAuditStore_RehashUserPassword(t *testing.T) {
+	s := db.NewAuditStore(testDB)
+	user := createRandomUser(t)
+	actor := randomAuditActor(t)
+
+	hashedPassword := util.RandomString(32)
+
+	n, err := s.RehashUserPassword(db.WithAuditActor(context.Background(), actor), db.RehashUserPasswordParams{
+		Username:          user.Username,
+		HashedPassword:    hashedPassword,
+		OldHashedPassword: user.HashedPassword,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	records := listRequestAuditLog(t, actor.RequestID)
+	require.Len(t, records, 1)
+	assert.Equal(t, "users", records[0].Entity)
+	assert.Equal(t, user.Username, records[0].EntityID)
+	assert.NotContains(t, string(records[0].Before), user.HashedPassword)
+	assert.NotContains(t, string(records[0].After), hashedPassword)
+}
+
+func TestAuditStore_UpsertTOTPCredential(t *testing.T) {
+	s := db.NewAuditStore(testDB)
+	user := createRandomUser(t)
+	actor := randomAuditActor(t)
+
+	secret := util.RandomString(32)
+
+	_, err := s.UpsertTOTPCredential(db.WithAuditActor(context.Background(), actor), db.UpsertTOTPCredentialParams{
+		Username: user.Username,
+		Secret:   secret,
+	})
+	require.NoError(t, err)
+
+	records := listRequestAuditLog(t, actor.RequestID)
+	require.Len(t, records, 1)
+	assert.Equal(t, db.AuditActionCreate, records[0].Action)
+	assert.Equal(t, "totp_credentials", records[0].Entity)
+	assert.NotContains(t, string(records[0].After), secret)
+}
+
+func TestAuditStore_RolledBack(t *testing.T) {
+	s := db.NewAuditStore(testDB)
+	actor := randomAuditActor(t)
+
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+
+	// a failed change leaves no audit record
+	_, err := s.TransferTx(db.WithAuditActor(context.Background(), actor), db.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        from.Balance + 1_000_000_000,
+	})
+	require.Error(t, err)
+	assert.Empty(t, listRequestAuditLog(t, actor.RequestID))
+}
+
+func TestAuditStore_BatchTransferTx_BestEffort(t *testing.T) {
+	s := db.NewAuditStore(testDB)
+	actor := randomAuditActor(t)
+
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+
+	// the failed leg is rolled back to its savepoint only
+	result, err := s.BatchTransferTx(db.WithAuditActor(context.Background(), actor), db.BatchTransferTxParams{
+		Initiator: from.Owner,
+		Mode:      db.BatchModeBestEffort,
+		Legs: []db.TransferTxParams{
+			{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 1},
+			{FromAccountID: from.ID, ToAccountID: to.ID, Amount: from.Balance + 1_000_000_000},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Legs, 2)
+	assert.Equal(t, db.BatchLegStatusCompleted, result.Legs[0].Status)
+	assert.Equal(t, db.BatchLegStatusFailed, result.Legs[1].Status)
+	assert.Equal(t, db.BatchStatusPartiallyCompleted, result.Batch.Status)
+
+	account, err := testQueries.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	assert.Equal(t, from.Balance-1, account.Balance)
+
+	records := listRequestAuditLog(t, actor.RequestID)
+	require.Len(t, records, 1)
+	assert.Equal(t, "batches", records[0].Entity)
+}
+
+func TestAuditLog_HashChain(t *testing.T) {
+	s := db.NewAuditStore(testDB)
+	account := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := s.UpdateAccountBalance(db.WithAuditActor(context.Background(), randomAuditActor(t)),
+			db.UpdateAccountBalanceParams{ID: account.ID, Balance: int64(i)})
+		require.NoError(t, err)
+	}
+
+	records, err := testQueries.ListAuditLog(context.Background(), db.ListAuditLogParams{
+		Entity:     "accounts",
+		EntityID:   strconv.FormatInt(account.ID, 10),
+		ToTime:     time.Now().Add(time.Hour),
+		MaxRecords: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	// the oldest record is listed last
+	first := chainAuditLog(t, records[2])
+
+	// the whole chain up to the latest record is valid
+	chain, err := testQueries.ListAuditLogAfter(context.Background(), db.ListAuditLogAfterParams{
+		ChainSeq: first.ChainSeq - 1,
+		Limit:    1_000_000,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(chain), 3)
+	require.NoError(t, db.VerifyAuditChain(chain[0].PrevHash, chain))
+
+	// tampering is detected
+	chain[1].Actor = util.RandomOwner()
+	assert.ErrorIs(t, db.VerifyAuditChain(chain[0].PrevHash, chain), db.ErrAuditChainBroken)
+
+	// the audit log is append-only
+	_, err = testDB.Exec(`UPDATE audit_log SET actor = '' WHERE id = $1`, records[0].ID)
+	assert.Error(t, err)
+
+	_, err = testDB.Exec(`DELETE FROM audit_log WHERE id = $1`, records[0].ID)
+	assert.Error(t, err)
+
+	// a chained record can not be chained again
+	_, err = testQueries.ChainAuditLog(context.Background(), db.ChainAuditLogParams{
+		ID:       first.ID,
+		ChainSeq: first.ChainSeq + 1_000_000,
+		PrevHash: first.PrevHash,
+		Hash:     util.RandomString(64),
+	})
+	assert.Error(t, err)
+}
+
+func TestAuditLog_ConcurrentWriters(t *testing.T) {
+	s := db.NewAuditStore(testDB)
+	account := createRandomAccount(t)
+
+	// an audited change is left uncommitted
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	pending, err := db.New(tx).CreateAuditLog(context.Background(), db.CreateAuditLogParams{
+		Actor:     util.RandomOwner(),
+		RequestID: util.RandomString(32),
+		Action:    db.AuditActionUpdate,
+		Entity:    "accounts",
+		EntityID:  util.RandomString(8),
+		Before:    json.RawMessage(`null`),
+		After:     json.RawMessage(`null`),
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	})
+	require.NoError(t, err)
+
+	// other audited changes do not wait for it
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	actor := randomAuditActor(t)
+	_, err = s.UpdateAccountBalance(db.WithAuditActor(ctx, actor), db.UpdateAccountBalanceParams{
+		ID:      account.ID,
+		Balance: account.Balance,
+	})
+	require.NoError(t, err)
+
+	records := listRequestAuditLog(t, actor.RequestID)
+	require.Len(t, records, 1)
+	committed := chainAuditLog(t, records[0])
+
+	// the record committed later is chained later, although its ID is lower
+	require.NoError(t, tx.Commit())
+	require.Less(t, pending.ID, committed.ID)
+
+	pending = chainAuditLog(t, pending)
+	assert.Greater(t, pending.ChainSeq, committed.ChainSeq)
+
+	chain, err := testQueries.ListAuditLogAfter(context.Background(), db.ListAuditLogAfterParams{
+		ChainSeq: committed.ChainSeq - 1,
+		Limit:    1_000_000,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.VerifyAuditChain(committed.PrevHash, chain))
+}
+
+// BenchmarkAuditStore compares concurrent updates of distinct accounts with
+// and without the audit log, whose records are written without any shared lock.
+func BenchmarkAuditStore(b *testing.B) {
+	accounts := make(chan db.Account, 64)
+	for i := 0; i < cap(accounts); i++ {
+		accounts <- createRandomAccount(b)
+	}
+
+	for _, bench := range []struct {
+		name  string
+		store db.Store
+		ctx   context.Context
+	}{
+		{"Unaudited", db.NewStore(testDB), context.Background()},
+		{"Audited", db.NewAuditStore(testDB), db.WithAuditActor(context.Background(), db.AuditActor{
+			Username:  util.RandomOwner(),
+			IP:        "192.0.2.1",
+			Route:     "PUT /accounts/:id",
+			RequestID: util.RandomString(32),
+		})},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				// each goroutine updates its own account
+				account := <-accounts
+				defer func() { accounts <- account }()
+
+				for pb.Next() {
+					if _, err := bench.store.UpdateAccountBalance(bench.ctx, db.UpdateAccountBalanceParams{
+						ID:      account.ID,
+						Balance: account.Balance,
+					}); err != nil {
+						b.Error(err)
+
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+)
+
+// Modes of a Batch.
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+)
+
+// Statuses of a Batch.
+const (
+	BatchStatusProcessing         = "processing"
+	BatchStatusCompleted          = "completed"
+	BatchStatusPartiallyCompleted = "partially_completed"
+	BatchStatusFailed             = "failed"
+)
+
+// Statuses of a BatchLeg.
+const (
+	BatchLegStatusCompleted = "completed"
+	BatchLegStatusFailed    = "failed"
+)
+
+// BatchTransferTxParams contains parameters of the batch transfer transaction.
+type BatchTransferTxParams struct {
+	Initiator string
+	Mode      string
+	Legs      []TransferTxParams
+}
+
+// BatchTransferTxResult contains result of the batch transfer transaction.
+type BatchTransferTxResult struct {
+	Batch Batch      `json:"batch"`
+	Legs  []BatchLeg `json:"legs"`
+}
+
+// BatchTransferTx performs all transfers of the batch and records the outcome
+// of every leg into the batches table.
+//
+// In the atomic mode every leg is executed within a single database transaction,
+// so either all legs succeed or none of them does. In the best effort mode every
+// leg is executed on its own and failed legs do not affect the others.
+// Failures of the legs are reported by the statuses of the result, the returned
+// error is set only if the batch itself can not be recorded.
+func (s *store) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	batch, err := s.CreateBatch(ctx, CreateBatchParams{
+		Initiator: arg.Initiator,
+		Mode:      arg.Mode,
+	})
+	if err != nil {
+		return BatchTransferTxResult{}, fmt.Errorf("can not create a batch: %w", err)
+	}
+
+	switch arg.Mode {
+	case BatchModeAtomic:
+		return s.atomicBatchTransfer(ctx, batch, arg.Legs)
+	case BatchModeBestEffort:
+		return s.bestEffortBatchTransfer(ctx, batch, arg.Legs)
+	default:
+		return BatchTransferTxResult{}, fmt.Errorf("unknown batch mode: %s", arg.Mode)
+	}
+}
+
+// atomicBatchTransfer executes all legs within a single database transaction.
+// The affected accounts, including the accounts collecting the fees, are locked
+// in the order of their IDs up front, so concurrent batches can not deadlock each other.
+func (s *store) atomicBatchTransfer(ctx context.Context, batch Batch, legs []TransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+	failed := -1
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		ids, err := batchAccountIDs(ctx, q, legs)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			if _, err := q.GetAccountForUpdate(ctx, id); err != nil {
+				return fmt.Errorf("failed to lock account %d: %w", id, err)
+			}
+		}
+
+		result.Legs = make([]BatchLeg, 0, len(legs))
+		for i, leg := range legs {
+			transfer, err := transferTx(ctx, q, leg)
+			if err != nil {
+				failed = i
+
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+
+			batchLeg, err := q.CreateBatchLeg(ctx, newBatchLegParams(batch.ID, i, leg, transfer.Transfer.ID, nil))
+			if err != nil {
+				return fmt.Errorf("failed to record leg %d: %w", i, err)
+			}
+
+			result.Legs = append(result.Legs, batchLeg)
+		}
+
+		if result.Batch, err = q.CompleteBatch(ctx, CompleteBatchParams{
+			ID:     batch.ID,
+			Status: BatchStatusCompleted,
+		}); err != nil {
+			return fmt.Errorf("failed to complete the batch: %w", err)
+		}
+
+		return nil
+	})
+	if err == nil {
+		return result, nil
+	}
+
+	// the transaction has been rolled back, record the failure of all legs
+	result.Legs = make([]BatchLeg, 0, len(legs))
+	for i, leg := range legs {
+		var legErr error
+		if i == failed {
+			legErr = err
+		}
+
+		batchLeg, errLeg := s.CreateBatchLeg(ctx, newBatchLegParams(batch.ID, i, leg, 0, legErr))
+		if errLeg != nil {
+			return BatchTransferTxResult{}, fmt.Errorf("can not record leg %d: %w", i, errLeg)
+		}
+
+		result.Legs = append(result.Legs, batchLeg)
+	}
+
+	if result.Batch, err = s.CompleteBatch(ctx, CompleteBatchParams{
+		ID:     batch.ID,
+		Status: BatchStatusFailed,
+		Error:  sql.NullString{String: err.Error(), Valid: true},
+	}); err != nil {
+		return BatchTransferTxResult{}, fmt.Errorf("can not complete the batch: %w", err)
+	}
+
+	return result, nil
+}
+
+// bestEffortBatchTransfer executes every leg in its own transfer transaction.
+func (s *store) bestEffortBatchTransfer(ctx context.Context, batch Batch, legs []TransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+	var completed int
+
+	result.Legs = make([]BatchLeg, 0, len(legs))
+	for i, leg := range legs {
+		transfer, legErr := s.TransferTx(ctx, leg)
+		if legErr == nil {
+			completed++
+		}
+
+		batchLeg, err := s.CreateBatchLeg(ctx, newBatchLegParams(batch.ID, i, leg, transfer.Transfer.ID, legErr))
+		if err != nil {
+			return BatchTransferTxResult{}, fmt.Errorf("can not record leg %d: %w", i, err)
+		}
+
+		result.Legs = append(result.Legs, batchLeg)
+	}
+
+	status := BatchStatusPartiallyCompleted
+	switch completed {
+	case len(legs):
+		status = BatchStatusCompleted
+	case 0:
+		status = BatchStatusFailed
+	}
+
+	var err error
+	if result.Batch, err = s.CompleteBatch(ctx, CompleteBatchParams{
+		ID:     batch.ID,
+		Status: status,
+	}); err != nil {
+		return BatchTransferTxResult{}, fmt.Errorf("can not complete the batch: %w", err)
+	}
+
+	return result, nil
+}
+
+// newBatchLegParams constructs the record of the leg at the given position.
+// The leg is failed if legErr is not nil.
+func newBatchLegParams(batchID int64, position int, leg TransferTxParams, transferID int64, legErr error) CreateBatchLegParams {
+	params := CreateBatchLegParams{
+		BatchID:       batchID,
+		Position:      int32(position),
+		FromAccountID: leg.FromAccountID,
+		ToAccountID:   leg.ToAccountID,
+		Amount:        leg.Amount,
+		Status:        BatchLegStatusCompleted,
+		TransferID:    sql.NullInt64{Int64: transferID, Valid: transferID != 0},
+	}
+
+	if legErr != nil || transferID == 0 {
+		params.Status = BatchLegStatusFailed
+	}
+
+	if legErr != nil {
+		params.Error = sql.NullString{String: legErr.Error(), Valid: true}
+	}
+
+	return params
+}
+
+// batchAccountIDs returns the distinct IDs of all accounts affected by the legs,
+// including the accounts collecting their fees, in ascending order.
+func batchAccountIDs(ctx context.Context, q *Queries, legs []TransferTxParams) ([]int64, error) {
+	seen := make(map[int64]struct{}, 3*len(legs))
+	ids := make([]int64, 0, 3*len(legs))
+
+	for i, leg := range legs {
+		feeID, err := feeAccountID(ctx, q, leg)
+		if err != nil {
+			return nil, fmt.Errorf("leg %d: %w", i, err)
+		}
+
+		for _, id := range []int64{leg.FromAccountID, leg.ToAccountID, feeID} {
+			if _, ok := seen[id]; !ok && id != 0 {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
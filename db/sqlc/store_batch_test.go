@@ -0,0 +1,208 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_BatchTransferTxAtomic(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	funding := createRandomAccount(t)
+	account1 := createRandomAccountWithCurrency(t, funding.Currency)
+	account2 := createRandomAccountWithCurrency(t, funding.Currency)
+	amount := util.RandomAmount()
+
+	result, err := s.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Initiator: funding.Owner,
+		Mode:      db.BatchModeAtomic,
+		Legs: []db.TransferTxParams{
+			{FromAccountID: funding.ID, ToAccountID: account1.ID, Amount: amount},
+			{FromAccountID: funding.ID, ToAccountID: account2.ID, Amount: amount},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.BatchStatusCompleted, result.Batch.Status)
+
+	if assert.Len(t, result.Legs, 2) {
+		for _, leg := range result.Legs {
+			assert.Equal(t, db.BatchLegStatusCompleted, leg.Status)
+			assert.True(t, leg.TransferID.Valid)
+		}
+	}
+
+	updated, err := testQueries.GetAccount(context.Background(), funding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, funding.Balance-2*amount, updated.Balance)
+}
+
+func TestStore_BatchTransferTxAtomicRollback(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	funding := createRandomAccount(t)
+	account := createRandomAccountWithCurrency(t, funding.Currency)
+
+	// the second leg refers to a non-existing account
+	result, err := s.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Initiator: funding.Owner,
+		Mode:      db.BatchModeAtomic,
+		Legs: []db.TransferTxParams{
+			{FromAccountID: funding.ID, ToAccountID: account.ID, Amount: util.RandomAmount()},
+			{FromAccountID: funding.ID, ToAccountID: -1, Amount: util.RandomAmount()},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.BatchStatusFailed, result.Batch.Status)
+	assert.True(t, result.Batch.Error.Valid)
+
+	if assert.Len(t, result.Legs, 2) {
+		assert.Equal(t, db.BatchLegStatusFailed, result.Legs[0].Status)
+		assert.Equal(t, db.BatchLegStatusFailed, result.Legs[1].Status)
+	}
+
+	// no leg has been executed
+	updated, err := testQueries.GetAccount(context.Background(), funding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, funding.Balance, updated.Balance)
+}
+
+func TestStore_BatchTransferTxBestEffort(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	funding := createRandomAccount(t)
+	account := createRandomAccountWithCurrency(t, funding.Currency)
+	amount := util.RandomAmount()
+
+	result, err := s.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+		Initiator: funding.Owner,
+		Mode:      db.BatchModeBestEffort,
+		Legs: []db.TransferTxParams{
+			{FromAccountID: funding.ID, ToAccountID: account.ID, Amount: amount},
+			{FromAccountID: funding.ID, ToAccountID: -1, Amount: amount},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.BatchStatusPartiallyCompleted, result.Batch.Status)
+
+	if assert.Len(t, result.Legs, 2) {
+		assert.Equal(t, db.BatchLegStatusCompleted, result.Legs[0].Status)
+		assert.Equal(t, db.BatchLegStatusFailed, result.Legs[1].Status)
+		assert.True(t, result.Legs[1].Error.Valid)
+	}
+
+	legs, err := testQueries.ListBatchLegs(context.Background(), result.Batch.ID)
+	require.NoError(t, err)
+	assert.Len(t, legs, 2)
+
+	updated, err := testQueries.GetAccount(context.Background(), funding.ID)
+	require.NoError(t, err)
+	assert.Equal(t, funding.Balance-amount, updated.Balance)
+}
+
+func TestStore_BatchTransferTxDeadLock(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+	amount := util.RandomAmount()
+
+	// batches touching the same accounts in opposite order
+	n := 10
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		legs := []db.TransferTxParams{
+			{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: amount},
+			{FromAccountID: account3.ID, ToAccountID: account2.ID, Amount: amount},
+			{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: amount},
+		}
+
+		if i%2 == 0 {
+			legs[0], legs[2] = legs[2], legs[0]
+		}
+
+		go func() {
+			result, err := s.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+				Initiator: account1.Owner,
+				Mode:      db.BatchModeAtomic,
+				Legs:      legs,
+			})
+			if err == nil && result.Batch.Status != db.BatchStatusCompleted {
+				err = assert.AnError
+			}
+
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// every batch is a cycle, the balances stay the same
+	for _, account := range []db.Account{account1, account2, account3} {
+		updated, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, account.Balance, updated.Balance)
+	}
+}
+
+func TestStore_BatchTransferTxFeeDeadLock(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	// the fee account is created before the accounts of the legs, so its ID is lower
+	template := createRandomAccount(t)
+	schedule := createRandomFeeSchedule(t, template, db.CreateFeeScheduleParams{
+		TransferKind: db.TransferKindInstant,
+		Method:       db.FeeMethodFlat,
+		FlatFee:      1,
+	})
+	account1 := createRandomAccountWithCurrency(t, template.Currency)
+	account2 := createRandomAccountWithCurrency(t, template.Currency)
+
+	feeAccount, err := testQueries.GetAccount(context.Background(), schedule.FeeAccountID)
+	require.NoError(t, err)
+
+	// batches charged a fee run along batches paying from the fee account
+	n := 10
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		legs := []db.TransferTxParams{{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 1, Instant: true}}
+		if i%2 == 0 {
+			legs = []db.TransferTxParams{{FromAccountID: schedule.FeeAccountID, ToAccountID: account1.ID, Amount: 1}}
+		}
+
+		go func() {
+			result, err := s.BatchTransferTx(context.Background(), db.BatchTransferTxParams{
+				Initiator: account1.Owner,
+				Mode:      db.BatchModeAtomic,
+				Legs:      legs,
+			})
+			if err == nil && result.Batch.Status != db.BatchStatusCompleted {
+				err = assert.AnError
+			}
+
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// the fees are paid back by the other batches
+	updated, err := testQueries.GetAccount(context.Background(), feeAccount.ID)
+	require.NoError(t, err)
+	assert.Equal(t, feeAccount.Balance, updated.Balance)
+
+	updated, err = testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, account1.Balance-int64(n/2), updated.Balance)
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Types of the events published by the store.
+const (
+	EventTransferCreated = "transfer.created"
+	EventEntryCreated    = "entry.created"
+	EventAccountOpened   = "account.opened"
+	EventAccountClosed   = "account.closed"
+	EventBalanceChanged  = "balance.changed"
+)
+
+// AccountEventsChannel is the Postgres notification channel of the committed outbox events.
+const AccountEventsChannel = "account_events"
+
+// AccountEventNotification is the payload of a notification on the AccountEventsChannel.
+// It is sent for every outbox row and delivered only when the transaction commits.
+type AccountEventNotification struct {
+	AccountID int64 `json:"account_id"`
+	ID        int64 `json:"id"`
+}
+
+// EventTypes are all event types webhook endpoints can subscribe to.
+var EventTypes = []string{EventTransferCreated, EventEntryCreated, EventAccountClosed}
+
+// Event is the JSON payload of an outbox row and of a webhook delivery.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// publishEvent writes a new event to the outbox once for every given account, so each
+// account has a complete ordered stream of its events, notifies the listeners of
+// the AccountEventsChannel and queues its deliveries to the webhook endpoints of
+// the account owners subscribed to the event type. Everything is written using
+// the given Queries, so the event is rolled back together with the operation
+// which published it.
+func publishEvent(ctx context.Context, q *Queries, eventType string, data interface{}, accountIDs ...int64) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("failed to generate event ID: %w", err)
+	}
+
+	event := Event{
+		ID:        hex.EncodeToString(id),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	written := make(map[int64]bool, len(accountIDs))
+	for _, accountID := range accountIDs {
+		if written[accountID] {
+			continue
+		}
+
+		row, err := q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+			EventID:   event.ID,
+			EventType: eventType,
+			AccountID: accountID,
+			Payload:   payload,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to write %s event to the outbox: %w", eventType, err)
+		}
+
+		notification, err := json.Marshal(AccountEventNotification{AccountID: accountID, ID: row.ID})
+		if err != nil {
+			return fmt.Errorf("failed to encode %s notification: %w", eventType, err)
+		}
+
+		if err := q.NotifyAccountEvent(ctx, string(notification)); err != nil {
+			return fmt.Errorf("failed to notify %s event: %w", eventType, err)
+		}
+
+		written[accountID] = true
+	}
+
+	if _, err := q.CreateWebhookDeliveries(ctx, CreateWebhookDeliveriesParams{
+		EventID:    event.ID,
+		EventType:  eventType,
+		Payload:    payload,
+		AccountIds: accountIDs,
+	}); err != nil {
+		return fmt.Errorf("failed to queue %s event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+// OpenAccountTx creates a new account and publishes the account.opened event
+// within a single database transaction.
+func (s *store) OpenAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if account, err = q.CreateAccount(ctx, arg); err != nil {
+			return fmt.Errorf("failed to create the account: %w", err)
+		}
+
+		return publishEvent(ctx, q, EventAccountOpened, account, account.ID)
+	})
+	if err != nil {
+		return Account{}, fmt.Errorf("can not open account: %w", err)
+	}
+
+	return account, nil
+}
+
+// CloseAccountTx deletes the account and publishes the account.closed event
+// within a single database transaction. Frozen accounts can not be closed.
+func (s *store) CloseAccountTx(ctx context.Context, id int64) (Account, error) {
+	var account Account
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if account, err = q.GetAccountForUpdate(ctx, id); err != nil {
+			return fmt.Errorf("failed to lock the account: %w", err)
+		}
+
+		if account.FrozenAt.Valid {
+			return fmt.Errorf("%w: account %d", ErrAccountFrozen, account.ID)
+		}
+
+		if err := publishEvent(ctx, q, EventAccountClosed, account, account.ID); err != nil {
+			return err
+		}
+
+		if err := q.DeleteAccount(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete the account: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Account{}, fmt.Errorf("can not close account: %w", err)
+	}
+
+	return account, nil
+}
+
+// DispatchFunc hands claimed outbox events over to their consumers.
+type DispatchFunc func([]Outbox) error
+
+// DispatchOutboxTx claims up to limit undispatched outbox events and hands them to
+// dispatch in the order of their IDs. The events are marked as dispatched if dispatch
+// succeeds, otherwise they are released to be claimed again. Only the oldest undispatched
+// event of an account can be claimed and claimed events are skipped by concurrent
+// dispatchers, so the events of every account are dispatched in order.
+// It returns the number of dispatched events.
+func (s *store) DispatchOutboxTx(ctx context.Context, limit int32, dispatch DispatchFunc) (int, error) {
+	var events []Outbox
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if events, err = q.ClaimOutboxEvents(ctx, limit); err != nil {
+			return fmt.Errorf("failed to claim outbox events: %w", err)
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		if err := dispatch(events); err != nil {
+			return err
+		}
+
+		ids := make([]int64, len(events))
+		for i, event := range events {
+			ids[i] = event.ID
+		}
+
+		if _, err := q.MarkOutboxEventsDispatched(ctx, ids); err != nil {
+			return fmt.Errorf("failed to mark outbox events dispatched: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("can not dispatch outbox events: %w", err)
+	}
+
+	return len(events), nil
+}
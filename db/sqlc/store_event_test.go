@@ -0,0 +1,269 @@
+package db_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listAccountOutboxEvents(t *testing.T, accountID int64) []db.Outbox {
+	t.Helper()
+
+	events, err := testQueries.ListAccountOutboxEvents(context.Background(), db.ListAccountOutboxEventsParams{
+		AccountID: accountID,
+		Limit:     100,
+	})
+	require.NoError(t, err)
+
+	return events
+}
+
+func TestStore_TransferTx_Outbox(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+
+	result, err := s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// both accounts receive the same transfer event
+	var transferEventID string
+	for _, account := range []db.Account{from, to} {
+		var types []string
+		for _, event := range listAccountOutboxEvents(t, account.ID) {
+			assert.False(t, event.DispatchedAt.Valid)
+			types = append(types, event.EventType)
+
+			if event.EventType != db.EventTransferCreated {
+				continue
+			}
+
+			var payload struct {
+				db.Event
+				Data db.Transfer `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(event.Payload, &payload))
+			assert.Equal(t, event.EventID, payload.ID)
+			assert.Equal(t, result.Transfer.ID, payload.Data.ID)
+
+			if transferEventID == "" {
+				transferEventID = event.EventID
+			}
+			assert.Equal(t, transferEventID, event.EventID)
+		}
+
+		assert.Contains(t, types, db.EventTransferCreated)
+		assert.Contains(t, types, db.EventEntryCreated)
+		assert.Contains(t, types, db.EventBalanceChanged)
+	}
+
+	// a rolled back transfer leaves no events behind
+	before := len(listAccountOutboxEvents(t, from.ID))
+
+	_, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        from.Balance + 1_000_000_000,
+	})
+	require.Error(t, err)
+	assert.Len(t, listAccountOutboxEvents(t, from.ID), before)
+}
+
+func TestStore_OpenAccountTx(t *testing.T) {
+	s := db.NewStore(testDB)
+	user := createRandomUser(t)
+
+	number, err := util.NewAccountNumber()
+	require.NoError(t, err)
+
+	account, err := s.OpenAccountTx(context.Background(), db.CreateAccountParams{
+		Owner:       user.Username,
+		Balance:     util.RandomBalance(),
+		Currency:    util.RandomCurrency(),
+		AccountType: db.AccountTypeChecking,
+		Number:      number,
+	})
+	require.NoError(t, err)
+
+	events := listAccountOutboxEvents(t, account.ID)
+	require.Len(t, events, 1)
+	assert.Equal(t, db.EventAccountOpened, events[0].EventType)
+}
+
+func TestQueries_ClaimOutboxEvents(t *testing.T) {
+	account := createRandomAccount(t)
+
+	for i := 0; i < 3; i++ {
+		_, err := testQueries.CreateOutboxEvent(context.Background(), db.CreateOutboxEventParams{
+			EventID:   util.RandomString(32),
+			EventType: db.EventEntryCreated,
+			AccountID: account.ID,
+			Payload:   []byte(`{}`),
+		})
+		require.NoError(t, err)
+	}
+
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+
+	defer func() { _ = tx.Rollback() }()
+
+	// only the oldest undispatched event of the account can be claimed
+	events, err := db.New(tx).ClaimOutboxEvents(context.Background(), 10000)
+	require.NoError(t, err)
+
+	var claimed []db.Outbox
+	for _, event := range events {
+		if event.AccountID == account.ID {
+			claimed = append(claimed, event)
+		}
+	}
+
+	require.Len(t, claimed, 1)
+	assert.Equal(t, listAccountOutboxEvents(t, account.ID)[0].ID, claimed[0].ID)
+}
+
+func TestStore_DispatchOutboxTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+
+	for i := 0; i < 2; i++ {
+		_, err := s.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			Amount:        1,
+		})
+		require.NoError(t, err)
+	}
+
+	// a failed dispatch releases the events
+	errSink := errors.New("sink unavailable")
+	_, err := s.DispatchOutboxTx(context.Background(), 10000, func([]db.Outbox) error { return errSink })
+	require.ErrorIs(t, err, errSink)
+
+	for _, event := range listAccountOutboxEvents(t, from.ID) {
+		assert.False(t, event.DispatchedAt.Valid)
+	}
+
+	// the events of the account are dispatched in order
+	var dispatched []int64
+	for {
+		n, err := s.DispatchOutboxTx(context.Background(), 10000, func(events []db.Outbox) error {
+			for _, event := range events {
+				if event.AccountID == from.ID {
+					dispatched = append(dispatched, event.ID)
+				}
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		if n == 0 {
+			break
+		}
+	}
+
+	events := listAccountOutboxEvents(t, from.ID)
+	require.Len(t, dispatched, len(events))
+
+	for i, event := range events {
+		assert.Equal(t, event.ID, dispatched[i])
+		assert.True(t, event.DispatchedAt.Valid)
+	}
+}
+
+func TestQueries_ListAccountEventsAfter(t *testing.T) {
+	account := createRandomAccount(t)
+
+	latest, err := testQueries.GetLatestAccountEventID(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.Zero(t, latest)
+
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		event, err := testQueries.CreateOutboxEvent(context.Background(), db.CreateOutboxEventParams{
+			EventID:   util.RandomString(32),
+			EventType: db.EventEntryCreated,
+			AccountID: account.ID,
+			Payload:   []byte(`{}`),
+		})
+		require.NoError(t, err)
+
+		ids = append(ids, event.ID)
+	}
+
+	latest, err = testQueries.GetLatestAccountEventID(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ids[2], latest)
+
+	events, err := testQueries.ListAccountEventsAfter(context.Background(), db.ListAccountEventsAfterParams{
+		AccountID: account.ID,
+		AfterID:   ids[0],
+		MaxEvents: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, ids[1], events[0].ID)
+	assert.Equal(t, ids[2], events[1].ID)
+}
+
+func TestStore_OpenAccountTx_Notify(t *testing.T) {
+	s := db.NewStore(testDB)
+	user := createRandomUser(t)
+
+	listener := pq.NewListener(testSource, time.Second, time.Second, nil)
+	defer listener.Close()
+	require.NoError(t, listener.Listen(db.AccountEventsChannel))
+
+	number, err := util.NewAccountNumber()
+	require.NoError(t, err)
+
+	account, err := s.OpenAccountTx(context.Background(), db.CreateAccountParams{
+		Owner:       user.Username,
+		Currency:    util.RandomCurrency(),
+		AccountType: db.AccountTypeChecking,
+		Number:      number,
+	})
+	require.NoError(t, err)
+
+	events := listAccountOutboxEvents(t, account.ID)
+	require.Len(t, events, 1)
+
+	// notifications of other tests may come first
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case n := <-listener.Notify:
+			if n == nil {
+				continue
+			}
+
+			var notification db.AccountEventNotification
+			require.NoError(t, json.Unmarshal([]byte(n.Extra), &notification))
+
+			if notification.AccountID == account.ID {
+				assert.Equal(t, events[0].ID, notification.ID)
+
+				return
+			}
+		case <-timeout:
+			t.Fatal("account event not notified")
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Kinds of a transfer a FeeSchedule applies to. There is no cross-currency kind,
+// transfers between accounts of different currencies are rejected with ErrCurrencyMismatch.
+const (
+	TransferKindStandard = "standard"
+	TransferKindInstant  = "instant"
+)
+
+// Methods of a FeeSchedule.
+const (
+	FeeMethodFlat       = "flat"
+	FeeMethodPercentage = "percentage"
+	FeeMethodTiered     = "tiered"
+)
+
+var (
+	// ErrUnknownFeeMethod is returned when a fee schedule has an unsupported method.
+	ErrUnknownFeeMethod = errors.New("unknown fee method")
+	// ErrFeeOverflow is returned when the fee or the total of a transfer overflows.
+	ErrFeeOverflow = errors.New("fee of the transfer overflows")
+)
+
+// Fee is the breakdown of a fee charged for a transfer.
+type Fee struct {
+	TransferKind string `json:"transfer_kind"`
+	// the following fields are empty if no fee schedule applies
+	ScheduleID   int64  `json:"schedule_id,omitempty"`
+	Method       string `json:"method,omitempty"`
+	FeeAccountID int64  `json:"fee_account_id,omitempty"`
+	// Computed is the fee given by the method before the min and max bounds are applied.
+	Computed int64 `json:"computed"`
+	// Waived is set if the transfer fits in the free monthly quota.
+	Waived bool  `json:"waived"`
+	Amount int64 `json:"amount"`
+	// Total is the amount debited from the sender including the fee.
+	Total int64 `json:"total"`
+}
+
+// QuoteTransferFee computes the fee of the transfer without executing it.
+func (s *store) QuoteTransferFee(ctx context.Context, arg TransferTxParams) (Fee, error) {
+	fee, err := quoteFee(ctx, s.Queries, arg)
+	if err != nil {
+		return Fee{}, fmt.Errorf("can not quote the fee: %w", err)
+	}
+
+	return fee, nil
+}
+
+// transferKind returns the kind of the transfer its fee schedule is chosen by.
+func transferKind(arg TransferTxParams) string {
+	if arg.Instant {
+		return TransferKindInstant
+	}
+
+	return TransferKindStandard
+}
+
+// feeAccountID returns the ID of the account collecting the fees of the transfer,
+// or zero if no fee schedule applies to it.
+func feeAccountID(ctx context.Context, q *Queries, arg TransferTxParams) (int64, error) {
+	account, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get account %d: %w", arg.FromAccountID, err)
+	}
+
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		AccountType:  account.AccountType,
+		TransferKind: transferKind(arg),
+		Currency:     account.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("failed to get a fee schedule: %w", err)
+	}
+
+	return schedule.FeeAccountID, nil
+}
+
+// quoteFee computes the fee of the transfer by the fee schedule of the type
+// and the currency of the sending account.
+func quoteFee(ctx context.Context, q *Queries, arg TransferTxParams) (Fee, error) {
+	fee := Fee{
+		TransferKind: transferKind(arg),
+		Total:        arg.Amount,
+	}
+
+	account, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return fee, fmt.Errorf("failed to get account %d: %w", arg.FromAccountID, err)
+	}
+
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		AccountType:  account.AccountType,
+		TransferKind: fee.TransferKind,
+		Currency:     account.Currency,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fee, nil
+		}
+
+		return fee, fmt.Errorf("failed to get a fee schedule: %w", err)
+	}
+
+	fee.ScheduleID = schedule.ID
+	fee.Method = schedule.Method
+	fee.FeeAccountID = schedule.FeeAccountID
+
+	var tiers []FeeTier
+	if schedule.Method == FeeMethodTiered {
+		if tiers, err = q.ListFeeTiers(ctx, schedule.ID); err != nil {
+			return fee, fmt.Errorf("failed to list fee tiers: %w", err)
+		}
+	}
+
+	if fee.Computed, err = computeFee(schedule, tiers, arg.Amount); err != nil {
+		return fee, err
+	}
+
+	fee.Amount = boundFee(schedule, fee.Computed)
+
+	// the first transfers of the month may be free
+	if schedule.FreeMonthlyTransfers > 0 && fee.Amount > 0 {
+		now := time.Now()
+
+		count, err := q.CountTransfersSince(ctx, CountTransfersSinceParams{
+			FromAccountID: arg.FromAccountID,
+			CreatedAt:     time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()),
+		})
+		if err != nil {
+			return fee, fmt.Errorf("failed to count transfers: %w", err)
+		}
+
+		if count < int64(schedule.FreeMonthlyTransfers) {
+			fee.Waived = true
+			fee.Amount = 0
+		}
+	}
+
+	if fee.Amount > math.MaxInt64-arg.Amount {
+		return fee, fmt.Errorf("%w: %d plus the fee of %d", ErrFeeOverflow, arg.Amount, fee.Amount)
+	}
+
+	fee.Total = arg.Amount + fee.Amount
+
+	return fee, nil
+}
+
+// computeFee computes the fee of the amount by the method of the schedule.
+// The tiers must be sorted by their min amounts.
+func computeFee(schedule FeeSchedule, tiers []FeeTier, amount int64) (int64, error) {
+	switch schedule.Method {
+	case FeeMethodFlat:
+		return schedule.FlatFee, nil
+	case FeeMethodPercentage:
+		rate := int64(schedule.RateBp)
+		if rate > 0 && amount > (math.MaxInt64-5000)/rate {
+			return 0, fmt.Errorf("%w: %d bp of %d", ErrFeeOverflow, rate, amount)
+		}
+
+		// round half up
+		return (amount*rate + 5000) / 10000, nil
+	case FeeMethodTiered:
+		var fee int64
+		for _, tier := range tiers {
+			if tier.MinAmount > amount {
+				break
+			}
+
+			fee = tier.Fee
+		}
+
+		return fee, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownFeeMethod, schedule.Method)
+	}
+}
+
+// boundFee applies the min and max bounds of the schedule to the fee.
+func boundFee(schedule FeeSchedule, fee int64) int64 {
+	if fee < schedule.MinFee {
+		fee = schedule.MinFee
+	}
+
+	if schedule.MaxFee.Valid && fee > schedule.MaxFee.Int64 {
+		fee = schedule.MaxFee.Int64
+	}
+
+	return fee
+}
@@ -0,0 +1,212 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createRandomFeeSchedule creates a fee schedule of the type and the currency
+// of the account with a new fee account.
+func createRandomFeeSchedule(t *testing.T, account db.Account, arg db.CreateFeeScheduleParams) db.FeeSchedule {
+	t.Helper()
+
+	arg.AccountType = account.AccountType
+	arg.Currency = account.Currency
+	arg.FeeAccountID = createRandomAccountWithType(t, db.AccountTypeSystem, account.Currency).ID
+
+	if arg.TransferKind == "" {
+		arg.TransferKind = db.TransferKindStandard
+	}
+
+	// the schedule is unique per type, kind and currency
+	if old, err := testQueries.GetFeeSchedule(context.Background(), db.GetFeeScheduleParams{
+		AccountType:  arg.AccountType,
+		TransferKind: arg.TransferKind,
+		Currency:     arg.Currency,
+	}); err == nil {
+		require.NoError(t, testQueries.DeleteFeeSchedule(context.Background(), old.ID))
+	}
+
+	schedule, err := testQueries.CreateFeeSchedule(context.Background(), arg)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = testQueries.DeleteFeeSchedule(context.Background(), schedule.ID)
+	})
+
+	return schedule
+}
+
+func TestStore_QuoteTransferFee(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	tests := []struct {
+		name     string
+		schedule db.CreateFeeScheduleParams
+		tiers    []db.CreateFeeTierParams
+		amount   int64
+		fee      int64
+	}{
+		{
+			name:     "Flat",
+			schedule: db.CreateFeeScheduleParams{Method: db.FeeMethodFlat, FlatFee: 25},
+			amount:   1000,
+			fee:      25,
+		},
+		{
+			name:     "Percentage",
+			schedule: db.CreateFeeScheduleParams{Method: db.FeeMethodPercentage, RateBp: 150},
+			amount:   1000,
+			fee:      15,
+		},
+		{
+			name:     "PercentageMin",
+			schedule: db.CreateFeeScheduleParams{Method: db.FeeMethodPercentage, RateBp: 150, MinFee: 20},
+			amount:   1000,
+			fee:      20,
+		},
+		{
+			name: "PercentageMax",
+			schedule: db.CreateFeeScheduleParams{
+				Method: db.FeeMethodPercentage,
+				RateBp: 150,
+				MaxFee: sql.NullInt64{Int64: 10, Valid: true},
+			},
+			amount: 1000,
+			fee:    10,
+		},
+		{
+			name:     "Tiered",
+			schedule: db.CreateFeeScheduleParams{Method: db.FeeMethodTiered},
+			tiers: []db.CreateFeeTierParams{
+				{MinAmount: 0, Fee: 5},
+				{MinAmount: 500, Fee: 8},
+				{MinAmount: 5000, Fee: 12},
+			},
+			amount: 1000,
+			fee:    8,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule := createRandomFeeSchedule(t, account, test.schedule)
+			for _, tier := range test.tiers {
+				tier.FeeScheduleID = schedule.ID
+				_, err := testQueries.CreateFeeTier(context.Background(), tier)
+				require.NoError(t, err)
+			}
+
+			fee, err := s.QuoteTransferFee(context.Background(), db.TransferTxParams{
+				FromAccountID: account.ID,
+				Amount:        test.amount,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, schedule.ID, fee.ScheduleID)
+			assert.Equal(t, test.fee, fee.Amount)
+			assert.Equal(t, test.amount+test.fee, fee.Total)
+		})
+	}
+}
+
+func TestStore_QuoteTransferFeeOverflow(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	// the percentage of the amount overflows
+	createRandomFeeSchedule(t, account, db.CreateFeeScheduleParams{Method: db.FeeMethodPercentage, RateBp: 10000})
+
+	_, err := s.QuoteTransferFee(context.Background(), db.TransferTxParams{
+		FromAccountID: account.ID,
+		Amount:        math.MaxInt64 / 1000,
+	})
+	assert.ErrorIs(t, err, db.ErrFeeOverflow)
+
+	// the amount with the fee overflows
+	createRandomFeeSchedule(t, account, db.CreateFeeScheduleParams{Method: db.FeeMethodFlat, FlatFee: 10})
+
+	_, err = s.QuoteTransferFee(context.Background(), db.TransferTxParams{
+		FromAccountID: account.ID,
+		Amount:        math.MaxInt64 - 5,
+	})
+	assert.ErrorIs(t, err, db.ErrFeeOverflow)
+}
+
+func TestStore_TransferTxCurrencyMismatch(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "EUR")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	_, err := s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.RandomAmount(),
+	})
+	assert.ErrorIs(t, err, db.ErrCurrencyMismatch)
+}
+
+func TestStore_TransferTxFee(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	schedule := createRandomFeeSchedule(t, account1, db.CreateFeeScheduleParams{
+		TransferKind:         db.TransferKindInstant,
+		Method:               db.FeeMethodFlat,
+		FlatFee:              30,
+		FreeMonthlyTransfers: 1,
+	})
+	amount := util.RandomAmount()
+
+	// the first transfer of the month is free
+	result, err := s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Instant:       true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Fee.Waived)
+	assert.Zero(t, result.Fee.Amount)
+	assert.Empty(t, result.FeeEntry)
+
+	feeAccount, err := testQueries.GetAccount(context.Background(), schedule.FeeAccountID)
+	require.NoError(t, err)
+
+	// the second one is charged
+	result, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Instant:       true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), result.Fee.Amount)
+	assert.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	assert.Equal(t, int64(-30), result.FeeEntry.Amount)
+	assert.Equal(t, account1.Balance-2*amount-30, result.FromAccount.Balance)
+
+	updated, err := testQueries.GetAccount(context.Background(), schedule.FeeAccountID)
+	require.NoError(t, err)
+	assert.Equal(t, feeAccount.Balance+30, updated.Balance)
+
+	// standard transfers are not affected by the instant schedule
+	result, err = s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, result.Fee.ScheduleID)
+	assert.Zero(t, result.Fee.Amount)
+}
@@ -0,0 +1,228 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Day count conventions of an InterestRate.
+const (
+	DayCountActual365 = "act/365"
+	DayCount30360     = "30/360"
+)
+
+// microsPerUnit is the number of accrued micros in the smallest currency unit.
+const microsPerUnit = 1000000
+
+var (
+	// ErrUnknownDayCount is returned when an interest rate has an unsupported day count convention.
+	ErrUnknownDayCount = errors.New("unknown day count convention")
+	// ErrInterestOverflow is returned when the accrued interest does not fit in the micros.
+	ErrInterestOverflow = errors.New("accrued interest overflows")
+)
+
+// AccrueInterestTxResult contains result of the accrue interest transaction.
+type AccrueInterestTxResult struct {
+	// Accruals are the accruals created by the transaction, accounts already
+	// accrued for the date are left out.
+	Accruals []InterestAccrual
+	// Errors are the errors of the accounts left out because their interest
+	// can not be computed, e.g. because of an unknown day count convention.
+	Errors []error
+}
+
+// AccrueInterestTx accrues a daily interest of every interest bearing account
+// for the given date. The interest is computed from the end-of-day balance
+// by the rate and the day count convention of the type and the currency of the account.
+// Accounts already accrued for the date are skipped, so the transaction can be
+// safely repeated. Accounts whose interest can not be computed are skipped too
+// and reported in the result, so they do not hold up the other accounts.
+func (s *store) AccrueInterestTx(ctx context.Context, date time.Time) (AccrueInterestTxResult, error) {
+	var result AccrueInterestTxResult
+
+	date = truncateDate(date)
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		accounts, err := q.ListInterestBearingAccounts(ctx, date.AddDate(0, 0, 1))
+		if err != nil {
+			return fmt.Errorf("failed to list interest bearing accounts: %w", err)
+		}
+
+		for _, account := range accounts {
+			micros, err := accrueInterest(account.Balance, account.RateBp, account.DayCount, date)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("account %d: %w", account.ID, err))
+
+				continue
+			}
+
+			accrual, err := q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+				AccountID:    account.ID,
+				AccrualDate:  date,
+				Balance:      account.Balance,
+				RateBp:       account.RateBp,
+				DayCount:     account.DayCount,
+				AmountMicros: micros,
+			})
+			if err != nil {
+				// already accrued
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+
+				return fmt.Errorf("failed to create an accrual of account %d: %w", account.ID, err)
+			}
+
+			result.Accruals = append(result.Accruals, accrual)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return AccrueInterestTxResult{}, fmt.Errorf("can not accrue interest: %w", err)
+	}
+
+	return result, nil
+}
+
+// CapitalizeInterestTxResult contains result of the capitalize interest transaction.
+type CapitalizeInterestTxResult struct {
+	// Capitalizations are the capitalizations created by the transaction,
+	// accounts already capitalized for the period are left out.
+	Capitalizations []InterestCapitalization
+}
+
+// CapitalizeInterestTx posts the interest accrued until the end of the month
+// of the given period to the accounts. The interest is rounded to the smallest
+// currency unit and paid from the interest account of the rate by a journal.
+// Every account is capitalized in its own database transaction at most once
+// per period, so the transaction can be safely repeated.
+func (s *store) CapitalizeInterestTx(ctx context.Context, period time.Time) (CapitalizeInterestTxResult, error) {
+	var result CapitalizeInterestTxResult
+
+	period = truncateDate(period)
+	period = period.AddDate(0, 0, 1-period.Day())
+	end := period.AddDate(0, 1, 0)
+
+	accruals, err := s.ListUncapitalizedInterest(ctx, end)
+	if err != nil {
+		return result, fmt.Errorf("can not list uncapitalized interest: %w", err)
+	}
+
+	for _, accrued := range accruals {
+		var capitalization InterestCapitalization
+
+		err := s.execTx(ctx, func(q *Queries) error {
+			var err error
+
+			amount := (accrued.AmountMicros + microsPerUnit/2) / microsPerUnit
+			if capitalization, err = q.CreateInterestCapitalization(ctx, CreateInterestCapitalizationParams{
+				AccountID: accrued.AccountID,
+				Period:    period,
+				Amount:    amount,
+			}); err != nil {
+				return fmt.Errorf("failed to create a capitalization: %w", err)
+			}
+
+			if amount > 0 {
+				journal, err := journalTx(ctx, q, []Leg{
+					{AccountID: accrued.InterestAccountID, Amount: -amount},
+					{AccountID: accrued.AccountID, Amount: amount},
+				})
+				if err != nil {
+					return fmt.Errorf("failed to post the interest: %w", err)
+				}
+
+				if capitalization, err = q.SetInterestCapitalizationJournal(ctx, SetInterestCapitalizationJournalParams{
+					ID:        capitalization.ID,
+					JournalID: sql.NullInt64{Int64: journal.Journal.ID, Valid: true},
+				}); err != nil {
+					return fmt.Errorf("failed to link the journal: %w", err)
+				}
+			}
+
+			if _, err = q.MarkInterestAccrualsCapitalized(ctx, MarkInterestAccrualsCapitalizedParams{
+				AccountID:        accrued.AccountID,
+				CapitalizationID: sql.NullInt64{Int64: capitalization.ID, Valid: true},
+				AccrualDate:      end,
+			}); err != nil {
+				return fmt.Errorf("failed to mark the accruals: %w", err)
+			}
+
+			return nil
+		})
+		if err != nil {
+			// already capitalized
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+
+			return result, fmt.Errorf("can not capitalize interest of account %d: %w", accrued.AccountID, err)
+		}
+
+		result.Capitalizations = append(result.Capitalizations, capitalization)
+	}
+
+	return result, nil
+}
+
+// accrueInterest computes the interest in micros of the balance for a single day.
+// Non-positive balances do not earn any interest.
+func accrueInterest(balance int64, rateBp int32, dayCount string, date time.Time) (int64, error) {
+	days, year, err := dayCountFraction(dayCount, date)
+	if err != nil {
+		return 0, err
+	}
+
+	if balance <= 0 {
+		return 0, nil
+	}
+
+	// balance * rate / 10000 * micros * days / year, rounded half up,
+	// the product does not fit in int64 at large balances
+	micros := new(big.Int).SetInt64(balance)
+	micros.Mul(micros, big.NewInt(int64(rateBp)*(microsPerUnit/10000)*days))
+	micros.Add(micros, big.NewInt(year/2))
+	micros.Quo(micros, big.NewInt(year))
+
+	if !micros.IsInt64() {
+		return 0, fmt.Errorf("%w: balance %d at %d bp", ErrInterestOverflow, balance, rateBp)
+	}
+
+	return micros.Int64(), nil
+}
+
+// dayCountFraction returns the number of days accrued on the date and
+// the number of days in a year by the day count convention.
+func dayCountFraction(dayCount string, date time.Time) (days, year int64, err error) {
+	switch dayCount {
+	case DayCountActual365:
+		return 1, 365, nil
+	case DayCount30360:
+		// every month has 30 days, the 31st is not accrued
+		// and the last day of February accrues the rest of the month
+		day := date.Day()
+		if day == 31 {
+			return 0, 360, nil
+		}
+
+		if date.Month() == time.February && date.AddDate(0, 0, 1).Day() == 1 {
+			return int64(30 - day + 1), 360, nil
+		}
+
+		return 1, 360, nil
+	default:
+		return 0, 0, fmt.Errorf("%w: %s", ErrUnknownDayCount, dayCount)
+	}
+}
+
+// truncateDate returns the midnight of the date in UTC.
+func truncateDate(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
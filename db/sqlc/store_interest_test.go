@@ -0,0 +1,183 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findAccrual(accruals []db.InterestAccrual, accountID int64) (db.InterestAccrual, bool) {
+	for _, accrual := range accruals {
+		if accrual.AccountID == accountID {
+			return accrual, true
+		}
+	}
+
+	return db.InterestAccrual{}, false
+}
+
+func findCapitalization(capitalizations []db.InterestCapitalization, accountID int64) (db.InterestCapitalization, bool) {
+	for _, capitalization := range capitalizations {
+		if capitalization.AccountID == accountID {
+			return capitalization, true
+		}
+	}
+
+	return db.InterestCapitalization{}, false
+}
+
+func TestStore_InterestTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account := createRandomAccount(t)
+	interestAccount := createRandomAccountWithType(t, db.AccountTypeSystem, account.Currency)
+
+	// 1 % a day
+	_, err := testQueries.UpsertInterestRate(context.Background(), db.UpsertInterestRateParams{
+		AccountType:       account.AccountType,
+		Currency:          account.Currency,
+		RateBp:            36500,
+		DayCount:          db.DayCountActual365,
+		InterestAccountID: interestAccount.ID,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = testQueries.DeleteInterestRate(context.Background(), db.DeleteInterestRateParams{
+			AccountType: account.AccountType,
+			Currency:    account.Currency,
+		})
+	})
+
+	today := time.Now().UTC()
+
+	// accrue
+	accrued, err := s.AccrueInterestTx(context.Background(), today)
+	require.NoError(t, err)
+
+	accrual, ok := findAccrual(accrued.Accruals, account.ID)
+	require.True(t, ok)
+	assert.Equal(t, account.Balance, accrual.Balance)
+	assert.Equal(t, account.Balance*10000, accrual.AmountMicros)
+
+	// the date is accrued only once
+	accrued, err = s.AccrueInterestTx(context.Background(), today)
+	require.NoError(t, err)
+
+	_, ok = findAccrual(accrued.Accruals, account.ID)
+	assert.False(t, ok)
+
+	// capitalize
+	capitalized, err := s.CapitalizeInterestTx(context.Background(), today)
+	require.NoError(t, err)
+
+	capitalization, ok := findCapitalization(capitalized.Capitalizations, account.ID)
+	require.True(t, ok)
+	assert.Equal(t, (account.Balance+50)/100, capitalization.Amount)
+	assert.True(t, capitalization.JournalID.Valid)
+
+	updated, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, account.Balance+capitalization.Amount, updated.Balance)
+
+	// the period is capitalized only once
+	capitalized, err = s.CapitalizeInterestTx(context.Background(), today)
+	require.NoError(t, err)
+
+	_, ok = findCapitalization(capitalized.Capitalizations, account.ID)
+	assert.False(t, ok)
+}
+
+func TestStore_AccrueInterestTxUnknownDayCount(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account := createRandomAccountWithType(t, db.AccountTypeBusiness, util.RandomCurrency())
+	interestAccount := createRandomAccountWithType(t, db.AccountTypeSystem, account.Currency)
+
+	_, err := testQueries.UpsertInterestRate(context.Background(), db.UpsertInterestRateParams{
+		AccountType:       account.AccountType,
+		Currency:          account.Currency,
+		RateBp:            100,
+		DayCount:          "act/366",
+		InterestAccountID: interestAccount.ID,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = testQueries.DeleteInterestRate(context.Background(), db.DeleteInterestRateParams{
+			AccountType: account.AccountType,
+			Currency:    account.Currency,
+		})
+	})
+
+	// the account is skipped without failing the other accounts
+	accrued, err := s.AccrueInterestTx(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	_, ok := findAccrual(accrued.Accruals, account.ID)
+	assert.False(t, ok)
+	require.NotEmpty(t, accrued.Errors)
+
+	var unknown bool
+	for _, err := range accrued.Errors {
+		unknown = unknown || errors.Is(err, db.ErrUnknownDayCount)
+	}
+	assert.True(t, unknown)
+}
+
+func TestStore_AccrueInterestTxLargeBalance(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	account := createRandomAccountWithType(t, db.AccountTypeBusiness, currency)
+
+	_, err := testQueries.UpsertInterestRate(context.Background(), db.UpsertInterestRateParams{
+		AccountType:       db.AccountTypeBusiness,
+		Currency:          currency,
+		RateBp:            10000,
+		DayCount:          db.DayCountActual365,
+		InterestAccountID: createRandomAccountWithType(t, db.AccountTypeSystem, currency).ID,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = testQueries.DeleteInterestRate(context.Background(), db.DeleteInterestRateParams{
+			AccountType: db.AccountTypeBusiness,
+			Currency:    currency,
+		})
+	})
+
+	large, err := testQueries.UpdateAccountBalance(context.Background(), db.UpdateAccountBalanceParams{
+		ID:      account.ID,
+		Balance: 100000000000000,
+	})
+	require.NoError(t, err)
+
+	huge, err := testQueries.UpdateAccountBalance(context.Background(), db.UpdateAccountBalanceParams{
+		ID:      createRandomAccountWithType(t, db.AccountTypeBusiness, currency).ID,
+		Balance: math.MaxInt64 / 1000,
+	})
+	require.NoError(t, err)
+
+	accrued, err := s.AccrueInterestTx(context.Background(), time.Now())
+	require.NoError(t, err)
+
+	// the product of the balance and the rate exceeds int64, the interest does not
+	accrual, ok := findAccrual(accrued.Accruals, large.ID)
+	require.True(t, ok)
+	assert.Equal(t, int64(273972602739726027), accrual.AmountMicros)
+
+	// the interest itself does not fit, the account is reported
+	_, ok = findAccrual(accrued.Accruals, huge.ID)
+	assert.False(t, ok)
+
+	var overflow bool
+	for _, err := range accrued.Errors {
+		overflow = overflow || errors.Is(err, db.ErrInterestOverflow)
+	}
+	assert.True(t, overflow)
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrJournalTooShort is returned when a journal has less than two legs.
+	ErrJournalTooShort = errors.New("journal requires at least two legs")
+	// ErrZeroLeg is returned when a leg of a journal does not move any money.
+	ErrZeroLeg = errors.New("amount of a journal leg must not be zero")
+	// ErrUnbalancedJournal is returned when the legs of a journal do not sum to zero per currency.
+	ErrUnbalancedJournal = errors.New("journal legs do not sum to zero per currency")
+)
+
+// Leg is a single movement of money within a journal. A negative amount
+// debits the account, a positive amount credits it.
+type Leg struct {
+	AccountID int64
+	Amount    int64
+	// Description is copied onto the entry of the leg.
+	Description string
+}
+
+// JournalTxResult contains result of the journal transaction.
+type JournalTxResult struct {
+	Journal Journal
+	// Entries are in the order of the legs.
+	Entries []Entry
+	// Accounts are the updated accounts in the ascending order of their IDs.
+	Accounts []Account
+}
+
+// Account returns the updated account with the given ID.
+func (r JournalTxResult) Account(id int64) Account {
+	i := sort.Search(len(r.Accounts), func(i int) bool { return r.Accounts[i].ID >= id })
+	if i < len(r.Accounts) && r.Accounts[i].ID == id {
+		return r.Accounts[i]
+	}
+
+	return Account{}
+}
+
+// JournalTx records a balanced set of legs. It creates a new Journal record with
+// an entry for every leg and updates the balances of the affected accounts
+// within a single database transaction. The legs must sum to zero per currency
+// of the accounts and the debited accounts must follow the rules of their types.
+// The entries are categorized by the category rules of the account owners
+// and an entry.created event is published for each of them.
+func (s *store) JournalTx(ctx context.Context, legs []Leg) (JournalTxResult, error) {
+	var result JournalTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = journalTx(ctx, q, legs)
+
+		return err
+	})
+	if err != nil {
+		return JournalTxResult{}, fmt.Errorf("can not make a transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// journalTx executes the queries of the journal transaction using the given Queries,
+// so it can be a part of a larger database transaction.
+func journalTx(ctx context.Context, q *Queries, legs []Leg) (JournalTxResult, error) {
+	var result JournalTxResult
+
+	if len(legs) < 2 {
+		return result, ErrJournalTooShort
+	}
+
+	// net amount of every account
+	amounts := make(map[int64]int64, len(legs))
+	for _, leg := range legs {
+		if leg.Amount == 0 {
+			return result, ErrZeroLeg
+		}
+
+		amounts[leg.AccountID] += leg.Amount
+	}
+
+	ids := make([]int64, 0, len(amounts))
+	for id := range amounts {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// lock the accounts in the order of their IDs to avoid deadlocks
+	sums := make(map[string]int64)
+	accounts := make([]Account, len(ids))
+	for i, id := range ids {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return result, fmt.Errorf("failed to lock account %d: %w", id, err)
+		}
+
+		sums[account.Currency] += amounts[id]
+		accounts[i] = account
+	}
+
+	for currency, sum := range sums {
+		if sum != 0 {
+			return result, fmt.Errorf("%w: %s is off by %d", ErrUnbalancedJournal, currency, sum)
+		}
+	}
+
+	// debited accounts must follow the rules of their types
+	for _, account := range accounts {
+		if amount := amounts[account.ID]; amount < 0 {
+			if err := checkDebit(ctx, q, account, -amount); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	// journal
+	var err error
+	if result.Journal, err = q.CreateJournal(ctx); err != nil {
+		return result, fmt.Errorf("failed to create a new journal: %w", err)
+	}
+
+	// entries
+	result.Entries = make([]Entry, len(legs))
+	for i, leg := range legs {
+		if result.Entries[i], err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:   leg.AccountID,
+			Amount:      leg.Amount,
+			JournalID:   sql.NullInt64{Int64: result.Journal.ID, Valid: true},
+			Description: leg.Description,
+		}); err != nil {
+			return result, fmt.Errorf("failed to create an entry for account %d: %w", leg.AccountID, err)
+		}
+	}
+
+	// categories given by the rules of the account owners
+	categorized, err := q.CategorizeJournalEntries(ctx, sql.NullInt64{Int64: result.Journal.ID, Valid: true})
+	if err != nil {
+		return result, fmt.Errorf("failed to categorize the entries: %w", err)
+	}
+
+	for _, entry := range categorized {
+		for i := range result.Entries {
+			if result.Entries[i].ID == entry.ID {
+				result.Entries[i] = entry
+			}
+		}
+	}
+
+	for _, entry := range result.Entries {
+		if err := publishEvent(ctx, q, EventEntryCreated, entry, entry.AccountID); err != nil {
+			return result, err
+		}
+	}
+
+	// accounts
+	result.Accounts = make([]Account, len(ids))
+	for i, id := range ids {
+		if result.Accounts[i], err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			Amount: amounts[id],
+			ID:     id,
+		}); err != nil {
+			return result, fmt.Errorf("failed to update balance of account %d: %w", id, err)
+		}
+
+		if err := publishEvent(ctx, q, EventBalanceChanged, result.Accounts[i], id); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
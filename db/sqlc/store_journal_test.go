@@ -0,0 +1,78 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_JournalTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	account3 := createRandomAccountWithCurrency(t, account1.Currency)
+	amount := util.RandomAmount()
+
+	// split a debit into two credits
+	legs := []db.Leg{
+		{AccountID: account1.ID, Amount: -2 * amount},
+		{AccountID: account2.ID, Amount: amount},
+		{AccountID: account3.ID, Amount: amount},
+	}
+
+	result, err := s.JournalTx(context.Background(), legs)
+	require.NoError(t, err)
+	assert.NotZero(t, result.Journal.ID)
+
+	if assert.Len(t, result.Entries, len(legs)) {
+		for i, entry := range result.Entries {
+			assert.Equal(t, legs[i].AccountID, entry.AccountID)
+			assert.Equal(t, legs[i].Amount, entry.Amount)
+			assert.Equal(t, sql.NullInt64{Int64: result.Journal.ID, Valid: true}, entry.JournalID)
+		}
+	}
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), sql.NullInt64{Int64: result.Journal.ID, Valid: true})
+	require.NoError(t, err)
+	assert.Len(t, entries, len(legs))
+
+	assert.Equal(t, account1.Balance-2*amount, result.Account(account1.ID).Balance)
+	assert.Equal(t, account2.Balance+amount, result.Account(account2.ID).Balance)
+	assert.Equal(t, account3.Balance+amount, result.Account(account3.ID).Balance)
+}
+
+func TestStore_JournalTxInvalid(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+	amount := util.RandomAmount()
+
+	_, err := s.JournalTx(context.Background(), []db.Leg{
+		{AccountID: account1.ID, Amount: -amount},
+	})
+	assert.ErrorIs(t, err, db.ErrJournalTooShort)
+
+	_, err = s.JournalTx(context.Background(), []db.Leg{
+		{AccountID: account1.ID, Amount: 0},
+		{AccountID: account2.ID, Amount: 0},
+	})
+	assert.ErrorIs(t, err, db.ErrZeroLeg)
+
+	_, err = s.JournalTx(context.Background(), []db.Leg{
+		{AccountID: account1.ID, Amount: -amount},
+		{AccountID: account2.ID, Amount: amount + 1},
+	})
+	assert.ErrorIs(t, err, db.ErrUnbalancedJournal)
+
+	// the balances have not been touched
+	updated, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, account1.Balance, updated.Balance)
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Scopes of the login throttles.
+const (
+	LoginThrottleUsername = "username"
+	LoginThrottleIP       = "ip"
+)
+
+// ReserveLoginAttemptTxParams contains the input parameters of the login attempt transaction.
+// The failures older than ResetBefore are forgotten. After a failure the next attempt
+// is delayed by Delay, which doubles with each further failure up to MaxDelay.
+// No further attempts are reserved once the failures reach MaxFailures.
+type ReserveLoginAttemptTxParams struct {
+	Scope       string
+	Subject     string
+	ResetBefore time.Time
+	MaxFailures int32
+	LockedUntil time.Time
+	Delay       time.Duration
+	MaxDelay    time.Duration
+}
+
+// ReserveLoginAttemptTxResult is the result of the login attempt transaction.
+// RetryAt is zero if the attempt is reserved, otherwise the attempt is rejected
+// and can be retried then. Locked reports whether the attempt is rejected because
+// the subject is locked.
+type ReserveLoginAttemptTxResult struct {
+	Throttle LoginThrottle
+	RetryAt  time.Time
+	Locked   bool
+}
+
+// ReserveLoginAttemptTx checks whether the subject may attempt to log in and counts
+// the attempt as a failure until it succeeds within a single database transaction.
+// The throttle of the subject is locked meanwhile, so the parallel attempts are
+// checked one by one and each of them sees the failures reserved before.
+func (s *store) ReserveLoginAttemptTx(ctx context.Context, arg ReserveLoginAttemptTxParams) (ReserveLoginAttemptTxResult, error) {
+	var result ReserveLoginAttemptTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		// the throttle must exist to be locked by the first attempts
+		if err := q.CreateLoginThrottle(ctx, CreateLoginThrottleParams{
+			Scope:   arg.Scope,
+			Subject: arg.Subject,
+		}); err != nil {
+			return fmt.Errorf("failed to create the throttle: %w", err)
+		}
+
+		throttle, err := q.GetLoginThrottleForUpdate(ctx, GetLoginThrottleForUpdateParams{
+			Scope:   arg.Scope,
+			Subject: arg.Subject,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to lock the throttle: %w", err)
+		}
+
+		now := time.Now()
+
+		failures := throttle.Failures
+		if throttle.LastFailedAt.Before(arg.ResetBefore) {
+			failures = 0
+		}
+
+		switch {
+		case throttle.LockedUntil.Valid && now.Before(throttle.LockedUntil.Time):
+			result = ReserveLoginAttemptTxResult{Throttle: throttle, RetryAt: throttle.LockedUntil.Time, Locked: true}
+
+			return nil
+		case failures >= arg.MaxFailures:
+			// the attempts in progress have used up the failures, the last one locks the subject
+			result = ReserveLoginAttemptTxResult{Throttle: throttle, RetryAt: arg.LockedUntil, Locked: true}
+
+			return nil
+		}
+
+		if delay := loginDelay(arg.Delay, arg.MaxDelay, failures); delay > 0 {
+			if retryAt := throttle.LastFailedAt.Add(delay); now.Before(retryAt) {
+				result = ReserveLoginAttemptTxResult{Throttle: throttle, RetryAt: retryAt}
+
+				return nil
+			}
+		}
+
+		if result.Throttle, err = q.RecordLoginFailure(ctx, RecordLoginFailureParams{
+			Scope:       arg.Scope,
+			Subject:     arg.Subject,
+			ResetBefore: arg.ResetBefore,
+		}); err != nil {
+			return fmt.Errorf("failed to reserve the attempt: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return ReserveLoginAttemptTxResult{}, fmt.Errorf("can not reserve login attempt: %w", err)
+	}
+
+	return result, nil
+}
+
+// loginDelay returns the delay of the next attempt after the failures. It doubles
+// with each failure up to the maximum.
+func loginDelay(delay, maxDelay time.Duration, failures int32) time.Duration {
+	if failures == 0 || delay <= 0 {
+		return 0
+	}
+
+	for i := int32(1); i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
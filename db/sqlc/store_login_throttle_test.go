@@ -0,0 +1,180 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_ReserveLoginAttemptTx(t *testing.T) {
+	s := db.NewStore(testDB)
+	username := util.RandomOwner()
+	lockedUntil := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	arg := db.ReserveLoginAttemptTxParams{
+		Scope:       db.LoginThrottleUsername,
+		Subject:     username,
+		ResetBefore: time.Now().Add(-time.Hour),
+		MaxFailures: 3,
+		LockedUntil: lockedUntil,
+	}
+
+	for i := int32(1); i <= arg.MaxFailures; i++ {
+		result, err := s.ReserveLoginAttemptTx(context.Background(), arg)
+		require.NoError(t, err)
+		assert.Zero(t, result.RetryAt)
+		assert.Equal(t, i, result.Throttle.Failures)
+	}
+
+	// the failures are used up
+	result, err := s.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.True(t, result.Locked)
+	assert.True(t, lockedUntil.Equal(result.RetryAt))
+
+	// the last failure locks the username and starts the count over
+	throttle, err := testQueries.LockLoginThrottle(context.Background(), db.LockLoginThrottleParams{
+		Scope:       db.LoginThrottleUsername,
+		Subject:     username,
+		LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+		MaxFailures: arg.MaxFailures,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, throttle.Failures)
+	assert.True(t, lockedUntil.Equal(throttle.LockedUntil.Time))
+
+	result, err = s.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.True(t, result.Locked)
+	assert.True(t, lockedUntil.Equal(result.RetryAt))
+
+	throttles, err := testQueries.ListLoginThrottles(context.Background(), db.ListLoginThrottlesParams{
+		Username: username,
+		ClientIP: util.RandomString(8),
+	})
+	require.NoError(t, err)
+	require.Len(t, throttles, 1)
+	assert.Equal(t, throttle, throttles[0])
+
+	throttle, err = testQueries.UnlockLoginThrottle(context.Background(), db.UnlockLoginThrottleParams{
+		Scope:   db.LoginThrottleUsername,
+		Subject: username,
+	})
+	require.NoError(t, err)
+	assert.False(t, throttle.LockedUntil.Valid)
+
+	result, err = s.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Zero(t, result.RetryAt)
+}
+
+func TestStore_ReserveLoginAttemptTx_Delay(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	arg := db.ReserveLoginAttemptTxParams{
+		Scope:       db.LoginThrottleUsername,
+		Subject:     util.RandomOwner(),
+		ResetBefore: time.Now().Add(-time.Hour),
+		MaxFailures: 5,
+		LockedUntil: time.Now().Add(time.Hour),
+		Delay:       time.Minute,
+		MaxDelay:    time.Hour,
+	}
+
+	result, err := s.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Zero(t, result.RetryAt)
+
+	// the next attempt waits for the delay
+	result, err = s.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.False(t, result.Locked)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), result.RetryAt, 5*time.Second)
+
+	// a released attempt is not counted
+	err = testQueries.ReleaseLoginAttempt(context.Background(), db.ReleaseLoginAttemptParams{
+		Scope:   arg.Scope,
+		Subject: arg.Subject,
+	})
+	require.NoError(t, err)
+
+	result, err = s.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Zero(t, result.RetryAt)
+}
+
+func TestStore_ReserveLoginAttemptTx_Parallel(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	arg := db.ReserveLoginAttemptTxParams{
+		Scope:       db.LoginThrottleIP,
+		Subject:     util.RandomString(12),
+		ResetBefore: time.Now().Add(-time.Hour),
+		MaxFailures: 3,
+		LockedUntil: time.Now().Add(time.Hour),
+	}
+
+	n := 10
+	results := make(chan db.ReserveLoginAttemptTxResult)
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := s.ReserveLoginAttemptTx(context.Background(), arg)
+			if err != nil {
+				errs <- err
+
+				return
+			}
+
+			results <- result
+		}()
+	}
+
+	// only the allowed number of attempts passes at once
+	var reserved int
+
+	for i := 0; i < n; i++ {
+		select {
+		case err := <-errs:
+			require.NoError(t, err)
+		case result := <-results:
+			if result.RetryAt.IsZero() {
+				reserved++
+			} else {
+				assert.True(t, result.Locked)
+			}
+		}
+	}
+
+	assert.Equal(t, int(arg.MaxFailures), reserved)
+}
+
+func TestStore_ReserveLoginAttemptTx_Window(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	arg := db.ReserveLoginAttemptTxParams{
+		Scope:       db.LoginThrottleIP,
+		Subject:     util.RandomString(12),
+		ResetBefore: time.Now().Add(-time.Hour),
+		MaxFailures: 5,
+		LockedUntil: time.Now().Add(time.Hour),
+	}
+
+	result, err := s.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), result.Throttle.Failures)
+
+	// the failures before the window are forgotten
+	arg.ResetBefore = time.Now().Add(time.Minute)
+
+	result, err = s.ReserveLoginAttemptTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), result.Throttle.Failures)
+}
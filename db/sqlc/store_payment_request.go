@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Statuses of a PaymentRequest.
+const (
+	PaymentRequestStatusPending  = "pending"
+	PaymentRequestStatusPaid     = "paid"
+	PaymentRequestStatusDeclined = "declined"
+	PaymentRequestStatusExpired  = "expired"
+)
+
+var (
+	// ErrPaymentRequestDecided is returned when the payment request has already been paid or declined.
+	ErrPaymentRequestDecided = errors.New("payment request has already been decided")
+	// ErrPaymentRequestExpired is returned when the payment request is past its expiry.
+	ErrPaymentRequestExpired = errors.New("payment request has expired")
+	// ErrNotPayer is returned when the user is not the payer of the payment request.
+	ErrNotPayer = errors.New("user is not the payer of the payment request")
+)
+
+// PayPaymentRequestTxParams contains parameters of the pay transaction.
+type PayPaymentRequestTxParams struct {
+	PaymentRequestID int64
+	Payer            string
+	FromAccountID    int64
+	// PayeeCap caps the payment to an account added as a payee recently.
+	PayeeCap PayeeCap
+}
+
+// PayPaymentRequestTxResult contains result of the pay transaction.
+type PayPaymentRequestTxResult struct {
+	PaymentRequest PaymentRequest   `json:"payment_request"`
+	Transfer       TransferTxResult `json:"transfer"`
+}
+
+// PayPaymentRequestTx pays the payment request from the account of the payer the same
+// way TransferTx does. The status change and the transfer itself are committed within
+// a single database transaction, so a payment request is never paid twice.
+func (s *store) PayPaymentRequestTx(ctx context.Context, arg PayPaymentRequestTxParams) (PayPaymentRequestTxResult, error) {
+	var result PayPaymentRequestTxResult
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		request, err := lockPaymentRequest(ctx, q, arg.PaymentRequestID, arg.Payer)
+		if err != nil {
+			return err
+		}
+
+		if result.Transfer, err = transferTx(ctx, q, TransferTxParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   request.ToAccountID,
+			Amount:        request.Amount,
+			Reference:     fmt.Sprintf("payment request %d", request.ID),
+			Memo:          request.Memo,
+			PayeeCap:      arg.PayeeCap,
+		}); err != nil {
+			return err
+		}
+
+		if result.PaymentRequest, err = q.DecidePaymentRequest(ctx, DecidePaymentRequestParams{
+			ID:            request.ID,
+			Status:        PaymentRequestStatusPaid,
+			FromAccountID: sql.NullInt64{Int64: arg.FromAccountID, Valid: true},
+			TransferID:    sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to mark the payment request as paid: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return PayPaymentRequestTxResult{}, fmt.Errorf("can not pay a payment request: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeclinePaymentRequestTxParams contains parameters of the decline transaction.
+type DeclinePaymentRequestTxParams struct {
+	PaymentRequestID int64
+	Payer            string
+}
+
+// DeclinePaymentRequestTx declines the payment request.
+func (s *store) DeclinePaymentRequestTx(ctx context.Context, arg DeclinePaymentRequestTxParams) (PaymentRequest, error) {
+	var result PaymentRequest
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		request, err := lockPaymentRequest(ctx, q, arg.PaymentRequestID, arg.Payer)
+		if err != nil {
+			return err
+		}
+
+		if result, err = q.DecidePaymentRequest(ctx, DecidePaymentRequestParams{
+			ID:     request.ID,
+			Status: PaymentRequestStatusDeclined,
+		}); err != nil {
+			return fmt.Errorf("failed to decline the payment request: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return PaymentRequest{}, fmt.Errorf("can not decline a payment request: %w", err)
+	}
+
+	return result, nil
+}
+
+// lockPaymentRequest locks the payment request and checks whether it can be
+// paid or declined by the payer.
+func lockPaymentRequest(ctx context.Context, q *Queries, id int64, payer string) (PaymentRequest, error) {
+	request, err := q.GetPaymentRequestForUpdate(ctx, id)
+	if err != nil {
+		return PaymentRequest{}, fmt.Errorf("failed to get the payment request: %w", err)
+	}
+
+	switch {
+	case request.Payer != payer:
+		return PaymentRequest{}, ErrNotPayer
+	case request.Status != PaymentRequestStatusPending:
+		return PaymentRequest{}, ErrPaymentRequestDecided
+	case !time.Now().Before(request.ExpiresAt):
+		return PaymentRequest{}, ErrPaymentRequestExpired
+	}
+
+	return request, nil
+}
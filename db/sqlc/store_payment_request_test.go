@@ -0,0 +1,141 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPaymentRequest(t *testing.T, expiresAt time.Time) (db.PaymentRequest, db.Account) {
+	t.Helper()
+
+	to := createRandomAccount(t)
+	from := createRandomAccountWithCurrency(t, to.Currency)
+
+	request, err := testQueries.CreatePaymentRequest(context.Background(), db.CreatePaymentRequestParams{
+		Requester:   to.Owner,
+		Payer:       from.Owner,
+		ToAccountID: to.ID,
+		Amount:      10,
+		Memo:        "dinner",
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.PaymentRequestStatusPending, request.Status)
+	require.False(t, request.FromAccountID.Valid)
+
+	return request, from
+}
+
+func TestStore_PayPaymentRequestTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	request, from := createRandomPaymentRequest(t, time.Now().Add(time.Hour))
+
+	// only the payer can pay the request
+	_, err := s.PayPaymentRequestTx(context.Background(), db.PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            request.Requester,
+		FromAccountID:    from.ID,
+	})
+	assert.ErrorIs(t, err, db.ErrNotPayer)
+
+	// concurrent payments transfer the money only once
+	n := 5
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := s.PayPaymentRequestTx(context.Background(), db.PayPaymentRequestTxParams{
+				PaymentRequestID: request.ID,
+				Payer:            request.Payer,
+				FromAccountID:    from.ID,
+			})
+
+			errs <- err
+		}()
+	}
+
+	var succeeded int
+	for i := 0; i < n; i++ {
+		if err := <-errs; err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, db.ErrPaymentRequestDecided)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+
+	paid, err := testQueries.GetPaymentRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.PaymentRequestStatusPaid, paid.Status)
+	assert.Equal(t, from.ID, paid.FromAccountID.Int64)
+	assert.True(t, paid.DecidedAt.Valid)
+	require.True(t, paid.TransferID.Valid)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), paid.TransferID.Int64)
+	require.NoError(t, err)
+	assert.Equal(t, from.ID, transfer.FromAccountID)
+	assert.Equal(t, request.ToAccountID, transfer.ToAccountID)
+	assert.Equal(t, request.Amount, transfer.Amount)
+	assert.Equal(t, request.Memo, transfer.Memo)
+
+	account, err := testQueries.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	// a fee may be charged on top of the amount
+	assert.LessOrEqual(t, account.Balance, from.Balance-request.Amount)
+}
+
+func TestStore_DeclinePaymentRequestTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	request, from := createRandomPaymentRequest(t, time.Now().Add(time.Hour))
+
+	declined, err := s.DeclinePaymentRequestTx(context.Background(), db.DeclinePaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            request.Payer,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.PaymentRequestStatusDeclined, declined.Status)
+	assert.False(t, declined.TransferID.Valid)
+
+	// a declined request can not be paid
+	_, err = s.PayPaymentRequestTx(context.Background(), db.PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            request.Payer,
+		FromAccountID:    from.ID,
+	})
+	assert.ErrorIs(t, err, db.ErrPaymentRequestDecided)
+}
+
+func TestQueries_ExpirePaymentRequests(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	request, from := createRandomPaymentRequest(t, time.Now().Add(-time.Minute))
+
+	_, err := s.PayPaymentRequestTx(context.Background(), db.PayPaymentRequestTxParams{
+		PaymentRequestID: request.ID,
+		Payer:            request.Payer,
+		FromAccountID:    from.ID,
+	})
+	assert.ErrorIs(t, err, db.ErrPaymentRequestExpired)
+
+	n, err := testQueries.ExpirePaymentRequests(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, int64(1))
+
+	expired, err := testQueries.GetPaymentRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.PaymentRequestStatusExpired, expired.Status)
+
+	requests, err := testQueries.ListIncomingPaymentRequests(context.Background(), db.ListIncomingPaymentRequestsParams{
+		Payer: request.Payer,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	assert.Len(t, requests, 1)
+}
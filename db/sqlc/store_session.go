@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrSessionRevoked is returned when the refresh token belongs to a revoked session.
+	ErrSessionRevoked = errors.New("session has been revoked")
+	// ErrSessionExpired is returned when the refresh token has expired.
+	ErrSessionExpired = errors.New("session has expired")
+	// ErrRefreshTokenReused is returned when a refresh token is used again after it has
+	// been rotated. The whole session family is revoked, as the token has likely leaked.
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+)
+
+// RotateSessionTxParams contains parameters of the session rotation transaction.
+type RotateSessionTxParams struct {
+	RefreshTokenHash    string
+	NewRefreshTokenHash string
+	UserAgent           string
+	ClientIP            string
+	ExpiresAt           time.Time
+}
+
+// RotateSessionTxResult contains result of the session rotation transaction.
+type RotateSessionTxResult struct {
+	Session Session
+	User    User
+}
+
+// RotateSessionTx exchanges the refresh token for a new one within a single database
+// transaction. The session of the old token is marked as rotated and a new session
+// of the same family is created. If the old token has already been rotated,
+// the whole family is revoked and ErrRefreshTokenReused is returned.
+func (s *store) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error) {
+	var result RotateSessionTxResult
+
+	// the revocation of a reused family must be committed
+	var reused bool
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		session, err := q.GetSessionByRefreshTokenForUpdate(ctx, arg.RefreshTokenHash)
+		if err != nil {
+			return fmt.Errorf("failed to get the session: %w", err)
+		}
+
+		if session.Revoked {
+			return ErrSessionRevoked
+		}
+
+		if session.RotatedAt.Valid {
+			if _, err := q.RevokeSessionFamily(ctx, session.FamilyID); err != nil {
+				return fmt.Errorf("failed to revoke the session family: %w", err)
+			}
+
+			reused = true
+
+			return nil
+		}
+
+		if time.Now().After(session.ExpiresAt) {
+			return ErrSessionExpired
+		}
+
+		if err := q.MarkSessionRotated(ctx, session.ID); err != nil {
+			return fmt.Errorf("failed to rotate the session: %w", err)
+		}
+
+		if result.Session, err = q.CreateSession(ctx, CreateSessionParams{
+			FamilyID:         session.FamilyID,
+			Username:         session.Username,
+			RefreshTokenHash: arg.NewRefreshTokenHash,
+			UserAgent:        arg.UserAgent,
+			ClientIP:         arg.ClientIP,
+			ExpiresAt:        arg.ExpiresAt,
+		}); err != nil {
+			return fmt.Errorf("failed to create a new session: %w", err)
+		}
+
+		if result.User, err = q.GetUser(ctx, session.Username); err != nil {
+			return fmt.Errorf("failed to get the user: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return RotateSessionTxResult{}, fmt.Errorf("can not rotate session: %w", err)
+	}
+
+	if reused {
+		return RotateSessionTxResult{}, ErrRefreshTokenReused
+	}
+
+	return result, nil
+}
+
+// ChangePasswordTxParams contains parameters of the password change transaction.
+type ChangePasswordTxParams struct {
+	Username       string
+	HashedPassword string
+	// KeepFamilyID is the session family which stays signed in, usually the one
+	// changing the password. Empty KeepFamilyID revokes all sessions.
+	KeepFamilyID string
+}
+
+// ChangePasswordTx sets the new password of the user and revokes the other sessions
+// of the user within a single database transaction, so the refresh tokens issued
+// before the change can not be used anymore.
+func (s *store) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+		if user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:       arg.Username,
+			HashedPassword: arg.HashedPassword,
+		}); err != nil {
+			return fmt.Errorf("failed to set the password: %w", err)
+		}
+
+		if _, err := q.RevokeUserSessions(ctx, RevokeUserSessionsParams{
+			Username:     arg.Username,
+			KeepFamilyID: arg.KeepFamilyID,
+		}); err != nil {
+			return fmt.Errorf("failed to revoke the sessions: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return User{}, fmt.Errorf("can not change password: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,153 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, expiresAt time.Time) db.Session {
+	user := createRandomUser(t)
+
+	session, err := testQueries.CreateSession(context.Background(), db.CreateSessionParams{
+		FamilyID:         util.RandomString(32),
+		Username:         user.Username,
+		RefreshTokenHash: util.RandomString(64),
+		UserAgent:        "curl/7.68.0",
+		ClientIP:         "192.0.2.1",
+		ExpiresAt:        expiresAt,
+	})
+	require.NoError(t, err)
+	assert.False(t, session.Revoked)
+	assert.False(t, session.RotatedAt.Valid)
+
+	return session
+}
+
+func rotateSessionParams(session db.Session) db.RotateSessionTxParams {
+	return db.RotateSessionTxParams{
+		RefreshTokenHash:    session.RefreshTokenHash,
+		NewRefreshTokenHash: util.RandomString(64),
+		UserAgent:           "Mozilla/5.0",
+		ClientIP:            "198.51.100.7",
+		ExpiresAt:           time.Now().Add(time.Hour),
+	}
+}
+
+func TestStore_RotateSessionTx(t *testing.T) {
+	s := db.NewStore(testDB)
+	session := createRandomSession(t, time.Now().Add(time.Hour))
+
+	arg := rotateSessionParams(session)
+	result, err := s.RotateSessionTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	assert.Equal(t, session.Username, result.User.Username)
+	assert.Equal(t, session.FamilyID, result.Session.FamilyID)
+	assert.Equal(t, arg.NewRefreshTokenHash, result.Session.RefreshTokenHash)
+	assert.Equal(t, arg.UserAgent, result.Session.UserAgent)
+	assert.Equal(t, arg.ClientIP, result.Session.ClientIP)
+
+	old, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	assert.True(t, old.RotatedAt.Valid)
+	assert.False(t, old.Revoked)
+
+	// only the new session is active
+	sessions, err := testQueries.ListActiveSessions(context.Background(), session.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, result.Session.ID, sessions[0].ID)
+}
+
+func TestStore_RotateSessionTx_Reused(t *testing.T) {
+	s := db.NewStore(testDB)
+	session := createRandomSession(t, time.Now().Add(time.Hour))
+
+	result, err := s.RotateSessionTx(context.Background(), rotateSessionParams(session))
+	require.NoError(t, err)
+
+	// the rotated token is presented again
+	_, err = s.RotateSessionTx(context.Background(), rotateSessionParams(session))
+	require.ErrorIs(t, err, db.ErrRefreshTokenReused)
+
+	// the whole family is revoked, including the latest session
+	latest, err := testQueries.GetSession(context.Background(), result.Session.ID)
+	require.NoError(t, err)
+	assert.True(t, latest.Revoked)
+
+	_, err = s.RotateSessionTx(context.Background(), rotateSessionParams(latest))
+	require.ErrorIs(t, err, db.ErrSessionRevoked)
+}
+
+func TestStore_RotateSessionTx_Expired(t *testing.T) {
+	s := db.NewStore(testDB)
+	session := createRandomSession(t, time.Now().Add(-time.Minute))
+
+	_, err := s.RotateSessionTx(context.Background(), rotateSessionParams(session))
+	require.ErrorIs(t, err, db.ErrSessionExpired)
+
+	// the expired session is not rotated
+	old, err := testQueries.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	assert.False(t, old.RotatedAt.Valid)
+
+	n, err := testQueries.DeleteExpiredSessions(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, int64(1))
+
+	_, err = testQueries.GetSession(context.Background(), session.ID)
+	require.Error(t, err)
+}
+
+func TestStore_RotateSessionTx_Revoked(t *testing.T) {
+	s := db.NewStore(testDB)
+	session := createRandomSession(t, time.Now().Add(time.Hour))
+
+	n, err := testQueries.RevokeSessionFamily(context.Background(), session.FamilyID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, err = s.RotateSessionTx(context.Background(), rotateSessionParams(session))
+	require.ErrorIs(t, err, db.ErrSessionRevoked)
+}
+
+func TestStore_ChangePasswordTx(t *testing.T) {
+	s := db.NewStore(testDB)
+	current := createRandomSession(t, time.Now().Add(time.Hour))
+
+	other, err := testQueries.CreateSession(context.Background(), db.CreateSessionParams{
+		FamilyID:         util.RandomString(32),
+		Username:         current.Username,
+		RefreshTokenHash: util.RandomString(64),
+		ExpiresAt:        time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	hashedPassword := util.RandomString(32)
+	user, err := s.ChangePasswordTx(context.Background(), db.ChangePasswordTxParams{
+		Username:       current.Username,
+		HashedPassword: hashedPassword,
+		KeepFamilyID:   current.FamilyID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, hashedPassword, user.HashedPassword)
+
+	// only the session changing the password stays signed in
+	sessions, err := testQueries.ListActiveSessions(context.Background(), current.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, current.ID, sessions[0].ID)
+
+	other, err = testQueries.GetSession(context.Background(), other.ID)
+	require.NoError(t, err)
+	assert.True(t, other.Revoked)
+
+	_, err = s.RotateSessionTx(context.Background(), rotateSessionParams(other))
+	assert.ErrorIs(t, err, db.ErrSessionRevoked)
+}
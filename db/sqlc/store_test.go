@@ -15,7 +15,7 @@ func TestStore_TransferTx(t *testing.T) {
 	s := db.NewStore(testDB)
 
 	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
 
 	// test concurrent transfer transactions
 	n := 10
@@ -23,6 +23,8 @@ func TestStore_TransferTx(t *testing.T) {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        util.RandomAmount(),
+		Reference:     util.RandomOwner(),
+		Memo:          "monthly rent",
 	}
 
 	results := make(chan db.TransferTxResult)
@@ -51,6 +53,9 @@ func TestStore_TransferTx(t *testing.T) {
 			assert.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 			assert.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 			assert.Equal(t, arg.Amount, transfer.Amount)
+			assert.Equal(t, arg.Reference, transfer.Reference)
+			assert.Equal(t, arg.Memo, transfer.Memo)
+			assert.JSONEq(t, "{}", string(transfer.RemittanceInfo))
 
 			assert.NotZero(t, transfer.ID)
 			assert.NotZero(t, transfer.CreatedAt)
@@ -64,6 +69,7 @@ func TestStore_TransferTx(t *testing.T) {
 		if assert.NotEmpty(t, entryFrom) {
 			assert.Equal(t, arg.FromAccountID, entryFrom.AccountID)
 			assert.Equal(t, arg.Amount, -entryFrom.Amount)
+			assert.Equal(t, arg.Description(), entryFrom.Description)
 
 			assert.NotZero(t, entryFrom.ID)
 			assert.NotZero(t, entryFrom.CreatedAt)
@@ -77,6 +83,7 @@ func TestStore_TransferTx(t *testing.T) {
 		if assert.NotEmpty(t, entryTo) {
 			assert.Equal(t, arg.ToAccountID, entryTo.AccountID)
 			assert.Equal(t, arg.Amount, entryTo.Amount)
+			assert.Equal(t, arg.Description(), entryTo.Description)
 
 			assert.NotZero(t, entryTo.ID)
 			assert.NotZero(t, entryTo.CreatedAt)
@@ -121,11 +128,31 @@ func TestStore_TransferTx(t *testing.T) {
 	assert.Equal(t, account2.Balance+arg.Amount*int64(n), updatedToAccount.Balance)
 }
 
+func TestStore_TransferTxNonPositiveAmount(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
+
+	for _, amount := range []int64{0, -util.RandomAmount()} {
+		_, err := s.TransferTx(context.Background(), db.TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		assert.ErrorIs(t, err, db.ErrNonPositiveAmount)
+	}
+
+	updated, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, account2.Balance, updated.Balance)
+}
+
 func TestStore_TransferTxDeadLock(t *testing.T) {
 	s := db.NewStore(testDB)
 
 	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account2 := createRandomAccountWithCurrency(t, account1.Currency)
 
 	// test concurrent transfer transactions
 	n := 10
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTOTPCodeReused is returned when a TOTP code of an already used time step is presented again.
+var ErrTOTPCodeReused = errors.New("code has already been used")
+
+// TOTPTxParams contains parameters of the two-factor authentication transactions.
+// Step is the time step of the verified TOTP code authorizing the change.
+type TOTPTxParams struct {
+	Username           string
+	Step               int64
+	RecoveryCodeHashes []string
+}
+
+// acceptTOTPStep records the time step of the accepted code, so it can not be replayed.
+func acceptTOTPStep(ctx context.Context, q *Queries, username string, step int64) error {
+	n, err := q.UseTOTPStep(ctx, UseTOTPStepParams{
+		Username:     username,
+		LastUsedStep: step,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to use the code: %w", err)
+	}
+
+	if n == 0 {
+		return ErrTOTPCodeReused
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes replaces all recovery codes of the user.
+func replaceRecoveryCodes(ctx context.Context, q *Queries, username string, hashes []string) ([]RecoveryCode, error) {
+	if _, err := q.DeleteRecoveryCodes(ctx, username); err != nil {
+		return nil, fmt.Errorf("failed to delete the recovery codes: %w", err)
+	}
+
+	codes := make([]RecoveryCode, len(hashes))
+	for i, hash := range hashes {
+		var err error
+		if codes[i], err = q.CreateRecoveryCode(ctx, CreateRecoveryCodeParams{
+			Username: username,
+			CodeHash: hash,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to create a recovery code: %w", err)
+		}
+	}
+
+	return codes, nil
+}
+
+// EnableTOTPTx confirms the enrolled TOTP credential of the user with the step of its first
+// code and issues new recovery codes within a single database transaction.
+func (s *store) EnableTOTPTx(ctx context.Context, arg TOTPTxParams) (TotpCredential, error) {
+	var credential TotpCredential
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		if err := acceptTOTPStep(ctx, q, arg.Username, arg.Step); err != nil {
+			return err
+		}
+
+		var err error
+		if credential, err = q.ConfirmTOTPCredential(ctx, arg.Username); err != nil {
+			return fmt.Errorf("failed to confirm the credential: %w", err)
+		}
+
+		_, err = replaceRecoveryCodes(ctx, q, arg.Username, arg.RecoveryCodeHashes)
+
+		return err
+	})
+	if err != nil {
+		return TotpCredential{}, fmt.Errorf("can not enable TOTP: %w", err)
+	}
+
+	return credential, nil
+}
+
+// RegenerateRecoveryCodesTx replaces the recovery codes of the user within
+// a single database transaction.
+func (s *store) RegenerateRecoveryCodesTx(ctx context.Context, arg TOTPTxParams) ([]RecoveryCode, error) {
+	var codes []RecoveryCode
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		if err := acceptTOTPStep(ctx, q, arg.Username, arg.Step); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(ctx, q, arg.Username, arg.RecoveryCodeHashes)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not regenerate recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// DisableTOTPTx deletes the TOTP credential and the recovery codes of the user
+// within a single database transaction.
+func (s *store) DisableTOTPTx(ctx context.Context, arg TOTPTxParams) error {
+	err := s.execTx(ctx, func(q *Queries) error {
+		if err := acceptTOTPStep(ctx, q, arg.Username, arg.Step); err != nil {
+			return err
+		}
+
+		if _, err := q.DeleteTOTPCredential(ctx, arg.Username); err != nil {
+			return fmt.Errorf("failed to delete the credential: %w", err)
+		}
+
+		if _, err := q.DeleteRecoveryCodes(ctx, arg.Username); err != nil {
+			return fmt.Errorf("failed to delete the recovery codes: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can not disable TOTP: %w", err)
+	}
+
+	return nil
+}
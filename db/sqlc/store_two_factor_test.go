@@ -0,0 +1,98 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomRecoveryCodeHashes(n int) []string {
+	hashes := make([]string, n)
+	for i := range hashes {
+		hashes[i] = util.RandomString(64)
+	}
+
+	return hashes
+}
+
+func TestStore_TOTP(t *testing.T) {
+	s := db.NewStore(testDB)
+	user := createRandomUser(t)
+
+	credential, err := testQueries.UpsertTOTPCredential(context.Background(), db.UpsertTOTPCredentialParams{
+		Username: user.Username,
+		Secret:   util.RandomString(32),
+	})
+	require.NoError(t, err)
+	assert.False(t, credential.ConfirmedAt.Valid)
+
+	hashes := randomRecoveryCodeHashes(3)
+
+	credential, err = s.EnableTOTPTx(context.Background(), db.TOTPTxParams{
+		Username:           user.Username,
+		Step:               100,
+		RecoveryCodeHashes: hashes,
+	})
+	require.NoError(t, err)
+	assert.True(t, credential.ConfirmedAt.Valid)
+	assert.Equal(t, int64(100), credential.LastUsedStep)
+
+	// an enabled credential can not be enrolled again
+	_, err = testQueries.UpsertTOTPCredential(context.Background(), db.UpsertTOTPCredentialParams{
+		Username: user.Username,
+		Secret:   util.RandomString(32),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the codes of the used step are rejected
+	_, err = s.RegenerateRecoveryCodesTx(context.Background(), db.TOTPTxParams{
+		Username:           user.Username,
+		Step:               100,
+		RecoveryCodeHashes: randomRecoveryCodeHashes(3),
+	})
+	require.ErrorIs(t, err, db.ErrTOTPCodeReused)
+
+	n, err := testQueries.UseRecoveryCode(context.Background(), db.UseRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: hashes[0],
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	left, err := testQueries.CountUnusedRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), left)
+
+	codes, err := s.RegenerateRecoveryCodesTx(context.Background(), db.TOTPTxParams{
+		Username:           user.Username,
+		Step:               101,
+		RecoveryCodeHashes: randomRecoveryCodeHashes(4),
+	})
+	require.NoError(t, err)
+	assert.Len(t, codes, 4)
+
+	// the old codes are replaced
+	n, err = testQueries.UseRecoveryCode(context.Background(), db.UseRecoveryCodeParams{
+		Username: user.Username,
+		CodeHash: hashes[1],
+	})
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	require.NoError(t, s.DisableTOTPTx(context.Background(), db.TOTPTxParams{
+		Username: user.Username,
+		Step:     102,
+	}))
+
+	_, err = testQueries.GetTOTPCredential(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	left, err = testQueries.CountUnusedRecoveryCodes(context.Background(), user.Username)
+	require.NoError(t, err)
+	assert.Zero(t, left)
+}
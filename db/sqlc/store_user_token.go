@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Purposes of the user tokens.
+const (
+	UserTokenVerifyEmail   = "verify_email"
+	UserTokenResetPassword = "reset_password"
+	// UserTokenLoginChallenge is issued by the first login step of the users
+	// with two-factor authentication, it is exchanged for a session by the second step.
+	UserTokenLoginChallenge = "login_challenge"
+)
+
+var (
+	// ErrUserTokenUsed is returned when the token has already been used.
+	ErrUserTokenUsed = errors.New("token has already been used")
+	// ErrUserTokenExpired is returned when the token has expired.
+	ErrUserTokenExpired = errors.New("token has expired")
+)
+
+// useUserToken locks the token of the purpose and marks it as used. A token
+// of another purpose is reported as missing.
+func useUserToken(ctx context.Context, q *Queries, tokenHash, purpose string) (UserToken, error) {
+	userToken, err := q.GetUserTokenForUpdate(ctx, tokenHash)
+	if err != nil {
+		return UserToken{}, fmt.Errorf("failed to get the token: %w", err)
+	}
+
+	if userToken.Purpose != purpose {
+		return UserToken{}, fmt.Errorf("failed to get the token: %w", sql.ErrNoRows)
+	}
+
+	if userToken.UsedAt.Valid {
+		return UserToken{}, ErrUserTokenUsed
+	}
+
+	if time.Now().After(userToken.ExpiresAt) {
+		return UserToken{}, ErrUserTokenExpired
+	}
+
+	if err := q.MarkUserTokenUsed(ctx, userToken.ID); err != nil {
+		return UserToken{}, fmt.Errorf("failed to use the token: %w", err)
+	}
+
+	return userToken, nil
+}
+
+// UseUserTokenTx uses the token of the purpose within a single database transaction.
+func (s *store) UseUserTokenTx(ctx context.Context, tokenHash, purpose string) (UserToken, error) {
+	var userToken UserToken
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+		userToken, err = useUserToken(ctx, q, tokenHash, purpose)
+
+		return err
+	})
+	if err != nil {
+		return UserToken{}, fmt.Errorf("can not use token: %w", err)
+	}
+
+	return userToken, nil
+}
+
+// VerifyEmailTx uses the email verification token and marks the email
+// address of its user as verified within a single database transaction.
+func (s *store) VerifyEmailTx(ctx context.Context, tokenHash string) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		userToken, err := useUserToken(ctx, q, tokenHash, UserTokenVerifyEmail)
+		if err != nil {
+			return err
+		}
+
+		if user, err = q.SetUserEmailVerified(ctx, userToken.Username); err != nil {
+			return fmt.Errorf("failed to verify the email: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return User{}, fmt.Errorf("can not verify email: %w", err)
+	}
+
+	return user, nil
+}
+
+// ResetPasswordTxParams contains parameters of the password reset transaction.
+type ResetPasswordTxParams struct {
+	TokenHash      string
+	HashedPassword string
+}
+
+// ResetPasswordTx uses the password reset token and sets the new password of its user
+// within a single database transaction. All sessions of the user are revoked.
+// The reset token proves the ownership of the email address, so the address
+// is marked as verified as well.
+func (s *store) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		userToken, err := useUserToken(ctx, q, arg.TokenHash, UserTokenResetPassword)
+		if err != nil {
+			return err
+		}
+
+		if _, err := q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username:       userToken.Username,
+			HashedPassword: arg.HashedPassword,
+		}); err != nil {
+			return fmt.Errorf("failed to set the password: %w", err)
+		}
+
+		if user, err = q.SetUserEmailVerified(ctx, userToken.Username); err != nil {
+			return fmt.Errorf("failed to verify the email: %w", err)
+		}
+
+		if _, err := q.RevokeUserSessions(ctx, RevokeUserSessionsParams{Username: userToken.Username}); err != nil {
+			return fmt.Errorf("failed to revoke the sessions: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return User{}, fmt.Errorf("can not reset password: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,97 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUserToken(t *testing.T, user db.User, purpose string, expiresAt time.Time) db.UserToken {
+	userToken, err := testQueries.CreateUserToken(context.Background(), db.CreateUserTokenParams{
+		Username:  user.Username,
+		Purpose:   purpose,
+		TokenHash: util.RandomString(64),
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	assert.False(t, userToken.UsedAt.Valid)
+
+	return userToken
+}
+
+func TestStore_VerifyEmailTx(t *testing.T) {
+	s := db.NewStore(testDB)
+	user := createRandomUser(t)
+	assert.False(t, user.EmailVerifiedAt.Valid)
+
+	userToken := createRandomUserToken(t, user, db.UserTokenVerifyEmail, time.Now().Add(time.Hour))
+
+	verified, err := s.VerifyEmailTx(context.Background(), userToken.TokenHash)
+	require.NoError(t, err)
+	assert.Equal(t, user.Username, verified.Username)
+	assert.True(t, verified.EmailVerifiedAt.Valid)
+
+	// the token is single-use
+	_, err = s.VerifyEmailTx(context.Background(), userToken.TokenHash)
+	require.ErrorIs(t, err, db.ErrUserTokenUsed)
+}
+
+func TestStore_VerifyEmailTx_Invalid(t *testing.T) {
+	s := db.NewStore(testDB)
+	user := createRandomUser(t)
+
+	expired := createRandomUserToken(t, user, db.UserTokenVerifyEmail, time.Now().Add(-time.Minute))
+	_, err := s.VerifyEmailTx(context.Background(), expired.TokenHash)
+	require.ErrorIs(t, err, db.ErrUserTokenExpired)
+
+	// a password reset token can not verify the email
+	reset := createRandomUserToken(t, user, db.UserTokenResetPassword, time.Now().Add(time.Hour))
+	_, err = s.VerifyEmailTx(context.Background(), reset.TokenHash)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = s.VerifyEmailTx(context.Background(), util.RandomString(64))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := testQueries.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	assert.False(t, got.EmailVerifiedAt.Valid)
+}
+
+func TestStore_ResetPasswordTx(t *testing.T) {
+	s := db.NewStore(testDB)
+	session := createRandomSession(t, time.Now().Add(time.Hour))
+
+	user, err := testQueries.GetUser(context.Background(), session.Username)
+	require.NoError(t, err)
+
+	userToken := createRandomUserToken(t, user, db.UserTokenResetPassword, time.Now().Add(time.Hour))
+
+	arg := db.ResetPasswordTxParams{
+		TokenHash:      userToken.TokenHash,
+		HashedPassword: util.RandomString(32),
+	}
+
+	reset, err := s.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Equal(t, arg.HashedPassword, reset.HashedPassword)
+	assert.True(t, reset.PasswordModifiedAt.After(user.PasswordModifiedAt))
+	assert.True(t, reset.EmailVerifiedAt.Valid)
+
+	// the sessions of the user are revoked
+	sessions, err := testQueries.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	_, err = s.ResetPasswordTx(context.Background(), arg)
+	require.ErrorIs(t, err, db.ErrUserTokenUsed)
+
+	n, err := testQueries.DeleteExpiredUserTokens(context.Background(), time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, n, int64(1))
+}
@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Statuses of a WebhookDelivery.
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusDead      = "dead"
+)
+
+// RecordWebhookAttemptTxParams contains parameters of the record webhook attempt transaction.
+type RecordWebhookAttemptTxParams struct {
+	DeliveryID int64
+	// StatusCode is the HTTP status of the response, 0 if none was received.
+	StatusCode int32
+	Error      string
+	Duration   time.Duration
+	// Status is the new status of the delivery and NextAttemptAt
+	// the time of its next attempt if it is still pending.
+	Status        string
+	NextAttemptAt time.Time
+}
+
+// RecordWebhookAttemptTx logs an attempt to deliver a webhook and updates
+// the status of the delivery within a single database transaction.
+func (s *store) RecordWebhookAttemptTx(ctx context.Context, arg RecordWebhookAttemptTxParams) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+
+	err := s.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if _, err = q.CreateWebhookAttempt(ctx, CreateWebhookAttemptParams{
+			DeliveryID: arg.DeliveryID,
+			StatusCode: arg.StatusCode,
+			Error:      arg.Error,
+			DurationMs: arg.Duration.Milliseconds(),
+		}); err != nil {
+			return fmt.Errorf("failed to log the attempt: %w", err)
+		}
+
+		if delivery, err = q.UpdateWebhookDelivery(ctx, UpdateWebhookDeliveryParams{
+			ID:            arg.DeliveryID,
+			Status:        arg.Status,
+			NextAttemptAt: arg.NextAttemptAt,
+		}); err != nil {
+			return fmt.Errorf("failed to update the delivery: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return WebhookDelivery{}, fmt.Errorf("can not record webhook attempt: %w", err)
+	}
+
+	return delivery, nil
+}
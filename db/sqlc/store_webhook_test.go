@@ -0,0 +1,171 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhookEndpoint(t *testing.T, owner string, events ...string) db.WebhookEndpoint {
+	t.Helper()
+
+	arg := db.CreateWebhookEndpointParams{
+		Owner:  owner,
+		URL:    "https://example.com/hooks",
+		Events: events,
+		Secret: util.RandomString(32),
+	}
+
+	endpoint, err := testQueries.CreateWebhookEndpoint(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Events, endpoint.Events)
+	require.Equal(t, arg.Secret, endpoint.Secret)
+
+	return endpoint
+}
+
+func TestStore_TransferTx_Webhooks(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+
+	transfers := createRandomWebhookEndpoint(t, to.Owner, db.EventTransferCreated)
+	entries := createRandomWebhookEndpoint(t, from.Owner, db.EventEntryCreated)
+	closed := createRandomWebhookEndpoint(t, from.Owner, db.EventAccountClosed)
+
+	result, err := s.TransferTx(context.Background(), db.TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), db.ListWebhookDeliveriesParams{
+		EndpointID: transfers.ID,
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, db.EventTransferCreated, deliveries[0].EventType)
+	assert.Equal(t, db.WebhookDeliveryStatusPending, deliveries[0].Status)
+
+	var event struct {
+		db.Event
+		Data db.Transfer `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(deliveries[0].Payload, &event))
+	assert.Equal(t, deliveries[0].EventID, event.ID)
+	assert.Equal(t, result.Transfer.ID, event.Data.ID)
+
+	// the sender is notified about its own entries only
+	deliveries, err = testQueries.ListWebhookDeliveries(context.Background(), db.ListWebhookDeliveriesParams{
+		EndpointID: entries.ID,
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, deliveries)
+
+	for _, delivery := range deliveries {
+		var event struct {
+			Data db.Entry `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(delivery.Payload, &event))
+		assert.Equal(t, from.ID, event.Data.AccountID)
+	}
+
+	deliveries, err = testQueries.ListWebhookDeliveries(context.Background(), db.ListWebhookDeliveriesParams{
+		EndpointID: closed.ID,
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestStore_RecordWebhookAttemptTx(t *testing.T) {
+	s := db.NewStore(testDB)
+
+	account := createRandomAccount(t)
+	endpoint := createRandomWebhookEndpoint(t, account.Owner, db.EventAccountClosed)
+
+	_, err := s.CloseAccountTx(context.Background(), account.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetAccount(context.Background(), account.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), db.ListWebhookDeliveriesParams{
+		EndpointID: endpoint.ID,
+		Limit:      10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+	assert.Equal(t, db.EventAccountClosed, delivery.EventType)
+
+	// a claimed delivery is not claimed again until its lease expires
+	claimed, err := testQueries.ClaimWebhookDeliveries(context.Background(), db.ClaimWebhookDeliveriesParams{
+		LeaseUntil:    time.Now().Add(time.Hour),
+		MaxDeliveries: 1000,
+	})
+	require.NoError(t, err)
+	require.True(t, containsDelivery(claimed, delivery.ID))
+
+	claimed, err = testQueries.ClaimWebhookDeliveries(context.Background(), db.ClaimWebhookDeliveriesParams{
+		LeaseUntil:    time.Now().Add(time.Hour),
+		MaxDeliveries: 1000,
+	})
+	require.NoError(t, err)
+	require.False(t, containsDelivery(claimed, delivery.ID))
+
+	dead, err := s.RecordWebhookAttemptTx(context.Background(), db.RecordWebhookAttemptTxParams{
+		DeliveryID:    delivery.ID,
+		Error:         "connection refused",
+		Duration:      time.Second,
+		Status:        db.WebhookDeliveryStatusDead,
+		NextAttemptAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.WebhookDeliveryStatusDead, dead.Status)
+	assert.Equal(t, int32(1), dead.Attempts)
+	assert.False(t, dead.DeliveredAt.Valid)
+
+	attempts, err := testQueries.ListWebhookAttempts(context.Background(), delivery.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, "connection refused", attempts[0].Error)
+	assert.Equal(t, int64(1000), attempts[0].DurationMs)
+
+	// a redelivered delivery can be claimed again
+	pending, err := testQueries.RedeliverWebhookDelivery(context.Background(), delivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.WebhookDeliveryStatusPending, pending.Status)
+	assert.Zero(t, pending.Attempts)
+
+	delivered, err := s.RecordWebhookAttemptTx(context.Background(), db.RecordWebhookAttemptTxParams{
+		DeliveryID:    delivery.ID,
+		StatusCode:    200,
+		Status:        db.WebhookDeliveryStatusDelivered,
+		NextAttemptAt: time.Now(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.WebhookDeliveryStatusDelivered, delivered.Status)
+	assert.True(t, delivered.DeliveredAt.Valid)
+}
+
+func containsDelivery(deliveries []db.ClaimWebhookDeliveriesRow, id int64) bool {
+	for _, delivery := range deliveries {
+		if delivery.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,22 +5,56 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+const countTransfersSince = `-- name: CountTransfersSince :one
+SELECT count(*)
+FROM transfers
+WHERE from_account_id = $1
+  AND created_at >= $2
+`
+
+type CountTransfersSinceParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (q *Queries) CountTransfersSince(ctx context.Context, arg CountTransfersSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransfersSince, arg.FromAccountID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount)
-VALUES ($1, $2, $3)
-RETURNING id, from_account_id, to_account_id, amount, created_at
+INSERT INTO transfers (from_account_id, to_account_id, amount, payee_id, reference, memo, remittance_info)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, from_account_id, to_account_id, amount, created_at, payee_id, reference, memo, remittance_info
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID  int64           `json:"from_account_id"`
+	ToAccountID    int64           `json:"to_account_id"`
+	Amount         int64           `json:"amount"`
+	PayeeID        sql.NullInt64   `json:"payee_id"`
+	Reference      string          `json:"reference"`
+	Memo           string          `json:"memo"`
+	RemittanceInfo json.RawMessage `json:"remittance_info"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.PayeeID,
+		arg.Reference,
+		arg.Memo,
+		arg.RemittanceInfo,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -28,6 +62,10 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.PayeeID,
+		&i.Reference,
+		&i.Memo,
+		&i.RemittanceInfo,
 	)
 	return i, err
 }
@@ -44,7 +82,7 @@ func (q *Queries) DeleteTransfer(ctx context.Context, id int64) error {
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, payee_id, reference, memo, remittance_info
 FROM transfers
 WHERE id = $1
 LIMIT 1
@@ -59,12 +97,16 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.PayeeID,
+		&i.Reference,
+		&i.Memo,
+		&i.RemittanceInfo,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at
+SELECT id, from_account_id, to_account_id, amount, created_at, payee_id, reference, memo, remittance_info
 FROM transfers
 WHERE from_account_id = $1
    OR to_account_id = $2
@@ -99,6 +141,65 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.PayeeID,
+			&i.Reference,
+			&i.Memo,
+			&i.RemittanceInfo,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfers = `-- name: SearchTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, payee_id, reference, memo, remittance_info
+FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $1)
+  AND to_tsvector('simple', reference || ' ' || memo) ||
+      jsonb_to_tsvector('simple', remittance_info, '["string"]') @@ websearch_to_tsquery('simple', $2::text)
+ORDER BY id
+LIMIT $3 OFFSET $4
+`
+
+type SearchTransfersParams struct {
+	AccountID  int64  `json:"account_id"`
+	Query      string `json:"query"`
+	PageSize   int32  `json:"page_size"`
+	PageOffset int32  `json:"page_offset"`
+}
+
+func (q *Queries) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfers,
+		arg.AccountID,
+		arg.Query,
+		arg.PageSize,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.PayeeID,
+			&i.Reference,
+			&i.Memo,
+			&i.RemittanceInfo,
 		); err != nil {
 			return nil, err
 		}
@@ -117,7 +218,7 @@ const updateTransfer = `-- name: UpdateTransfer :one
 UPDATE transfers
 SET amount = $2
 WHERE id = $1
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, payee_id, reference, memo, remittance_info
 `
 
 type UpdateTransferParams struct {
@@ -134,6 +235,10 @@ func (q *Queries) UpdateTransfer(ctx context.Context, arg UpdateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.PayeeID,
+		&i.Reference,
+		&i.Memo,
+		&i.RemittanceInfo,
 	)
 	return i, err
 }
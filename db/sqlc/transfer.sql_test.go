@@ -3,6 +3,7 @@ package db_test
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"testing"
 
 	db "github.com/chutommy/simple-bank/db/sqlc"
@@ -15,9 +16,11 @@ func createRandomTransfer(t *testing.T, from, to db.Account) db.Transfer {
 	t.Helper()
 
 	arg := db.CreateTransferParams{
-		FromAccountID: from.ID,
-		ToAccountID:   to.ID,
-		Amount:        util.RandomAmount(),
+		FromAccountID:  from.ID,
+		ToAccountID:    to.ID,
+		Amount:         util.RandomAmount(),
+		Reference:      util.RandomOwner(),
+		RemittanceInfo: json.RawMessage("{}"),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -27,6 +30,7 @@ func createRandomTransfer(t *testing.T, from, to db.Account) db.Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.Reference, transfer.Reference)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -84,6 +88,50 @@ func TestQueries_ListTransfers(t *testing.T) {
 	}
 }
 
+func TestQueries_SearchTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	createRandomTransfer(t, account1, account2)
+	transfer1 := createRandomTransfer(t, account2, account1)
+
+	invoice := util.RandomOwner()
+	transfer2, err := testQueries.CreateTransfer(context.Background(), db.CreateTransferParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         util.RandomAmount(),
+		Memo:           "consulting services",
+		RemittanceInfo: json.RawMessage(`{"invoice": "` + invoice + `"}`),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		query string
+		want  []db.Transfer
+	}{
+		{query: transfer1.Reference, want: []db.Transfer{transfer1}},
+		{query: "consulting", want: []db.Transfer{transfer2}},
+		{query: invoice, want: []db.Transfer{transfer2}},
+		{query: "consulting -services", want: []db.Transfer{}},
+	}
+
+	for _, test := range tests {
+		transfers, err := testQueries.SearchTransfers(context.Background(), db.SearchTransfersParams{
+			AccountID:  account1.ID,
+			Query:      test.query,
+			PageSize:   10,
+			PageOffset: 0,
+		})
+		require.NoError(t, err)
+
+		if assert.Len(t, transfers, len(test.want), test.query) {
+			for i := range transfers {
+				assert.Equal(t, test.want[i].ID, transfers[i].ID)
+			}
+		}
+	}
+}
+
 func TestQueries_UpdateTransfer(t *testing.T) {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
@@ -0,0 +1,9 @@
+package db
+
+// Roles of a User. Customers use the banking service, the other roles are staff.
+const (
+	UserRoleCustomer = "customer"
+	UserRoleSupport  = "support"
+	UserRoleAdmin    = "admin"
+	UserRoleAuditor  = "auditor"
+)
@@ -11,7 +11,7 @@ import (
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username, hashed_password, first_name, last_name, email, password_modified_at)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING username, hashed_password, first_name, last_name, email, password_modified_at, created_at
+RETURNING username, hashed_password, first_name, last_name, email, password_modified_at, created_at, role, email_verified_at
 `
 
 type CreateUserParams struct {
@@ -41,6 +41,8 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordModifiedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -49,21 +51,15 @@ const deleteUser = `-- name: DeleteUser :exec
 DELETE
 FROM users
 WHERE username = $1
-  AND hashed_password = $2
 `
 
-type DeleteUserParams struct {
-	Username       string `json:"username"`
-	HashedPassword string `json:"hashed_password"`
-}
-
-func (q *Queries) DeleteUser(ctx context.Context, arg DeleteUserParams) error {
-	_, err := q.db.ExecContext(ctx, deleteUser, arg.Username, arg.HashedPassword)
+func (q *Queries) DeleteUser(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, username)
 	return err
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, first_name, last_name, email, password_modified_at, created_at
+SELECT username, hashed_password, first_name, last_name, email, password_modified_at, created_at, role, email_verified_at
 FROM users
 WHERE username = $1
 LIMIT 1
@@ -80,26 +76,182 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.Email,
 		&i.PasswordModifiedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT username, hashed_password, first_name, last_name, email, password_modified_at, created_at, role, email_verified_at
+FROM users
+WHERE email = $1
+LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+		&i.PasswordModifiedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users
+SET hashed_password = $1
+WHERE username = $2
+  AND hashed_password = $3
+`
+
+type RehashUserPasswordParams struct {
+	HashedPassword    string `json:"hashed_password"`
+	Username          string `json:"username"`
+	OldHashedPassword string `json:"old_hashed_password"`
+}
+
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rehashUserPassword, arg.HashedPassword, arg.Username, arg.OldHashedPassword)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT username, hashed_password, first_name, last_name, email, password_modified_at, created_at, role, email_verified_at
+FROM users
+WHERE ($1::text = ''
+    OR username ILIKE '%' || $1::text || '%'
+    OR email ILIKE '%' || $1::text || '%'
+    OR first_name || ' ' || last_name ILIKE '%' || $1::text || '%')
+  AND ($2::text = '' OR role = $2::text)
+ORDER BY username
+LIMIT $3 OFFSET $4
+`
+
+type SearchUsersParams struct {
+	Query     string `json:"query"`
+	Role      string `json:"role"`
+	MaxUsers  int32  `json:"max_users"`
+	SkipUsers int32  `json:"skip_users"`
+}
+
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers,
+		arg.Query,
+		arg.Role,
+		arg.MaxUsers,
+		arg.SkipUsers,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.Username,
+			&i.HashedPassword,
+			&i.FirstName,
+			&i.LastName,
+			&i.Email,
+			&i.PasswordModifiedAt,
+			&i.CreatedAt,
+			&i.Role,
+			&i.EmailVerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserEmailVerified = `-- name: SetUserEmailVerified :one
+UPDATE users
+SET email_verified_at = coalesce(email_verified_at, now())
+WHERE username = $1
+RETURNING username, hashed_password, first_name, last_name, email, password_modified_at, created_at, role, email_verified_at
+`
+
+func (q *Queries) SetUserEmailVerified(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserEmailVerified, username)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+		&i.PasswordModifiedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const setUserRole = `-- name: SetUserRole :one
+UPDATE users
+SET role = $2
+WHERE username = $1
+RETURNING username, hashed_password, first_name, last_name, email, password_modified_at, created_at, role, email_verified_at
+`
+
+type SetUserRoleParams struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+func (q *Queries) SetUserRole(ctx context.Context, arg SetUserRoleParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserRole, arg.Username, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FirstName,
+		&i.LastName,
+		&i.Email,
+		&i.PasswordModifiedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const updateUserPassword = `-- name: UpdateUserPassword :one
 UPDATE users
-SET hashed_password = $3
+SET hashed_password      = $2,
+    password_modified_at = now()
 WHERE username = $1
-  AND hashed_password = $2
-RETURNING username, hashed_password, first_name, last_name, email, password_modified_at, created_at
+RETURNING username, hashed_password, first_name, last_name, email, password_modified_at, created_at, role, email_verified_at
 `
 
 type UpdateUserPasswordParams struct {
-	Username         string `json:"username"`
-	HashedPassword   string `json:"hashed_password"`
-	HashedPassword_2 string `json:"hashed_password_2"`
+	Username       string `json:"username"`
+	HashedPassword string `json:"hashed_password"`
 }
 
 func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.Username, arg.HashedPassword, arg.HashedPassword_2)
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.Username, arg.HashedPassword)
 	var i User
 	err := row.Scan(
 		&i.Username,
@@ -109,6 +261,8 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.Email,
 		&i.PasswordModifiedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
@@ -3,6 +3,7 @@ package db_test
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 
 	db "github.com/chutommy/simple-bank/db/sqlc"
@@ -11,7 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func createRandomUser(t *testing.T) db.User {
+func createRandomUser(t testing.TB) db.User {
 	t.Helper()
 
 	// construct params
@@ -81,6 +82,31 @@ func TestQueries_UpdateUserPassword(t *testing.T) {
 	}
 }
 
+func TestQueries_RehashUserPassword(t *testing.T) {
+	user1 := createRandomUser(t)
+
+	arg := db.RehashUserPasswordParams{
+		Username:          user1.Username,
+		HashedPassword:    "rehashed_password",
+		OldHashedPassword: user1.HashedPassword,
+	}
+
+	// rehash password
+	n, err := testQueries.RehashUserPassword(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	user2, err := testQueries.GetUser(context.Background(), user1.Username)
+	require.NoError(t, err)
+	assert.Equal(t, "rehashed_password", user2.HashedPassword)
+	assert.Equal(t, user1.PasswordModifiedAt, user2.PasswordModifiedAt)
+
+	// the hash changed meanwhile is kept
+	n, err = testQueries.RehashUserPassword(context.Background(), arg)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
 func TestQueries_DeleteUser(t *testing.T) {
 	user1 := createRandomUser(t)
 
@@ -93,3 +119,43 @@ func TestQueries_DeleteUser(t *testing.T) {
 	assert.ErrorIs(t, err, sql.ErrNoRows)
 	assert.Empty(t, user2)
 }
+
+func TestQueries_SetUserRole(t *testing.T) {
+	user1 := createRandomUser(t)
+	assert.Equal(t, db.UserRoleCustomer, user1.Role)
+
+	user2, err := testQueries.SetUserRole(context.Background(), db.SetUserRoleParams{
+		Username: user1.Username,
+		Role:     db.UserRoleAuditor,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, db.UserRoleAuditor, user2.Role)
+
+	// unknown roles are rejected
+	_, err = testQueries.SetUserRole(context.Background(), db.SetUserRoleParams{
+		Username: user1.Username,
+		Role:     "root",
+	})
+	assert.Error(t, err)
+}
+
+func TestQueries_SearchUsers(t *testing.T) {
+	user := createRandomUser(t)
+
+	users, err := testQueries.SearchUsers(context.Background(), db.SearchUsersParams{
+		Query:    strings.ToUpper(user.Email[:len(user.Email)-2]),
+		Role:     db.UserRoleCustomer,
+		MaxUsers: 10,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, users)
+	assert.Equal(t, user.Username, users[0].Username)
+
+	users, err = testQueries.SearchUsers(context.Background(), db.SearchUsersParams{
+		Query:    user.Username,
+		Role:     db.UserRoleAdmin,
+		MaxUsers: 10,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
@@ -0,0 +1,19 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// ExpirePendingTransfers marks unapproved pending transfers past their expiry as expired.
+func ExpirePendingTransfers(store db.Store) Func {
+	return func(ctx context.Context) error {
+		if _, err := store.ExpirePendingTransfers(ctx); err != nil {
+			return fmt.Errorf("failed to expire pending transfers: %w", err)
+		}
+
+		return nil
+	}
+}
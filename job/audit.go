@@ -0,0 +1,28 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// auditChainBatchSize is the maximal number of audit records chained in one transaction.
+const auditChainBatchSize = 500
+
+// ChainAuditLog appends the audit records written since the last run to the hash chain
+// of the audit log. It runs until no unchained record is left.
+func ChainAuditLog(store db.Store) Func {
+	return func(ctx context.Context) error {
+		for {
+			n, err := store.ChainAuditLogTx(ctx, auditChainBatchSize)
+			if err != nil {
+				return fmt.Errorf("failed to chain audit log: %w", err)
+			}
+
+			if n < auditChainBatchSize {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package job_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/chutommy/simple-bank/db/mocks"
+	"github.com/chutommy/simple-bank/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestChainAuditLog(t *testing.T) {
+	store := new(mocks.Store)
+
+	// full batches are followed by another one
+	store.On("ChainAuditLogTx", mock.Anything, int32(500)).Return(500, nil).Once()
+	store.On("ChainAuditLogTx", mock.Anything, int32(500)).Return(3, nil).Once()
+
+	err := job.ChainAuditLog(store)(context.Background())
+	assert.NoError(t, err)
+	store.AssertExpectations(t)
+}
+
+func TestChainAuditLog_Error(t *testing.T) {
+	store := new(mocks.Store)
+	store.On("ChainAuditLogTx", mock.Anything, mock.Anything).Return(0, sql.ErrConnDone)
+
+	err := job.ChainAuditLog(store)(context.Background())
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	store.AssertExpectations(t)
+}
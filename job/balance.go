@@ -0,0 +1,27 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// SnapshotBalances writes the end-of-day balances of the last finished day.
+// Snapshots already taken for the date are kept, so the job can run more often than daily.
+func SnapshotBalances(store db.Store) Func {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+		if _, err := store.CreateBalanceSnapshots(ctx, db.CreateBalanceSnapshotsParams{
+			SnapshotDate: today.AddDate(0, 0, -1),
+			EndOfDay:     today,
+		}); err != nil {
+			return fmt.Errorf("failed to snapshot balances: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,28 @@
+package job_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSnapshotBalances(t *testing.T) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	store := new(mocks.Store)
+	store.On("CreateBalanceSnapshots", mock.Anything, db.CreateBalanceSnapshotsParams{
+		SnapshotDate: today.AddDate(0, 0, -1),
+		EndOfDay:     today,
+	}).Return(int64(2), nil)
+
+	err := job.SnapshotBalances(store)(context.Background())
+	assert.NoError(t, err)
+	store.AssertExpectations(t)
+}
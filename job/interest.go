@@ -0,0 +1,63 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// Interest accrues the daily interest of the last finished day and then posts
+// the interest accrued in the last finished month. The capitalization runs only
+// after a successful accrual, so the interest of the last day of a month
+// is capitalized within the month. Both steps are idempotent per date and month,
+// so the job can run more often than daily.
+func Interest(store db.Store) Func {
+	accrue := AccrueInterest(store)
+	capitalize := CapitalizeInterest(store)
+
+	return func(ctx context.Context) error {
+		if err := accrue(ctx); err != nil {
+			return err
+		}
+
+		return capitalize(ctx)
+	}
+}
+
+// AccrueInterest accrues the daily interest of the last finished day.
+// The accrual is idempotent per date, so the job can run more often than daily.
+// Accounts whose interest can not be computed are logged and skipped.
+func AccrueInterest(store db.Store) Func {
+	return func(ctx context.Context) error {
+		yesterday := time.Now().UTC().AddDate(0, 0, -1)
+
+		result, err := store.AccrueInterestTx(ctx, yesterday)
+		if err != nil {
+			return fmt.Errorf("failed to accrue interest: %w", err)
+		}
+
+		for _, err := range result.Errors {
+			log.Printf("failed to accrue interest of %v", err)
+		}
+
+		return nil
+	}
+}
+
+// CapitalizeInterest posts the interest accrued in the last finished month.
+// The capitalization is idempotent per month, so the job can run more often than monthly.
+func CapitalizeInterest(store db.Store) Func {
+	return func(ctx context.Context) error {
+		now := time.Now().UTC()
+		lastMonth := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+
+		if _, err := store.CapitalizeInterestTx(ctx, lastMonth); err != nil {
+			return fmt.Errorf("failed to capitalize interest: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package job_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/job"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAccrueInterest(t *testing.T) {
+	store := new(mocks.Store)
+	store.On("AccrueInterestTx", mock.Anything, mock.MatchedBy(func(date time.Time) bool {
+		return time.Since(date) >= 24*time.Hour && time.Since(date) < 25*time.Hour
+	})).Return(db.AccrueInterestTxResult{}, nil)
+
+	err := job.AccrueInterest(store)(context.Background())
+	assert.NoError(t, err)
+	store.AssertExpectations(t)
+}
+
+func TestCapitalizeInterest(t *testing.T) {
+	now := time.Now().UTC()
+	lastMonth := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+
+	store := new(mocks.Store)
+	store.On("CapitalizeInterestTx", mock.Anything, lastMonth).
+		Return(db.CapitalizeInterestTxResult{}, context.DeadlineExceeded)
+
+	err := job.CapitalizeInterest(store)(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	store.AssertExpectations(t)
+}
+
+func TestInterest(t *testing.T) {
+	now := time.Now().UTC()
+	lastMonth := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the capitalization follows the accrual, accounts which can not
+	// be accrued do not stop it
+	var accrued bool
+	store := new(mocks.Store)
+	store.On("AccrueInterestTx", mock.Anything, mock.Anything).
+		Return(db.AccrueInterestTxResult{Errors: []error{db.ErrUnknownDayCount}}, nil).
+		Run(func(mock.Arguments) { accrued = true })
+	store.On("CapitalizeInterestTx", mock.Anything, lastMonth).
+		Return(db.CapitalizeInterestTxResult{}, nil).
+		Run(func(mock.Arguments) { assert.True(t, accrued) })
+
+	err := job.Interest(store)(context.Background())
+	assert.NoError(t, err)
+	store.AssertExpectations(t)
+}
+
+func TestInterestAccrualFailed(t *testing.T) {
+	// the month is not capitalized until its last day is accrued
+	store := new(mocks.Store)
+	store.On("AccrueInterestTx", mock.Anything, mock.Anything).
+		Return(db.AccrueInterestTxResult{}, context.DeadlineExceeded)
+
+	err := job.Interest(store)(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	store.AssertExpectations(t)
+	store.AssertNotCalled(t, "CapitalizeInterestTx", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,28 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Func is a unit of work run periodically by Every.
+type Func func(ctx context.Context) error
+
+// Every runs fn every interval until the context is cancelled. Errors
+// are logged and do not stop the following runs.
+func Every(ctx context.Context, interval time.Duration, name string, fn Func) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := fn(ctx); err != nil {
+				log.Printf("job %s failed: %v", name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// PurgeLoginThrottles deletes the unlocked login throttles whose failures
+// are older than the window and would be forgotten anyway.
+func PurgeLoginThrottles(store db.Store, window time.Duration) Func {
+	return func(ctx context.Context) error {
+		if _, err := store.DeleteStaleLoginThrottles(ctx, time.Now().Add(-window)); err != nil {
+			return fmt.Errorf("failed to purge stale login throttles: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,19 @@
+package job
+
+import (
+	"context"
+	"fmt"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// ExpirePaymentRequests marks unpaid payment requests past their expiry as expired.
+func ExpirePaymentRequests(store db.Store) Func {
+	return func(ctx context.Context) error {
+		if _, err := store.ExpirePaymentRequests(ctx); err != nil {
+			return fmt.Errorf("failed to expire payment requests: %w", err)
+		}
+
+		return nil
+	}
+}
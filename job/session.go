@@ -0,0 +1,20 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// PurgeSessions deletes the sessions whose refresh tokens have expired.
+func PurgeSessions(store db.Store) Func {
+	return func(ctx context.Context) error {
+		if _, err := store.DeleteExpiredSessions(ctx, time.Now()); err != nil {
+			return fmt.Errorf("failed to purge expired sessions: %w", err)
+		}
+
+		return nil
+	}
+}
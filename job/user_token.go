@@ -0,0 +1,20 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// PurgeUserTokens deletes the expired email verification and password reset tokens.
+func PurgeUserTokens(store db.Store) Func {
+	return func(ctx context.Context) error {
+		if _, err := store.DeleteExpiredUserTokens(ctx, time.Now()); err != nil {
+			return fmt.Errorf("failed to purge expired user tokens: %w", err)
+		}
+
+		return nil
+	}
+}
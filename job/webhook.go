@@ -0,0 +1,68 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/webhook"
+)
+
+const (
+	// webhookBatchSize is the maximal number of deliveries attempted in one run.
+	webhookBatchSize = 50
+	// webhookLease is the time a claimed delivery is hidden from other runs.
+	webhookLease = 5 * time.Minute
+)
+
+// DeliverWebhooks attempts the pending webhook deliveries which are due. A failed
+// delivery is retried with an exponential backoff and marked as dead after maxAttempts.
+// Deliveries are claimed before they are sent, so the job can run on multiple servers.
+func DeliverWebhooks(store db.Store, sender *webhook.Sender, maxAttempts int32, backoff time.Duration) Func {
+	return func(ctx context.Context) error {
+		deliveries, err := store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+			LeaseUntil:    time.Now().Add(webhookLease),
+			MaxDeliveries: webhookBatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+		}
+
+		for _, delivery := range deliveries {
+			start := time.Now()
+			code, err := sender.Send(ctx, webhook.Request{
+				URL:        delivery.URL,
+				Secret:     delivery.Secret,
+				DeliveryID: delivery.ID,
+				EventType:  delivery.EventType,
+				Payload:    delivery.Payload,
+			})
+
+			arg := db.RecordWebhookAttemptTxParams{
+				DeliveryID:    delivery.ID,
+				StatusCode:    int32(code),
+				Duration:      time.Since(start),
+				Status:        db.WebhookDeliveryStatusDelivered,
+				NextAttemptAt: time.Now(),
+			}
+
+			if err != nil {
+				arg.Error = err.Error()
+
+				if attempts := delivery.Attempts + 1; attempts >= maxAttempts {
+					arg.Status = db.WebhookDeliveryStatusDead
+				} else {
+					arg.Status = db.WebhookDeliveryStatusPending
+					arg.NextAttemptAt = time.Now().Add(webhook.Backoff(backoff, attempts))
+				}
+			}
+
+			if _, err := store.RecordWebhookAttemptTx(ctx, arg); err != nil {
+				return fmt.Errorf("failed to record attempt of webhook delivery %d: %w", delivery.ID, err)
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package job_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/job"
+	"github.com/chutommy/simple-bank/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeliverWebhooks(t *testing.T) {
+	// the receiver accepts the deliveries with odd IDs only
+	var signed int
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.TimestampHeader), 10, 64)
+		if webhook.Verify("secret", r.Header.Get(webhook.SignatureHeader), timestamp, []byte(`{}`)) {
+			signed++
+		}
+
+		id, _ := strconv.Atoi(r.Header.Get(webhook.DeliveryHeader))
+		if id%2 == 1 {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+
+	delivery := func(id int64, attempts int32) db.ClaimWebhookDeliveriesRow {
+		return db.ClaimWebhookDeliveriesRow{
+			ID:        id,
+			EventType: db.EventTransferCreated,
+			Payload:   []byte(`{}`),
+			Attempts:  attempts,
+			URL:       receiver.URL,
+			Secret:    "secret",
+		}
+	}
+
+	store := new(mocks.Store)
+	store.On("ClaimWebhookDeliveries", mock.Anything, mock.MatchedBy(func(arg db.ClaimWebhookDeliveriesParams) bool {
+		return arg.LeaseUntil.After(time.Now()) && arg.MaxDeliveries > 0
+	})).Return([]db.ClaimWebhookDeliveriesRow{delivery(1, 0), delivery(2, 0), delivery(4, 2)}, nil)
+
+	// accepted
+	store.On("RecordWebhookAttemptTx", mock.Anything, mock.MatchedBy(func(arg db.RecordWebhookAttemptTxParams) bool {
+		return arg.DeliveryID == 1 && arg.StatusCode == http.StatusOK &&
+			arg.Error == "" && arg.Status == db.WebhookDeliveryStatusDelivered
+	})).Return(db.WebhookDelivery{}, nil)
+
+	// retried with a backoff
+	store.On("RecordWebhookAttemptTx", mock.Anything, mock.MatchedBy(func(arg db.RecordWebhookAttemptTxParams) bool {
+		return arg.DeliveryID == 2 && arg.StatusCode == http.StatusServiceUnavailable &&
+			arg.Error != "" && arg.Status == db.WebhookDeliveryStatusPending &&
+			time.Until(arg.NextAttemptAt) > 50*time.Second
+	})).Return(db.WebhookDelivery{}, nil)
+
+	// out of attempts
+	store.On("RecordWebhookAttemptTx", mock.Anything, mock.MatchedBy(func(arg db.RecordWebhookAttemptTxParams) bool {
+		return arg.DeliveryID == 4 && arg.Status == db.WebhookDeliveryStatusDead
+	})).Return(db.WebhookDelivery{}, nil)
+
+	err := job.DeliverWebhooks(store, webhook.NewSender(time.Second, true), 3, time.Minute)(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, signed)
+	store.AssertExpectations(t)
+}
+
+func TestDeliverWebhooks_ClaimError(t *testing.T) {
+	store := new(mocks.Store)
+	store.On("ClaimWebhookDeliveries", mock.Anything, mock.Anything).Return(nil, context.DeadlineExceeded)
+
+	err := job.DeliverWebhooks(store, webhook.NewSender(time.Second, true), 3, time.Minute)(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	store.AssertExpectations(t)
+}
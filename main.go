@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -12,6 +13,11 @@ import (
 	"github.com/chutommy/simple-bank/api"
 	"github.com/chutommy/simple-bank/config"
 	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/job"
+	"github.com/chutommy/simple-bank/mail"
+	"github.com/chutommy/simple-bank/outbox"
+	"github.com/chutommy/simple-bank/stream"
+	"github.com/chutommy/simple-bank/webhook"
 	_ "github.com/lib/pq"
 )
 
@@ -41,9 +47,43 @@ func main() {
 			}
 		}
 
-		store := db.NewStore(dbConn)
+		store := db.NewAuditStore(dbConn)
 
-		server := api.NewServer(store)
+		sink, err := outbox.NewSink(cfg.OutboxSink, cfg.OutboxTarget)
+		if err != nil {
+			log.Fatal(fmt.Errorf("cannot create outbox sink: %w", err))
+		}
+
+		mailer, err := mail.NewMailer(cfg.Mailer, cfg.MailerTarget, cfg.MailFrom)
+		if err != nil {
+			log.Fatal(fmt.Errorf("cannot create mailer: %w", err))
+		}
+
+		hub := stream.NewHub()
+
+		server, err := api.NewServer(cfg, store, hub, mailer)
+		if err != nil {
+			log.Fatal(fmt.Errorf("cannot create server: %w", err))
+		}
+
+		// run background jobs
+		ctx, cancel := context.WithCancel(context.Background())
+		go job.Every(ctx, time.Minute, "expire pending transfers", job.ExpirePendingTransfers(store))
+		go job.Every(ctx, time.Minute, "expire payment requests", job.ExpirePaymentRequests(store))
+		go job.Every(ctx, time.Hour, "interest", job.Interest(store))
+		go job.Every(ctx, time.Hour, "snapshot balances", job.SnapshotBalances(store))
+		go job.Every(ctx, time.Hour, "purge sessions", job.PurgeSessions(store))
+		go job.Every(ctx, time.Hour, "purge user tokens", job.PurgeUserTokens(store))
+		go job.Every(ctx, time.Hour, "purge login throttles", job.PurgeLoginThrottles(store, cfg.LoginFailureWindow))
+		go job.Every(ctx, 10*time.Second, "chain audit log", job.ChainAuditLog(store))
+		go job.Every(ctx, 10*time.Second, "deliver webhooks", job.DeliverWebhooks(store,
+			webhook.NewSender(cfg.WebhookTimeout, cfg.WebhookAllowInternal), cfg.WebhookMaxAttempts, cfg.WebhookBackoff))
+		go outbox.NewDispatcher(store, sink, cfg.OutboxInterval).Run(ctx)
+		go func() {
+			if err := stream.Listen(ctx, cfg.DBSource, hub); err != nil {
+				log.Printf("cannot listen to account events: %v", err)
+			}
+		}()
 
 		// run the server concurrently
 		go func() {
@@ -66,6 +106,8 @@ func main() {
 			fmt.Println("Restating the server...")
 		}
 
+		cancel()
+
 		if err := server.Stop(); err != nil {
 			log.Fatal(fmt.Errorf("unsuccessfully closed server: %w", err))
 		}
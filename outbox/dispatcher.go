@@ -0,0 +1,79 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// batchSize is the maximal number of events published at once.
+const batchSize = 100
+
+// Dispatcher moves the events from the outbox of the store to a sink.
+type Dispatcher struct {
+	store    db.Store
+	sink     Sink
+	interval time.Duration
+}
+
+// NewDispatcher constructs a new Dispatcher polling the outbox every interval.
+func NewDispatcher(store db.Store, sink Sink, interval time.Duration) *Dispatcher {
+	return &Dispatcher{
+		store:    store,
+		sink:     sink,
+		interval: interval,
+	}
+}
+
+// Run dispatches the events until the context is cancelled. Errors are logged
+// and the failed events are dispatched again on the next poll.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Drain(ctx); err != nil {
+				log.Printf("outbox dispatcher failed: %v", err)
+			}
+		}
+	}
+}
+
+// Drain dispatches batches of events until the outbox is empty.
+func (d *Dispatcher) Drain(ctx context.Context) error {
+	for ctx.Err() == nil {
+		n, err := d.Dispatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			return nil
+		}
+	}
+
+	return ctx.Err()
+}
+
+// Dispatch publishes a single batch of events to the sink and returns its size.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	n, err := d.store.DispatchOutboxTx(ctx, batchSize, func(events []db.Outbox) error {
+		if err := d.sink.Publish(ctx, events); err != nil {
+			return fmt.Errorf("failed to publish events: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to dispatch outbox: %w", err)
+	}
+
+	return n, nil
+}
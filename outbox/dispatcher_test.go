@@ -0,0 +1,82 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/db/mocks"
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// dispatchEvents makes the DispatchOutboxTx of the store hand the events to the dispatch function.
+func dispatchEvents(store *mocks.Store, events []db.Outbox) *mock.Call {
+	return store.On("DispatchOutboxTx", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			_ = args.Get(2).(db.DispatchFunc)(events)
+		}).Once()
+}
+
+type failingSink struct{}
+
+var errSink = errors.New("sink unavailable")
+
+func (failingSink) Publish(context.Context, []db.Outbox) error {
+	return errSink
+}
+
+func TestDispatcher_Drain(t *testing.T) {
+	first := sampleEvents()
+	second := []db.Outbox{{ID: 3, EventID: "c", EventType: db.EventAccountClosed, AccountID: 7}}
+
+	store := new(mocks.Store)
+	dispatchEvents(store, first).Return(len(first), nil)
+	dispatchEvents(store, second).Return(len(second), nil)
+	store.On("DispatchOutboxTx", mock.Anything, mock.Anything, mock.Anything).Return(0, nil).Once()
+
+	sink := outbox.NewMemorySink()
+	require.NoError(t, outbox.NewDispatcher(store, sink, time.Second).Drain(context.Background()))
+	assert.Equal(t, append(first, second...), sink.Events())
+	store.AssertExpectations(t)
+}
+
+func TestDispatcher_Dispatch_SinkError(t *testing.T) {
+	var dispatchErr error
+
+	store := new(mocks.Store)
+	store.On("DispatchOutboxTx", mock.Anything, int32(100), mock.Anything).
+		Run(func(args mock.Arguments) {
+			dispatchErr = args.Get(2).(db.DispatchFunc)(sampleEvents())
+		}).
+		Return(0, errSink)
+
+	n, err := outbox.NewDispatcher(store, failingSink{}, time.Second).Dispatch(context.Background())
+	assert.ErrorIs(t, dispatchErr, errSink)
+	assert.ErrorIs(t, err, errSink)
+	assert.Zero(t, n)
+	store.AssertExpectations(t)
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	store := new(mocks.Store)
+	dispatchEvents(store, sampleEvents()).Return(2, nil)
+	store.On("DispatchOutboxTx", mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+
+	sink := outbox.NewMemorySink()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		outbox.NewDispatcher(store, sink, 10*time.Millisecond).Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return len(sink.Events()) == 2 }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
@@ -0,0 +1,188 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+)
+
+// Kinds of the sinks constructed by NewSink.
+const (
+	SinkLog  = "log"
+	SinkFile = "file"
+	SinkHTTP = "http"
+)
+
+var (
+	// ErrUnknownSink is returned when a sink of an unknown kind is requested.
+	ErrUnknownSink = errors.New("unknown outbox sink")
+	// ErrUnexpectedStatus is returned when an HTTP sink does not respond with a 2xx status.
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+)
+
+// Sink is an interface for consumers of the outbox events.
+type Sink interface {
+	// Publish publishes the events in the given order. An error makes
+	// the events to be published again, so they are delivered at least once.
+	Publish(ctx context.Context, events []db.Outbox) error
+}
+
+// NewSink constructs a new Sink of the given kind. The target is the path
+// of a file sink or the URL of an HTTP sink.
+func NewSink(kind, target string) (Sink, error) {
+	switch kind {
+	case SinkLog:
+		return NewLogSink(log.New(os.Stdout, "outbox: ", log.LstdFlags)), nil
+	case SinkFile:
+		return NewFileSink(target)
+	case SinkHTTP:
+		return NewHTTPSink(target, 10*time.Second), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSink, kind)
+	}
+}
+
+// LogSink writes the events to a logger.
+type LogSink struct {
+	logger *log.Logger
+}
+
+// NewLogSink constructs a new LogSink.
+func NewLogSink(logger *log.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// Publish logs the events.
+func (s *LogSink) Publish(_ context.Context, events []db.Outbox) error {
+	for _, event := range events {
+		s.logger.Printf("%s %s account=%d %s", event.EventID, event.EventType, event.AccountID, event.Payload)
+	}
+
+	return nil
+}
+
+// FileSink appends the events to a file as JSON lines.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink constructs a new FileSink appending to the file at path.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open outbox file: %w", err)
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+// Publish appends a JSON line for each event and syncs the file.
+func (s *FileSink) Publish(_ context.Context, events []db.Outbox) error {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode event %d: %w", event.ID, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write events: %w", err)
+	}
+
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// HTTPSink posts the events as a JSON array to a URL.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink constructs a new HTTPSink with the given request timeout.
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Publish posts the events in a single request.
+func (s *HTTPSink) Publish(ctx context.Context, events []db.Outbox) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to encode events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid outbox request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post events: %w", err)
+	}
+
+	// drain the body, so the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	return nil
+}
+
+// MemorySink keeps the events in memory, it is meant for tests.
+type MemorySink struct {
+	mu     sync.Mutex
+	events []db.Outbox
+}
+
+// NewMemorySink constructs a new MemorySink.
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Publish appends the events.
+func (s *MemorySink) Publish(_ context.Context, events []db.Outbox) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, events...)
+
+	return nil
+}
+
+// Events returns a copy of the published events.
+func (s *MemorySink) Events() []db.Outbox {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make([]db.Outbox, len(s.events))
+	copy(events, s.events)
+
+	return events
+}
@@ -0,0 +1,120 @@
+package outbox_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/chutommy/simple-bank/outbox"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleEvents() []db.Outbox {
+	return []db.Outbox{
+		{ID: 1, EventID: "a", EventType: db.EventTransferCreated, AccountID: 7, Payload: []byte(`{"id":"a"}`)},
+		{ID: 2, EventID: "b", EventType: db.EventEntryCreated, AccountID: 7, Payload: []byte(`{"id":"b"}`)},
+	}
+}
+
+func TestNewSink(t *testing.T) {
+	sink, err := outbox.NewSink(outbox.SinkLog, "")
+	require.NoError(t, err)
+	assert.IsType(t, &outbox.LogSink{}, sink)
+
+	sink, err = outbox.NewSink(outbox.SinkHTTP, "http://localhost")
+	require.NoError(t, err)
+	assert.IsType(t, &outbox.HTTPSink{}, sink)
+
+	_, err = outbox.NewSink("kafka", "")
+	assert.ErrorIs(t, err, outbox.ErrUnknownSink)
+}
+
+func TestLogSink_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	sink := outbox.NewLogSink(log.New(&buf, "", 0))
+
+	require.NoError(t, sink.Publish(context.Background(), sampleEvents()))
+	assert.Equal(t,
+		"a transfer.created account=7 {\"id\":\"a\"}\nb entry.created account=7 {\"id\":\"b\"}\n",
+		buf.String())
+}
+
+func TestFileSink_Publish(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "events.jsonl")
+
+	sink, err := outbox.NewFileSink(path)
+	require.NoError(t, err)
+	require.NoError(t, sink.Publish(context.Background(), sampleEvents()[:1]))
+	require.NoError(t, sink.Publish(context.Background(), sampleEvents()[1:]))
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event db.Outbox
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.EventID)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"a", "b"}, ids)
+}
+
+func TestHTTPSink_Publish(t *testing.T) {
+	var received []db.Outbox
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer receiver.Close()
+
+	sink := outbox.NewHTTPSink(receiver.URL, time.Second)
+	require.NoError(t, sink.Publish(context.Background(), sampleEvents()))
+	require.Len(t, received, 2)
+	assert.Equal(t, "a", received[0].EventID)
+	assert.Equal(t, "b", received[1].EventID)
+}
+
+func TestHTTPSink_Publish_UnexpectedStatus(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	sink := outbox.NewHTTPSink(receiver.URL, time.Second)
+	assert.ErrorIs(t, sink.Publish(context.Background(), sampleEvents()), outbox.ErrUnexpectedStatus)
+}
+
+func TestMemorySink_Publish(t *testing.T) {
+	sink := outbox.NewMemorySink()
+	require.NoError(t, sink.Publish(context.Background(), sampleEvents()))
+
+	events := sink.Events()
+	assert.Equal(t, sampleEvents(), events)
+
+	// the returned events are a copy
+	events[0].EventID = "c"
+	assert.Equal(t, "a", sink.Events()[0].EventID)
+}
@@ -0,0 +1,216 @@
+// Package password hashes passwords into PHC strings and enforces the password policy.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// Argon2id hashes are PHC strings: $argon2id$v=19$m=<memory>,t=<time>,p=<threads>$<salt>$<key>.
+	Argon2id = "argon2id"
+	// Bcrypt hashes keep their native format: $2b$<cost>$<salt and key>.
+	Bcrypt = "bcrypt"
+
+	// argon2SaltSize and argon2KeySize are the lengths of the salt and the key in bytes.
+	argon2SaltSize = 16
+	argon2KeySize  = 32
+)
+
+var (
+	// ErrMismatch is returned when the password does not match the hash.
+	ErrMismatch = errors.New("password does not match")
+	// ErrUnknownAlgorithm is returned when the algorithm is neither Argon2id nor bcrypt.
+	ErrUnknownAlgorithm = errors.New("unknown password hash algorithm")
+	// ErrMalformedHash is returned when the hash can not be parsed.
+	ErrMalformedHash = errors.New("malformed password hash")
+)
+
+// b64 encodes the salt and the key of the PHC strings.
+var b64 = base64.RawStdEncoding
+
+// Params holds the algorithm and its parameters for the new hashes.
+type Params struct {
+	Algorithm string
+	// Argon2Time is the number of passes, Argon2Memory the memory in KiB
+	// and Argon2Threads the degree of parallelism of Argon2id.
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+	// BcryptCost is the logarithmic cost of bcrypt.
+	BcryptCost int
+}
+
+// Hasher hashes passwords with the current parameters. It verifies
+// the hashes of both algorithms, so the stored hashes can be upgraded
+// on the next login.
+type Hasher struct {
+	params Params
+}
+
+// NewHasher constructs a Hasher with the given parameters.
+func NewHasher(params Params) (*Hasher, error) {
+	switch params.Algorithm {
+	case Argon2id:
+		if params.Argon2Time < 1 || params.Argon2Threads < 1 || params.Argon2Memory < 8*uint32(params.Argon2Threads) {
+			return nil, fmt.Errorf("invalid Argon2id parameters: t=%d, m=%d, p=%d",
+				params.Argon2Time, params.Argon2Memory, params.Argon2Threads)
+		}
+	case Bcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost: %d", params.BcryptCost)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownAlgorithm, params.Algorithm)
+	}
+
+	return &Hasher{params: params}, nil
+}
+
+// Hash returns the hash of the password with a random salt.
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.params.Algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.params.BcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+
+		return string(hash), nil
+	}
+
+	salt := make([]byte, argon2SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return argon2Hash{
+		time:    h.params.Argon2Time,
+		memory:  h.params.Argon2Memory,
+		threads: h.params.Argon2Threads,
+		salt:    salt,
+		key: argon2.IDKey([]byte(password), salt,
+			h.params.Argon2Time, h.params.Argon2Memory, h.params.Argon2Threads, argon2KeySize),
+	}.String(), nil
+}
+
+// NeedsRehash reports whether the hash was made by another algorithm or with
+// other parameters than the current ones. Such a hash should be replaced after
+// the password is verified.
+func (h *Hasher) NeedsRehash(hash string) bool {
+	switch algorithm(hash) {
+	case Argon2id:
+		parsed, err := parseArgon2Hash(hash)
+
+		return err != nil || h.params.Algorithm != Argon2id ||
+			parsed.time != h.params.Argon2Time ||
+			parsed.memory != h.params.Argon2Memory ||
+			parsed.threads != h.params.Argon2Threads ||
+			len(parsed.salt) != argon2SaltSize ||
+			len(parsed.key) != argon2KeySize
+	case Bcrypt:
+		cost, err := bcrypt.Cost([]byte(hash))
+
+		return err != nil || h.params.Algorithm != Bcrypt || cost != h.params.BcryptCost
+	default:
+		return true
+	}
+}
+
+// Verify checks the password against the hash of either algorithm.
+// It returns ErrMismatch if the password does not match.
+func Verify(password, hash string) error {
+	switch algorithm(hash) {
+	case Argon2id:
+		parsed, err := parseArgon2Hash(hash)
+		if err != nil {
+			return err
+		}
+
+		key := argon2.IDKey([]byte(password), parsed.salt,
+			parsed.time, parsed.memory, parsed.threads, uint32(len(parsed.key)))
+		if subtle.ConstantTimeCompare(key, parsed.key) != 1 {
+			return ErrMismatch
+		}
+
+		return nil
+	case Bcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrMismatch
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrMalformedHash, err)
+		}
+
+		return nil
+	default:
+		return ErrUnknownAlgorithm
+	}
+}
+
+// algorithm returns the algorithm of the hash by its identifier.
+func algorithm(hash string) string {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return Argon2id
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		return Bcrypt
+	default:
+		return ""
+	}
+}
+
+// argon2Hash holds the parts of an Argon2id PHC string.
+type argon2Hash struct {
+	time    uint32
+	memory  uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (h argon2Hash) String() string {
+	return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s", Argon2id, argon2.Version,
+		h.memory, h.time, h.threads, b64.EncodeToString(h.salt), b64.EncodeToString(h.key))
+}
+
+func parseArgon2Hash(hash string) (argon2Hash, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return argon2Hash{}, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Hash{}, fmt.Errorf("%w: unsupported version %q", ErrMalformedHash, parts[2])
+	}
+
+	var h argon2Hash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &h.memory, &h.time, &h.threads); err != nil {
+		return argon2Hash{}, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+
+	var err error
+	if h.salt, err = b64.DecodeString(parts[4]); err != nil {
+		return argon2Hash{}, fmt.Errorf("%w: %v", ErrMalformedHash, err)
+	}
+
+	if h.key, err = b64.DecodeString(parts[5]); err != nil || len(h.key) == 0 {
+		return argon2Hash{}, ErrMalformedHash
+	}
+
+	if h.time < 1 || h.threads < 1 {
+		return argon2Hash{}, ErrMalformedHash
+	}
+
+	return h, nil
+}
@@ -0,0 +1,105 @@
+package password_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chutommy/simple-bank/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	argon2Params = password.Params{
+		Algorithm:     password.Argon2id,
+		Argon2Time:    1,
+		Argon2Memory:  64,
+		Argon2Threads: 1,
+	}
+	bcryptParams = password.Params{
+		Algorithm:  password.Bcrypt,
+		BcryptCost: 4,
+	}
+)
+
+func TestHasher(t *testing.T) {
+	for _, params := range []password.Params{argon2Params, bcryptParams} {
+		t.Run(params.Algorithm, func(t *testing.T) {
+			hasher, err := password.NewHasher(params)
+			require.NoError(t, err)
+
+			hash, err := hasher.Hash("correct horse")
+			require.NoError(t, err)
+			assert.False(t, hasher.NeedsRehash(hash))
+
+			// the salt is random
+			other, err := hasher.Hash("correct horse")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, other)
+
+			require.NoError(t, password.Verify("correct horse", hash))
+			require.ErrorIs(t, password.Verify("battery staple", hash), password.ErrMismatch)
+		})
+	}
+}
+
+func TestHasher_Argon2idFormat(t *testing.T) {
+	hasher, err := password.NewHasher(argon2Params)
+	require.NoError(t, err)
+
+	hash, err := hasher.Hash("secret")
+	require.NoError(t, err)
+
+	parts := strings.Split(hash, "$")
+	require.Len(t, parts, 6)
+	assert.Equal(t, "argon2id", parts[1])
+	assert.Equal(t, "v=19", parts[2])
+	assert.Equal(t, "m=64,t=1,p=1", parts[3])
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	argon2Hasher, err := password.NewHasher(argon2Params)
+	require.NoError(t, err)
+
+	bcryptHasher, err := password.NewHasher(bcryptParams)
+	require.NoError(t, err)
+
+	stronger := argon2Params
+	stronger.Argon2Time = 2
+	strongerHasher, err := password.NewHasher(stronger)
+	require.NoError(t, err)
+
+	argon2Hash, err := argon2Hasher.Hash("secret")
+	require.NoError(t, err)
+
+	bcryptHash, err := bcryptHasher.Hash("secret")
+	require.NoError(t, err)
+
+	// other algorithms and parameters are outdated
+	assert.True(t, argon2Hasher.NeedsRehash(bcryptHash))
+	assert.True(t, bcryptHasher.NeedsRehash(argon2Hash))
+	assert.True(t, strongerHasher.NeedsRehash(argon2Hash))
+	assert.True(t, argon2Hasher.NeedsRehash("secret"))
+
+	// the hashes of both algorithms are verified by any hasher
+	require.NoError(t, password.Verify("secret", bcryptHash))
+	require.NoError(t, password.Verify("secret", argon2Hash))
+}
+
+func TestVerify_Malformed(t *testing.T) {
+	require.ErrorIs(t, password.Verify("secret", "secret"), password.ErrUnknownAlgorithm)
+	require.ErrorIs(t, password.Verify("secret", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA"), password.ErrMalformedHash)
+	require.ErrorIs(t, password.Verify("secret", "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5"), password.ErrMalformedHash)
+	require.ErrorIs(t, password.Verify("secret", "$2b$04$short"), password.ErrMalformedHash)
+}
+
+func TestNewHasher_Invalid(t *testing.T) {
+	_, err := password.NewHasher(password.Params{Algorithm: "md5"})
+	require.ErrorIs(t, err, password.ErrUnknownAlgorithm)
+
+	_, err = password.NewHasher(password.Params{Algorithm: password.Argon2id})
+	require.Error(t, err)
+
+	_, err = password.NewHasher(password.Params{Algorithm: password.Bcrypt, BcryptCost: 50})
+	require.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrWeakPassword is returned when the password does not satisfy the policy.
+var ErrWeakPassword = errors.New("password does not satisfy the policy")
+
+// Policy holds the requirements of new passwords. The zero value accepts any password.
+type Policy struct {
+	// MinLength and MaxLength limit the number of characters, zero MaxLength
+	// does not limit the length.
+	MinLength int
+	MaxLength int
+	// RequireMixedCase requires both an upper and a lower case letter.
+	RequireMixedCase bool
+	RequireDigit     bool
+	// RequireSymbol requires a character that is neither a letter nor a digit.
+	RequireSymbol bool
+}
+
+// Check returns ErrWeakPassword wrapped with the unmet requirements
+// if the password does not satisfy the policy.
+func (p Policy) Check(password string) error {
+	var upper, lower, digit, symbol bool
+
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+
+	var unmet []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		unmet = append(unmet, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+
+	if p.MaxLength > 0 && length > p.MaxLength {
+		unmet = append(unmet, fmt.Sprintf("at most %d characters", p.MaxLength))
+	}
+
+	if p.RequireMixedCase && !(upper && lower) {
+		unmet = append(unmet, "upper and lower case letters")
+	}
+
+	if p.RequireDigit && !digit {
+		unmet = append(unmet, "a digit")
+	}
+
+	if p.RequireSymbol && !symbol {
+		unmet = append(unmet, "a symbol")
+	}
+
+	if len(unmet) > 0 {
+		return fmt.Errorf("%w: requires %s", ErrWeakPassword, strings.Join(unmet, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package password_test
+
+import (
+	"testing"
+
+	"github.com/chutommy/simple-bank/password"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	policy := password.Policy{
+		MinLength:        8,
+		MaxLength:        16,
+		RequireMixedCase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		unmet    string
+	}{
+		{name: "OK", password: "Secr3t-pass"},
+		{name: "Unicode", password: "Heslo-žluť1"},
+		{name: "Short", password: "S3c-ret", unmet: "at least 8 characters"},
+		{name: "Long", password: "S3cret-passphrase", unmet: "at most 16 characters"},
+		{name: "LowerCase", password: "secr3t-pass", unmet: "upper and lower case letters"},
+		{name: "NoDigit", password: "Secret-pass", unmet: "a digit"},
+		{name: "NoSymbol", password: "Secr3tpass", unmet: "a symbol"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := policy.Check(test.password)
+			if test.unmet == "" {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, password.ErrWeakPassword)
+			assert.Contains(t, err.Error(), test.unmet)
+		})
+	}
+
+	// the zero policy accepts any password
+	require.NoError(t, password.Policy{}.Check(""))
+}
@@ -0,0 +1,77 @@
+package rate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"time"
+)
+
+// fileRates is the format of an exchange rates file.
+type fileRates struct {
+	Base      string                 `json:"base"`
+	Timestamp time.Time              `json:"timestamp"`
+	Rates     map[string]json.Number `json:"rates"`
+}
+
+// FileSource is a Source of static exchange rates loaded from a JSON file.
+// It is meant for local use, the rates never change.
+type FileSource struct {
+	rates Rates
+}
+
+// NewFileSource constructs a new FileSource from the JSON file at path. The file
+// holds a base currency, a timestamp and the amounts of the other currencies per
+// one unit of the base currency, e.g.
+//
+//	{"base": "EUR", "timestamp": "2021-05-01T00:00:00Z", "rates": {"CZK": 25.9}}
+func NewFileSource(path string) (Source, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read exchange rates: %w", err)
+	}
+
+	var file fileRates
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("cannot parse exchange rates: %w", err)
+	}
+
+	rates := Rates{
+		Base:      file.Base,
+		Timestamp: file.Timestamp,
+		Rates:     map[string]*big.Rat{file.Base: big.NewRat(1, 1)},
+	}
+
+	for currency, number := range file.Rates {
+		rate, ok := new(big.Rat).SetString(number.String())
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate of %s: %s", currency, number)
+		}
+
+		rates.Rates[currency] = rate
+	}
+
+	return &FileSource{rates: rates}, nil
+}
+
+// Rates returns the rates of the file quoted against the base currency.
+func (s *FileSource) Rates(_ context.Context, base string) (*Rates, error) {
+	baseRate, ok := s.rates.Rates[base]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCurrency, base)
+	}
+
+	rates := &Rates{
+		Base:      base,
+		Timestamp: s.rates.Timestamp,
+		Rates:     make(map[string]*big.Rat, len(s.rates.Rates)),
+	}
+
+	for currency, rate := range s.rates.Rates {
+		rates.Rates[currency] = new(big.Rat).Quo(rate, baseRate)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,86 @@
+package rate_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/rate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRates(t *testing.T, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "rates")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "rates.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestFileSource(t *testing.T) {
+	path := writeRates(t, `{
+		"base": "EUR",
+		"timestamp": "2021-05-03T00:00:00Z",
+		"rates": {"CZK": 25, "BTC": "0.00002"}
+	}`)
+
+	source, err := rate.NewFileSource(path)
+	require.NoError(t, err)
+
+	rates, err := source.Rates(context.Background(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", rates.Base)
+	assert.Equal(t, time.Date(2021, time.May, 3, 0, 0, 0, 0, time.UTC), rates.Timestamp)
+
+	tests := []struct {
+		amount   int64
+		currency string
+		expected int64
+	}{
+		{amount: 100, currency: "EUR", expected: 100},
+		{amount: 2500, currency: "CZK", expected: 100},
+		{amount: 1262, currency: "CZK", expected: 50},
+		{amount: 1263, currency: "CZK", expected: 51},
+		{amount: -1263, currency: "CZK", expected: -51},
+		{amount: 1, currency: "BTC", expected: 50000},
+	}
+	for _, test := range tests {
+		converted, err := rates.Convert(test.amount, test.currency)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, converted, "%d %s", test.amount, test.currency)
+	}
+
+	_, err = rates.Convert(1, "USD")
+	assert.ErrorIs(t, err, rate.ErrUnknownCurrency)
+
+	// the rates are rebased to any known currency
+	rates, err = source.Rates(context.Background(), "CZK")
+	require.NoError(t, err)
+
+	converted, err := rates.Convert(2, "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), converted)
+
+	_, err = source.Rates(context.Background(), "USD")
+	assert.ErrorIs(t, err, rate.ErrUnknownCurrency)
+}
+
+func TestNewFileSource_Invalid(t *testing.T) {
+	_, err := rate.NewFileSource(filepath.Join(os.TempDir(), "missing-rates.json"))
+	assert.Error(t, err)
+
+	_, err = rate.NewFileSource(writeRates(t, `{"base": "EUR", "rates": {"CZK": 0}}`))
+	assert.Error(t, err)
+
+	_, err = rate.NewFileSource(writeRates(t, `not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package rate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrUnknownCurrency is returned when no exchange rate of a currency is known.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Source is an interface for providers of exchange rates.
+type Source interface {
+	// Rates returns the latest exchange rates quoted against the base currency.
+	Rates(ctx context.Context, base string) (*Rates, error)
+}
+
+// Rates are exchange rates quoted against a base currency.
+type Rates struct {
+	Base string
+	// Timestamp is the time the rates were published at.
+	Timestamp time.Time
+	// Rates maps currencies to their amounts per one unit of the base currency.
+	Rates map[string]*big.Rat
+}
+
+// Convert converts the amount in the currency into the base currency.
+// The result is rounded half away from zero.
+func (r *Rates) Convert(amount int64, currency string) (int64, error) {
+	if currency == r.Base {
+		return amount, nil
+	}
+
+	rate, ok := r.Rates[currency]
+	if !ok || rate.Sign() <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+
+	x := new(big.Rat).SetInt64(amount)
+	x.Quo(x, rate)
+
+	// round half away from zero
+	num, denom := x.Num(), x.Denom()
+	q, m := new(big.Int).QuoRem(num, denom, new(big.Int))
+	if m.Abs(m).Lsh(m, 1).Cmp(denom) >= 0 {
+		q.Add(q, big.NewInt(int64(num.Sign())))
+	}
+
+	if !q.IsInt64() {
+		return 0, fmt.Errorf("conversion of %d %s overflows", amount, currency)
+	}
+
+	return q.Int64(), nil
+}
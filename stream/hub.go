@@ -0,0 +1,81 @@
+package stream
+
+import (
+	"sync"
+)
+
+// Broker notifies subscribers about new events of accounts.
+type Broker interface {
+	// Subscribe returns a channel receiving a value whenever there may be new events
+	// of the account. Notifications are coalesced, so a subscriber is expected to
+	// fetch all the events it has not seen yet on every receive. The returned
+	// function cancels the subscription.
+	Subscribe(accountID int64) (<-chan struct{}, func())
+}
+
+// Hub is a Broker fanning out the notifications of the accounts in the process.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[int64]map[chan struct{}]struct{}
+}
+
+// NewHub constructs a new Hub.
+func NewHub() *Hub {
+	return &Hub{subs: make(map[int64]map[chan struct{}]struct{})}
+}
+
+// Subscribe subscribes to the notifications of the account.
+func (h *Hub) Subscribe(accountID int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subs[accountID] == nil {
+		h.subs[accountID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[accountID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			delete(h.subs[accountID], ch)
+			if len(h.subs[accountID]) == 0 {
+				delete(h.subs, accountID)
+			}
+		})
+	}
+}
+
+// Notify wakes up the subscribers of the account.
+func (h *Hub) Notify(accountID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[accountID] {
+		wake(ch)
+	}
+}
+
+// NotifyAll wakes up all subscribers, e.g. after notifications might have been lost.
+func (h *Hub) NotifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subs {
+		for ch := range subs {
+			wake(ch)
+		}
+	}
+}
+
+// wake sends to the channel unless a notification is already pending.
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
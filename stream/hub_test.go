@@ -0,0 +1,48 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/chutommy/simple-bank/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+// woken reports whether a notification is pending on the channel.
+func woken(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHub_Notify(t *testing.T) {
+	hub := stream.NewHub()
+
+	a1, cancelA1 := hub.Subscribe(1)
+	a2, cancelA2 := hub.Subscribe(1)
+	b, cancelB := hub.Subscribe(2)
+
+	defer cancelA2()
+	defer cancelB()
+
+	// notifications are coalesced
+	hub.Notify(1)
+	hub.Notify(1)
+	assert.True(t, woken(a1))
+	assert.False(t, woken(a1))
+	assert.True(t, woken(a2))
+	assert.False(t, woken(b))
+
+	// cancelled subscriptions are not notified
+	cancelA1()
+	cancelA1()
+	hub.Notify(1)
+	assert.False(t, woken(a1))
+	assert.True(t, woken(a2))
+
+	hub.NotifyAll()
+	assert.True(t, woken(a2))
+	assert.True(t, woken(b))
+}
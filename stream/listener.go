@@ -0,0 +1,64 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/chutommy/simple-bank/db/sqlc"
+	"github.com/lib/pq"
+)
+
+// pingInterval is the period of the health checks of an idle listener connection.
+const pingInterval = 90 * time.Second
+
+// Listen listens to the account event notifications published by the store
+// and forwards them to the hub until the context is cancelled. Every instance
+// of the service listens on its own, so the subscribers are notified about
+// the events committed by any of them.
+func Listen(ctx context.Context, dataSource string, hub *Hub) error {
+	listener := pq.NewListener(dataSource, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("account events listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(db.AccountEventsChannel); err != nil {
+		return fmt.Errorf("failed to listen to %s: %w", db.AccountEventsChannel, err)
+	}
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n := <-listener.Notify:
+			// the connection was re-established, notifications might have been lost
+			if n == nil {
+				hub.NotifyAll()
+
+				continue
+			}
+
+			var notification db.AccountEventNotification
+			if err := json.Unmarshal([]byte(n.Extra), &notification); err != nil {
+				log.Printf("invalid account event notification %q: %v", n.Extra, err)
+
+				continue
+			}
+
+			hub.Notify(notification.AccountID)
+		case <-ticker.C:
+			go func() {
+				if err := listener.Ping(); err != nil {
+					log.Printf("account events listener ping failed: %v", err)
+				}
+			}()
+		}
+	}
+}
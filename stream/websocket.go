@@ -0,0 +1,279 @@
+package stream
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketGUID is appended to the key of a WebSocket handshake (RFC 6455).
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes of the WebSocket frames.
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+const (
+	// maxFrameSize limits the payload of the frames received from a client.
+	maxFrameSize = 1 << 16
+	// writeTimeout limits writing a single frame.
+	writeTimeout = 10 * time.Second
+)
+
+var (
+	// ErrNotWebSocket is returned when the request is not a WebSocket handshake.
+	ErrNotWebSocket = errors.New("not a websocket handshake")
+	// ErrUnsupportedVersion is returned when the client requests an unsupported WebSocket version.
+	ErrUnsupportedVersion = errors.New("unsupported websocket version")
+	// ErrUnmaskedFrame is returned when a client sends an unmasked frame.
+	ErrUnmaskedFrame = errors.New("client frames must be masked")
+	// ErrFrameTooLarge is returned when a client sends a frame larger than maxFrameSize.
+	ErrFrameTooLarge = errors.New("websocket frame too large")
+	// ErrConnClosed is returned when writing to a closed connection.
+	ErrConnClosed = errors.New("websocket connection closed")
+)
+
+// IsWebSocket reports whether the request asks for a WebSocket upgrade.
+func IsWebSocket(r *http.Request) bool {
+	return headerContains(r.Header, "Connection", "upgrade") &&
+		headerContains(r.Header, "Upgrade", "websocket")
+}
+
+// headerContains reports whether the comma separated header contains the token.
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// AcceptKey computes the Sec-WebSocket-Accept header for the Sec-WebSocket-Key of a handshake.
+func AcceptKey(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// Conn is a server side WebSocket connection meant for pushing messages to the client.
+// Pings are answered and the messages sent by the client are discarded.
+type Conn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+
+	mu        sync.Mutex // guards writing
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Upgrade takes over the connection of the request and completes the WebSocket handshake.
+// The error response is written if the request is not a valid handshake.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || !IsWebSocket(r) || key == "" {
+		http.Error(w, ErrNotWebSocket.Error(), http.StatusBadRequest)
+
+		return nil, ErrNotWebSocket
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, ErrUnsupportedVersion.Error(), http.StatusUpgradeRequired)
+
+		return nil, ErrUnsupportedVersion
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket not supported", http.StatusInternalServerError)
+
+		return nil, fmt.Errorf("%w: connection cannot be hijacked", ErrNotWebSocket)
+	}
+
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hijack the connection: %w", err)
+	}
+
+	_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		_ = conn.Close()
+
+		return nil, fmt.Errorf("failed to complete the handshake: %w", err)
+	}
+
+	c := &Conn{conn: conn, rw: rw, done: make(chan struct{})}
+	go c.readLoop()
+
+	return c, nil
+}
+
+// Done is closed when the connection is closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// WriteText sends a text message.
+func (c *Conn) WriteText(p []byte) error {
+	return c.writeFrame(opText, p)
+}
+
+// Ping sends a ping, the client is expected to answer it with a pong.
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close sends a normal closure frame and closes the connection.
+func (c *Conn) Close() error {
+	_ = c.writeFrame(opClose, []byte{0x03, 0xE8}) // 1000
+
+	return c.close()
+}
+
+func (c *Conn) close() error {
+	var err error
+
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.conn.Close()
+	})
+
+	return err
+}
+
+// writeFrame writes a single unmasked final frame.
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return ErrConnClosed
+	default:
+	}
+
+	header := []byte{0x80 | opcode}
+
+	switch n := len(payload); {
+	case n < 126:
+		header = append(header, byte(n))
+	case n <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	if _, err := c.rw.Write(header); err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+
+	if _, err := c.rw.Write(payload); err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+
+	if err := c.rw.Flush(); err != nil {
+		return fmt.Errorf("failed to write frame: %w", err)
+	}
+
+	return nil
+}
+
+// readLoop reads the frames of the client until the connection is closed.
+func (c *Conn) readLoop() {
+	defer c.close()
+
+	for {
+		opcode, payload, err := c.readFrame()
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return
+			}
+		case opClose:
+			_ = c.writeFrame(opClose, payload)
+
+			return
+		}
+	}
+}
+
+// readFrame reads a single frame of the client and unmasks its payload.
+func (c *Conn) readFrame() (byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+		return 0, nil, err
+	}
+
+	opcode := header[0] & 0x0F
+
+	if header[1]&0x80 == 0 {
+		return 0, nil, ErrUnmaskedFrame
+	}
+
+	size := uint64(header[1] & 0x7F)
+
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return 0, nil, err
+		}
+
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if size > maxFrameSize {
+		return 0, nil, ErrFrameTooLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return 0, nil, err
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return opcode, payload, nil
+}
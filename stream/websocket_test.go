@@ -0,0 +1,127 @@
+package stream_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// example of RFC 6455
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", stream.AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+// readFrame reads a single unmasked frame sent by the server.
+func readFrame(t *testing.T, r io.Reader) (byte, []byte) {
+	t.Helper()
+
+	var header [2]byte
+	_, err := io.ReadFull(r, header[:])
+	require.NoError(t, err)
+	require.Zero(t, header[1]&0x80, "server frames must not be masked")
+
+	size := int(header[1] & 0x7F)
+	if size == 126 {
+		var ext [2]byte
+		_, err = io.ReadFull(r, ext[:])
+		require.NoError(t, err)
+
+		size = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(r, payload)
+	require.NoError(t, err)
+
+	return header[0] & 0x0F, payload
+}
+
+// writeFrame writes a single masked frame as a client.
+func writeFrame(t *testing.T, w io.Writer, opcode byte, payload []byte) {
+	t.Helper()
+
+	mask := []byte{1, 2, 3, 4}
+	frame := append([]byte{0x80 | opcode, 0x80 | byte(len(payload))}, mask...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	_, err := w.Write(frame)
+	require.NoError(t, err)
+}
+
+func TestUpgrade(t *testing.T) {
+	upgraded := make(chan *stream.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := stream.Upgrade(w, r)
+		if err == nil {
+			upgraded <- conn
+		}
+	}))
+	defer server.Close()
+
+	// not a handshake
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	client, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+
+	defer client.Close()
+
+	_ = client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	_, err = io.WriteString(client, "GET / HTTP/1.1\r\n"+
+		"Host: localhost\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	require.NoError(t, err)
+
+	r := bufio.NewReader(client)
+	resp, err = http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	conn := <-upgraded
+
+	// messages
+	require.NoError(t, conn.WriteText([]byte(`{"id":1}`)))
+	opcode, payload := readFrame(t, r)
+	assert.Equal(t, byte(0x1), opcode)
+	assert.Equal(t, `{"id":1}`, string(payload))
+
+	// pings are answered
+	writeFrame(t, client, 0x9, []byte("ping"))
+	opcode, payload = readFrame(t, r)
+	assert.Equal(t, byte(0xA), opcode)
+	assert.Equal(t, "ping", string(payload))
+
+	// the closing handshake closes the connection
+	writeFrame(t, client, 0x8, []byte{0x03, 0xE8})
+	opcode, _ = readFrame(t, r)
+	assert.Equal(t, byte(0x8), opcode)
+
+	select {
+	case <-conn.Done():
+	case <-time.After(time.Second):
+		t.Fatal("connection not closed")
+	}
+
+	assert.ErrorIs(t, conn.WriteText([]byte("{}")), stream.ErrConnClosed)
+}
@@ -0,0 +1,51 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// apiKeyType starts every API key, so leaked keys are easy to recognize.
+	apiKeyType = "sbk"
+	// apiKeyPrefixSize is the number of random bytes of the public prefix of an API key.
+	apiKeyPrefixSize = 8
+)
+
+// ErrInvalidAPIKey is returned when the API key is malformed.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// NewAPIKeyPrefix generates a new public prefix identifying an API key.
+func NewAPIKeyPrefix() (string, error) {
+	prefix := make([]byte, apiKeyPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return "", fmt.Errorf("failed to generate API key prefix: %w", err)
+	}
+
+	return hex.EncodeToString(prefix), nil
+}
+
+// NewAPIKey generates a new secret of the API key with the prefix. It returns
+// the key in the form sbk_<prefix>_<secret> and the hash of the secret
+// to be stored instead of the secret itself.
+func NewAPIKey(prefix string) (string, string, error) {
+	secret, secretHash, err := NewOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	return apiKeyType + "_" + prefix + "_" + secret, secretHash, nil
+}
+
+// ParseAPIKey splits the API key into its prefix and the hash of its secret.
+func ParseAPIKey(key string) (string, string, error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyType || parts[1] == "" || parts[2] == "" {
+		return "", "", ErrInvalidAPIKey
+	}
+
+	return parts[1], HashOpaqueToken(parts[2]), nil
+}
@@ -0,0 +1,36 @@
+package token_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chutommy/simple-bank/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKey(t *testing.T) {
+	prefix, err := token.NewAPIKeyPrefix()
+	require.NoError(t, err)
+	assert.Len(t, prefix, 16)
+
+	key, secretHash, err := token.NewAPIKey(prefix)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "sbk_"+prefix+"_"))
+
+	parsedPrefix, parsedHash, err := token.ParseAPIKey(key)
+	require.NoError(t, err)
+	assert.Equal(t, prefix, parsedPrefix)
+	assert.Equal(t, secretHash, parsedHash)
+
+	// a rotated key keeps the prefix but not the secret
+	rotated, rotatedHash, err := token.NewAPIKey(prefix)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, rotated)
+	assert.NotEqual(t, secretHash, rotatedHash)
+
+	for _, invalid := range []string{"", "sbk_" + prefix, "xyz_" + prefix + "_secret", "sbk__secret"} {
+		_, _, err := token.ParseAPIKey(invalid)
+		assert.ErrorIs(t, err, token.ErrInvalidAPIKey, invalid)
+	}
+}
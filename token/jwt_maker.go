@@ -0,0 +1,94 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// minSecretKeySize is the minimal length of the JWT signing key.
+const minSecretKeySize = 32
+
+// jwtHeader is the only header JWTMaker issues and accepts.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// JWTMaker is a JSON Web Token maker signing tokens with HMAC-SHA256.
+type JWTMaker struct {
+	secretKey string
+}
+
+// NewJWTMaker constructs a new JWTMaker.
+func NewJWTMaker(secretKey string) (Maker, error) {
+	if len(secretKey) < minSecretKeySize {
+		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+	}
+
+	return &JWTMaker{secretKey: secretKey}, nil
+}
+
+// CreateToken creates a new signed token for the given username, role, session family and duration.
+func (m *JWTMaker) CreateToken(username string, role string, familyID string, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, role, familyID, duration)
+	if err != nil {
+		return "", err
+	}
+
+	return m.sign(payload)
+}
+
+// VerifyToken checks the signature and the expiration of the token.
+func (m *JWTMaker) VerifyToken(token string) (*Payload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if !hmac.Equal(signature, m.signature(parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidToken
+	}
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(claims, &payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+
+	return &payload, nil
+}
+
+// sign serializes the payload and appends its signature.
+func (m *JWTMaker) sign(payload interface{}) (string, error) {
+	claims, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize the payload: %w", err)
+	}
+
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(m.signature(unsigned)), nil
+}
+
+// signature computes the HMAC-SHA256 signature of the data.
+func (m *JWTMaker) signature(data string) []byte {
+	mac := hmac.New(sha256.New, []byte(m.secretKey))
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,83 @@
+package token_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/token"
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTMaker(t *testing.T) {
+	maker, err := token.NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	username := util.RandomOwner()
+	role := util.RandomString(6)
+	familyID := util.RandomString(32)
+	duration := time.Minute
+
+	tkn, err := maker.CreateToken(username, role, familyID, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, tkn)
+
+	payload, err := maker.VerifyToken(tkn)
+	require.NoError(t, err)
+
+	if assert.NotNil(t, payload) {
+		assert.NotEmpty(t, payload.ID)
+		assert.Equal(t, username, payload.Username)
+		assert.Equal(t, role, payload.Role)
+		assert.Equal(t, familyID, payload.FamilyID)
+		assert.WithinDuration(t, time.Now(), payload.IssuedAt, time.Second)
+		assert.WithinDuration(t, time.Now().Add(duration), payload.ExpiredAt, time.Second)
+	}
+}
+
+func TestJWTMaker_ExpiredToken(t *testing.T) {
+	maker, err := token.NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	tkn, err := maker.CreateToken(util.RandomOwner(), util.RandomString(6), "", -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(tkn)
+	assert.ErrorIs(t, err, token.ErrExpiredToken)
+	assert.Nil(t, payload)
+}
+
+func TestJWTMaker_InvalidToken(t *testing.T) {
+	maker1, err := token.NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	maker2, err := token.NewJWTMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	tkn, err := maker1.CreateToken(util.RandomOwner(), util.RandomString(6), "", time.Minute)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "OtherKey", token: tkn},
+		{name: "Malformed", token: strings.Replace(tkn, ".", "", 1)},
+		{name: "Empty", token: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := maker2.VerifyToken(test.token)
+			assert.ErrorIs(t, err, token.ErrInvalidToken)
+			assert.Nil(t, payload)
+		})
+	}
+}
+
+func TestNewJWTMaker_ShortKey(t *testing.T) {
+	_, err := token.NewJWTMaker(util.RandomString(31))
+	assert.Error(t, err)
+}
@@ -0,0 +1,13 @@
+package token
+
+import (
+	"time"
+)
+
+// Maker is an interface for managing access tokens.
+type Maker interface {
+	// CreateToken creates a new token for the given username, role, session family and duration.
+	CreateToken(username string, role string, familyID string, duration time.Duration) (string, error)
+	// VerifyToken checks whether the token is valid and returns its payload.
+	VerifyToken(token string) (*Payload, error)
+}
@@ -0,0 +1,34 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// opaqueTokenSize is the number of random bytes of an opaque token.
+const opaqueTokenSize = 32
+
+// NewOpaqueToken generates a new opaque token, such as a refresh token or an email
+// verification token. It returns the token for the client and its hash to be stored
+// instead of the token itself.
+func NewOpaqueToken() (string, string, error) {
+	secret := make([]byte, opaqueTokenSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("failed to generate opaque token: %w", err)
+	}
+
+	opaqueToken := base64.RawURLEncoding.EncodeToString(secret)
+
+	return opaqueToken, HashOpaqueToken(opaqueToken), nil
+}
+
+// HashOpaqueToken returns the SHA-256 hash of the opaque token. The tokens are
+// random, so a plain hash is enough to protect them at rest.
+func HashOpaqueToken(opaqueToken string) string {
+	sum := sha256.Sum256([]byte(opaqueToken))
+
+	return hex.EncodeToString(sum[:])
+}
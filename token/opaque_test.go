@@ -0,0 +1,22 @@
+package token_test
+
+import (
+	"testing"
+
+	"github.com/chutommy/simple-bank/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOpaqueToken(t *testing.T) {
+	opaqueToken1, hash1, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+	assert.Len(t, opaqueToken1, 43)
+	assert.Equal(t, token.HashOpaqueToken(opaqueToken1), hash1)
+	assert.NotEqual(t, opaqueToken1, hash1)
+
+	opaqueToken2, hash2, err := token.NewOpaqueToken()
+	require.NoError(t, err)
+	assert.NotEqual(t, opaqueToken1, opaqueToken2)
+	assert.NotEqual(t, hash1, hash2)
+}
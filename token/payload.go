@@ -0,0 +1,56 @@
+package token
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned when the token can not be verified.
+	ErrInvalidToken = errors.New("token is invalid")
+	// ErrExpiredToken is returned when the token is no longer valid.
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+// Payload contains the data carried by a token.
+type Payload struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// FamilyID is the session family the token was issued for, empty if it was
+	// not issued for a session.
+	FamilyID  string    `json:"family_id"`
+	IssuedAt  time.Time `json:"issued_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// NewPayload constructs a new Payload for the given username, role, session family and duration.
+func NewPayload(username string, role string, familyID string, duration time.Duration) (*Payload, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	now := time.Now()
+
+	return &Payload{
+		ID:        hex.EncodeToString(id),
+		Username:  username,
+		Role:      role,
+		FamilyID:  familyID,
+		IssuedAt:  now,
+		ExpiredAt: now.Add(duration),
+	}, nil
+}
+
+// Valid checks whether the payload has not expired yet.
+func (p *Payload) Valid() error {
+	if time.Now().After(p.ExpiredAt) {
+		return ErrExpiredToken
+	}
+
+	return nil
+}
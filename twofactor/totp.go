@@ -0,0 +1,132 @@
+package twofactor
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+const (
+	// period is the number of seconds a TOTP code is valid for.
+	period = 30
+	// skew is the number of periods before and after the current one
+	// whose codes are accepted, to tolerate clock drifts.
+	skew = 1
+	// qrCodeSize is the width and the height of the QR code image in pixels.
+	qrCodeSize = 256
+
+	// RecoveryCodeCount is the number of recovery codes issued at once.
+	RecoveryCodeCount = 10
+	// recoveryCodeSize is the number of random bytes of a recovery code.
+	recoveryCodeSize = 10
+)
+
+var validateOpts = totp.ValidateOpts{
+	Period:    period,
+	Digits:    otp.DigitsSix,
+	Algorithm: otp.AlgorithmSHA1,
+}
+
+// Enrollment holds a new TOTP secret and its provisioning URI for authenticator apps.
+type Enrollment struct {
+	Secret string
+	// URI is the otpauth:// provisioning URI of the secret.
+	URI string
+	// QRCode is a PNG image of the QR code encoding the URI.
+	QRCode []byte
+}
+
+// NewEnrollment generates a new TOTP secret (RFC 6238) of the account labeled
+// with the issuer, with the default parameters of the authenticator apps.
+func NewEnrollment(issuer, account string) (Enrollment, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+		Period:      period,
+		Digits:      validateOpts.Digits,
+		Algorithm:   validateOpts.Algorithm,
+	})
+	if err != nil {
+		return Enrollment{}, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	img, err := key.Image(qrCodeSize, qrCodeSize)
+	if err != nil {
+		return Enrollment{}, fmt.Errorf("failed to generate QR code: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return Enrollment{}, fmt.Errorf("failed to encode QR code: %w", err)
+	}
+
+	return Enrollment{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: buf.Bytes(),
+	}, nil
+}
+
+// Validate checks the TOTP code of the secret at the given time. It returns
+// the time step of the matching code, so a code can be rejected once its step
+// has been used.
+func Validate(secret, code string, now time.Time) (int64, bool) {
+	if len(code) != validateOpts.Digits.Length() {
+		return 0, false
+	}
+
+	for i := -skew; i <= skew; i++ {
+		t := now.Add(time.Duration(i) * period * time.Second)
+
+		expected, err := totp.GenerateCodeCustom(secret, t, validateOpts)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return t.Unix() / period, true
+		}
+	}
+
+	return 0, false
+}
+
+// NewRecoveryCodes generates RecoveryCodeCount single-use recovery codes.
+// It returns the codes for the user and their hashes to be stored instead.
+func NewRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+
+	for i := range codes {
+		secret := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+
+		// 16 characters in groups of four, e.g. ABCD-EFGH-IJKL-MNOP
+		code := base32.StdEncoding.EncodeToString(secret)
+		codes[i] = code[:4] + "-" + code[4:8] + "-" + code[8:12] + "-" + code[12:]
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode returns the SHA-256 hash of the recovery code. The case
+// and the separators of the code do not matter.
+func HashRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,75 @@
+package twofactor_test
+
+import (
+	"bytes"
+	"image/png"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/twofactor"
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEnrollment(t *testing.T) {
+	enrollment, err := twofactor.NewEnrollment("Simple Bank", "john")
+	require.NoError(t, err)
+	assert.NotEmpty(t, enrollment.Secret)
+
+	u, err := url.Parse(enrollment.URI)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, enrollment.Secret, u.Query().Get("secret"))
+	assert.Equal(t, "Simple Bank", u.Query().Get("issuer"))
+
+	img, err := png.Decode(bytes.NewReader(enrollment.QRCode))
+	require.NoError(t, err)
+	assert.Equal(t, 256, img.Bounds().Dx())
+}
+
+func TestValidate(t *testing.T) {
+	enrollment, err := twofactor.NewEnrollment("Simple Bank", "john")
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+
+	code, err := totp.GenerateCode(enrollment.Secret, now)
+	require.NoError(t, err)
+
+	step, ok := twofactor.Validate(enrollment.Secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	// the code of the previous period is still accepted
+	step, ok = twofactor.Validate(enrollment.Secret, code, now.Add(30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	_, ok = twofactor.Validate(enrollment.Secret, code, now.Add(2*time.Minute))
+	assert.False(t, ok)
+
+	_, ok = twofactor.Validate(enrollment.Secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, hashes, err := twofactor.NewRecoveryCodes()
+	require.NoError(t, err)
+	require.Len(t, codes, twofactor.RecoveryCodeCount)
+	require.Len(t, hashes, twofactor.RecoveryCodeCount)
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		assert.Len(t, code, 19)
+		assert.Equal(t, twofactor.HashRecoveryCode(code), hashes[i])
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	// the case and the separators do not matter
+	assert.Equal(t, hashes[0], twofactor.HashRecoveryCode(strings.ToLower(strings.ReplaceAll(codes[0], "-", " "))))
+}
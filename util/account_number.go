@@ -0,0 +1,77 @@
+package util
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Prefix of generated account numbers. The country code is from the user-assigned
+// range of ISO 3166, the bank code identifies the bank within the country.
+const (
+	AccountNumberCountry = "XB"
+	AccountNumberBank    = "1000"
+)
+
+// accountNumberDigits is the number of random digits identifying the account.
+const accountNumberDigits = 12
+
+// AccountNumberLength is the length of account numbers generated by NewAccountNumber.
+const AccountNumberLength = len(AccountNumberCountry) + 2 + len(AccountNumberBank) + accountNumberDigits
+
+// NewAccountNumber generates a random IBAN-style account number
+// of the country and the bank with valid check digits.
+func NewAccountNumber() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1e12))
+	if err != nil {
+		return "", err
+	}
+
+	digits := strconv.FormatInt(n.Int64(), 10)
+	bban := AccountNumberBank + strings.Repeat("0", accountNumberDigits-len(digits)) + digits
+
+	return AccountNumberCountry + accountNumberCheckDigits(AccountNumberCountry, bban) + bban, nil
+}
+
+// ValidAccountNumber reports whether the number is a well formed account number
+// with valid check digits. It does not check whether the account exists.
+func ValidAccountNumber(number string) bool {
+	if len(number) != AccountNumberLength || !strings.HasPrefix(number, AccountNumberCountry) {
+		return false
+	}
+
+	for _, r := range number[2:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	// the number with the first four characters moved to the end must be 1 mod 97
+	return mod97(number[4:]+number[:4]) == 1
+}
+
+// accountNumberCheckDigits computes the two check digits of the basic account
+// number in the country by the ISO 7064 mod-97 algorithm.
+func accountNumberCheckDigits(country, bban string) string {
+	check := 98 - mod97(bban+country+"00")
+
+	return strconv.Itoa(check/10) + strconv.Itoa(check%10)
+}
+
+// mod97 returns the remainder of the division of the number by 97. Letters
+// are converted to numbers, A = 10 up to Z = 35.
+func mod97(s string) int {
+	var rem int
+
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A'+10)) % 97
+		}
+	}
+
+	return rem
+}
@@ -0,0 +1,56 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chutommy/simple-bank/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAccountNumber(t *testing.T) {
+	number1, err := util.NewAccountNumber()
+	require.NoError(t, err)
+	assert.Len(t, number1, util.AccountNumberLength)
+	assert.True(t, strings.HasPrefix(number1, util.AccountNumberCountry))
+	assert.Equal(t, util.AccountNumberBank, number1[4:8])
+	assert.True(t, util.ValidAccountNumber(number1))
+
+	number2, err := util.NewAccountNumber()
+	require.NoError(t, err)
+	assert.NotEqual(t, number1, number2)
+}
+
+func TestValidAccountNumber(t *testing.T) {
+	number, err := util.NewAccountNumber()
+	require.NoError(t, err)
+
+	// a single mistyped digit
+	typo := []byte(number)
+	typo[10] = '0' + (typo[10]-'0'+1)%10
+
+	// two swapped adjacent digits
+	swapped := []byte(number)
+	swapped[10], swapped[11] = swapped[11], swapped[10]
+
+	tests := []struct {
+		name   string
+		number string
+		valid  bool
+	}{
+		{name: "Generated", number: number, valid: true},
+		{name: "Typo", number: string(typo), valid: false},
+		{name: "Swapped", number: string(swapped), valid: swapped[10] == swapped[11]},
+		{name: "Short", number: number[:len(number)-1], valid: false},
+		{name: "Lowercase", number: strings.ToLower(number), valid: false},
+		{name: "Letters", number: number[:8] + "ABCDEFGHIJKL", valid: false},
+		{name: "Empty", number: "", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.valid, util.ValidAccountNumber(test.number))
+		})
+	}
+}
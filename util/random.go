@@ -16,8 +16,8 @@ func RandomInt(min, max int64) int64 {
 	return min + rand.Int63n(max-min)
 }
 
-// randomString returns a string of the given length n.
-func randomString(n int) string {
+// RandomString returns a random string of the given length n.
+func RandomString(n int) string {
 	l := len(alphabet)
 	bs := make([]byte, n)
 
@@ -30,7 +30,7 @@ func randomString(n int) string {
 
 // RandomOwner returns random owner.
 func RandomOwner() string {
-	return randomString(8)
+	return RandomString(8)
 }
 
 // RandomBalance returns random balance.
@@ -51,5 +51,5 @@ func RandomAmount() int64 {
 
 // RandomEmail generates random email.
 func RandomEmail() string {
-	return randomString(12) + "email.com"
+	return RandomString(12) + "email.com"
 }
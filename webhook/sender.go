@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// maxBackoff caps the delay between two attempts of a delivery.
+const maxBackoff = 24 * time.Hour
+
+var (
+	// ErrUnexpectedStatus is returned when the receiver does not respond with a 2xx status.
+	ErrUnexpectedStatus = errors.New("unexpected response status")
+	// ErrInternalAddress is returned when the webhook URL resolves to an internal address.
+	ErrInternalAddress = errors.New("webhooks can not be sent to internal addresses")
+)
+
+// internalNetworks are the loopback, private, link-local (including the cloud
+// metadata endpoints) and other special-purpose networks.
+var internalNetworks = parseCIDRs(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/3",
+	"::/128", "::1/128", "64:ff9b::/96", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+func parseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+
+		networks[i] = network
+	}
+
+	return networks
+}
+
+// IsInternalIP reports whether the IP address belongs to an internal network.
+func IsInternalIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+
+	for _, network := range internalNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Request is a signed webhook request.
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID int64
+	EventType  string
+	Payload    []byte
+}
+
+// Sender posts signed webhook requests.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender constructs a new Sender with the given request timeout. Unless allowInternal
+// is set, the Sender refuses to connect to internal addresses. The address is checked
+// after it is resolved, so a host name can not be pointed at an internal address later.
+// Redirects are not followed.
+func NewSender(timeout time.Duration, allowInternal bool) *Sender {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowInternal {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || IsInternalIP(ip) {
+				return fmt.Errorf("%w: %s", ErrInternalAddress, host)
+			}
+
+			return nil
+		}
+	}
+
+	return &Sender{client: &http.Client{
+		Timeout: timeout,
+		// no proxy, the receiver is dialed directly
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Send posts the payload of the request to its URL. It returns the status
+// of the response, or 0 if none was received, and an error if the request
+// has not been accepted with a 2xx status.
+func (s *Sender) Send(ctx context.Context, r Request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "simple-bank-webhooks")
+	req.Header.Set(EventHeader, r.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(r.DeliveryID, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(r.Secret, timestamp, r.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+
+	// drain the body, so the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Backoff returns the delay before the next attempt of a delivery which failed
+// the given number of times. The delay doubles with every attempt.
+func Backoff(base time.Duration, attempts int32) time.Duration {
+	delay := base
+	for i := int32(1); i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxBackoff {
+		return maxBackoff
+	}
+
+	return delay
+}
@@ -0,0 +1,132 @@
+package webhook_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chutommy/simple-bank/util"
+	"github.com/chutommy/simple-bank/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender_Send(t *testing.T) {
+	secret := util.RandomString(32)
+	payload := []byte(`{"id":"1","type":"transfer.created"}`)
+
+	var received *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sender := webhook.NewSender(time.Second, true)
+	code, err := sender.Send(context.Background(), webhook.Request{
+		URL:        receiver.URL,
+		Secret:     secret,
+		DeliveryID: 7,
+		EventType:  "transfer.created",
+		Payload:    payload,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, code)
+
+	require.NotNil(t, received)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "transfer.created", received.Header.Get(webhook.EventHeader))
+	assert.Equal(t, "7", received.Header.Get(webhook.DeliveryHeader))
+	assert.Equal(t, payload, body)
+
+	timestamp, err := strconv.ParseInt(received.Header.Get(webhook.TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+
+	signature := received.Header.Get(webhook.SignatureHeader)
+	assert.True(t, webhook.Verify(secret, signature, timestamp, body))
+	assert.False(t, webhook.Verify(util.RandomString(32), signature, timestamp, body))
+	assert.False(t, webhook.Verify(secret, signature, timestamp+1, body))
+}
+
+func TestSender_Send_Failure(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	sender := webhook.NewSender(time.Second, true)
+	req := webhook.Request{URL: receiver.URL, Secret: "secret", Payload: []byte("{}")}
+
+	code, err := sender.Send(context.Background(), req)
+	assert.ErrorIs(t, err, webhook.ErrUnexpectedStatus)
+	assert.Equal(t, http.StatusInternalServerError, code)
+
+	// no response at all
+	receiver.Close()
+
+	code, err = sender.Send(context.Background(), req)
+	assert.Error(t, err)
+	assert.Zero(t, code)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhook.Backoff(30*time.Second, 1))
+	assert.Equal(t, time.Minute, webhook.Backoff(30*time.Second, 2))
+	assert.Equal(t, 4*time.Minute, webhook.Backoff(30*time.Second, 4))
+	assert.Equal(t, 24*time.Hour, webhook.Backoff(30*time.Second, 40))
+}
+
+func TestSender_Send_Internal(t *testing.T) {
+	received := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer receiver.Close()
+
+	sender := webhook.NewSender(time.Second, false)
+	code, err := sender.Send(context.Background(), webhook.Request{URL: receiver.URL, Payload: []byte("{}")})
+	assert.ErrorIs(t, err, webhook.ErrInternalAddress)
+	assert.Zero(t, code)
+	assert.False(t, received)
+}
+
+func TestSender_Send_Redirect(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer receiver.Close()
+
+	// redirects are not followed
+	sender := webhook.NewSender(time.Second, true)
+	code, err := sender.Send(context.Background(), webhook.Request{URL: receiver.URL, Payload: []byte("{}")})
+	assert.ErrorIs(t, err, webhook.ErrUnexpectedStatus)
+	assert.Equal(t, http.StatusTemporaryRedirect, code)
+}
+
+func TestIsInternalIP(t *testing.T) {
+	for _, ip := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "::1", "::ffff:127.0.0.1", "fd00:ec2::254", "fe80::1",
+	} {
+		assert.True(t, webhook.IsInternalIP(net.ParseIP(ip)), ip)
+	}
+
+	for _, ip := range []string{"93.184.216.34", "8.8.8.8", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.False(t, webhook.IsInternalIP(net.ParseIP(ip)), ip)
+	}
+}
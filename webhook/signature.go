@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers of a webhook request.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// signaturePrefix identifies the algorithm of a signature.
+const signaturePrefix = "sha256="
+
+// Sign computes the signature of the payload sent at the Unix timestamp. The signature
+// is the hex encoded HMAC-SHA256 of the timestamp, a dot and the payload keyed by the secret.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks whether the signature of the payload sent at the Unix timestamp is valid.
+func Verify(secret, signature string, timestamp int64, payload []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, payload)))
+}
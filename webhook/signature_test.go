@@ -0,0 +1,24 @@
+package webhook_test
+
+import (
+	"testing"
+
+	"github.com/chutommy/simple-bank/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	// signature computed independently for the known inputs
+	assert.Equal(t,
+		"sha256=4de1b55993ef1e88b33397d145aef387b2cc39c5b78bd4300ced725aeffb8e0b",
+		webhook.Sign("secret", 1620000000, []byte(`{}`)))
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"id":"1"}`)
+	signature := webhook.Sign("secret", 1620000000, payload)
+
+	assert.True(t, webhook.Verify("secret", signature, 1620000000, payload))
+	assert.False(t, webhook.Verify("secret", signature, 1620000000, []byte(`{"id":"2"}`)))
+	assert.False(t, webhook.Verify("secret", "sha256=00", 1620000000, payload))
+}